@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// DeliveryResponseError represents a failed response from the deliveries API endpoint.
+type DeliveryResponseError struct {
+	Ok    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// NewDeliveryHandler returns an HTTP handler that reports the state of an asynchronous delivery.
+// It expects a GET request with the delivery ID as path value "id".
+func NewDeliveryHandler[T any](get func(id uuid.UUID) (T, bool)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			sendJSONResponseWithStatus(w, http.StatusBadRequest, DeliveryResponseError{
+				Ok:    false,
+				Error: "invalid delivery ID",
+			})
+			return
+		}
+
+		delivery, exists := get(id)
+		if !exists {
+			sendJSONResponseWithStatus(w, http.StatusNotFound, DeliveryResponseError{
+				Ok:    false,
+				Error: "delivery not found",
+			})
+			return
+		}
+
+		sendJSONResponse(w, delivery)
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type testDelivery struct {
+	ID    uuid.UUID `json:"id"`
+	State string    `json:"state"`
+}
+
+func newTestDeliveryHandler(id uuid.UUID) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /deliveries/{id}", NewDeliveryHandler(func(got uuid.UUID) (testDelivery, bool) {
+		if got != id {
+			return testDelivery{}, false
+		}
+		return testDelivery{ID: id, State: "delivered"}, true
+	}))
+	return mux
+}
+
+func TestNewDeliveryHandler_Success(t *testing.T) {
+	id := uuid.New()
+	req := httptest.NewRequest(http.MethodGet, "/deliveries/"+id.String(), nil)
+	rec := httptest.NewRecorder()
+
+	newTestDeliveryHandler(id).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp testDelivery
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.ID != id || resp.State != "delivered" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestNewDeliveryHandler_NotFound(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/deliveries/"+uuid.New().String(), nil)
+	rec := httptest.NewRecorder()
+
+	newTestDeliveryHandler(uuid.New()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestNewDeliveryHandler_InvalidID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/deliveries/not-a-uuid", nil)
+	rec := httptest.NewRecorder()
+
+	newTestDeliveryHandler(uuid.New()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
@@ -4,13 +4,33 @@ import (
 	"net/http"
 )
 
-func NewHealthHandler() http.HandlerFunc {
+// HealthResponse represents the response from the health API endpoint.
+type HealthResponse[T any] struct {
+	Ok          bool `json:"ok"`
+	Subscribers []T  `json:"subscribers"`
+}
+
+// NewHealthHandler returns an HTTP handler that reports the service and subscriber health.
+func NewHealthHandler[T any](subscribers func() []T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		sendJSONResponse(w, HealthResponse[T]{
+			Ok:          true,
+			Subscribers: subscribers(),
+		})
+	}
+}
+
+// NewSubscribersHandler returns an HTTP handler that lists the health of all subscribers.
+func NewSubscribersHandler[T any](subscribers func() []T) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if !validateMethod(w, r, http.MethodGet) {
 			return
 		}
 
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
+		sendJSONResponse(w, subscribers())
 	}
 }
@@ -1,14 +1,23 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 )
 
-func TestNewHealthHandler(t *testing.T) {
-	handler := NewHealthHandler()
+type testSubscriber struct {
+	Subscriber string `json:"subscriber"`
+	State      string `json:"state"`
+}
 
+func testSubscribers() []testSubscriber {
+	return []testSubscriber{{Subscriber: "http://localhost:4000", State: "open"}}
+}
+
+func TestNewHealthHandler(t *testing.T) {
+	handler := NewHealthHandler(testSubscribers)
 	req := httptest.NewRequest(http.MethodGet, "/health", nil)
 	w := httptest.NewRecorder()
 
@@ -18,14 +27,20 @@ func TestNewHealthHandler(t *testing.T) {
 		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
 	}
 
-	if w.Body.String() != "OK" {
-		t.Errorf("expected body %q, got %q", "OK", w.Body.String())
+	var resp HealthResponse[testSubscriber]
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.Ok {
+		t.Errorf("expected ok response, got %+v", resp)
+	}
+	if len(resp.Subscribers) != 1 || resp.Subscribers[0].State != "open" {
+		t.Errorf("expected subscriber health in response, got %+v", resp.Subscribers)
 	}
 }
 
 func TestNewHealthHandler_MethodNotAllowed(t *testing.T) {
-	handler := NewHealthHandler()
-
+	handler := NewHealthHandler(testSubscribers)
 	req := httptest.NewRequest(http.MethodPost, "/health", nil)
 	w := httptest.NewRecorder()
 
@@ -35,3 +50,23 @@ func TestNewHealthHandler_MethodNotAllowed(t *testing.T) {
 		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, w.Code)
 	}
 }
+
+func TestNewSubscribersHandler(t *testing.T) {
+	handler := NewSubscribersHandler(testSubscribers)
+	req := httptest.NewRequest(http.MethodGet, "/subscribers", nil)
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var resp []testSubscriber
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp) != 1 || resp[0].Subscriber != "http://localhost:4000" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
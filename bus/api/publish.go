@@ -4,6 +4,7 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/google/uuid"
 	"github.com/nicograef/cloudevents/event"
 )
 
@@ -12,24 +13,37 @@ type PublishResponseSuccess struct {
 	Ok bool `json:"ok"`
 }
 
+// PublishResponseAccepted represents the response from the publish API endpoint in async mode.
+type PublishResponseAccepted struct {
+	Ok         bool      `json:"ok"`
+	DeliveryID uuid.UUID `json:"deliveryId"`
+}
+
 // PublishResponseError represents a failed response from the publish API endpoint.
+// Violations lists why the data does not conform to the schema of the event type, if that is the error.
 type PublishResponseError struct {
-	Ok    bool   `json:"ok"`
-	Error string `json:"error"`
+	Ok         bool                    `json:"ok"`
+	Error      string                  `json:"error"`
+	Violations []event.SchemaViolation `json:"violations,omitempty"`
 }
 
 type PublishFunc func(e event.Event) error
 
+// EnqueueFunc queues an event for asynchronous delivery and returns the delivery ID.
+type EnqueueFunc func(e event.Event) (uuid.UUID, error)
+
 // NewPublishHandler returns an HTTP handler for publishing messages to the subscribers.
-// It expects a POST request with a JSON body containing a cloudevent.
-func NewPublishHandler(publish PublishFunc) http.HandlerFunc {
+// It expects a POST request with a JSON or protobuf cloudevent, or the event data in binary content mode.
+// With the query parameter async=true the event is queued and 202 Accepted is returned with the delivery ID.
+// If schemas is not nil, events whose data does not conform to the schema of their type are rejected with 422.
+func NewPublishHandler(publish PublishFunc, enqueue EnqueueFunc, schemas *event.SchemaRegistry) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if !validateMethod(w, r, http.MethodPost) {
 			return
 		}
 
 		message := event.Event{}
-		if !readJSONRequest(w, r, &message) {
+		if !readEventRequest(w, r, &message) {
 			return
 		}
 
@@ -42,6 +56,29 @@ func NewPublishHandler(publish PublishFunc) http.HandlerFunc {
 			return
 		}
 
+		if !validateSchema(w, schemas, message) {
+			return
+		}
+
+		if r.URL.Query().Get("async") == "true" {
+			deliveryID, err := enqueue(message)
+			if err != nil {
+				log.Printf("Error queuing message: %v", err)
+				sendJSONResponseWithStatus(w, http.StatusServiceUnavailable, PublishResponseError{
+					Ok:    false,
+					Error: err.Error(),
+				})
+				return
+			}
+
+			w.Header().Set("Location", "/deliveries/"+deliveryID.String())
+			sendJSONResponseWithStatus(w, http.StatusAccepted, PublishResponseAccepted{
+				Ok:         true,
+				DeliveryID: deliveryID,
+			})
+			return
+		}
+
 		if err := publish(message); err != nil {
 			log.Printf("Error publishing message: %v", err)
 			sendJSONResponse(w, PublishResponseError{
@@ -7,12 +7,13 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/nicograef/cloudevents/event"
 )
 
 func TestNewPublishHandler_Success(t *testing.T) {
 	publish := func(e event.Event) error { return nil }
-	handler := NewPublishHandler(publish)
+	handler := NewPublishHandler(publish, nil, nil)
 
 	e, err := event.New(event.Candidate{Type: "com.example.event:v1", Source: "https://example.com", Subject: "/users/123", Data: map[string]any{"k": "v"}})
 	if err != nil {
@@ -38,7 +39,7 @@ func TestNewPublishHandler_Success(t *testing.T) {
 
 func TestNewPublishHandler_MethodNotAllowed(t *testing.T) {
 	publish := func(e event.Event) error { return nil }
-	handler := NewPublishHandler(publish)
+	handler := NewPublishHandler(publish, nil, nil)
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	rec := httptest.NewRecorder()
 	handler(rec, req)
@@ -49,7 +50,7 @@ func TestNewPublishHandler_MethodNotAllowed(t *testing.T) {
 
 func TestNewPublishHandler_InvalidJSON(t *testing.T) {
 	publish := func(e event.Event) error { return nil }
-	handler := NewPublishHandler(publish)
+	handler := NewPublishHandler(publish, nil, nil)
 	body := bytes.NewBufferString(`{"invalid_json":}`)
 	req := httptest.NewRequest(http.MethodPost, "/", body)
 	rec := httptest.NewRecorder()
@@ -61,7 +62,7 @@ func TestNewPublishHandler_InvalidJSON(t *testing.T) {
 
 func TestNewPublishHandler_InvalidEvent(t *testing.T) {
 	publish := func(e event.Event) error { return nil }
-	handler := NewPublishHandler(publish)
+	handler := NewPublishHandler(publish, nil, nil)
 	body := bytes.NewBufferString(`{"type":"", "source":""}`)
 	req := httptest.NewRequest(http.MethodPost, "/", body)
 	rec := httptest.NewRecorder()
@@ -80,3 +81,144 @@ func TestNewPublishHandler_InvalidEvent(t *testing.T) {
 		t.Errorf("expected error message, got empty")
 	}
 }
+
+func TestNewPublishHandler_Async(t *testing.T) {
+	published := false
+	publish := func(e event.Event) error {
+		published = true
+		return nil
+	}
+	deliveryID := uuid.New()
+	enqueue := func(e event.Event) (uuid.UUID, error) { return deliveryID, nil }
+	handler := NewPublishHandler(publish, enqueue, nil)
+
+	e, err := event.New(event.Candidate{Type: "com.example.event:v1", Source: "https://example.com", Subject: "/users/123", Data: map[string]any{"k": "v"}})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	body, _ := json.Marshal(e)
+	req := httptest.NewRequest(http.MethodPost, "/publish?async=true", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("expected status 202, got %d", rec.Code)
+	}
+	if published {
+		t.Errorf("expected event not to be published synchronously")
+	}
+	if loc := rec.Header().Get("Location"); loc != "/deliveries/"+deliveryID.String() {
+		t.Errorf("unexpected Location header: %s", loc)
+	}
+
+	var resp PublishResponseAccepted
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.Ok || resp.DeliveryID != deliveryID {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestNewPublishHandler_SchemaValidation(t *testing.T) {
+	schemas := event.NewSchemaRegistry()
+	if _, err := schemas.Register("com.example.event:v1", json.RawMessage(`{"type":"object","required":["k"]}`)); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	published := 0
+	publish := func(e event.Event) error { published++; return nil }
+	handler := NewPublishHandler(publish, nil, schemas)
+
+	e, err := event.New(event.Candidate{Type: "com.example.event:v1", Source: "https://example.com", Subject: "/users/123", Data: map[string]any{"other": "v"}})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	body, _ := json.Marshal(e)
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status 422, got %d", rec.Code)
+	}
+	var resp PublishResponseError
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Ok || len(resp.Violations) != 1 || published != 0 {
+		t.Errorf("expected the event to be rejected with one violation, got %+v", resp)
+	}
+}
+
+func TestNewPublishHandler_BinaryContentMode(t *testing.T) {
+	var published event.Event
+	publish := func(e event.Event) error { published = e; return nil }
+	handler := NewPublishHandler(publish, nil, nil)
+
+	e, err := event.New(event.Candidate{Type: "com.example.event:v1", Source: "https://example.com", Subject: "/users/123", DataContentType: "application/xml", Data: "<book/>"})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	header, body, err := event.EncodeHTTP(*e)
+	if err != nil {
+		t.Fatalf("failed to encode event: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header = header
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	var resp PublishResponseSuccess
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || !resp.Ok {
+		t.Fatalf("expected ok response, got %+v, %v", resp, err)
+	}
+	if published.DataContentType != "application/xml" || published.Data != "<book/>" {
+		t.Errorf("unexpected published event %+v", published)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header = header.Clone()
+	req.Header.Set(event.HeaderTime, "yesterday")
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid binary event, got %d", rec.Code)
+	}
+}
+
+func TestNewPublishHandler_Protobuf(t *testing.T) {
+	var published event.Event
+	publish := func(e event.Event) error { published = e; return nil }
+	handler := NewPublishHandler(publish, nil, nil)
+
+	e, err := event.New(event.Candidate{Type: "com.example.event:v1", Source: "https://example.com", Subject: "/users/123", Data: map[string]any{"k": "v"}, Extensions: map[string]any{"traceparent": "00-abc-01"}})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	body, err := event.MarshalProto(*e)
+	if err != nil {
+		t.Fatalf("failed to encode event: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", event.ContentTypeProtobuf)
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	var resp PublishResponseSuccess
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || !resp.Ok {
+		t.Fatalf("expected ok response, got %+v, %v", resp, err)
+	}
+	if published.ID != e.ID || published.Data.(map[string]any)["k"] != "v" || published.Extensions["traceparent"] != "00-abc-01" {
+		t.Errorf("unexpected published event %+v", published)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body[:len(body)-1]))
+	req.Header.Set("Content-Type", event.ContentTypeProtobuf)
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid protobuf event, got %d", rec.Code)
+	}
+}
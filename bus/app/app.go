@@ -10,16 +10,33 @@ import (
 	"github.com/nicograef/cloudevents/bus/api"
 	"github.com/nicograef/cloudevents/bus/bus"
 	"github.com/nicograef/cloudevents/bus/config"
+	"github.com/nicograef/cloudevents/event"
 )
 
 type App struct {
-	Server *http.Server
-	Config config.Config
-	router *http.ServeMux
+	Server     *http.Server
+	Config     config.Config
+	Deliveries *bus.Deliveries
+	Health     *bus.Health
+	// Queues maps queue sink URLs to queues running in the same process, e.g. *queue.Queue.
+	// Queue sinks that are not registered here are forwarded to the queue service over HTTP.
+	// Queues must be registered before SetupRoutes; later changes are ignored.
+	Queues     map[string]bus.Enqueuer
+	Schemas    *event.SchemaRegistry
+	dispatcher *bus.Dispatcher
+	router     *http.ServeMux
 }
 
 // NewApp creates a new application instance
 func NewApp(cfg config.Config) (*App, error) {
+	schemas, err := openSchemaRegistry(cfg.SchemaFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SchemaCompatibility != "" {
+		schemas.SetCompatibility(cfg.SchemaCompatibility)
+	}
+
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Port),
 		ReadTimeout:  30 * time.Second,
@@ -29,23 +46,84 @@ func NewApp(cfg config.Config) (*App, error) {
 	router := http.NewServeMux()
 
 	return &App{
-		Server: server,
-		Config: cfg,
-		router: router,
+		Server:     server,
+		Config:     cfg,
+		Deliveries: bus.NewDeliveries(cfg.DeliveryRetention),
+		Health:     bus.NewHealth(cfg.Subscribers, cfg.BreakerThreshold, cfg.BreakerCooldown, cfg.BreakerProbes, cfg.BreakerBacklog),
+		Queues:     make(map[string]bus.Enqueuer),
+		Schemas:    schemas,
+		router:     router,
 	}, nil
 }
 
 // SetupRoutes configures HTTP routes
 func (app *App) SetupRoutes() {
-	app.router.HandleFunc("POST /publish", api.NewPublishHandler(bus.NewPublish(app.Config.Subscribers, bus.SendToWebhook)))
-	app.router.HandleFunc("GET /health", api.NewHealthHandler())
+	send := bus.NewSubjectFilter(app.Config.Subjects).Wrap(app.Health.Wrap(app.sendFunc()))
+	app.dispatcher = bus.NewDispatcher(app.Config.Subscribers, send, app.Deliveries, app.Health, app.Config.Capacity, app.Config.DeliveryAttempts)
+
+	var schemas *event.SchemaRegistry
+	if app.Config.SchemaValidation {
+		schemas = app.Schemas
+	}
+
+	app.router.HandleFunc("POST /publish", api.NewPublishHandler(bus.NewPublish(app.Config.Subscribers, send, app.dispatcher.Park), app.dispatcher.Enqueue, schemas))
+	app.router.HandleFunc("OPTIONS /publish", event.HandshakeHandler())
+	app.router.HandleFunc("GET /deliveries/{id}", api.NewDeliveryHandler(app.Deliveries.Get))
+	app.router.HandleFunc("GET /subscribers", api.NewSubscribersHandler(app.Health.Subscribers))
+	app.router.HandleFunc("GET /health", api.NewHealthHandler(app.Health.Subscribers))
+	schemaHandler := event.SchemaHandler(app.Schemas)
+	app.router.Handle("/schemas", schemaHandler)
+	app.router.Handle("/schemas/", schemaHandler)
 	app.Server.Handler = app.router
 }
 
+// openSchemaRegistry opens the schema registry persisted to the file, or an in-memory registry if file is empty.
+func openSchemaRegistry(file string) (*event.SchemaRegistry, error) {
+	if file == "" {
+		return event.NewSchemaRegistry(), nil
+	}
+
+	return event.OpenSchemaRegistry(file)
+}
+
+// sendFunc returns the function used to deliver events to subscribers.
+// Events for queue sinks are enqueued in the queue, all other subscribers receive webhook requests.
+func (app *App) sendFunc() bus.SendFunc {
+	return bus.Route(app.webhookSendFunc(), bus.NewSendToQueue(app.Queues))
+}
+
+// webhookSendFunc returns the function used to deliver events to webhook subscribers.
+// Requests are signed for subscribers with secrets and encoded in the event format configured for the subscriber.
+// If a webhook origin is configured, the subscribers are validated with the webhook handshake first. The
+// handshakes start in the background, so unreachable subscribers do not delay the startup.
+func (app *App) webhookSendFunc() bus.SendFunc {
+	send := bus.NewSignedSendToWebhook(app.Config.Secrets, app.Config.Formats)
+	if app.Config.WebhookOrigin == "" {
+		return send
+	}
+
+	handshaker := event.NewHandshaker(app.Config.WebhookOrigin)
+	for _, sub := range app.Config.Subscribers {
+		if bus.IsQueueSink(sub) {
+			continue
+		}
+		go func() {
+			if err := handshaker.Register(sub); err != nil {
+				log.Printf("WARN Webhook handshake failed for subscriber %s: %v", sub, err)
+			}
+		}()
+	}
+
+	return handshaker.Wrap(send)
+}
+
 // Run starts the application with graceful shutdown
 func (app *App) Run(ctx context.Context) error {
 	app.SetupRoutes()
 
+	// Start asynchronous delivery worker
+	app.dispatcher.Start()
+
 	// Start server in goroutine
 	errChan := make(chan error, 1)
 	go func() {
@@ -76,6 +154,11 @@ func (app *App) Shutdown() error {
 		log.Printf("Error shutting down server: %v", err)
 	}
 
+	// Stop asynchronous delivery worker
+	if app.dispatcher != nil {
+		app.dispatcher.Stop()
+	}
+
 	fmt.Println("Shutdown complete")
 	return nil
 }
@@ -1,12 +1,16 @@
 package app
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/nicograef/cloudevents/bus/config"
+	"github.com/nicograef/cloudevents/event"
 )
 
 func TestNewApp(t *testing.T) {
@@ -129,3 +133,45 @@ func TestRun_ContextCancellation(t *testing.T) {
 		t.Errorf("Run() returned error: %v", err)
 	}
 }
+
+type testQueue struct {
+	events []event.Event
+}
+
+func (q *testQueue) Enqueue(ev event.Event) error {
+	q.events = append(q.events, ev)
+	return nil
+}
+
+func TestSetupRoutes_EmbeddedQueueSink(t *testing.T) {
+	cfg := config.Config{
+		Port:        8080,
+		Subscribers: []string{"queue+embedded://orders"},
+	}
+
+	app, err := NewApp(cfg)
+	if err != nil {
+		t.Fatalf("NewApp() failed: %v", err)
+	}
+
+	q := &testQueue{}
+	app.Queues["queue+embedded://orders"] = q
+	app.SetupRoutes()
+
+	e, err := event.New(event.Candidate{Type: "com.example.event:v1", Source: "https://example.com", Subject: "/orders/1", Data: map[string]any{"k": "v"}})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	body, _ := json.Marshal(e)
+	req := httptest.NewRequest(http.MethodPost, "/publish", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	app.Server.Handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(q.events) != 1 || q.events[0].ID != e.ID {
+		t.Errorf("expected event to be enqueued in the embedded queue, got %+v", q.events)
+	}
+}
@@ -0,0 +1,147 @@
+package bus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nicograef/cloudevents/event"
+)
+
+// DeliveryState describes where the delivery of an event to a single subscriber stands.
+type DeliveryState string
+
+const (
+	StatePending      DeliveryState = "pending"
+	StateDelivered    DeliveryState = "delivered"
+	StateRetrying     DeliveryState = "retrying"
+	StateDeadLettered DeliveryState = "dead-lettered"
+	// StateSkipped means the event did not match the subject filter of the subscriber.
+	StateSkipped DeliveryState = "skipped"
+)
+
+// Delivery tracks the asynchronous delivery of one published event to all subscribers.
+type Delivery struct {
+	ID          uuid.UUID            `json:"id"`
+	EventID     uuid.UUID            `json:"eventId"`
+	CreatedAt   time.Time            `json:"createdAt"`
+	Subscribers []SubscriberDelivery `json:"subscribers"`
+}
+
+// SubscriberDelivery holds the delivery state of an event for one subscriber.
+type SubscriberDelivery struct {
+	Subscriber  string        `json:"subscriber"`
+	State       DeliveryState `json:"state"`
+	Attempts    int           `json:"attempts"`
+	LastError   string        `json:"lastError,omitempty"`
+	UpdatedAt   time.Time     `json:"updatedAt"`
+	DeliveredAt *time.Time    `json:"deliveredAt,omitempty"`
+}
+
+// Deliveries stores delivery records in memory.
+// Finished records are removed once they are older than the retention window.
+type Deliveries struct {
+	mu        sync.Mutex
+	retention time.Duration
+	records   map[uuid.UUID]*Delivery
+}
+
+// NewDeliveries creates an empty delivery store with the given retention window.
+func NewDeliveries(retention time.Duration) *Deliveries {
+	return &Deliveries{retention: retention, records: make(map[uuid.UUID]*Delivery)}
+}
+
+// Create adds a new delivery record for the event with all subscribers pending.
+func (d *Deliveries) Create(ev event.Event, subs []string) Delivery {
+	now := time.Now().UTC()
+	delivery := &Delivery{ID: uuid.New(), EventID: ev.ID, CreatedAt: now}
+	for _, sub := range subs {
+		delivery.Subscribers = append(delivery.Subscribers, SubscriberDelivery{Subscriber: sub, State: StatePending, UpdatedAt: now})
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.prune(now)
+	d.records[delivery.ID] = delivery
+
+	return delivery.copy()
+}
+
+// Get returns a copy of the delivery record with the given ID.
+func (d *Deliveries) Get(id uuid.UUID) (Delivery, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.prune(time.Now().UTC())
+
+	delivery, exists := d.records[id]
+	if !exists {
+		return Delivery{}, false
+	}
+
+	return delivery.copy(), true
+}
+
+// Update records the state of the delivery to a subscriber after the given number of attempts.
+func (d *Deliveries) Update(id uuid.UUID, sub string, state DeliveryState, attempts int, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delivery, exists := d.records[id]
+	if !exists {
+		return
+	}
+
+	now := time.Now().UTC()
+	for i := range delivery.Subscribers {
+		s := &delivery.Subscribers[i]
+		if s.Subscriber != sub {
+			continue
+		}
+
+		s.State = state
+		s.Attempts = attempts
+		s.UpdatedAt = now
+		if err != nil {
+			s.LastError = err.Error()
+		}
+		if state == StateDelivered {
+			s.DeliveredAt = &now
+		}
+	}
+}
+
+// Delete removes the delivery record with the given ID.
+func (d *Deliveries) Delete(id uuid.UUID) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.records, id)
+}
+
+// prune removes finished records that are older than the retention window.
+func (d *Deliveries) prune(now time.Time) {
+	for id, delivery := range d.records {
+		if now.Sub(delivery.CreatedAt) > d.retention && delivery.finished() {
+			delete(d.records, id)
+		}
+	}
+}
+
+// finished reports whether the event reached a final state for every subscriber.
+func (d *Delivery) finished() bool {
+	for _, s := range d.Subscribers {
+		if s.State != StateDelivered && s.State != StateDeadLettered && s.State != StateSkipped {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (d *Delivery) copy() Delivery {
+	c := *d
+	c.Subscribers = append([]SubscriberDelivery(nil), d.Subscribers...)
+	return c
+}
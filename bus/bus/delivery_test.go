@@ -0,0 +1,57 @@
+package bus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nicograef/cloudevents/event"
+)
+
+func TestDeliveries_CreateAndUpdate(t *testing.T) {
+	d := NewDeliveries(time.Hour)
+	ev := event.Event{ID: uuid.New(), Type: "test"}
+
+	created := d.Create(ev, []string{"http://a", "http://b"})
+	if created.EventID != ev.ID {
+		t.Errorf("expected event ID %s, got %s", ev.ID, created.EventID)
+	}
+	for _, s := range created.Subscribers {
+		if s.State != StatePending {
+			t.Errorf("expected pending state for %s, got %s", s.Subscriber, s.State)
+		}
+	}
+
+	d.Update(created.ID, "http://a", StateDelivered, 1, nil)
+	d.Update(created.ID, "http://b", StateRetrying, 1, errors.New("connection refused"))
+
+	got, ok := d.Get(created.ID)
+	if !ok {
+		t.Fatalf("expected delivery to exist")
+	}
+	if a := got.Subscribers[0]; a.State != StateDelivered || a.Attempts != 1 || a.DeliveredAt == nil {
+		t.Errorf("unexpected state for subscriber a: %+v", a)
+	}
+	if b := got.Subscribers[1]; b.State != StateRetrying || b.Attempts != 1 || b.LastError != "connection refused" {
+		t.Errorf("unexpected state for subscriber b: %+v", b)
+	}
+}
+
+func TestDeliveries_Retention(t *testing.T) {
+	d := NewDeliveries(0)
+	ev := event.Event{ID: uuid.New(), Type: "test"}
+
+	finished := d.Create(ev, []string{"http://a"})
+	d.Update(finished.ID, "http://a", StateDelivered, 1, nil)
+	pending := d.Create(ev, []string{"http://a"})
+
+	time.Sleep(time.Millisecond)
+
+	if _, ok := d.Get(finished.ID); ok {
+		t.Errorf("expected finished delivery to be removed after retention")
+	}
+	if _, ok := d.Get(pending.ID); !ok {
+		t.Errorf("expected unfinished delivery to be kept")
+	}
+}
@@ -0,0 +1,227 @@
+package bus
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nicograef/cloudevents/event"
+)
+
+// ErrQueueFull is returned by Enqueue when the capacity of the delivery queue is exhausted.
+var ErrQueueFull = errors.New("delivery queue is full")
+
+// ErrDispatcherStopped is returned by Enqueue after Stop and recorded for deliveries that were abandoned by Stop.
+var ErrDispatcherStopped = errors.New("dispatcher is stopped")
+
+// Dispatcher delivers published events to the subscribers in the background.
+// Failed deliveries are retried with exponential backoff and dead-lettered after the maximum number of attempts.
+// Events for subscribers with an open circuit are parked and sent again once the circuit closes.
+type Dispatcher struct {
+	subs          []string
+	send          SendFunc
+	attempts      int
+	retryDelay    time.Duration
+	probeInterval time.Duration
+	deliveries    *Deliveries
+	health        *Health
+	jobs          chan []job
+	done          chan struct{}
+	mu            sync.Mutex // guards stopped and the start of senders to jobs
+	stopped       bool
+	wg            sync.WaitGroup
+}
+
+// job is a single delivery attempt of an event to one subscriber.
+type job struct {
+	deliveryID uuid.UUID
+	sub        string
+	ev         event.Event
+	attempt    int
+}
+
+// NewDispatcher creates a Dispatcher that delivers to the subscribers using the provided SendFunc.
+// The SendFunc is expected to be wrapped by the given Health so that open circuits are reported.
+// Up to capacity deliveries can be waiting; each subscriber is attempted at most attempts times.
+func NewDispatcher(subs []string, send SendFunc, deliveries *Deliveries, health *Health, capacity, attempts int) *Dispatcher {
+	return &Dispatcher{
+		subs:          subs,
+		send:          send,
+		attempts:      attempts,
+		retryDelay:    time.Second,
+		probeInterval: time.Second,
+		deliveries:    deliveries,
+		health:        health,
+		jobs:          make(chan []job, capacity),
+		done:          make(chan struct{}),
+	}
+}
+
+// Enqueue records a new delivery for the event and queues it for all subscribers.
+// It returns the delivery ID that can be used to look up the delivery state, or ErrQueueFull
+// without recording a delivery if capacity deliveries are already waiting.
+func (d *Dispatcher) Enqueue(ev event.Event) (uuid.UUID, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopped {
+		return uuid.Nil, ErrDispatcherStopped
+	}
+
+	delivery := d.deliveries.Create(ev, d.subs)
+	jobs := make([]job, 0, len(d.subs))
+	for _, sub := range d.subs {
+		jobs = append(jobs, job{deliveryID: delivery.ID, sub: sub, ev: ev, attempt: 1})
+	}
+
+	select {
+	case d.jobs <- jobs:
+	default:
+		d.deliveries.Delete(delivery.ID)
+		return uuid.Nil, ErrQueueFull
+	}
+
+	log.Printf("INFO Queued delivery %s for event %s", delivery.ID, ev.ID)
+	return delivery.ID, nil
+}
+
+// Park adds an event that could not be sent synchronously to the backlog of the subscriber.
+// It is delivered in the background once the circuit of the subscriber closes.
+// It returns ErrBacklogFull if the backlog of the subscriber is full.
+func (d *Dispatcher) Park(sub string, ev event.Event) error {
+	return d.health.park(job{deliveryID: uuid.Nil, sub: sub, ev: ev, attempt: 1})
+}
+
+// Start launches the background worker that processes queued deliveries
+// and probes subscribers with an open circuit using their parked events.
+func (d *Dispatcher) Start() {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		ticker := time.NewTicker(d.probeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case jobs := <-d.jobs:
+				for _, j := range jobs {
+					d.deliver(j)
+				}
+			case <-ticker.C:
+				for _, j := range d.health.probeJobs() {
+					d.deliver(j)
+				}
+			case <-d.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the background worker. Deliveries that are still queued or waiting for a retry
+// are dead-lettered with ErrDispatcherStopped.
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		return
+	}
+	d.stopped = true
+	close(d.done)
+	d.mu.Unlock()
+
+	d.wg.Wait()
+
+	// No sender is left, so the queue can be emptied without racing new jobs.
+	for {
+		select {
+		case jobs := <-d.jobs:
+			d.abandon(jobs...)
+		default:
+			return
+		}
+	}
+}
+
+// deliver attempts to send the event to the subscriber and schedules a retry on failure.
+func (d *Dispatcher) deliver(j job) {
+	_, err := d.send(j.sub, j.ev)
+	if err == nil {
+		d.deliveries.Update(j.deliveryID, j.sub, StateDelivered, j.attempt, nil)
+		d.requeue(d.health.drain(j.sub)...)
+		return
+	}
+
+	if errors.Is(err, ErrFiltered) {
+		d.deliveries.Update(j.deliveryID, j.sub, StateSkipped, 0, nil)
+		return
+	}
+
+	if errors.Is(err, ErrCircuitOpen) {
+		if err := d.health.park(j); err != nil {
+			log.Printf("ERROR Failed to park event %s for subscriber %s: %v", j.ev.ID, j.sub, err)
+			d.deliveries.Update(j.deliveryID, j.sub, StateDeadLettered, j.attempt-1, err)
+			return
+		}
+		d.deliveries.Update(j.deliveryID, j.sub, StateRetrying, j.attempt-1, err)
+		return
+	}
+
+	var rateLimit *event.RateLimitError
+	if errors.As(err, &rateLimit) {
+		d.deliveries.Update(j.deliveryID, j.sub, StateRetrying, j.attempt-1, err)
+		time.AfterFunc(rateLimit.RetryAfter, func() { d.requeue(j) })
+		return
+	}
+
+	log.Printf("ERROR Failed to deliver event %s to subscriber %s (attempt %d): %v", j.ev.ID, j.sub, j.attempt, err)
+
+	if j.attempt >= d.attempts {
+		d.deliveries.Update(j.deliveryID, j.sub, StateDeadLettered, j.attempt, err)
+		return
+	}
+
+	d.deliveries.Update(j.deliveryID, j.sub, StateRetrying, j.attempt, err)
+
+	delay := d.retryDelay << (j.attempt - 1)
+	j.attempt++
+	time.AfterFunc(delay, func() { d.requeue(j) })
+}
+
+// requeue puts jobs back into the delivery queue without blocking the worker.
+// Jobs requeued after Stop are abandoned.
+func (d *Dispatcher) requeue(jobs ...job) {
+	if len(jobs) == 0 {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopped {
+		d.abandon(jobs...)
+		return
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		select {
+		case d.jobs <- jobs:
+		case <-d.done:
+			d.abandon(jobs...)
+		}
+	}()
+}
+
+// abandon dead-letters jobs that can no longer be delivered because the dispatcher stopped.
+func (d *Dispatcher) abandon(jobs ...job) {
+	for _, j := range jobs {
+		log.Printf("WARN Dropping delivery of event %s to subscriber %s: %v", j.ev.ID, j.sub, ErrDispatcherStopped)
+		d.deliveries.Update(j.deliveryID, j.sub, StateDeadLettered, j.attempt-1, ErrDispatcherStopped)
+	}
+}
@@ -0,0 +1,259 @@
+package bus
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nicograef/cloudevents/event"
+)
+
+// waitForDelivery polls the delivery until cond is true or the timeout expires.
+func waitForDelivery(t *testing.T, d *Deliveries, id uuid.UUID, cond func(Delivery) bool) Delivery {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if delivery, ok := d.Get(id); ok && cond(delivery) {
+			return delivery
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	delivery, _ := d.Get(id)
+	t.Fatalf("delivery did not reach expected state: %+v", delivery)
+	return delivery
+}
+
+func TestDispatcher_Delivers(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	send := func(url string, ev event.Event) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, url)
+		return "ok", nil
+	}
+
+	deliveries := NewDeliveries(time.Hour)
+	d := NewDispatcher([]string{"http://a", "http://b"}, send, deliveries, NewHealth(nil, 5, time.Minute, 1, 10), 10, 3)
+	d.Start()
+	defer d.Stop()
+
+	id, err := d.Enqueue(event.Event{ID: uuid.New(), Type: "test"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	waitForDelivery(t, deliveries, id, func(delivery Delivery) bool { return delivery.finished() })
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != 2 {
+		t.Errorf("expected 2 sends, got %d", len(sent))
+	}
+}
+
+func TestDispatcher_SkipsFilteredSubscribers(t *testing.T) {
+	send := func(url string, ev event.Event) (string, error) {
+		return "ok", nil
+	}
+	filter := NewSubjectFilter(map[string][]string{"http://orders": {"/orders/**"}})
+
+	deliveries := NewDeliveries(time.Hour)
+	d := NewDispatcher([]string{"http://orders", "http://all"}, filter.Wrap(send), deliveries, NewHealth(nil, 5, time.Minute, 1, 10), 10, 3)
+	d.Start()
+	defer d.Stop()
+
+	id, _ := d.Enqueue(event.Event{ID: uuid.New(), Type: "test", Subject: "/users/1"})
+	delivery := waitForDelivery(t, deliveries, id, func(delivery Delivery) bool { return delivery.finished() })
+
+	for _, s := range delivery.Subscribers {
+		want := StateDelivered
+		if s.Subscriber == "http://orders" {
+			want = StateSkipped
+		}
+		if s.State != want {
+			t.Errorf("expected state %s for %s, got %s", want, s.Subscriber, s.State)
+		}
+	}
+}
+
+func TestDispatcher_RetriesAndDeadLetters(t *testing.T) {
+	send := func(url string, ev event.Event) (string, error) {
+		if url == "http://down" {
+			return "", errors.New("connection refused")
+		}
+		return "ok", nil
+	}
+
+	deliveries := NewDeliveries(time.Hour)
+	d := NewDispatcher([]string{"http://up", "http://down"}, send, deliveries, NewHealth(nil, 5, time.Minute, 1, 10), 10, 3)
+	d.retryDelay = time.Millisecond
+	d.Start()
+	defer d.Stop()
+
+	id, err := d.Enqueue(event.Event{ID: uuid.New(), Type: "test"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	delivery := waitForDelivery(t, deliveries, id, func(delivery Delivery) bool { return delivery.finished() })
+
+	if up := delivery.Subscribers[0]; up.State != StateDelivered {
+		t.Errorf("expected subscriber up to be delivered, got %+v", up)
+	}
+	down := delivery.Subscribers[1]
+	if down.State != StateDeadLettered || down.Attempts != 3 || down.LastError != "connection refused" {
+		t.Errorf("expected subscriber down to be dead-lettered after 3 attempts, got %+v", down)
+	}
+}
+
+func TestDispatcher_DeadLettersErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	deliveries := NewDeliveries(time.Hour)
+	d := NewDispatcher([]string{ts.URL}, SendToWebhook, deliveries, NewHealth(nil, 5, time.Minute, 1, 10), 10, 2)
+	d.retryDelay = time.Millisecond
+	d.Start()
+	defer d.Stop()
+
+	id, _ := d.Enqueue(event.Event{ID: uuid.New(), Type: "test", Source: "https://example.com"})
+	delivery := waitForDelivery(t, deliveries, id, func(delivery Delivery) bool { return delivery.finished() })
+
+	if s := delivery.Subscribers[0]; s.State != StateDeadLettered || s.Attempts != 2 || !strings.Contains(s.LastError, "status 500") {
+		t.Errorf("expected delivery answered with 500 to be dead-lettered, got %+v", s)
+	}
+}
+
+func TestDispatcher_RetriesRateLimitedDeliveries(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	send := func(url string, ev event.Event) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls < 3 {
+			return "", &event.RateLimitError{URL: url, RetryAfter: time.Millisecond}
+		}
+		return "ok", nil
+	}
+
+	deliveries := NewDeliveries(time.Hour)
+	d := NewDispatcher([]string{"http://limited"}, send, deliveries, NewHealth(nil, 1, time.Hour, 1, 10), 10, 1)
+	d.Start()
+	defer d.Stop()
+
+	id, _ := d.Enqueue(event.Event{ID: uuid.New(), Type: "test"})
+	delivery := waitForDelivery(t, deliveries, id, func(delivery Delivery) bool { return delivery.finished() })
+
+	if s := delivery.Subscribers[0]; s.State != StateDelivered || s.Attempts != 1 {
+		t.Errorf("expected rate limited delivery to be delivered without using up attempts, got %+v", s)
+	}
+}
+
+func TestDispatcher_QueueFull(t *testing.T) {
+	send := func(url string, ev event.Event) (string, error) { return "ok", nil }
+	deliveries := NewDeliveries(time.Hour)
+	d := NewDispatcher([]string{"http://a", "http://b"}, send, deliveries, NewHealth(nil, 5, time.Minute, 1, 10), 1, 3)
+
+	// The capacity counts deliveries, not subscribers: one delivery to both subscribers fits.
+	if _, err := d.Enqueue(event.Event{ID: uuid.New(), Type: "test"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	id, err := d.Enqueue(event.Event{ID: uuid.New(), Type: "test"})
+	if !errors.Is(err, ErrQueueFull) {
+		t.Errorf("expected ErrQueueFull, got %v", err)
+	}
+	if id != uuid.Nil {
+		t.Errorf("expected no delivery ID for a rejected event, got %s", id)
+	}
+	if n := len(deliveries.records); n != 1 {
+		t.Errorf("expected the rejected delivery not to be recorded, got %d records", n)
+	}
+}
+
+func TestDispatcher_StopDeadLettersQueuedDeliveries(t *testing.T) {
+	send := func(url string, ev event.Event) (string, error) { return "ok", nil }
+	deliveries := NewDeliveries(time.Hour)
+	d := NewDispatcher([]string{"http://a"}, send, deliveries, NewHealth(nil, 5, time.Minute, 1, 10), 10, 3)
+
+	id, err := d.Enqueue(event.Event{ID: uuid.New(), Type: "test"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	d.Stop()
+
+	delivery, _ := deliveries.Get(id)
+	if s := delivery.Subscribers[0]; s.State != StateDeadLettered || s.LastError != ErrDispatcherStopped.Error() {
+		t.Errorf("expected the queued delivery to be dead-lettered, got %+v", s)
+	}
+	if _, err := d.Enqueue(event.Event{ID: uuid.New(), Type: "test"}); !errors.Is(err, ErrDispatcherStopped) {
+		t.Errorf("expected ErrDispatcherStopped after Stop, got %v", err)
+	}
+}
+
+func TestDispatcher_ParksWhileCircuitOpen(t *testing.T) {
+	var mu sync.Mutex
+	down := true
+	delivered := 0
+	health := NewHealth([]string{"http://flaky"}, 1, 20*time.Millisecond, 1, 10)
+	send := health.Wrap(func(url string, ev event.Event) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if down {
+			return "", errors.New("connection refused")
+		}
+		delivered++
+		return "ok", nil
+	})
+
+	deliveries := NewDeliveries(time.Hour)
+	d := NewDispatcher([]string{"http://flaky"}, send, deliveries, health, 10, 10)
+	d.retryDelay = time.Hour
+	d.probeInterval = 5 * time.Millisecond
+
+	// The first failure opens the circuit, the synchronously parked events wait in the backlog.
+	send("http://flaky", event.Event{Type: "test"})
+	d.Park("http://flaky", event.Event{Type: "test"})
+	d.Park("http://flaky", event.Event{Type: "test"})
+
+	if backlog := health.Subscribers()[0].Backlog; backlog != 2 {
+		t.Fatalf("expected 2 parked events, got %d", backlog)
+	}
+
+	mu.Lock()
+	down = false
+	mu.Unlock()
+
+	d.Start()
+	defer d.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := delivered
+		mu.Unlock()
+		if n == 2 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if state := health.State("http://flaky"); state != CircuitClosed {
+		t.Errorf("expected circuit to close after a successful probe, got %s", state)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if delivered != 2 {
+		t.Errorf("expected parked events to be delivered, got %d", delivered)
+	}
+}
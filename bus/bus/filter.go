@@ -0,0 +1,47 @@
+package bus
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+// ErrFiltered is returned by a filtered SendFunc if the event does not match the subject filter of the subscriber.
+var ErrFiltered = errors.New("event does not match the subject filter of the subscriber")
+
+// SubjectFilter restricts the events a subscriber receives to subjects matching its patterns,
+// e.g. /libraries/7/** or /libraries/*/books/123 (see event.MatchSubject).
+// Subscribers without patterns receive all events.
+type SubjectFilter struct {
+	patterns map[string][]string
+}
+
+// NewSubjectFilter creates a SubjectFilter with the subject patterns per subscriber URL.
+func NewSubjectFilter(patterns map[string][]string) *SubjectFilter {
+	return &SubjectFilter{patterns: patterns}
+}
+
+// Match reports whether the event should be sent to the subscriber.
+func (f *SubjectFilter) Match(sub string, ev event.Event) bool {
+	patterns := f.patterns[sub]
+	if len(patterns) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		return event.MatchSubject(pattern, ev.Subject)
+	})
+}
+
+// Wrap returns a SendFunc that only sends events matching the subject filter of the subscriber
+// and returns ErrFiltered for all others.
+func (f *SubjectFilter) Wrap(send SendFunc) SendFunc {
+	return func(url string, ev event.Event) (string, error) {
+		if !f.Match(url, ev) {
+			return "", ErrFiltered
+		}
+
+		return send(url, ev)
+	}
+}
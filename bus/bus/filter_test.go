@@ -0,0 +1,53 @@
+package bus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+func TestSubjectFilter_Match(t *testing.T) {
+	filter := NewSubjectFilter(map[string][]string{
+		"http://library-7": {"/libraries/7/**"},
+		"http://books-123": {"/libraries/*/books/123", "/books/123"},
+	})
+
+	tests := []struct {
+		sub     string
+		subject string
+		want    bool
+	}{
+		{"http://library-7", "/libraries/7/books/1", true},
+		{"http://library-7", "/libraries/8/books/1", false},
+		{"http://books-123", "/libraries/8/books/123", true},
+		{"http://books-123", "/books/123", true},
+		{"http://books-123", "/libraries/8/books/124", false},
+		{"http://all", "/anything", true},
+	}
+
+	for _, tt := range tests {
+		if got := filter.Match(tt.sub, event.Event{Subject: tt.subject}); got != tt.want {
+			t.Errorf("Match(%s, %s) = %v, want %v", tt.sub, tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestSubjectFilter_Wrap(t *testing.T) {
+	var sent []string
+	send := func(url string, ev event.Event) (string, error) {
+		sent = append(sent, url)
+		return "ok", nil
+	}
+	filtered := NewSubjectFilter(map[string][]string{"http://a": {"/users/*"}}).Wrap(send)
+
+	if _, err := filtered("http://a", event.Event{Subject: "/orders/1"}); !errors.Is(err, ErrFiltered) {
+		t.Errorf("expected ErrFiltered, got %v", err)
+	}
+	if _, err := filtered("http://a", event.Event{Subject: "/users/1"}); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if len(sent) != 1 {
+		t.Errorf("expected 1 send, got %d", len(sent))
+	}
+}
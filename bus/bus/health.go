@@ -0,0 +1,288 @@
+package bus
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+// ErrCircuitOpen is returned instead of sending when the circuit breaker of a subscriber is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// ErrBacklogFull is returned when an event cannot be parked because the backlog of the subscriber is full.
+var ErrBacklogFull = errors.New("subscriber backlog is full")
+
+// CircuitState is the state of a subscriber's circuit breaker.
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// outcomeWindow is the number of recent delivery outcomes used to compute the error rate.
+const outcomeWindow = 20
+
+// SubscriberHealth is a snapshot of the health of one subscriber.
+type SubscriberHealth struct {
+	Subscriber          string       `json:"subscriber"`
+	State               CircuitState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutiveFailures"`
+	ErrorRate           float64      `json:"errorRate"`
+	LatencyMs           float64      `json:"latencyMs"`
+	Backlog             int          `json:"backlog"`
+	LastError           string       `json:"lastError,omitempty"`
+	LastSuccess         *time.Time   `json:"lastSuccess,omitempty"`
+	LastFailure         *time.Time   `json:"lastFailure,omitempty"`
+}
+
+// Health tracks the health of every subscriber and runs a circuit breaker per subscriber.
+// A circuit opens after threshold consecutive failures. Once the cooldown has passed it becomes
+// half-open and closes again after the given number of successful probes, sent one at a time.
+// Events that cannot be sent while the circuit is open are parked in the subscriber's backlog,
+// which holds at most backlog events.
+type Health struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	probes    int
+	backlog   int
+	subs      map[string]*subscriberHealth
+}
+
+type subscriberHealth struct {
+	state               CircuitState
+	openedAt            time.Time
+	consecutiveFailures int
+	successfulProbes    int
+	probing             bool   // a half-open probe is in flight
+	outcomes            []bool // true for failures, most recent last
+	latency             time.Duration
+	lastError           string
+	lastSuccess         *time.Time
+	lastFailure         *time.Time
+	backlog             []job
+}
+
+// NewHealth creates a Health tracker for the given subscribers.
+func NewHealth(subs []string, threshold int, cooldown time.Duration, probes, backlog int) *Health {
+	h := &Health{threshold: threshold, cooldown: cooldown, probes: probes, backlog: backlog, subs: make(map[string]*subscriberHealth)}
+	for _, sub := range subs {
+		h.subs[sub] = &subscriberHealth{state: CircuitClosed}
+	}
+
+	return h
+}
+
+// Wrap returns a SendFunc that records the outcome and latency of every send.
+// While the circuit of a subscriber is open, or half-open with a probe in flight, ErrCircuitOpen is
+// returned without sending.
+func (h *Health) Wrap(send SendFunc) SendFunc {
+	return func(url string, ev event.Event) (string, error) {
+		allowed, probe := h.allow(url)
+		if !allowed {
+			return "", ErrCircuitOpen
+		}
+
+		start := time.Now()
+		resp, err := send(url, ev)
+		h.record(url, time.Since(start), err, probe)
+
+		return resp, err
+	}
+}
+
+// State returns the circuit state of the subscriber.
+func (h *Health) State(sub string) CircuitState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.get(sub).state
+}
+
+// Subscribers returns a health snapshot of all subscribers sorted by URL.
+func (h *Health) Subscribers() []SubscriberHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	snapshot := make([]SubscriberHealth, 0, len(h.subs))
+	for url, s := range h.subs {
+		failures := 0
+		for _, failed := range s.outcomes {
+			if failed {
+				failures++
+			}
+		}
+
+		errorRate := 0.0
+		if len(s.outcomes) > 0 {
+			errorRate = float64(failures) / float64(len(s.outcomes))
+		}
+
+		snapshot = append(snapshot, SubscriberHealth{
+			Subscriber:          url,
+			State:               s.state,
+			ConsecutiveFailures: s.consecutiveFailures,
+			ErrorRate:           errorRate,
+			LatencyMs:           float64(s.latency.Microseconds()) / 1000,
+			Backlog:             len(s.backlog),
+			LastError:           s.lastError,
+			LastSuccess:         s.lastSuccess,
+			LastFailure:         s.lastFailure,
+		})
+	}
+
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].Subscriber < snapshot[j].Subscriber
+	})
+
+	return snapshot
+}
+
+// park adds a job to the backlog of its subscriber.
+// It returns ErrBacklogFull if the backlog already holds the maximum number of jobs.
+func (h *Health) park(j job) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.get(j.sub)
+	if len(s.backlog) >= h.backlog {
+		return ErrBacklogFull
+	}
+
+	s.backlog = append(s.backlog, j)
+	return nil
+}
+
+// probeJobs removes and returns the oldest parked job of every subscriber that may be probed.
+// The whole backlog is returned for subscribers whose circuit has closed in the meantime.
+func (h *Health) probeJobs() []job {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var jobs []job
+	for _, s := range h.subs {
+		switch {
+		case len(s.backlog) == 0:
+			continue
+		case s.state == CircuitClosed:
+			jobs = append(jobs, s.backlog...)
+			s.backlog = nil
+		case s.probeable(h.cooldown):
+			jobs = append(jobs, s.backlog[0])
+			s.backlog = s.backlog[1:]
+		}
+	}
+
+	return jobs
+}
+
+// drain removes and returns the whole backlog of the subscriber if its circuit is closed.
+func (h *Health) drain(sub string) []job {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.get(sub)
+	if s.state != CircuitClosed {
+		return nil
+	}
+
+	backlog := s.backlog
+	s.backlog = nil
+	return backlog
+}
+
+// allow reports whether a send to the subscriber may be attempted and whether it is a half-open probe.
+// An open circuit becomes half-open once the cooldown has passed. A half-open circuit allows a single
+// probe at a time.
+func (h *Health) allow(sub string) (allowed, probe bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.get(sub)
+	if s.state == CircuitOpen && s.probeable(h.cooldown) {
+		s.state = CircuitHalfOpen
+		s.successfulProbes = 0
+	}
+
+	switch {
+	case s.state == CircuitClosed:
+		return true, false
+	case s.state == CircuitHalfOpen && !s.probing:
+		s.probing = true
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// record updates the subscriber statistics and circuit state with the outcome of a send.
+func (h *Health) record(sub string, latency time.Duration, err error, probe bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.get(sub)
+	now := time.Now().UTC()
+	if probe {
+		s.probing = false
+	}
+
+	// A send held back by the allowed rate of the webhook says nothing about its health
+	var rateLimit *event.RateLimitError
+	if errors.As(err, &rateLimit) {
+		return
+	}
+
+	if s.latency == 0 {
+		s.latency = latency
+	} else {
+		s.latency = (s.latency*4 + latency) / 5
+	}
+
+	s.outcomes = append(s.outcomes, err != nil)
+	if len(s.outcomes) > outcomeWindow {
+		s.outcomes = s.outcomes[1:]
+	}
+
+	if err != nil {
+		s.consecutiveFailures++
+		s.lastError = err.Error()
+		s.lastFailure = &now
+
+		if s.state == CircuitHalfOpen || s.consecutiveFailures >= h.threshold {
+			s.state = CircuitOpen
+			s.openedAt = now
+		}
+		return
+	}
+
+	s.consecutiveFailures = 0
+	s.lastSuccess = &now
+
+	if s.state == CircuitHalfOpen {
+		s.successfulProbes++
+		if s.successfulProbes >= h.probes {
+			s.state = CircuitClosed
+		}
+	}
+}
+
+// get returns the health of the subscriber, adding it if it is not tracked yet.
+func (h *Health) get(sub string) *subscriberHealth {
+	s, exists := h.subs[sub]
+	if !exists {
+		s = &subscriberHealth{state: CircuitClosed}
+		h.subs[sub] = s
+	}
+
+	return s
+}
+
+// probeable reports whether the circuit is half-open or has been open for longer than the cooldown.
+func (s *subscriberHealth) probeable(cooldown time.Duration) bool {
+	return s.state == CircuitHalfOpen || (s.state == CircuitOpen && time.Since(s.openedAt) >= cooldown)
+}
@@ -0,0 +1,148 @@
+package bus
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+func TestHealth_OpensAfterThreshold(t *testing.T) {
+	calls := 0
+	h := NewHealth([]string{"http://down"}, 3, time.Hour, 1, 10)
+	send := h.Wrap(func(url string, ev event.Event) (string, error) {
+		calls++
+		return "", errors.New("connection refused")
+	})
+
+	for range 3 {
+		send("http://down", event.Event{Type: "test"})
+	}
+	if state := h.State("http://down"); state != CircuitOpen {
+		t.Fatalf("expected circuit to be open, got %s", state)
+	}
+
+	if _, err := send("http://down", event.Event{Type: "test"}); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected no send while the circuit is open, got %d calls", calls)
+	}
+
+	subs := h.Subscribers()
+	if len(subs) != 1 {
+		t.Fatalf("expected 1 subscriber, got %d", len(subs))
+	}
+	if subs[0].ConsecutiveFailures != 3 || subs[0].ErrorRate != 1 || subs[0].LastError != "connection refused" {
+		t.Errorf("unexpected subscriber health: %+v", subs[0])
+	}
+}
+
+func TestHealth_OpensOnErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	h := NewHealth([]string{ts.URL}, 2, time.Hour, 1, 10)
+	send := h.Wrap(SendToWebhook)
+
+	for range 2 {
+		send(ts.URL, event.Event{Type: "test", Source: "https://example.com"})
+	}
+	if state := h.State(ts.URL); state != CircuitOpen {
+		t.Errorf("expected circuit to open for a subscriber answering 503, got %s", state)
+	}
+}
+
+func TestHealth_ClosesAfterSuccessfulProbes(t *testing.T) {
+	failing := true
+	h := NewHealth([]string{"http://flaky"}, 1, 0, 2, 10)
+	send := h.Wrap(func(url string, ev event.Event) (string, error) {
+		if failing {
+			return "", errors.New("connection refused")
+		}
+		return "ok", nil
+	})
+
+	send("http://flaky", event.Event{Type: "test"})
+	if state := h.State("http://flaky"); state != CircuitOpen {
+		t.Fatalf("expected circuit to be open, got %s", state)
+	}
+
+	failing = false
+	send("http://flaky", event.Event{Type: "test"})
+	if state := h.State("http://flaky"); state != CircuitHalfOpen {
+		t.Fatalf("expected circuit to be half-open after the first probe, got %s", state)
+	}
+
+	send("http://flaky", event.Event{Type: "test"})
+	if state := h.State("http://flaky"); state != CircuitClosed {
+		t.Fatalf("expected circuit to be closed after two probes, got %s", state)
+	}
+}
+
+func TestHealth_ReopensOnFailedProbe(t *testing.T) {
+	h := NewHealth([]string{"http://down"}, 1, 0, 1, 10)
+	send := h.Wrap(func(url string, ev event.Event) (string, error) {
+		return "", errors.New("connection refused")
+	})
+
+	send("http://down", event.Event{Type: "test"})
+	send("http://down", event.Event{Type: "test"})
+
+	if state := h.State("http://down"); state != CircuitOpen {
+		t.Errorf("expected circuit to reopen after a failed probe, got %s", state)
+	}
+}
+
+func TestHealth_AllowsSingleHalfOpenProbe(t *testing.T) {
+	h := NewHealth([]string{"http://flaky"}, 1, 0, 2, 10)
+	h.record("http://flaky", 0, errors.New("connection refused"), false)
+
+	if allowed, probe := h.allow("http://flaky"); !allowed || !probe {
+		t.Fatalf("expected the first half-open send to be allowed as probe")
+	}
+	if allowed, _ := h.allow("http://flaky"); allowed {
+		t.Errorf("expected concurrent sends to be rejected while a probe is in flight")
+	}
+
+	h.record("http://flaky", 0, nil, true)
+	if allowed, probe := h.allow("http://flaky"); !allowed || !probe {
+		t.Errorf("expected the next probe to be allowed after the first one finished")
+	}
+}
+
+func TestHealth_BoundsBacklog(t *testing.T) {
+	h := NewHealth([]string{"http://down"}, 1, time.Hour, 1, 2)
+
+	for range 2 {
+		if err := h.park(job{sub: "http://down"}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if err := h.park(job{sub: "http://down"}); !errors.Is(err, ErrBacklogFull) {
+		t.Errorf("expected ErrBacklogFull, got %v", err)
+	}
+	if backlog := h.Subscribers()[0].Backlog; backlog != 2 {
+		t.Errorf("expected backlog of 2, got %d", backlog)
+	}
+}
+
+func TestHealth_IgnoresRateLimitedSends(t *testing.T) {
+	h := NewHealth([]string{"http://limited"}, 1, time.Hour, 1, 10)
+	send := h.Wrap(func(url string, ev event.Event) (string, error) {
+		return "", &event.RateLimitError{URL: url, RetryAfter: time.Second}
+	})
+
+	send("http://limited", event.Event{Type: "test"})
+	if state := h.State("http://limited"); state != CircuitClosed {
+		t.Errorf("expected circuit to stay closed, got %s", state)
+	}
+	if subs := h.Subscribers(); subs[0].ConsecutiveFailures != 0 {
+		t.Errorf("expected no failure to be recorded, got %+v", subs[0])
+	}
+}
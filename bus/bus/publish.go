@@ -2,11 +2,12 @@ package bus
 
 import (
 	"bytes"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/nicograef/cloudevents/bus/api"
 	"github.com/nicograef/cloudevents/event"
@@ -14,14 +15,30 @@ import (
 
 type SendFunc func(url string, ev event.Event) (string, error)
 
+// ParkFunc stores an event for later delivery to a subscriber whose circuit is open.
+// It returns an error if the event cannot be stored.
+type ParkFunc func(sub string, ev event.Event) error
+
 // NewPublish creates a PublishFunc that sends the event to all subscribers using the provided SendFunc.
+// Events for subscribers with an open circuit are handed to park instead of failing the publish,
+// unless park rejects them.
+// Subscribers whose subject filter does not match the event are skipped.
 // It returns an error if sending to any subscriber fails.
-func NewPublish(subs []string, send SendFunc) api.PublishFunc {
+func NewPublish(subs []string, send SendFunc, park ParkFunc) api.PublishFunc {
 	return func(ev event.Event) error {
 		var failedSubs []string
 
 		for _, sub := range subs {
 			_, err := send(sub, ev)
+			if errors.Is(err, ErrFiltered) {
+				continue
+			}
+			if errors.Is(err, ErrCircuitOpen) && park != nil {
+				log.Printf("WARN Circuit open for subscriber %s, parking event %s", sub, ev.ID)
+				if err = park(sub, ev); err == nil {
+					continue
+				}
+			}
 			if err != nil {
 				log.Printf("ERROR Failed to send event %s to subscriber %s: %v", ev.ID, sub, err)
 				failedSubs = append(failedSubs, sub)
@@ -38,24 +55,56 @@ func NewPublish(subs []string, send SendFunc) api.PublishFunc {
 	}
 }
 
-// SendToWebhook posts the event to the subscriber webhook and returns the response body or error
+// SendToWebhook posts the event to the subscriber webhook and returns the response body.
+// It returns an error if the request fails or the webhook responds with a non-2xx status.
 func SendToWebhook(url string, ev event.Event) (string, error) {
-	importBytes, err := json.Marshal(ev)
+	return sendToWebhook(url, ev, nil, "")
+}
+
+// NewSignedSendToWebhook returns a SendFunc that posts the event like SendToWebhook, in the event format
+// configured for the subscriber, and signs the request with the secrets configured for the subscriber.
+// Subscribers without secrets receive unsigned requests.
+func NewSignedSendToWebhook(secrets map[string][]string, formats map[string]string) SendFunc {
+	return func(url string, ev event.Event) (string, error) {
+		return sendToWebhook(url, ev, secrets[url], formats[url])
+	}
+}
+
+// sendToWebhook posts the event to the webhook in the given format, signing it if any secrets are given.
+// Without a format, JSON data is sent as a JSON event, binary and text data as the body with the attributes
+// in Ce- headers.
+func sendToWebhook(url string, ev event.Event, secrets []string, format string) (string, error) {
+	header, body, err := event.EncodeHTTPFormat(ev, format)
 	if err != nil {
 		return "", err
 	}
 
-	resp, err := http.Post(url, "application/json", bytes.NewReader(importBytes))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if len(secrets) > 0 {
+		req.Header.Set(event.SignatureHeader, event.Sign(event.SignedContent(req.Header, body), time.Now(), secrets...))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return "", err
 	}
 
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", err
 	}
 
-	return string(body), nil
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("webhook %s returned status %d", url, resp.StatusCode)
+	}
+
+	return string(respBody), nil
 }
@@ -1,6 +1,7 @@
 package bus
 
 import (
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -8,6 +9,58 @@ import (
 	"github.com/nicograef/cloudevents/event"
 )
 
+func TestNewPublish_ParksOnOpenCircuit(t *testing.T) {
+	var parked []string
+	send := func(url string, ev event.Event) (string, error) {
+		if url == "http://down" {
+			return "", ErrCircuitOpen
+		}
+		return "ok", nil
+	}
+	park := func(sub string, ev event.Event) error {
+		parked = append(parked, sub)
+		return nil
+	}
+
+	publish := NewPublish([]string{"http://up", "http://down"}, send, park)
+	if err := publish(event.Event{Type: "test"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(parked) != 1 || parked[0] != "http://down" {
+		t.Errorf("expected event to be parked for http://down, got %v", parked)
+	}
+}
+
+func TestNewPublish_SkipsFilteredSubscribers(t *testing.T) {
+	var sent []string
+	send := func(url string, ev event.Event) (string, error) {
+		sent = append(sent, url)
+		return "ok", nil
+	}
+	filter := NewSubjectFilter(map[string][]string{"http://orders": {"/orders/**"}})
+
+	publish := NewPublish([]string{"http://orders", "http://all"}, filter.Wrap(send), nil)
+	if err := publish(event.Event{Type: "test", Subject: "/users/1"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(sent) != 1 || sent[0] != "http://all" {
+		t.Errorf("expected event to be sent to http://all only, got %v", sent)
+	}
+}
+
+func TestNewPublish_Fails(t *testing.T) {
+	send := func(url string, ev event.Event) (string, error) {
+		return "", ErrCircuitOpen
+	}
+
+	publish := NewPublish([]string{"http://down"}, send, nil)
+	if err := publish(event.Event{Type: "test"}); err == nil {
+		t.Errorf("expected error without park function")
+	}
+}
+
 func TestSendToWebhook_Success(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("ok"))
@@ -24,6 +77,76 @@ func TestSendToWebhook_Success(t *testing.T) {
 	}
 }
 
+func TestSendToWebhook_ContentType(t *testing.T) {
+	var contentTypes []string
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	xml := event.Event{Type: "test", DataContentType: "application/xml", Data: "<book/>"}
+	png := event.Event{Type: "test", DataContentType: "image/png", Data: []byte{0x89, 0x50}}
+	for _, e := range []event.Event{{Type: "test", Data: map[string]any{"k": "v"}}, xml, png} {
+		if _, err := SendToWebhook(ts.URL, e); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	if contentTypes[0] != "application/json" || bodies[0][0] != '{' {
+		t.Errorf("expected a JSON event, got %s %s", contentTypes[0], bodies[0])
+	}
+	if contentTypes[1] != "application/xml" || bodies[1] != "<book/>" {
+		t.Errorf("expected the XML data, got %s %s", contentTypes[1], bodies[1])
+	}
+	if contentTypes[2] != "image/png" || bodies[2] != "\x89P" {
+		t.Errorf("expected the binary data, got %s %q", contentTypes[2], bodies[2])
+	}
+}
+
+func TestNewSignedSendToWebhook_Format(t *testing.T) {
+	var contentType string
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		contentType = r.Header.Get("Content-Type")
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	e, err := event.New(event.Candidate{Type: "com.example.event:v1", Source: "https://example.com", Subject: "/users/123", Data: map[string]any{"k": "v"}})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+
+	send := NewSignedSendToWebhook(nil, map[string]string{ts.URL: event.ContentTypeAvro})
+	if _, err := send(ts.URL, *e); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if contentType != event.ContentTypeAvro {
+		t.Errorf("expected Content-Type %s, got %s", event.ContentTypeAvro, contentType)
+	}
+	decoded, err := event.UnmarshalAvro(body)
+	if err != nil || decoded.ID != e.ID || decoded.Data.(map[string]any)["k"] != "v" {
+		t.Errorf("expected the event in the Avro format, got %+v, %v", decoded, err)
+	}
+}
+
+func TestSendToWebhook_ErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	if _, err := SendToWebhook(ts.URL, event.Event{Type: "test"}); err == nil {
+		t.Error("expected error for status 500")
+	}
+}
+
 func TestSendToWebhook_BadURL(t *testing.T) {
 	e := event.Event{Type: "test"}
 	_, err := SendToWebhook("http://bad url", e)
@@ -72,3 +195,82 @@ func TestSendToWebhook_InvalidResponseBody(t *testing.T) {
 		t.Errorf("expected empty response body on server error, got %s", resp)
 	}
 }
+
+func TestNewSignedSendToWebhook(t *testing.T) {
+	var header string
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get(event.SignatureHeader)
+		body, _ = io.ReadAll(r.Body)
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	send := NewSignedSendToWebhook(map[string][]string{ts.URL: {"new-secret", "old-secret"}}, nil)
+	if _, err := send(ts.URL, event.Event{Type: "test"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if header == "" {
+		t.Fatalf("expected signature header to be set")
+	}
+	for _, secret := range []string{"new-secret", "old-secret"} {
+		if err := event.VerifySignature(header, body, event.DefaultSignatureTolerance, secret); err != nil {
+			t.Errorf("expected signature to verify with %s, got %v", secret, err)
+		}
+	}
+}
+
+func TestNewSignedSendToWebhook_BinaryMode(t *testing.T) {
+	var header http.Header
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	send := NewSignedSendToWebhook(map[string][]string{ts.URL: {"secret"}}, nil)
+	if _, err := send(ts.URL, event.Event{Type: "test", DataContentType: "text/plain", Data: "hello"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	signature := header.Get(event.SignatureHeader)
+	if err := event.VerifySignature(signature, event.SignedContent(header, body), event.DefaultSignatureTolerance, "secret"); err != nil {
+		t.Errorf("expected signature to verify over the headers and body, got %v", err)
+	}
+	if err := event.VerifySignature(signature, body, event.DefaultSignatureTolerance, "secret"); err == nil {
+		t.Error("expected signature over the body alone not to verify")
+	}
+}
+
+func TestNewSignedSendToWebhook_NoSecrets(t *testing.T) {
+	header := "unset"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get(event.SignatureHeader)
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	send := NewSignedSendToWebhook(map[string][]string{}, nil)
+	if _, err := send(ts.URL, event.Event{Type: "test"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if header != "" {
+		t.Errorf("expected no signature header, got %q", header)
+	}
+}
+
+func TestNewPublish_FailsWhenBacklogFull(t *testing.T) {
+	send := func(url string, ev event.Event) (string, error) {
+		return "", ErrCircuitOpen
+	}
+	park := func(sub string, ev event.Event) error { return ErrBacklogFull }
+
+	publish := NewPublish([]string{"http://down"}, send, park)
+	if err := publish(event.Event{Type: "test"}); err == nil {
+		t.Errorf("expected error when the backlog is full")
+	}
+}
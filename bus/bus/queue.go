@@ -0,0 +1,89 @@
+package bus
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+// QueueSinkPrefix marks a subscriber URL as a queue sink, e.g. queue+http://queue:3000.
+// Events for queue sinks are enqueued in a queue instead of being posted to a webhook,
+// so that delivery guarantees, retries and dead-lettering are handled by the queue.
+const QueueSinkPrefix = "queue+"
+
+// Enqueuer is implemented by queues that can be embedded into the bus process, e.g. *queue.Queue.
+type Enqueuer interface {
+	Enqueue(ev event.Event) error
+}
+
+// IsQueueSink reports whether the subscriber URL refers to a queue sink.
+func IsQueueSink(url string) bool {
+	return strings.HasPrefix(url, QueueSinkPrefix)
+}
+
+// Route returns a SendFunc that sends to queue sinks using toQueue and to all other subscribers using toWebhook.
+func Route(toWebhook, toQueue SendFunc) SendFunc {
+	return func(url string, ev event.Event) (string, error) {
+		if IsQueueSink(url) {
+			return toQueue(url, ev)
+		}
+		return toWebhook(url, ev)
+	}
+}
+
+// NewSendToQueue returns a SendFunc for queue sinks. Sinks registered in embedded are enqueued
+// in process; all other sinks are forwarded to the /enqueue endpoint of the queue service.
+// The map is copied, so later changes to embedded have no effect.
+func NewSendToQueue(embedded map[string]Enqueuer) SendFunc {
+	embedded = maps.Clone(embedded)
+	return func(url string, ev event.Event) (string, error) {
+		if q, ok := embedded[url]; ok {
+			if err := q.Enqueue(ev); err != nil {
+				return "", err
+			}
+			return "enqueued", nil
+		}
+		return SendToQueue(url, ev)
+	}
+}
+
+// SendToQueue posts the event to the /enqueue endpoint of the queue service behind the queue sink URL.
+// It returns an error if the queue did not accept the event.
+func SendToQueue(url string, ev event.Event) (string, error) {
+	target := strings.TrimSuffix(strings.TrimPrefix(url, QueueSinkPrefix), "/")
+	if !strings.HasSuffix(target, "/enqueue") {
+		target += "/enqueue"
+	}
+
+	importBytes, err := json.Marshal(ev)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.Post(target, "application/json", bytes.NewReader(importBytes))
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	var result struct {
+		Ok    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("queue %s returned status %d: %w", target, resp.StatusCode, err)
+	}
+
+	if !result.Ok {
+		return "", errors.New("queue rejected event: " + result.Error)
+	}
+
+	return "enqueued", nil
+}
@@ -0,0 +1,96 @@
+package bus
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+type testQueue struct {
+	events []event.Event
+	err    error
+}
+
+func (q *testQueue) Enqueue(ev event.Event) error {
+	if q.err != nil {
+		return q.err
+	}
+	q.events = append(q.events, ev)
+	return nil
+}
+
+func TestRoute(t *testing.T) {
+	var routed []string
+	toWebhook := func(url string, ev event.Event) (string, error) {
+		routed = append(routed, "webhook:"+url)
+		return "ok", nil
+	}
+	toQueue := func(url string, ev event.Event) (string, error) {
+		routed = append(routed, "queue:"+url)
+		return "ok", nil
+	}
+
+	send := Route(toWebhook, toQueue)
+	send("http://a", event.Event{Type: "test"})
+	send("queue+http://b", event.Event{Type: "test"})
+
+	if len(routed) != 2 || routed[0] != "webhook:http://a" || routed[1] != "queue:queue+http://b" {
+		t.Errorf("unexpected routing: %v", routed)
+	}
+}
+
+func TestSendToQueue_Success(t *testing.T) {
+	var path string
+	var received event.Event
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"ok":true,"queueSize":1}`))
+	}))
+	defer ts.Close()
+
+	if _, err := SendToQueue(QueueSinkPrefix+ts.URL, event.Event{Type: "test"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if path != "/enqueue" {
+		t.Errorf("expected request to /enqueue, got %s", path)
+	}
+	if received.Type != "test" {
+		t.Errorf("expected event to be forwarded, got %+v", received)
+	}
+}
+
+func TestSendToQueue_Rejected(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":false,"error":"event type must be at least 5 characters long"}`))
+	}))
+	defer ts.Close()
+
+	if _, err := SendToQueue(QueueSinkPrefix+ts.URL, event.Event{Type: "test"}); err == nil {
+		t.Errorf("expected error when the queue rejects the event")
+	}
+}
+
+func TestNewSendToQueue_Embedded(t *testing.T) {
+	q := &testQueue{}
+	embedded := map[string]Enqueuer{"queue+embedded://orders": q}
+	send := NewSendToQueue(embedded)
+	delete(embedded, "queue+embedded://orders") // later changes must not affect the send function
+
+	if _, err := send("queue+embedded://orders", event.Event{Type: "test"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(q.events) != 1 {
+		t.Errorf("expected event in embedded queue, got %d", len(q.events))
+	}
+
+	q.err = errors.New("queue is full")
+	if _, err := send("queue+embedded://orders", event.Event{Type: "test"}); err == nil {
+		t.Errorf("expected error when the embedded queue is full")
+	}
+}
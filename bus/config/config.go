@@ -5,12 +5,30 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/nicograef/cloudevents/event"
 )
 
 // Config holds application configuration values loaded from environment variables.
 type Config struct {
-	Port        int      // Port for the HTTP server
-	Subscribers []string // Webhook URLs to deliver messages
+	Port              int                 // Port for the HTTP server
+	Subscribers       []string            // Webhook URLs to deliver messages
+	WebhookOrigin     string              // Origin announced in the webhook validation handshake (handshake disabled if empty)
+	Secrets           map[string][]string // Signing secrets per subscriber URL (at most two for rotation)
+	Subjects          map[string][]string // Subject patterns per subscriber URL (all events if empty)
+	Formats           map[string]string   // Event format (media type) per subscriber URL (default encoding if empty)
+	Capacity          int                 // Maximum number of queued asynchronous deliveries
+	DeliveryAttempts  int                 // Number of attempts for delivering an event asynchronously
+	DeliveryRetention time.Duration       // How long finished delivery records are kept
+	BreakerThreshold  int                 // Consecutive failures after which a subscriber's circuit opens
+	BreakerCooldown   time.Duration       // How long a circuit stays open before it is probed
+	BreakerProbes     int                 // Successful half-open probes needed to close a circuit
+	BreakerBacklog    int                 // Maximum number of events parked per subscriber while its circuit is open
+	SchemaValidation  bool                // Validate the data of published events against the schema of their type
+	SchemaFile        string              // File the schema registry is persisted to (kept in memory if empty)
+	// Compatibility rule for new schema versions
+	SchemaCompatibility event.Compatibility
 }
 
 // Load reads configuration from environment variables and returns a Config.
@@ -18,17 +36,160 @@ type Config struct {
 func Load() (Config, error) {
 	port := parseEnvInt("PORT", 3000)
 	subscriberURLs := parseEnvString("SUBSCRIBER_URLS", "")
+	webhookOrigin := parseWebhookOrigin()
+	subscriberSecrets := parseEnvString("SUBSCRIBER_SECRETS", "")
+	subscriberSubjects := parseEnvString("SUBSCRIBER_SUBJECTS", "")
+	subscriberFormats := parseEnvString("SUBSCRIBER_FORMATS", "")
+	capacity := parseEnvInt("CAPACITY", 1000)
+	deliveryAttempts := parseEnvInt("DELIVERY_ATTEMPTS", 3)
+	deliveryRetention := parseEnvInt("DELIVERY_RETENTION_MINUTES", 60)
+	breakerThreshold := parseEnvInt("BREAKER_THRESHOLD", 5)
+	breakerCooldown := parseEnvInt("BREAKER_COOLDOWN_SECONDS", 30)
+	breakerProbes := parseEnvInt("BREAKER_PROBES", 2)
+	breakerBacklog := parseEnvInt("BREAKER_BACKLOG", 1000)
+	schemaValidation := parseEnvBool("SCHEMA_VALIDATION", false)
+	schemaFile := parseEnvString("SCHEMA_FILE", "")
+	schemaCompatibility := parseEnvString("SCHEMA_COMPATIBILITY", "backward")
 
 	if strings.TrimSpace(subscriberURLs) == "" {
 		return Config{}, fmt.Errorf("missing required env SUBSCRIBER_URLS (comma-separated webhook URLs)")
 	}
 
+	subscribers := splitAndTrim(subscriberURLs, ",")
+	secrets, err := parseSecrets(subscribers, subscriberSecrets)
+	if err != nil {
+		return Config{}, err
+	}
+	subjects, err := parseSubjects(subscribers, subscriberSubjects)
+	if err != nil {
+		return Config{}, err
+	}
+	formats, err := parseFormats(subscribers, subscriberFormats)
+	if err != nil {
+		return Config{}, err
+	}
+	compatibility, err := event.ParseCompatibility(schemaCompatibility)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid SCHEMA_COMPATIBILITY: %w", err)
+	}
+
 	return Config{
-		Port:        port,
-		Subscribers: splitAndTrim(subscriberURLs, ","),
+		Port:              port,
+		Subscribers:       subscribers,
+		WebhookOrigin:     webhookOrigin,
+		Secrets:           secrets,
+		Subjects:          subjects,
+		Formats:           formats,
+		Capacity:          capacity,
+		DeliveryAttempts:  deliveryAttempts,
+		DeliveryRetention: time.Duration(deliveryRetention) * time.Minute,
+		BreakerThreshold:  breakerThreshold,
+		BreakerCooldown:   time.Duration(breakerCooldown) * time.Second,
+		BreakerProbes:     breakerProbes,
+		BreakerBacklog:    breakerBacklog,
+		SchemaValidation:  schemaValidation,
+		SchemaFile:        schemaFile,
+
+		SchemaCompatibility: compatibility,
 	}, nil
 }
 
+// parseSecrets maps the comma-separated secrets to the subscribers in the same position.
+// Each entry may hold two secrets separated by "|" to support rotation; empty entries disable signing.
+func parseSecrets(subscribers []string, s string) (map[string][]string, error) {
+	secrets := make(map[string][]string)
+	if strings.TrimSpace(s) == "" {
+		return secrets, nil
+	}
+
+	entries := strings.Split(s, ",")
+	if len(entries) > len(subscribers) {
+		return nil, fmt.Errorf("SUBSCRIBER_SECRETS has %d entries but only %d subscribers are configured", len(entries), len(subscribers))
+	}
+
+	for i, entry := range entries {
+		subSecrets := splitAndTrim(entry, "|")
+		if len(subSecrets) > 2 {
+			return nil, fmt.Errorf("SUBSCRIBER_SECRETS entry %d has more than two secrets", i+1)
+		}
+		if len(subSecrets) > 0 {
+			secrets[subscribers[i]] = subSecrets
+		}
+	}
+
+	return secrets, nil
+}
+
+// parseSubjects maps the comma-separated subject patterns to the subscribers in the same position.
+// Each entry may hold several patterns separated by "|"; empty entries deliver all events.
+func parseSubjects(subscribers []string, s string) (map[string][]string, error) {
+	subjects := make(map[string][]string)
+	if strings.TrimSpace(s) == "" {
+		return subjects, nil
+	}
+
+	entries := strings.Split(s, ",")
+	if len(entries) > len(subscribers) {
+		return nil, fmt.Errorf("SUBSCRIBER_SUBJECTS has %d entries but only %d subscribers are configured", len(entries), len(subscribers))
+	}
+
+	for i, entry := range entries {
+		patterns := splitAndTrim(entry, "|")
+		for _, pattern := range patterns {
+			if err := event.ValidateSubjectPattern(pattern); err != nil {
+				return nil, fmt.Errorf("SUBSCRIBER_SUBJECTS entry %d: %w", i+1, err)
+			}
+		}
+		if len(patterns) > 0 {
+			subjects[subscribers[i]] = patterns
+		}
+	}
+
+	return subjects, nil
+}
+
+// parseFormats maps the comma-separated event formats to the subscribers in the same position.
+// Each entry is the media type of an event format, e.g. application/cloudevents+avro; empty entries
+// use the default encoding.
+func parseFormats(subscribers []string, s string) (map[string]string, error) {
+	formats := make(map[string]string)
+	if strings.TrimSpace(s) == "" {
+		return formats, nil
+	}
+
+	entries := strings.Split(s, ",")
+	if len(entries) > len(subscribers) {
+		return nil, fmt.Errorf("SUBSCRIBER_FORMATS has %d entries but only %d subscribers are configured", len(entries), len(subscribers))
+	}
+
+	for i, entry := range entries {
+		format, err := event.ParseFormat(entry)
+		if err != nil {
+			return nil, fmt.Errorf("SUBSCRIBER_FORMATS entry %d: %w", i+1, err)
+		}
+		if format != "" {
+			formats[subscribers[i]] = format
+		}
+	}
+
+	return formats, nil
+}
+
+// parseWebhookOrigin returns the origin announced in the webhook validation handshake.
+// WEBHOOK_ORIGIN defaults to the host name; WEBHOOK_HANDSHAKE=false disables the handshake.
+func parseWebhookOrigin() string {
+	if !parseEnvBool("WEBHOOK_HANDSHAKE", true) {
+		return ""
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+
+	return strings.TrimSpace(parseEnvString("WEBHOOK_ORIGIN", hostname))
+}
+
 // parseEnvString reads an environment variable by name and returns its value, or the provided default if unset.
 func parseEnvString(name, defaultValue string) string {
 	v := os.Getenv(name)
@@ -39,6 +200,23 @@ func parseEnvString(name, defaultValue string) string {
 	return v
 }
 
+// parseEnvBool reads an environment variable by name and converts it to bool.
+// If conversion fails, logs an error and returns the provided default value.
+func parseEnvBool(name string, defaultValue bool) bool {
+	v := os.Getenv(name)
+	if v == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid %s value: %v\n", name, err)
+		return defaultValue
+	}
+
+	return b
+}
+
 // parseEnvInt reads an environment variable by name and converts it to int.
 // If conversion fails, logs an error and returns the provided default value.
 func parseEnvInt(name string, defaultValue int) int {
@@ -4,6 +4,9 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/nicograef/cloudevents/event"
 )
 
 func TestLoad_MissingSubscribers(t *testing.T) {
@@ -30,6 +33,18 @@ func TestLoad_Defaults(t *testing.T) {
 	if cfg.Port != 3000 {
 		t.Errorf("expected default port 3000, got %d", cfg.Port)
 	}
+	if cfg.Capacity != 1000 {
+		t.Errorf("expected default capacity 1000, got %d", cfg.Capacity)
+	}
+	if cfg.DeliveryAttempts != 3 {
+		t.Errorf("expected default delivery attempts 3, got %d", cfg.DeliveryAttempts)
+	}
+	if cfg.DeliveryRetention != time.Hour {
+		t.Errorf("expected default delivery retention 1h, got %v", cfg.DeliveryRetention)
+	}
+	if cfg.BreakerThreshold != 5 || cfg.BreakerCooldown != 30*time.Second || cfg.BreakerProbes != 2 || cfg.BreakerBacklog != 1000 {
+		t.Errorf("unexpected default breaker settings: %d, %v, %d, %d", cfg.BreakerThreshold, cfg.BreakerCooldown, cfg.BreakerProbes, cfg.BreakerBacklog)
+	}
 }
 
 func TestLoad_EnvValues(t *testing.T) {
@@ -139,3 +154,245 @@ func TestLoad_HighValues(t *testing.T) {
 		t.Errorf("expected long subscriber URL, got %s", cfg.Subscribers[0])
 	}
 }
+
+func TestLoad_WebhookOrigin(t *testing.T) {
+	os.Clearenv()
+
+	if err := os.Setenv("SUBSCRIBER_URLS", "http://test/webhook"); err != nil {
+		t.Fatalf("Failed to set SUBSCRIBER_URLS: %v", err)
+	}
+	if err := os.Setenv("WEBHOOK_ORIGIN", "bus.example.com"); err != nil {
+		t.Fatalf("Failed to set WEBHOOK_ORIGIN: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if cfg.WebhookOrigin != "bus.example.com" {
+		t.Errorf("expected webhook origin 'bus.example.com', got %q", cfg.WebhookOrigin)
+	}
+}
+
+func TestLoad_WebhookOriginDefault(t *testing.T) {
+	os.Clearenv()
+
+	if err := os.Setenv("SUBSCRIBER_URLS", "http://test/webhook"); err != nil {
+		t.Fatalf("Failed to set SUBSCRIBER_URLS: %v", err)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+	if cfg.WebhookOrigin != hostname {
+		t.Errorf("expected webhook origin %q, got %q", hostname, cfg.WebhookOrigin)
+	}
+}
+
+func TestLoad_WebhookHandshakeDisabled(t *testing.T) {
+	os.Clearenv()
+
+	if err := os.Setenv("SUBSCRIBER_URLS", "http://test/webhook"); err != nil {
+		t.Fatalf("Failed to set SUBSCRIBER_URLS: %v", err)
+	}
+	if err := os.Setenv("WEBHOOK_HANDSHAKE", "false"); err != nil {
+		t.Fatalf("Failed to set WEBHOOK_HANDSHAKE: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if cfg.WebhookOrigin != "" {
+		t.Errorf("expected handshake to be disabled, got webhook origin %q", cfg.WebhookOrigin)
+	}
+}
+
+func TestLoad_SubscriberSecrets(t *testing.T) {
+	os.Clearenv()
+
+	if err := os.Setenv("SUBSCRIBER_URLS", "http://a/webhook,http://b/webhook,http://c/webhook"); err != nil {
+		t.Fatalf("Failed to set SUBSCRIBER_URLS: %v", err)
+	}
+	if err := os.Setenv("SUBSCRIBER_SECRETS", "new|old,,secret-c"); err != nil {
+		t.Fatalf("Failed to set SUBSCRIBER_SECRETS: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if got := cfg.Secrets["http://a/webhook"]; len(got) != 2 || got[0] != "new" || got[1] != "old" {
+		t.Errorf("expected rotated secrets for subscriber a, got %v", got)
+	}
+	if got, ok := cfg.Secrets["http://b/webhook"]; ok {
+		t.Errorf("expected no secrets for subscriber b, got %v", got)
+	}
+	if got := cfg.Secrets["http://c/webhook"]; len(got) != 1 || got[0] != "secret-c" {
+		t.Errorf("expected one secret for subscriber c, got %v", got)
+	}
+}
+
+func TestLoad_SubscriberSecretsInvalid(t *testing.T) {
+	cases := map[string]string{
+		"too many entries": "a,b",
+		"too many secrets": "a|b|c",
+	}
+
+	for name, secrets := range cases {
+		t.Run(name, func(t *testing.T) {
+			os.Clearenv()
+
+			if err := os.Setenv("SUBSCRIBER_URLS", "http://a/webhook"); err != nil {
+				t.Fatalf("Failed to set SUBSCRIBER_URLS: %v", err)
+			}
+			if err := os.Setenv("SUBSCRIBER_SECRETS", secrets); err != nil {
+				t.Fatalf("Failed to set SUBSCRIBER_SECRETS: %v", err)
+			}
+
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), "SUBSCRIBER_SECRETS") {
+				t.Fatalf("expected error about SUBSCRIBER_SECRETS, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoad_SubscriberSubjects(t *testing.T) {
+	os.Clearenv()
+
+	if err := os.Setenv("SUBSCRIBER_URLS", "http://a/webhook,http://b/webhook"); err != nil {
+		t.Fatalf("Failed to set SUBSCRIBER_URLS: %v", err)
+	}
+	if err := os.Setenv("SUBSCRIBER_SUBJECTS", "/libraries/7/**|/libraries/*/books/123"); err != nil {
+		t.Fatalf("Failed to set SUBSCRIBER_SUBJECTS: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if got := cfg.Subjects["http://a/webhook"]; len(got) != 2 || got[0] != "/libraries/7/**" || got[1] != "/libraries/*/books/123" {
+		t.Errorf("expected two patterns for subscriber a, got %v", got)
+	}
+	if got, ok := cfg.Subjects["http://b/webhook"]; ok {
+		t.Errorf("expected no patterns for subscriber b, got %v", got)
+	}
+}
+
+func TestLoad_SubscriberSubjectsInvalid(t *testing.T) {
+	cases := map[string]string{
+		"too many entries": "/a,/b",
+		"invalid pattern":  "/libraries/7*",
+	}
+
+	for name, subjects := range cases {
+		t.Run(name, func(t *testing.T) {
+			os.Clearenv()
+
+			if err := os.Setenv("SUBSCRIBER_URLS", "http://a/webhook"); err != nil {
+				t.Fatalf("Failed to set SUBSCRIBER_URLS: %v", err)
+			}
+			if err := os.Setenv("SUBSCRIBER_SUBJECTS", subjects); err != nil {
+				t.Fatalf("Failed to set SUBSCRIBER_SUBJECTS: %v", err)
+			}
+
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), "SUBSCRIBER_SUBJECTS") {
+				t.Fatalf("expected error about SUBSCRIBER_SUBJECTS, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoad_SubscriberFormats(t *testing.T) {
+	os.Clearenv()
+
+	if err := os.Setenv("SUBSCRIBER_URLS", "http://a/webhook,http://b/webhook"); err != nil {
+		t.Fatalf("Failed to set SUBSCRIBER_URLS: %v", err)
+	}
+	if err := os.Setenv("SUBSCRIBER_FORMATS", ",application/cloudevents+avro"); err != nil {
+		t.Fatalf("Failed to set SUBSCRIBER_FORMATS: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if got, ok := cfg.Formats["http://a/webhook"]; ok {
+		t.Errorf("expected no format for subscriber a, got %q", got)
+	}
+	if got := cfg.Formats["http://b/webhook"]; got != event.ContentTypeAvro {
+		t.Errorf("expected Avro for subscriber b, got %q", got)
+	}
+}
+
+func TestLoad_SubscriberFormatsInvalid(t *testing.T) {
+	cases := map[string]string{
+		"too many entries":   "application/cloudevents+avro,application/cloudevents+json",
+		"unsupported format": "application/xml",
+	}
+
+	for name, formats := range cases {
+		t.Run(name, func(t *testing.T) {
+			os.Clearenv()
+
+			if err := os.Setenv("SUBSCRIBER_URLS", "http://a/webhook"); err != nil {
+				t.Fatalf("Failed to set SUBSCRIBER_URLS: %v", err)
+			}
+			if err := os.Setenv("SUBSCRIBER_FORMATS", formats); err != nil {
+				t.Fatalf("Failed to set SUBSCRIBER_FORMATS: %v", err)
+			}
+
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), "SUBSCRIBER_FORMATS") {
+				t.Fatalf("expected error about SUBSCRIBER_FORMATS, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoad_SchemaValidation(t *testing.T) {
+	os.Clearenv()
+
+	if err := os.Setenv("SUBSCRIBER_URLS", "http://test/webhook"); err != nil {
+		t.Fatalf("Failed to set SUBSCRIBER_URLS: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.SchemaValidation || cfg.SchemaFile != "" || cfg.SchemaCompatibility != event.CompatibilityBackward {
+		t.Errorf("expected schema validation to be disabled and backward compatibility by default, got %+v", cfg)
+	}
+
+	if err := os.Setenv("SCHEMA_VALIDATION", "true"); err != nil {
+		t.Fatalf("Failed to set SCHEMA_VALIDATION: %v", err)
+	}
+	if err := os.Setenv("SCHEMA_FILE", "/data/schemas.json"); err != nil {
+		t.Fatalf("Failed to set SCHEMA_FILE: %v", err)
+	}
+
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !cfg.SchemaValidation || cfg.SchemaFile != "/data/schemas.json" {
+		t.Errorf("expected schema validation with /data/schemas.json, got %v and %q", cfg.SchemaValidation, cfg.SchemaFile)
+	}
+
+	if err := os.Setenv("SCHEMA_COMPATIBILITY", "sideways"); err != nil {
+		t.Fatalf("Failed to set SCHEMA_COMPATIBILITY: %v", err)
+	}
+	if _, err := Load(); err == nil {
+		t.Error("expected error for invalid SCHEMA_COMPATIBILITY")
+	}
+}
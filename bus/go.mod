@@ -2,6 +2,9 @@ module github.com/nicograef/cloudevents/bus
 
 go 1.25.1
 
-require github.com/nicograef/cloudevents/event v0.0.0-20250915211104-c6d6ef787e93
+require (
+	github.com/google/uuid v1.6.0
+	github.com/nicograef/cloudevents/event v0.0.0-20250915211104-c6d6ef787e93
+)
 
-require github.com/google/uuid v1.6.0 // indirect
+replace github.com/nicograef/cloudevents/event => ../event
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
@@ -18,29 +19,41 @@ type AddEventResponseSuccess struct {
 }
 
 // AddEventResponseError represents a failed response from the enqueue API endpoint.
+// Violations lists why the data does not conform to the schema of the event type, if that is the error.
 type AddEventResponseError struct {
-	Ok    bool   `json:"ok"`
-	Error string `json:"error"`
+	Ok         bool                    `json:"ok"`
+	Error      string                  `json:"error"`
+	Violations []event.SchemaViolation `json:"violations,omitempty"`
 }
 
+// EventWriter stores events. *database.Database stores them locally, *cluster.Node commits them to the cluster.
+type EventWriter interface {
+	AddEvent(candidate event.Candidate) (*event.Event, error)
+	AppendEvent(e event.Event) (*event.Event, bool, error)
+}
+
+var _ EventWriter = (*database.Database)(nil)
+
 // NewAddEventHandler creates an HTTP handler for adding events to the database.
-func NewAddEventHandler(db database.Database) http.HandlerFunc {
+// The request body is a JSON candidate or an event in another format, such as application/cloudevents+protobuf.
+func NewAddEventHandler(db EventWriter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if !validateMethod(w, r, http.MethodPost) {
 			return
 		}
 
 		candidate := event.Candidate{}
-		if !readJSONRequest(w, r, &candidate) {
+		if !readCandidateRequest(w, r, &candidate) {
 			return
 		}
 
 		event, err := db.AddEvent(candidate)
 		if err != nil {
 			log.Printf("ERROR Failed to add event to database: %v", err)
-			sendJSONResponse(w, AddEventResponseError{
-				Ok:    false,
-				Error: err.Error(),
+			sendJSONResponseWithStatus(w, writeErrorStatus(err), AddEventResponseError{
+				Ok:         false,
+				Error:      err.Error(),
+				Violations: schemaViolations(err),
 			})
 			return
 		}
@@ -54,3 +67,20 @@ func NewAddEventHandler(db database.Database) http.HandlerFunc {
 	}
 
 }
+
+// writeErrorStatus returns 503 for writes that reached a node which is not the cluster leader, 507 for writes
+// that exceed the quota, 422 for events that do not conform to their schema and 200 otherwise, as failed writes
+// report their error in the response body.
+func writeErrorStatus(err error) int {
+	if isNotLeader(err) {
+		return http.StatusServiceUnavailable
+	}
+	if errors.Is(err, database.ErrQuotaExceeded) {
+		return http.StatusInsufficientStorage
+	}
+	if schemaViolations(err) != nil {
+		return http.StatusUnprocessableEntity
+	}
+
+	return http.StatusOK
+}
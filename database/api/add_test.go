@@ -14,7 +14,7 @@ import (
 
 func TestNewAddEventHandler_Success(t *testing.T) {
 	db := database.New()
-	handler := NewAddEventHandler(*db)
+	handler := NewAddEventHandler(db)
 
 	e := event.Candidate{Type: "com.example.event:v1", Source: "https://example.com", Subject: "/users/123", Data: map[string]any{"k": "v"}}
 	body, _ := json.Marshal(e)
@@ -41,7 +41,7 @@ func TestNewAddEventHandler_Success(t *testing.T) {
 
 func TestNewAddEventHandler_MethodNotAllowed(t *testing.T) {
 	db := database.New()
-	handler := NewAddEventHandler(*db)
+	handler := NewAddEventHandler(db)
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	rec := httptest.NewRecorder()
 	handler(rec, req)
@@ -49,3 +49,45 @@ func TestNewAddEventHandler_MethodNotAllowed(t *testing.T) {
 		t.Errorf("expected 405, got %d", rec.Code)
 	}
 }
+
+func TestNewAddEventHandler_Protobuf(t *testing.T) {
+	db := database.New()
+	handler := NewAddEventHandler(db)
+
+	e := event.Event{ID: uuid.New(), Type: "com.example.event:v1", Source: "https://example.com", Subject: "/users/123", DataContentType: "text/plain", Data: "hello"}
+	body, err := event.MarshalProto(e)
+	if err != nil {
+		t.Fatalf("failed to encode event: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", event.ContentTypeProtobuf)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	var resp AddEventResponseSuccess
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || !resp.Ok {
+		t.Fatalf("expected ok response, got %+v, %v", resp, err)
+	}
+	stored := db.GetEvent(resp.Event.Source, resp.Event.ID)
+	if stored.ID == e.ID || stored.Data != "hello" || stored.DataContentType != "text/plain" {
+		t.Errorf("expected a new event with the text data, got %+v", stored)
+	}
+}
+
+func TestNewAddEventHandler_BinaryData(t *testing.T) {
+	db := database.New()
+	handler := NewAddEventHandler(db)
+
+	body := `{"type":"com.example.cover:v1","source":"https://example.com","subject":"/books/123","datacontenttype":"image/png","data_base64":"iVBORw=="}`
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body)))
+
+	var resp AddEventResponseSuccess
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || !resp.Ok {
+		t.Fatalf("expected ok response, got %+v, %v", resp, err)
+	}
+	stored := db.GetEvent(resp.Event.Source, resp.Event.ID)
+	if data, ok := stored.Data.([]byte); !ok || string(data) != "\x89PNG" || stored.DataContentType != "image/png" {
+		t.Errorf("expected binary data, got %T %v", stored.Data, stored.Data)
+	}
+}
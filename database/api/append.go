@@ -0,0 +1,55 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+// AppendEventResponseSuccess represents a successful response from the append API endpoint.
+// Duplicate is true if the event had been stored before; Event is then the originally stored event.
+type AppendEventResponseSuccess struct {
+	Ok        bool        `json:"ok"`
+	Event     event.Event `json:"event"`
+	Duplicate bool        `json:"duplicate"`
+}
+
+// NewAppendEventHandler creates an HTTP handler for appending complete events with producer-chosen ID and time.
+// Appending the same event (same source and ID) again is safe and returns the originally stored event.
+// The request body is a JSON event or an event in another format, such as application/cloudevents+protobuf.
+func NewAppendEventHandler(db EventWriter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodPost) {
+			return
+		}
+
+		e := event.Event{}
+		if !readEventRequest(w, r, &e) {
+			return
+		}
+
+		stored, duplicate, err := db.AppendEvent(e)
+		if err != nil {
+			log.Printf("ERROR Failed to append event to database: %v", err)
+			sendJSONResponseWithStatus(w, writeErrorStatus(err), AddEventResponseError{
+				Ok:         false,
+				Error:      err.Error(),
+				Violations: schemaViolations(err),
+			})
+			return
+		}
+
+		if duplicate {
+			log.Printf("INFO Event already in database: %s", stored.ID)
+		} else {
+			log.Printf("INFO Appended event to database: %s", stored.ID)
+		}
+
+		sendJSONResponse(w, AppendEventResponseSuccess{
+			Ok:        true,
+			Event:     *stored,
+			Duplicate: duplicate,
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/event"
+)
+
+func TestNewAppendEventHandler_Success(t *testing.T) {
+	db := database.New()
+	handler := NewAppendEventHandler(db)
+
+	e := event.Event{ID: uuid.New(), Type: "com.example.event:v1", Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Source: "https://example.com", Subject: "/users/123", Data: map[string]any{"k": "v"}}
+	body, _ := json.Marshal(e)
+
+	for i, expectDuplicate := range []bool{false, true} {
+		req := httptest.NewRequest(http.MethodPost, "/append", bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+
+		handler(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected status 200, got %d", i, rec.Code)
+		}
+		var resp AppendEventResponseSuccess
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("request %d: failed to decode response: %v", i, err)
+		}
+		if !resp.Ok || resp.Duplicate != expectDuplicate {
+			t.Errorf("request %d: unexpected response %+v", i, resp)
+		}
+		if resp.Event.ID != e.ID || !resp.Event.Time.Equal(e.Time) {
+			t.Errorf("request %d: expected producer-chosen ID and time, got %+v", i, resp.Event)
+		}
+	}
+
+	if db.Count() != 1 {
+		t.Errorf("expected 1 stored event, got %d", db.Count())
+	}
+}
+
+func TestNewAppendEventHandler_Protobuf(t *testing.T) {
+	db := database.New()
+	handler := NewAppendEventHandler(db)
+
+	e := event.Event{ID: uuid.New(), Type: "com.example.event:v1", Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Source: "https://example.com", Subject: "/users/123", Data: map[string]any{"k": "v"}, Extensions: map[string]any{"priority": 2}}
+	body, err := event.MarshalProto(e)
+	if err != nil {
+		t.Fatalf("failed to encode event: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/append", bytes.NewReader(body))
+	req.Header.Set("Content-Type", event.ContentTypeProtobuf)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	var resp AppendEventResponseSuccess
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || !resp.Ok {
+		t.Fatalf("expected ok response, got %+v, %v", resp, err)
+	}
+	stored := db.GetEvent(e.Source, e.ID)
+	if stored == nil || !stored.Time.Equal(e.Time) || stored.Extensions["priority"] != int32(2) {
+		t.Errorf("expected the protobuf event to be stored, got %+v", stored)
+	}
+}
+
+func TestNewAppendEventHandler_InvalidEvent(t *testing.T) {
+	db := database.New()
+	handler := NewAppendEventHandler(db)
+
+	body := bytes.NewBufferString(`{"id":"550e8400-e29b-41d4-a716-446655440000","type":"abc"}`)
+	req := httptest.NewRequest(http.MethodPost, "/append", body)
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	var resp AddEventResponseError
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Ok || resp.Error == "" {
+		t.Errorf("expected error response, got %+v", resp)
+	}
+}
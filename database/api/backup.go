@@ -0,0 +1,80 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/database/namespace"
+)
+
+// BackupRequest represents the expected request body for the backup API endpoint.
+// Name is the directory of the backup within the backup directory; it defaults to the current time.
+type BackupRequest struct {
+	Name string `json:"name"`
+}
+
+// BackupResponseSuccess represents a successful response from the backup API endpoint.
+type BackupResponseSuccess struct {
+	Ok       bool                    `json:"ok"`
+	Path     string                  `json:"path"`
+	Manifest database.BackupManifest `json:"manifest"`
+}
+
+// NewBackupHandler creates an HTTP handler that writes an online backup of the database
+// into a new directory within the backup directory. Backups only cover the default namespace,
+// so they are rejected while other namespaces exist.
+func NewBackupHandler(db *database.Database, namespaces *namespace.Registry, backupDir string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodPost) {
+			return
+		}
+
+		if len(namespaces.List()) > 0 {
+			sendJSONResponseWithStatus(w, http.StatusConflict, AddEventResponseError{
+				Ok:    false,
+				Error: "backups are not supported while namespaces exist",
+			})
+			return
+		}
+
+		request := BackupRequest{}
+		if r.ContentLength != 0 && !readJSONRequest(w, r, &request) {
+			return
+		}
+
+		name := request.Name
+		if name == "" {
+			name = time.Now().UTC().Format("20060102T150405Z")
+		}
+		if name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			sendJSONResponseWithStatus(w, http.StatusBadRequest, AddEventResponseError{
+				Ok:    false,
+				Error: "invalid backup name: must not contain path separators",
+			})
+			return
+		}
+
+		path := filepath.Join(backupDir, name)
+		manifest, err := db.Backup(path)
+		if err != nil {
+			log.Printf("ERROR Backup to %s failed: %v", path, err)
+			sendJSONResponse(w, AddEventResponseError{
+				Ok:    false,
+				Error: err.Error(),
+			})
+			return
+		}
+
+		log.Printf("INFO Backed up %d events up to position %d to %s", manifest.EventCount, manifest.LastPosition, path)
+
+		sendJSONResponse(w, BackupResponseSuccess{
+			Ok:       true,
+			Path:     path,
+			Manifest: manifest,
+		})
+	}
+}
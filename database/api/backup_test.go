@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/database/namespace"
+	"github.com/nicograef/cloudevents/event"
+)
+
+func TestNewBackupHandler(t *testing.T) {
+	db := database.New()
+	db.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}})
+
+	backupDir := t.TempDir()
+	registry, _ := namespace.NewRegistry(t.TempDir(), func(string) (*database.Database, error) { return database.New(), nil })
+	handler := NewBackupHandler(db, registry, backupDir)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/admin/backup", strings.NewReader(`{"name":"nightly"}`)))
+
+	var resp BackupResponseSuccess
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.Ok || resp.Path != filepath.Join(backupDir, "nightly") || resp.Manifest.EventCount != 1 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if _, err := os.Stat(filepath.Join(backupDir, "nightly", "manifest.json")); err != nil {
+		t.Errorf("expected manifest to be written: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/admin/backup", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected backup with default name to succeed, got status %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/admin/backup", strings.NewReader(`{"name":"../escape"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for name with path separator, got %d", rec.Code)
+	}
+
+	registry.Create("tenant-a", namespace.Settings{})
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/admin/backup", strings.NewReader(`{"name":"tenants"}`)))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 while namespaces exist, got %d", rec.Code)
+	}
+}
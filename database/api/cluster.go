@@ -0,0 +1,133 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/nicograef/cloudevents/database/cluster"
+)
+
+// ClusterResponse reports the Raft state and the members of the cluster.
+type ClusterResponse struct {
+	Ok      bool           `json:"ok"`
+	Cluster cluster.Status `json:"cluster"`
+}
+
+// LeaveClusterRequest represents the expected request body for the cluster leave API endpoint.
+type LeaveClusterRequest struct {
+	ID string `json:"id"`
+}
+
+// NewClusterStatusHandler creates an HTTP handler that reports the Raft state and the members of the cluster.
+func NewClusterStatusHandler(node *cluster.Node) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		sendJSONResponse(w, ClusterResponse{
+			Ok:      true,
+			Cluster: node.Status(),
+		})
+	}
+}
+
+// NewJoinClusterHandler creates an HTTP handler that adds a node as a voting member of the cluster.
+// It expects a POST request with the member ID, Raft address and HTTP address and must reach the leader.
+func NewJoinClusterHandler(node *cluster.Node) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodPost) {
+			return
+		}
+
+		member := cluster.Member{}
+		if !readJSONRequest(w, r, &member) {
+			return
+		}
+
+		if err := node.Join(member); err != nil {
+			log.Printf("ERROR Failed to add %s to the cluster: %v", member.ID, err)
+			sendJSONResponse(w, AddEventResponseError{
+				Ok:    false,
+				Error: err.Error(),
+			})
+			return
+		}
+
+		log.Printf("INFO Added %s at %s to the cluster", member.ID, member.RaftAddr)
+
+		sendJSONResponse(w, ClusterResponse{
+			Ok:      true,
+			Cluster: node.Status(),
+		})
+	}
+}
+
+// NewLeaveClusterHandler creates an HTTP handler that removes a node from the cluster.
+// It expects a POST request with the member ID and must reach the leader.
+func NewLeaveClusterHandler(node *cluster.Node) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodPost) {
+			return
+		}
+
+		request := LeaveClusterRequest{}
+		if !readJSONRequest(w, r, &request) {
+			return
+		}
+
+		if err := node.Leave(request.ID); err != nil {
+			log.Printf("ERROR Failed to remove %s from the cluster: %v", request.ID, err)
+			sendJSONResponse(w, AddEventResponseError{
+				Ok:    false,
+				Error: err.Error(),
+			})
+			return
+		}
+
+		log.Printf("INFO Removed %s from the cluster", request.ID)
+
+		sendJSONResponse(w, ClusterResponse{
+			Ok:      true,
+			Cluster: node.Status(),
+		})
+	}
+}
+
+// ForwardToLeader wraps a handler that writes to the cluster. On a node that is not the leader, the request
+// is forwarded to the leader and its response is returned. Without a known leader it responds with 503.
+func ForwardToLeader(node *cluster.Node, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if node.IsLeader() {
+			next(w, r)
+			return
+		}
+
+		leader, err := url.Parse(node.LeaderURL())
+		if err != nil || leader.Host == "" {
+			sendJSONResponseWithStatus(w, http.StatusServiceUnavailable, AddEventResponseError{
+				Ok:    false,
+				Error: cluster.ErrNotLeader.Error() + " and no leader is known",
+			})
+			return
+		}
+
+		proxy := httputil.NewSingleHostReverseProxy(leader)
+		proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Printf("ERROR Failed to forward %s to the leader at %s: %v", r.URL.Path, leader, err)
+			sendJSONResponseWithStatus(w, http.StatusBadGateway, AddEventResponseError{
+				Ok:    false,
+				Error: "cannot reach the cluster leader",
+			})
+		}
+		proxy.ServeHTTP(w, r)
+	}
+}
+
+// isNotLeader reports whether the error means that the write reached a node that lost its leadership.
+func isNotLeader(err error) bool {
+	return errors.Is(err, cluster.ErrNotLeader)
+}
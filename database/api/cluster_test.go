@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nicograef/cloudevents/database/cluster"
+	"github.com/nicograef/cloudevents/database/database"
+)
+
+// newTestNode starts a cluster node on a free local port.
+func newTestNode(t *testing.T, id string, bootstrap bool) *cluster.Node {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	node, err := cluster.NewNode(cluster.Config{ID: id, RaftAddr: addr, HTTPAddr: "http://" + id, DataDir: t.TempDir(), Bootstrap: bootstrap}, database.New())
+	if err != nil {
+		t.Fatalf("NewNode failed: %v", err)
+	}
+	t.Cleanup(func() { node.Shutdown() })
+
+	if bootstrap {
+		deadline := time.Now().Add(10 * time.Second)
+		for !node.IsLeader() {
+			if time.Now().After(deadline) {
+				t.Fatal("node did not become leader")
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	return node
+}
+
+func TestNewClusterStatusHandler(t *testing.T) {
+	node := newTestNode(t, "node-1", true)
+	handler := NewClusterStatusHandler(node)
+
+	req := httptest.NewRequest(http.MethodGet, "/cluster", nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	var resp ClusterResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.Ok || resp.Cluster.ID != "node-1" || resp.Cluster.State != "Leader" || len(resp.Cluster.Members) != 1 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+}
+
+func TestNewJoinClusterHandler_InvalidMember(t *testing.T) {
+	node := newTestNode(t, "node-1", true)
+	handler := NewJoinClusterHandler(node)
+
+	req := httptest.NewRequest(http.MethodPost, "/cluster/join", strings.NewReader(`{"id":"node-2"}`))
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	var resp AddEventResponseError
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Ok || resp.Error == "" {
+		t.Errorf("expected error response, got %+v", resp)
+	}
+}
+
+func TestForwardToLeader(t *testing.T) {
+	called := false
+	next := func(w http.ResponseWriter, r *http.Request) { called = true }
+
+	t.Run("leader handles the request", func(t *testing.T) {
+		node := newTestNode(t, "node-1", true)
+
+		w := httptest.NewRecorder()
+		ForwardToLeader(node, next)(w, httptest.NewRequest(http.MethodPost, "/add", nil))
+
+		if !called {
+			t.Error("expected the leader to handle the request")
+		}
+	})
+
+	t.Run("no known leader", func(t *testing.T) {
+		called = false
+		node := newTestNode(t, "node-2", false)
+
+		w := httptest.NewRecorder()
+		ForwardToLeader(node, next)(w, httptest.NewRequest(http.MethodPost, "/add", nil))
+
+		if called {
+			t.Error("expected the request not to be handled locally")
+		}
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+		}
+	})
+}
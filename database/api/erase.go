@@ -0,0 +1,66 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/nicograef/cloudevents/database/database"
+)
+
+// EraseRequest represents the expected request body for the erase API endpoint.
+// Key is the data subject key, i.e. the event subject or the value at the configured data path.
+type EraseRequest struct {
+	Key string `json:"key"`
+}
+
+// EraseResponseSuccess represents a successful response from the erase API endpoint.
+type EraseResponseSuccess struct {
+	Ok     bool   `json:"ok"`
+	Key    string `json:"key"`
+	Events int    `json:"events"`
+}
+
+// NewEraseHandler creates an HTTP handler that destroys the encryption key of a data subject.
+// The affected events keep their metadata and positions but are returned with redacted data.
+// The database is persisted to the data directory afterwards, because snapshots of the affected subjects are removed.
+func NewEraseHandler(db *database.Database, dataDir string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodPost) {
+			return
+		}
+
+		request := EraseRequest{}
+		if !readJSONRequest(w, r, &request) {
+			return
+		}
+
+		if request.Key == "" {
+			sendJSONResponseWithStatus(w, http.StatusBadRequest, AddEventResponseError{
+				Ok:    false,
+				Error: "key is required",
+			})
+			return
+		}
+
+		events, err := db.EraseKey(request.Key)
+		if err != nil {
+			log.Printf("ERROR Failed to erase key: %v", err)
+			sendJSONResponse(w, AddEventResponseError{
+				Ok:    false,
+				Error: err.Error(),
+			})
+			return
+		}
+
+		log.Printf("INFO Erased key of %d events", events)
+		if err := db.Persist(dataDir); err != nil {
+			log.Printf("ERROR Failed to persist database after erasure: %v", err)
+		}
+
+		sendJSONResponse(w, EraseResponseSuccess{
+			Ok:     true,
+			Key:    request.Key,
+			Events: events,
+		})
+	}
+}
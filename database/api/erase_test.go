@@ -0,0 +1,43 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/database/shred"
+	"github.com/nicograef/cloudevents/event"
+)
+
+func TestNewEraseHandler(t *testing.T) {
+	keys, _ := shred.NewKeyStore("")
+	cipher, _ := shred.NewCipher(keys, shred.KeyBySubject)
+	db := database.New()
+	db.SetPayloadCipher(cipher)
+	e, _ := db.AddEvent(event.Candidate{Type: "user.registered", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{"email": "john@example.com"}})
+
+	handler := NewEraseHandler(db, t.TempDir())
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/erase", strings.NewReader(`{"key":"/users/1"}`)))
+
+	var resp EraseResponseSuccess
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.Ok || resp.Events != 1 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if _, ok := db.GetEvent(e.Source, e.ID).Data.(shred.Erased); !ok {
+		t.Error("expected event data to be erased")
+	}
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/erase", strings.NewReader(`{"key":""}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for missing key, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/database/query"
+	"github.com/nicograef/cloudevents/event"
+)
+
+// GetEventsResponseSuccess represents a successful response from the events API endpoint.
+type GetEventsResponseSuccess struct {
+	Ok     bool          `json:"ok"`
+	Events []event.Event `json:"events"`
+}
+
+// NewGetEventsHandler creates an HTTP handler for querying events sorted by their timestamp.
+// It expects a GET request with the optional query parameters "type", "subject", "from", "to" and "where".
+// The subject may be a pattern such as /libraries/7/** (see event.MatchSubject).
+// "where" filters on the data payload, e.g. data.memberId = 99 (see query.Query).
+// The time bounds are inclusive RFC 3339 timestamps; "to" alone returns the events as of that time.
+// With "upcast=true" and a versioned type such as com.library.book.borrowed:v3, the events of all earlier
+// versions of the type are upcast to that version with the upcasters, and "where" applies to the upcast data.
+func NewGetEventsHandler(db *database.Database, upcasters *event.Upcasters) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		params := r.URL.Query()
+
+		timeRange, ok := readTimeRange(w, params)
+		if !ok {
+			return
+		}
+
+		eventType, subject := params.Get("type"), params.Get("subject")
+		if !validateSubjectPattern(w, subject) {
+			return
+		}
+
+		var where *query.Query
+		if s := params.Get("where"); s != "" {
+			q, err := query.Parse(s)
+			if err != nil {
+				sendJSONResponseWithStatus(w, http.StatusBadRequest, AddEventResponseError{
+					Ok:    false,
+					Error: "invalid where clause: " + err.Error(),
+				})
+				return
+			}
+			where = &q
+		}
+
+		if params.Get("upcast") != "true" {
+			sendJSONResponse(w, GetEventsResponseSuccess{
+				Ok:     true,
+				Events: queryEvents(db, eventType, subject, where, timeRange),
+			})
+			return
+		}
+
+		events, err := queryUpcastEvents(db, upcasters, eventType, subject, where, timeRange)
+		if err != nil {
+			status := http.StatusBadRequest
+			if !errors.Is(err, event.ErrNoUpcaster) && !errors.Is(err, errUpcastType) {
+				status = http.StatusInternalServerError
+			}
+			sendJSONResponseWithStatus(w, status, AddEventResponseError{
+				Ok:    false,
+				Error: err.Error(),
+			})
+			return
+		}
+
+		sendJSONResponse(w, GetEventsResponseSuccess{
+			Ok:     true,
+			Events: events,
+		})
+	}
+}
+
+var errUpcastType = errors.New("upcast requires a versioned type such as com.library.book.borrowed:v2")
+
+// queryEvents returns the events matching the optional type, subject pattern and where clause in the time range.
+func queryEvents(db *database.Database, eventType, subject string, where *query.Query, timeRange database.TimeRange) []event.Event {
+	switch {
+	case where != nil:
+		events := db.GetEventsWhere(eventType, *where, timeRange)
+		if subject != "" {
+			events = filterEventsBySubject(events, subject)
+		}
+		return events
+	case event.IsSubjectPattern(subject):
+		events := db.GetEventsMatchingSubject(subject, timeRange)
+		if eventType != "" {
+			events = filterEventsByType(events, eventType)
+		}
+		return events
+	case subject != "":
+		events := db.GetEventsBySubjectInRange(subject, timeRange)
+		if eventType != "" {
+			events = filterEventsByType(events, eventType)
+		}
+		return events
+	case eventType != "":
+		return db.GetEventsByTypeInRange(eventType, timeRange)
+	default:
+		return db.GetEventsInRange(timeRange)
+	}
+}
+
+// queryUpcastEvents returns the events of all versions of the type up to its version, upcast to that version.
+// The where clause is matched against the upcast data.
+func queryUpcastEvents(db *database.Database, upcasters *event.Upcasters, eventType, subject string, where *query.Query, timeRange database.TimeRange) ([]event.Event, error) {
+	name, version := event.ParseEventType(eventType)
+	if version == 0 {
+		return nil, errUpcastType
+	}
+	if upcasters == nil {
+		upcasters = event.NewUpcasters()
+	}
+
+	var stored []event.Event
+	for v := 1; v <= version; v++ {
+		stored = append(stored, queryEvents(db, fmt.Sprintf("%s:v%d", name, v), subject, nil, timeRange)...)
+	}
+	sort.SliceStable(stored, func(i, j int) bool { return stored[i].Time.Before(stored[j].Time) })
+
+	events := make([]event.Event, 0, len(stored))
+	for _, e := range stored {
+		upcast, err := upcasters.Upcast(e, eventType)
+		if err != nil {
+			return nil, fmt.Errorf("event %s: %w", e.ID, err)
+		}
+		if where == nil || where.Match(upcast.Data) {
+			events = append(events, upcast)
+		}
+	}
+
+	return events, nil
+}
+
+// readTimeRange reads the inclusive time bounds "from" and "to" from the query parameters.
+// It sends a 400 response and returns false if a bound is not an RFC 3339 timestamp.
+func readTimeRange(w http.ResponseWriter, params url.Values) (database.TimeRange, bool) {
+	timeRange := database.TimeRange{}
+	for _, bound := range []struct {
+		name string
+		dest *time.Time
+	}{{"from", &timeRange.From}, {"to", &timeRange.To}} {
+		value := params.Get(bound.name)
+		if value == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			sendJSONResponseWithStatus(w, http.StatusBadRequest, AddEventResponseError{
+				Ok:    false,
+				Error: "invalid " + bound.name + " time: must be an RFC 3339 timestamp",
+			})
+			return database.TimeRange{}, false
+		}
+		*bound.dest = t
+	}
+
+	return timeRange, true
+}
+
+// validateSubjectPattern sends a 400 response and returns false if the subject is set but not a valid pattern.
+func validateSubjectPattern(w http.ResponseWriter, subject string) bool {
+	if subject == "" {
+		return true
+	}
+
+	if err := event.ValidateSubjectPattern(subject); err != nil {
+		sendJSONResponseWithStatus(w, http.StatusBadRequest, AddEventResponseError{
+			Ok:    false,
+			Error: err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
+
+func filterEventsBySubject(events []event.Event, pattern string) []event.Event {
+	filtered := make([]event.Event, 0, len(events))
+	for _, e := range events {
+		if event.MatchSubject(pattern, e.Subject) {
+			filtered = append(filtered, e)
+		}
+	}
+
+	return filtered
+}
+
+func filterEventsByType(events []event.Event, eventType string) []event.Event {
+	filtered := make([]event.Event, 0, len(events))
+	for _, e := range events {
+		if e.Type == eventType {
+			filtered = append(filtered, e)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/event"
+)
+
+func TestNewGetEventsHandler(t *testing.T) {
+	db := database.New()
+	for _, e := range []struct {
+		minute  int
+		typ     string
+		subject string
+	}{
+		{10, "order.placed", "/orders/42"},
+		{20, "order.paid", "/orders/42"},
+		{30, "order.placed", "/orders/1"},
+		{40, "order.shipped", "/orders/42"},
+	} {
+		db.AppendEvent(event.Event{ID: uuid.New(), Type: e.typ, Time: time.Date(2024, 1, 1, 0, e.minute, 0, 0, time.UTC), Source: "https://example.com", Subject: e.subject, Data: map[string]any{"amount": e.minute}})
+	}
+	handler := NewGetEventsHandler(db, nil)
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", 4},
+		{"?subject=/orders/42&from=2024-01-01T00:15:00Z&to=2024-01-01T00:40:00Z", 2},
+		{"?subject=/orders/42&type=order.placed", 1},
+		{"?type=order.placed&to=2024-01-01T00:20:00Z", 1},
+		{"?from=2024-01-01T00:30:00Z", 2},
+		{"?subject=/orders/*", 4},
+		{"?subject=/orders/**&type=order.placed&to=2024-01-01T00:30:00Z", 2},
+		{"?where=data.amount+>=+20", 3},
+		{"?type=order.placed&where=data.amount+%3C+20", 1},
+		{"?subject=/orders/42&where=EXISTS+data.amount&from=2024-01-01T00:15:00Z", 2},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/events"+tt.query, nil))
+
+		var resp GetEventsResponseSuccess
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("%q: failed to decode response: %v", tt.query, err)
+		}
+		if !resp.Ok || len(resp.Events) != tt.want {
+			t.Errorf("%q: expected %d events, got %d", tt.query, tt.want, len(resp.Events))
+		}
+	}
+}
+
+func TestNewGetEventsHandler_InvalidQuery(t *testing.T) {
+	handler := NewGetEventsHandler(database.New(), nil)
+
+	for _, query := range []string{"?from=yesterday", "?subject=/orders/4*", "?where=amount+=+1"} {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/events"+query, nil))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected status 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestNewGetEventsHandler_Upcast(t *testing.T) {
+	db := database.New()
+	for i, e := range []struct {
+		typ  string
+		data map[string]any
+	}{
+		{"com.library.book.borrowed:v1", map[string]any{"book": "b-1"}},
+		{"com.library.book.borrowed:v2", map[string]any{"bookId": "b-2"}},
+		{"com.library.book.borrowed:v1", map[string]any{"book": "b-3"}},
+		{"com.library.book.returned:v1", map[string]any{"bookId": "b-1"}},
+	} {
+		db.AppendEvent(event.Event{ID: uuid.New(), Type: e.typ, Time: time.Date(2024, 1, 1, 0, i, 0, 0, time.UTC), Source: "https://example.com", Subject: "/books", Data: e.data})
+	}
+	upcasters := event.NewUpcasters()
+	upcasters.Register("com.library.book.borrowed:v1", func(e event.Event) (event.Event, error) {
+		e.Data = map[string]any{"bookId": e.Data.(map[string]any)["book"]}
+		return e, nil
+	})
+	handler := NewGetEventsHandler(db, upcasters)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/events?type=com.library.book.borrowed:v2&upcast=true", nil))
+	var resp GetEventsResponseSuccess
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.Ok || len(resp.Events) != 3 {
+		t.Fatalf("expected 3 events, got %+v", resp)
+	}
+	for i, want := range []string{"b-1", "b-2", "b-3"} {
+		e := resp.Events[i]
+		if e.Type != "com.library.book.borrowed:v2" || e.Data.(map[string]any)["bookId"] != want {
+			t.Errorf("event %d: unexpected %s %v", i, e.Type, e.Data)
+		}
+	}
+
+	// The where clause matches the upcast data
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/events?type=com.library.book.borrowed:v2&upcast=true&where=data.bookId+=+%22b-3%22", nil))
+	resp = GetEventsResponseSuccess{}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Events) != 1 {
+		t.Errorf("expected 1 event, got %d", len(resp.Events))
+	}
+
+	for _, query := range []string{"?type=com.library.book.borrowed&upcast=true", "?type=com.library.book.returned:v2&upcast=true"} {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/events"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected status 400, got %d", query, rec.Code)
+		}
+	}
+}
@@ -2,15 +2,26 @@ package api
 
 import (
 	"net/http"
+
+	"github.com/nicograef/cloudevents/database/replication"
 )
 
-func NewHealthHandler() http.HandlerFunc {
+// HealthResponse represents the response from the health API endpoint.
+type HealthResponse struct {
+	Ok          bool               `json:"ok"`
+	Replication replication.Status `json:"replication"`
+}
+
+// NewHealthHandler returns an HTTP handler that reports the service health and the replication status.
+func NewHealthHandler(replica *replication.Replica) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if !validateMethod(w, r, http.MethodGet) {
 			return
 		}
 
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
+		sendJSONResponse(w, HealthResponse{
+			Ok:          true,
+			Replication: replica.Status(),
+		})
 	}
 }
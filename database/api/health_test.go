@@ -1,13 +1,18 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/database/replication"
 )
 
 func TestNewHealthHandler(t *testing.T) {
-	handler := NewHealthHandler()
+	replica, _ := replication.NewReplica(database.New(), "", t.TempDir())
+	handler := NewHealthHandler(replica)
 
 	req := httptest.NewRequest(http.MethodGet, "/health", nil)
 	w := httptest.NewRecorder()
@@ -18,13 +23,18 @@ func TestNewHealthHandler(t *testing.T) {
 		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
 	}
 
-	if w.Body.String() != "OK" {
-		t.Errorf("expected body %q, got %q", "OK", w.Body.String())
+	var resp HealthResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.Ok || resp.Replication.Role != replication.RoleLeader {
+		t.Errorf("unexpected response %+v", resp)
 	}
 }
 
 func TestNewHealthHandler_MethodNotAllowed(t *testing.T) {
-	handler := NewHealthHandler()
+	replica, _ := replication.NewReplica(database.New(), "", t.TempDir())
+	handler := NewHealthHandler(replica)
 
 	req := httptest.NewRequest(http.MethodPost, "/health", nil)
 	w := httptest.NewRecorder()
@@ -3,13 +3,21 @@ package api
 // helper function for sending json responses
 import (
 	"encoding/json"
+	"io"
 	"log"
 	"net/http"
+
+	"github.com/nicograef/cloudevents/event"
 )
 
 func sendJSONResponse(w http.ResponseWriter, data any) {
+	sendJSONResponseWithStatus(w, http.StatusOK, data)
+}
+
+// sendJSONResponseWithStatus sends a json response with the given HTTP status code.
+func sendJSONResponseWithStatus(w http.ResponseWriter, status int, data any) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(data)
 }
 
@@ -29,6 +37,55 @@ func readJSONRequest[T any](w http.ResponseWriter, r *http.Request, dest *T) boo
 	return true
 }
 
+// readEventRequest reads an event from the request: a JSON event, a protobuf event
+// (application/cloudevents+protobuf) or, in binary content mode, the event data with the attributes in Ce- headers.
+// It sends a 400 response and returns false if the request is invalid.
+func readEventRequest(w http.ResponseWriter, r *http.Request, dest *event.Event) bool {
+	if event.IsJSONHTTP(r.Header) {
+		return readJSONRequest(w, r, dest)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	e, err := event.DecodeHTTP(r.Header, body)
+	if err != nil {
+		log.Printf("ERROR Failed to decode event request: %v", err)
+		http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	*dest = *e
+
+	return true
+}
+
+// readCandidateRequest reads a candidate from the request like readEventRequest.
+// The ID and time of events in other formats than JSON are ignored.
+func readCandidateRequest(w http.ResponseWriter, r *http.Request, dest *event.Candidate) bool {
+	if event.IsJSONHTTP(r.Header) {
+		return readJSONRequest(w, r, dest)
+	}
+
+	var e event.Event
+	if !readEventRequest(w, r, &e) {
+		return false
+	}
+	*dest = event.Candidate{
+		Type:            e.Type,
+		Source:          e.Source,
+		Subject:         e.Subject,
+		DataContentType: e.DataContentType,
+		DataSchema:      e.DataSchema,
+		Data:            e.Data,
+		Extensions:      e.Extensions,
+	}
+
+	return true
+}
+
 func validateMethod(w http.ResponseWriter, r *http.Request, expectedMethod string) bool {
 	if r.Method != expectedMethod {
 		log.Printf("WARN Invalid method %s, expected %s", r.Method, expectedMethod)
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/database/replication"
+)
+
+// NewMetricsHandler returns an HTTP handler that reports the head and replication metrics
+// in the Prometheus text format.
+func NewMetricsHandler(db *database.Database, replica *replication.Replica) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		status := replica.Status()
+		follower := 0
+		if status.Role == replication.RoleFollower {
+			follower = 1
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		fmt.Fprintf(w, "# HELP database_head Position of the most recently appended event.\n")
+		fmt.Fprintf(w, "# TYPE database_head gauge\n")
+		fmt.Fprintf(w, "database_head %d\n", db.Head())
+		fmt.Fprintf(w, "# HELP database_replication_follower Whether the instance is a follower.\n")
+		fmt.Fprintf(w, "# TYPE database_replication_follower gauge\n")
+		fmt.Fprintf(w, "database_replication_follower %d\n", follower)
+		fmt.Fprintf(w, "# HELP database_replication_position Last leader position applied by the instance.\n")
+		fmt.Fprintf(w, "# TYPE database_replication_position gauge\n")
+		fmt.Fprintf(w, "database_replication_position %d\n", status.Position)
+		fmt.Fprintf(w, "# HELP database_replication_lag Number of leader positions the instance is behind.\n")
+		fmt.Fprintf(w, "# TYPE database_replication_lag gauge\n")
+		fmt.Fprintf(w, "database_replication_lag %d\n", status.Lag)
+	}
+}
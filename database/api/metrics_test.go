@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/database/replication"
+	"github.com/nicograef/cloudevents/event"
+)
+
+func TestNewMetricsHandler(t *testing.T) {
+	db := database.New()
+	db.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}})
+	replica, _ := replication.NewReplica(db, "", t.TempDir())
+
+	rec := httptest.NewRecorder()
+	NewMetricsHandler(db, replica)(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	for _, line := range []string{"database_head 1", "database_replication_follower 0", "database_replication_lag 0"} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("expected metric %q in %q", line, body)
+		}
+	}
+}
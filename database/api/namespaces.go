@@ -0,0 +1,207 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/nicograef/cloudevents/database/namespace"
+)
+
+// NamespaceHeader selects the namespace of a request as an alternative to the /namespaces/{name} path prefix.
+const NamespaceHeader = "X-Namespace"
+
+// namespacePrefix is the path prefix that selects the namespace of a request.
+const namespacePrefix = "/namespaces/"
+
+// CreateNamespaceRequest represents the expected request body for the create namespace API endpoint.
+type CreateNamespaceRequest struct {
+	Name string `json:"name"`
+	namespace.Settings
+}
+
+// NamespaceInfo describes a namespace with its settings and the number of its events.
+type NamespaceInfo struct {
+	Name     string             `json:"name"`
+	Settings namespace.Settings `json:"settings"`
+	Events   int                `json:"events"`
+	Head     int                `json:"head"`
+}
+
+// NamespacesResponse represents a successful response from the list namespaces API endpoint.
+type NamespacesResponse struct {
+	Ok         bool            `json:"ok"`
+	Namespaces []NamespaceInfo `json:"namespaces"`
+}
+
+// DeleteNamespaceResponse represents a successful response from the delete namespace API endpoint.
+type DeleteNamespaceResponse struct {
+	Ok   bool   `json:"ok"`
+	Name string `json:"name"`
+}
+
+// NamespaceResponse represents a successful response from the create namespace API endpoint.
+type NamespaceResponse struct {
+	Ok        bool          `json:"ok"`
+	Namespace NamespaceInfo `json:"namespace"`
+}
+
+// NewListNamespacesHandler creates an HTTP handler that lists the namespaces besides the default namespace.
+func NewListNamespacesHandler(registry *namespace.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		namespaces := []NamespaceInfo{}
+		for _, ns := range registry.List() {
+			namespaces = append(namespaces, namespaceInfo(ns))
+		}
+
+		sendJSONResponse(w, NamespacesResponse{
+			Ok:         true,
+			Namespaces: namespaces,
+		})
+	}
+}
+
+// NewCreateNamespaceHandler creates an HTTP handler that creates an empty namespace.
+// It expects a POST request with the name and optionally the retention and quota of the namespace.
+func NewCreateNamespaceHandler(registry *namespace.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodPost) {
+			return
+		}
+
+		request := CreateNamespaceRequest{}
+		if !readJSONRequest(w, r, &request) {
+			return
+		}
+
+		ns, err := registry.Create(request.Name, request.Settings)
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, namespace.ErrExists) {
+				status = http.StatusConflict
+			}
+			log.Printf("WARN Failed to create namespace %q: %v", request.Name, err)
+			sendJSONResponseWithStatus(w, status, AddEventResponseError{
+				Ok:    false,
+				Error: err.Error(),
+			})
+			return
+		}
+
+		log.Printf("INFO Created namespace %s", ns.Name)
+
+		sendJSONResponse(w, NamespaceResponse{
+			Ok:        true,
+			Namespace: namespaceInfo(ns),
+		})
+	}
+}
+
+// NewDeleteNamespaceHandler creates an HTTP handler that deletes the namespace in the path with all its events.
+func NewDeleteNamespaceHandler(registry *namespace.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodDelete) {
+			return
+		}
+
+		name := r.PathValue("name")
+		if err := registry.Delete(name); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, namespace.ErrNotFound) {
+				status = http.StatusNotFound
+			}
+			log.Printf("WARN Failed to delete namespace %q: %v", name, err)
+			sendJSONResponseWithStatus(w, status, AddEventResponseError{
+				Ok:    false,
+				Error: err.Error(),
+			})
+			return
+		}
+
+		log.Printf("INFO Deleted namespace %s", name)
+
+		sendJSONResponse(w, DeleteNamespaceResponse{
+			Ok:   true,
+			Name: name,
+		})
+	}
+}
+
+// WithNamespace dispatches requests to the handler of their namespace, selected by the /namespaces/{name}
+// path prefix or the X-Namespace header. The prefix is removed from the path. Requests without a namespace
+// or for the default namespace go to next; requests for unknown namespaces are rejected with 404.
+// The handler of a namespace is created by routes on its first request.
+func WithNamespace(registry *namespace.Registry, next http.Handler, routes func(*namespace.Namespace) http.Handler) http.Handler {
+	var mu sync.Mutex
+	handlers := make(map[*namespace.Namespace]http.Handler)
+
+	handlerFor := func(ns *namespace.Namespace) http.Handler {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if h, exists := handlers[ns]; exists {
+			return h
+		}
+
+		// Drop the handlers of deleted namespaces
+		for cached := range handlers {
+			if current, exists := registry.Get(cached.Name); !exists || current != cached {
+				delete(handlers, cached)
+			}
+		}
+
+		h := routes(ns)
+		handlers[ns] = h
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.Header.Get(NamespaceHeader)
+		if rest, found := strings.CutPrefix(r.URL.Path, namespacePrefix); found {
+			prefixName, path, _ := strings.Cut(rest, "/")
+			if name != "" && name != prefixName {
+				sendJSONResponseWithStatus(w, http.StatusBadRequest, AddEventResponseError{
+					Ok:    false,
+					Error: "namespace in path and " + NamespaceHeader + " header differ",
+				})
+				return
+			}
+			name = prefixName
+
+			r = r.Clone(r.Context())
+			r.URL.Path = "/" + path
+			r.URL.RawPath = ""
+		}
+
+		if name == "" || name == namespace.Default {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ns, exists := registry.Get(name)
+		if !exists {
+			sendJSONResponseWithStatus(w, http.StatusNotFound, AddEventResponseError{
+				Ok:    false,
+				Error: "namespace " + name + " not found",
+			})
+			return
+		}
+
+		handlerFor(ns).ServeHTTP(w, r)
+	})
+}
+
+func namespaceInfo(ns *namespace.Namespace) NamespaceInfo {
+	return NamespaceInfo{
+		Name:     ns.Name,
+		Settings: ns.Settings,
+		Events:   ns.DB.Count(),
+		Head:     ns.DB.Head(),
+	}
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/database/namespace"
+)
+
+func newTestRegistry(t *testing.T) *namespace.Registry {
+	t.Helper()
+
+	registry, err := namespace.NewRegistry(t.TempDir(), func(string) (*database.Database, error) {
+		return database.New(), nil
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	t.Cleanup(func() { registry.Close() })
+
+	return registry
+}
+
+func TestNamespaceHandlers(t *testing.T) {
+	registry := newTestRegistry(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/namespaces", NewListNamespacesHandler(registry))
+	mux.HandleFunc("POST /admin/namespaces", NewCreateNamespaceHandler(registry))
+	mux.HandleFunc("DELETE /admin/namespaces/{name}", NewDeleteNamespaceHandler(registry))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/namespaces", strings.NewReader(`{"name":"tenant","quota":{"maxEvents":10}}`)))
+	var created NamespaceResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !created.Ok || created.Namespace.Name != "tenant" || created.Namespace.Settings.Quota.MaxEvents != 10 {
+		t.Errorf("unexpected response %+v", created)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/namespaces", strings.NewReader(`{"name":"tenant"}`)))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 for an existing namespace, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/namespaces", strings.NewReader(`{"name":"Not Valid"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid name, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/namespaces", nil))
+	var list NamespacesResponse
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !list.Ok || len(list.Namespaces) != 1 || list.Namespaces[0].Name != "tenant" {
+		t.Errorf("unexpected response %+v", list)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/admin/namespaces/tenant", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/admin/namespaces/tenant", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for a deleted namespace, got %d", rec.Code)
+	}
+}
+
+func TestWithNamespace(t *testing.T) {
+	registry := newTestRegistry(t)
+	if _, err := registry.Create("tenant", namespace.Settings{}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("default " + r.URL.Path))
+	})
+	handler := WithNamespace(registry, next, func(ns *namespace.Namespace) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(ns.Name + " " + r.URL.Path))
+		})
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		status int
+		body   string
+	}{
+		{"no namespace", "/events", "", http.StatusOK, "default /events"},
+		{"default prefix", "/namespaces/default/events", "", http.StatusOK, "default /events"},
+		{"prefix", "/namespaces/tenant/events", "", http.StatusOK, "tenant /events"},
+		{"header", "/events", "tenant", http.StatusOK, "tenant /events"},
+		{"prefix and header", "/namespaces/tenant/events", "tenant", http.StatusOK, "tenant /events"},
+		{"conflicting header", "/namespaces/tenant/events", "other", http.StatusBadRequest, ""},
+		{"unknown namespace", "/namespaces/unknown/events", "", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(NamespaceHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, rec.Code)
+			}
+			if tt.body != "" && rec.Body.String() != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, rec.Body.String())
+			}
+		})
+	}
+}
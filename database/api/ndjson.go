@@ -0,0 +1,89 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/event"
+)
+
+// ImportResponseSuccess represents a successful response from the import API endpoint.
+type ImportResponseSuccess struct {
+	Ok bool `json:"ok"`
+	database.ImportSummary
+}
+
+// NewExportHandler creates an HTTP handler that streams events as NDJSON sorted by their timestamp.
+// It expects a GET request with the optional query parameters "type", "subject", "from" and "to"
+// as for the events API endpoint. Requests that accept application/cloudevents+avro rather than NDJSON
+// receive an Avro object container file instead.
+func NewExportHandler(db *database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		params := r.URL.Query()
+
+		timeRange, ok := readTimeRange(w, params)
+		if !ok {
+			return
+		}
+
+		subject := params.Get("subject")
+		if !validateSubjectPattern(w, subject) {
+			return
+		}
+
+		filter := database.ExportFilter{Type: params.Get("type"), Subject: subject, Range: timeRange}
+		export := db.Export
+		contentType := "application/x-ndjson"
+		if event.NegotiateFormat(r.Header.Get("Accept"), contentType, event.ContentTypeAvro) == event.ContentTypeAvro {
+			export, contentType = db.ExportAvro, event.ContentTypeAvro
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		exported, err := export(w, filter)
+		if err != nil {
+			log.Printf("ERROR Export failed after %d events: %v", exported, err)
+			return
+		}
+
+		log.Printf("INFO Exported %d events", exported)
+	}
+}
+
+// NewImportHandler creates an HTTP handler that imports NDJSON events from the request body, keeping their IDs
+// and times. Events are stored with the writer, which is the database itself or the cluster node.
+// Events that are already stored are skipped. The response summarises the imported, skipped and
+// invalid lines. The database is persisted to the data directory after the import.
+func NewImportHandler(db *database.Database, writer EventWriter, dataDir string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodPost) {
+			return
+		}
+
+		summary, err := database.ImportEvents(r.Body, writer.AppendEvent)
+		if err != nil {
+			log.Printf("ERROR Import failed: %v", err)
+			sendJSONResponseWithStatus(w, http.StatusBadRequest, AddEventResponseError{
+				Ok:    false,
+				Error: err.Error(),
+			})
+			return
+		}
+
+		log.Printf("INFO Imported %d events, skipped %d, invalid %d", summary.Imported, summary.Skipped, summary.Invalid)
+		if summary.Imported > 0 {
+			if err := db.Persist(dataDir); err != nil {
+				log.Printf("ERROR Failed to persist database after import: %v", err)
+			}
+		}
+
+		sendJSONResponse(w, ImportResponseSuccess{
+			Ok:            true,
+			ImportSummary: summary,
+		})
+	}
+}
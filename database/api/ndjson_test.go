@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/event"
+)
+
+func TestNewExportHandler(t *testing.T) {
+	db := database.New()
+	db.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}})
+	db.AddEvent(event.Candidate{Type: "user.logout", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}})
+
+	handler := NewExportHandler(db)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/export?type=user.login", nil))
+
+	if rec.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Errorf("expected NDJSON content type, got %q", rec.Header().Get("Content-Type"))
+	}
+	if lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n"); len(lines) != 1 {
+		t.Errorf("expected 1 exported event, got %d", len(lines))
+	}
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/export?from=yesterday", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for invalid time, got %d", rec.Code)
+	}
+}
+
+func TestNewExportHandler_Avro(t *testing.T) {
+	db := database.New()
+	login, _ := db.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{"k": "v"}})
+	db.AddEvent(event.Candidate{Type: "user.logout", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}})
+
+	handler := NewExportHandler(db)
+
+	req := httptest.NewRequest(http.MethodGet, "/export?type=user.login", nil)
+	req.Header.Set("Accept", "application/x-ndjson;q=0.5, application/cloudevents+avro")
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Header().Get("Content-Type") != event.ContentTypeAvro {
+		t.Errorf("expected Avro content type, got %q", rec.Header().Get("Content-Type"))
+	}
+	events, err := event.ReadAvroFile(rec.Body)
+	if err != nil {
+		t.Fatalf("failed to read Avro export: %v", err)
+	}
+	if len(events) != 1 || events[0].ID != login.ID || events[0].Data.(map[string]any)["k"] != "v" {
+		t.Errorf("unexpected exported events %+v", events)
+	}
+
+	// Clients that accept anything get NDJSON
+	req = httptest.NewRequest(http.MethodGet, "/export", nil)
+	req.Header.Set("Accept", "*/*")
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Errorf("expected NDJSON content type, got %q", rec.Header().Get("Content-Type"))
+	}
+}
+
+func TestNewImportHandler(t *testing.T) {
+	db := database.New()
+	handler := NewImportHandler(db, db, t.TempDir())
+
+	body := `{"id":"f8ceae97-5a98-473d-a075-c1f0a530da2c","type":"user.new","time":"2025-09-01T17:09:53Z","source":"https://example.com","subject":"/users/1","data":{}}
+not json
+`
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(body)))
+
+	var resp ImportResponseSuccess
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.Ok || resp.Imported != 1 || resp.Invalid != 1 || len(resp.Errors) != 1 || resp.Errors[0].Line != 2 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if db.Count() != 1 {
+		t.Errorf("expected 1 stored event, got %d", db.Count())
+	}
+}
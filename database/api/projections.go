@@ -0,0 +1,102 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/nicograef/cloudevents/database/projection"
+)
+
+// ProjectionsResponse lists the status of all projections.
+type ProjectionsResponse struct {
+	Ok          bool                `json:"ok"`
+	Projections []projection.Status `json:"projections"`
+}
+
+// ProjectionResponse holds the status and the current state of a single projection.
+type ProjectionResponse struct {
+	Ok         bool              `json:"ok"`
+	Projection projection.Status `json:"projection"`
+	State      any               `json:"state"`
+}
+
+// ProjectionResponseError represents a failed response from the projections API endpoints.
+type ProjectionResponseError struct {
+	Ok    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// NewProjectionsHandler returns an HTTP handler that reports position and lag of all projections.
+func NewProjectionsHandler(runner *projection.Runner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		sendJSONResponse(w, ProjectionsResponse{
+			Ok:          true,
+			Projections: runner.Statuses(),
+		})
+	}
+}
+
+// NewProjectionHandler returns an HTTP handler that reports the status and state of a projection.
+// It expects a GET request with the projection name as path value "name".
+func NewProjectionHandler(runner *projection.Runner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		status, state, exists := runner.Get(r.PathValue("name"))
+		if !exists {
+			sendJSONResponseWithStatus(w, http.StatusNotFound, ProjectionResponseError{
+				Ok:    false,
+				Error: "projection not found",
+			})
+			return
+		}
+
+		sendJSONResponse(w, ProjectionResponse{
+			Ok:         true,
+			Projection: status,
+			State:      state,
+		})
+	}
+}
+
+// NewRebuildProjectionHandler returns an HTTP handler that rebuilds a projection from the first event.
+// It expects a POST request with the projection name as path value "name".
+// The rebuild runs in the background; its progress is visible via the projection status.
+func NewRebuildProjectionHandler(runner *projection.Runner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodPost) {
+			return
+		}
+
+		name := r.PathValue("name")
+		if _, _, exists := runner.Get(name); !exists {
+			sendJSONResponseWithStatus(w, http.StatusNotFound, ProjectionResponseError{
+				Ok:    false,
+				Error: "projection not found",
+			})
+			return
+		}
+
+		if err := runner.Rebuild(name); err != nil {
+			log.Printf("ERROR Failed to rebuild projection %s: %v", name, err)
+			sendJSONResponse(w, ProjectionResponseError{
+				Ok:    false,
+				Error: err.Error(),
+			})
+			return
+		}
+
+		status, state, _ := runner.Get(name)
+		sendJSONResponseWithStatus(w, http.StatusAccepted, ProjectionResponse{
+			Ok:         true,
+			Projection: status,
+			State:      state,
+		})
+	}
+}
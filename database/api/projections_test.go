@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/database/projection"
+	"github.com/nicograef/cloudevents/event"
+)
+
+func newTestRunner(t *testing.T) *projection.Runner {
+	t.Helper()
+
+	db := database.New()
+	db.AddEvent(event.Candidate{Type: "user.new", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}})
+	db.AddEvent(event.Candidate{Type: "user.new", Source: "https://example.com", Subject: "/users/2", Data: map[string]any{}})
+
+	runner := projection.NewRunner(db, projection.NewMemoryCheckpoints())
+	if err := runner.Register(projection.NewTypeCounts()); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	runner.CatchUp()
+
+	return runner
+}
+
+func TestNewProjectionsHandler(t *testing.T) {
+	handler := NewProjectionsHandler(newTestRunner(t))
+
+	req := httptest.NewRequest(http.MethodGet, "/projections", nil)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	var resp ProjectionsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.Ok || len(resp.Projections) != 1 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if p := resp.Projections[0]; p.Name != "type-counts" || p.Position != 2 || p.Lag != 0 {
+		t.Errorf("unexpected projection status %+v", p)
+	}
+}
+
+func TestNewProjectionHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /projections/{name}", NewProjectionHandler(newTestRunner(t)))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/projections/type-counts", nil))
+
+	var resp struct {
+		Ok    bool           `json:"ok"`
+		State map[string]int `json:"state"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.Ok || resp.State["user.new"] != 2 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/projections/unknown", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for unknown projection, got %d", rec.Code)
+	}
+}
+
+func TestNewRebuildProjectionHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /projections/{name}/rebuild", NewRebuildProjectionHandler(newTestRunner(t)))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/projections/type-counts/rebuild", nil))
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d", rec.Code)
+	}
+	var resp ProjectionResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.Ok || resp.Projection.Position != 0 || resp.Projection.Lag != 2 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/projections/unknown/rebuild", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for unknown projection, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/database/replication"
+)
+
+// maxReplicationWait limits how long a replication log request is held open, below the server write timeout.
+const maxReplicationWait = 20 * time.Second
+
+// ReplicationResponse reports the replication status.
+type ReplicationResponse struct {
+	Ok          bool               `json:"ok"`
+	Replication replication.Status `json:"replication"`
+}
+
+// NewReplicationLogHandler creates an HTTP handler that ships the append log to followers.
+// It expects a GET request with the query parameters "from" (the last position the follower has),
+// "limit" (the maximum number of records) and "wait" (a duration such as 10s). If there are no records
+// after the position, the request is held open until the next append or until the wait duration is over.
+// The encryption keys are local to the instance, so the log of a database with payload encryption is not
+// shipped: followers could not read the payloads and erasures would not reach them.
+func NewReplicationLogHandler(db *database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		if db.Encrypted() {
+			sendJSONResponseWithStatus(w, http.StatusConflict, AddEventResponseError{
+				Ok:    false,
+				Error: "replication is not supported with payload encryption",
+			})
+			return
+		}
+
+		params := r.URL.Query()
+		from, fromErr := parseOptionalInt(params.Get("from"))
+		limit, limitErr := parseOptionalInt(params.Get("limit"))
+		wait, waitErr := time.Duration(0), error(nil)
+		if value := params.Get("wait"); value != "" {
+			wait, waitErr = time.ParseDuration(value)
+		}
+		if fromErr != nil || limitErr != nil || waitErr != nil || from < 0 || limit < 0 || wait < 0 {
+			sendJSONResponseWithStatus(w, http.StatusBadRequest, AddEventResponseError{
+				Ok:    false,
+				Error: "invalid from, limit or wait parameter",
+			})
+			return
+		}
+
+		// Take the change channel before reading, so that an append in between is not missed.
+		changed := db.Changed()
+		records := db.ReadSealedFrom(from, limit)
+		if len(records) == 0 && wait > 0 {
+			timer := time.NewTimer(min(wait, maxReplicationWait))
+			defer timer.Stop()
+
+			select {
+			case <-changed:
+				records = db.ReadSealedFrom(from, limit)
+			case <-timer.C:
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		sendJSONResponse(w, replication.LogResponse{
+			Ok:      true,
+			Head:    db.Head(),
+			Records: records,
+		})
+	}
+}
+
+// NewReplicationStatusHandler creates an HTTP handler that reports the replication role, position and lag.
+func NewReplicationStatusHandler(replica *replication.Replica) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		sendJSONResponse(w, ReplicationResponse{
+			Ok:          true,
+			Replication: replica.Status(),
+		})
+	}
+}
+
+// NewPromoteHandler creates an HTTP handler that promotes a follower to leader.
+// The replication stops and the instance accepts writes from then on.
+func NewPromoteHandler(replica *replication.Replica) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodPost) {
+			return
+		}
+
+		if err := replica.Promote(); err != nil {
+			log.Printf("WARN Promotion failed: %v", err)
+			sendJSONResponseWithStatus(w, http.StatusConflict, AddEventResponseError{
+				Ok:    false,
+				Error: err.Error(),
+			})
+			return
+		}
+
+		sendJSONResponse(w, ReplicationResponse{
+			Ok:          true,
+			Replication: replica.Status(),
+		})
+	}
+}
+
+// RejectWritesOnFollower wraps a handler that writes to the database. On a follower it responds
+// with 403 and the leader to send the write to instead.
+func RejectWritesOnFollower(replica *replication.Replica, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if replica.ReadOnly() {
+			sendJSONResponseWithStatus(w, http.StatusForbidden, AddEventResponseError{
+				Ok:    false,
+				Error: fmt.Sprintf("read-only follower: send writes to the leader at %s", replica.Status().Leader),
+			})
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func parseOptionalInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(value)
+}
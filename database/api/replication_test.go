@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/database/replication"
+	"github.com/nicograef/cloudevents/database/shred"
+	"github.com/nicograef/cloudevents/event"
+)
+
+func TestNewReplicationLogHandler(t *testing.T) {
+	db := database.New()
+	for range 3 {
+		db.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}})
+	}
+	handler := NewReplicationLogHandler(db)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/replication/log?from=1&limit=1", nil))
+
+	var resp replication.LogResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.Ok || resp.Head != 3 || len(resp.Records) != 1 || resp.Records[0].Position != 2 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/replication/log?from=-1", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for negative position, got %d", rec.Code)
+	}
+}
+
+func TestNewReplicationLogHandler_Encrypted(t *testing.T) {
+	keys, _ := shred.NewKeyStore("")
+	cipher, _ := shred.NewCipher(keys, shred.KeyBySubject)
+	db := database.New()
+	db.SetPayloadCipher(cipher)
+	db.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{"name": "Ada"}})
+
+	rec := httptest.NewRecorder()
+	NewReplicationLogHandler(db)(rec, httptest.NewRequest(http.MethodGet, "/replication/log", nil))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 for an encrypted database, got %d", rec.Code)
+	}
+}
+
+func TestNewReplicationLogHandler_Wait(t *testing.T) {
+	db := database.New()
+	handler := NewReplicationLogHandler(db)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		db.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}})
+	}()
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/replication/log?from=0&wait=5s", nil))
+
+	var resp replication.LogResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if len(resp.Records) != 1 {
+		t.Errorf("expected the appended record after waiting, got %+v", resp)
+	}
+}
+
+func TestRejectWritesOnFollower(t *testing.T) {
+	follower, _ := replication.NewReplica(database.New(), "http://leader:5000", t.TempDir())
+	handler := RejectWritesOnFollower(follower, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/add", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status 403 on follower, got %d", rec.Code)
+	}
+
+	if err := follower.Promote(); err != nil {
+		t.Fatalf("Promote failed: %v", err)
+	}
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/add", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected writes after promotion, got status %d", rec.Code)
+	}
+}
+
+func TestNewPromoteHandler(t *testing.T) {
+	leader, _ := replication.NewReplica(database.New(), "", t.TempDir())
+
+	rec := httptest.NewRecorder()
+	NewPromoteHandler(leader)(rec, httptest.NewRequest(http.MethodPost, "/admin/promote", nil))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 when promoting a leader, got %d", rec.Code)
+	}
+}
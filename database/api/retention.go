@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/nicograef/cloudevents/database/database"
+)
+
+// RetentionDryRunResponse reports the events that the retention would remove.
+type RetentionDryRunResponse struct {
+	Ok        bool                    `json:"ok"`
+	Retention database.Retention      `json:"retention"`
+	At        time.Time               `json:"at"`
+	Count     int                     `json:"count"`
+	Expired   []database.ExpiredEvent `json:"expired"`
+}
+
+// NewRetentionDryRunHandler creates an HTTP handler that reports which events the retention would remove
+// without removing them. It expects a GET request with the optional query parameter "at",
+// an RFC 3339 timestamp to evaluate the retention at instead of now.
+func NewRetentionDryRunHandler(db *database.Database, retention database.Retention) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		at := time.Now().UTC()
+		if value := r.URL.Query().Get("at"); value != "" {
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				sendJSONResponseWithStatus(w, http.StatusBadRequest, AddEventResponseError{
+					Ok:    false,
+					Error: "invalid at time: must be an RFC 3339 timestamp",
+				})
+				return
+			}
+			at = t
+		}
+
+		expired := db.ExpiredEvents(retention, at)
+
+		sendJSONResponse(w, RetentionDryRunResponse{
+			Ok:        true,
+			Retention: retention,
+			At:        at,
+			Count:     len(expired),
+			Expired:   expired,
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/event"
+)
+
+func TestNewRetentionDryRunHandler(t *testing.T) {
+	db := database.New()
+	db.AppendEvent(event.Event{ID: uuid.New(), Type: "user.login", Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}})
+	db.AppendEvent(event.Event{ID: uuid.New(), Type: "user.login", Time: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}})
+
+	handler := NewRetentionDryRunHandler(db, database.Retention{Default: database.RetentionPolicy{MaxAge: 7 * 24 * time.Hour}})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/retention/dry-run?at=2024-01-12T00:00:00Z", nil))
+
+	var resp RetentionDryRunResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.Ok || resp.Count != 1 || resp.Expired[0].Reason != database.ReasonMaxAge {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if db.Count() != 2 {
+		t.Error("expected dry run not to remove events")
+	}
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/retention/dry-run?at=tomorrow", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for invalid time, got %d", rec.Code)
+	}
+}
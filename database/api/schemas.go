@@ -0,0 +1,45 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+// schemaValidatingWriter rejects events whose data does not conform to the schema of their type.
+type schemaValidatingWriter struct {
+	EventWriter
+	schemas *event.SchemaRegistry
+}
+
+// WithSchemaValidation returns an EventWriter that validates the data of events against the schemas before
+// they are stored with the writer. Events that do not conform are rejected with an *event.SchemaValidationError.
+func WithSchemaValidation(writer EventWriter, schemas *event.SchemaRegistry) EventWriter {
+	return &schemaValidatingWriter{EventWriter: writer, schemas: schemas}
+}
+
+func (w *schemaValidatingWriter) AddEvent(candidate event.Candidate) (*event.Event, error) {
+	if err := w.schemas.ValidateEvent(event.Event{Type: candidate.Type, DataSchema: candidate.DataSchema, Data: candidate.Data}); err != nil {
+		return nil, err
+	}
+
+	return w.EventWriter.AddEvent(candidate)
+}
+
+func (w *schemaValidatingWriter) AppendEvent(e event.Event) (*event.Event, bool, error) {
+	if err := w.schemas.ValidateEvent(e); err != nil {
+		return nil, false, err
+	}
+
+	return w.EventWriter.AppendEvent(e)
+}
+
+// schemaViolations returns the violations of a schema validation error, or nil for other errors.
+func schemaViolations(err error) []event.SchemaViolation {
+	var validationErr *event.SchemaValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Violations
+	}
+
+	return nil
+}
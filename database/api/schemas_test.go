@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/event"
+)
+
+const bookBorrowedSchema = `{"type":"object","required":["bookId"],"properties":{"bookId":{"type":"string"}}}`
+
+func TestWithSchemaValidation(t *testing.T) {
+	schemas := event.NewSchemaRegistry()
+	if _, err := schemas.Register("com.library.book.borrowed:v1", json.RawMessage(bookBorrowedSchema)); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	db := database.New()
+	handler := NewAddEventHandler(WithSchemaValidation(db, schemas))
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/add", strings.NewReader(`{"type":"com.library.book.borrowed:v1","source":"https://library.example.com","subject":"/books/123","data":{"bookId":123}}`)))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status 422, got %d", rec.Code)
+	}
+	var resp AddEventResponseError
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Ok || len(resp.Violations) != 1 || resp.Violations[0].Path != "/bookId" {
+		t.Errorf("expected the violation in the response, got %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/add", strings.NewReader(`{"type":"com.library.book.borrowed:v1","source":"https://library.example.com","subject":"/books/123","data":{"bookId":"b-123"}}`)))
+	if rec.Code != http.StatusOK || db.Count() != 1 {
+		t.Errorf("expected conforming event to be stored, got status %d and %d events", rec.Code, db.Count())
+	}
+
+	summary, err := database.ImportEvents(strings.NewReader(`{"id":"123e4567-e89b-12d3-a456-426614174000","type":"com.library.book.borrowed:v1","time":"2025-09-15T10:00:00Z","source":"https://library.example.com","subject":"/books/123","data":{}}`+"\n"), WithSchemaValidation(db, schemas).AppendEvent)
+	if err != nil || summary.Invalid != 1 || db.Count() != 1 {
+		t.Errorf("expected the non-conforming imported event to be rejected, got %+v, %v", summary, err)
+	}
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/nicograef/cloudevents/database/database"
+)
+
+// SaveSnapshotRequest represents the expected request body for the snapshots API endpoint.
+type SaveSnapshotRequest struct {
+	Subject        string          `json:"subject"`
+	Version        int             `json:"version"`
+	ReducerVersion string          `json:"reducerVersion"`
+	State          json.RawMessage `json:"state"`
+}
+
+// SaveSnapshotResponseSuccess represents a successful response from the snapshots API endpoint.
+// Snapshot is the latest stored snapshot of the subject, which is not the saved one if that was outdated.
+type SaveSnapshotResponseSuccess struct {
+	Ok       bool              `json:"ok"`
+	Snapshot database.Snapshot `json:"snapshot"`
+}
+
+// AggregateResponse represents a successful response from the aggregate API endpoint.
+// SnapshotDue is true if the client should save a new snapshot after folding the events.
+type AggregateResponse struct {
+	Ok bool `json:"ok"`
+	database.Aggregate
+	SnapshotDue bool `json:"snapshotDue"`
+}
+
+// NewSaveSnapshotHandler creates an HTTP handler for storing aggregate snapshots.
+func NewSaveSnapshotHandler(db *database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodPost) {
+			return
+		}
+
+		request := SaveSnapshotRequest{}
+		if !readJSONRequest(w, r, &request) {
+			return
+		}
+
+		snapshot, err := db.SaveSnapshot(database.Snapshot{
+			Subject:        request.Subject,
+			Version:        request.Version,
+			ReducerVersion: request.ReducerVersion,
+			State:          request.State,
+		})
+		if err != nil {
+			log.Printf("ERROR Failed to save snapshot: %v", err)
+			sendJSONResponse(w, AddEventResponseError{
+				Ok:    false,
+				Error: err.Error(),
+			})
+			return
+		}
+
+		log.Printf("INFO Saved snapshot of %s at version %d", snapshot.Subject, snapshot.Version)
+
+		sendJSONResponse(w, SaveSnapshotResponseSuccess{
+			Ok:       true,
+			Snapshot: *snapshot,
+		})
+	}
+}
+
+// NewAggregateHandler creates an HTTP handler that returns the latest snapshot of a subject plus the events after it.
+// It expects a GET request with the query parameters "subject" and "reducerVersion".
+func NewAggregateHandler(db *database.Database, policy database.SnapshotPolicy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		subject := r.URL.Query().Get("subject")
+		if subject == "" {
+			sendJSONResponseWithStatus(w, http.StatusBadRequest, AddEventResponseError{
+				Ok:    false,
+				Error: "subject is required",
+			})
+			return
+		}
+
+		aggregate := db.LoadAggregate(subject, r.URL.Query().Get("reducerVersion"))
+
+		sendJSONResponse(w, AggregateResponse{
+			Ok:          true,
+			Aggregate:   aggregate,
+			SnapshotDue: policy.Due(aggregate),
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/event"
+)
+
+func TestNewSaveSnapshotHandler(t *testing.T) {
+	db := database.New()
+	db.AddEvent(event.Candidate{Type: "user.new", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}})
+	handler := NewSaveSnapshotHandler(db)
+
+	body := bytes.NewBufferString(`{"subject":"/users/1","version":1,"reducerVersion":"v1","state":{"name":"John"}}`)
+	req := httptest.NewRequest(http.MethodPost, "/snapshots", body)
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	var resp SaveSnapshotResponseSuccess
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.Ok || resp.Snapshot.Version != 1 || string(resp.Snapshot.State) != `{"name":"John"}` {
+		t.Errorf("unexpected response %+v", resp)
+	}
+}
+
+func TestNewSaveSnapshotHandler_VersionAhead(t *testing.T) {
+	handler := NewSaveSnapshotHandler(database.New())
+
+	body := bytes.NewBufferString(`{"subject":"/users/1","version":1,"reducerVersion":"v1","state":{}}`)
+	req := httptest.NewRequest(http.MethodPost, "/snapshots", body)
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	var resp AddEventResponseError
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Ok || resp.Error == "" {
+		t.Errorf("expected error response, got %+v", resp)
+	}
+}
+
+func TestNewAggregateHandler(t *testing.T) {
+	db := database.New()
+	for range 3 {
+		db.AddEvent(event.Candidate{Type: "user.update", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}})
+	}
+	db.SaveSnapshot(database.Snapshot{Subject: "/users/1", Version: 1, ReducerVersion: "v1", State: json.RawMessage(`{}`)})
+	handler := NewAggregateHandler(db, database.SnapshotPolicy{Every: 2})
+
+	req := httptest.NewRequest(http.MethodGet, "/aggregate?subject=/users/1&reducerVersion=v1", nil)
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	var resp AggregateResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.Ok || resp.Version != 3 || resp.Snapshot == nil || len(resp.Events) != 2 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if !resp.SnapshotDue {
+		t.Error("expected snapshot to be due after 2 events")
+	}
+}
+
+func TestNewAggregateHandler_MissingSubject(t *testing.T) {
+	handler := NewAggregateHandler(database.New(), database.SnapshotPolicy{Every: 100})
+
+	req := httptest.NewRequest(http.MethodGet, "/aggregate", nil)
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
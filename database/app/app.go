@@ -2,30 +2,111 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"path/filepath"
 	"time"
 
 	"github.com/nicograef/cloudevents/database/api"
+	"github.com/nicograef/cloudevents/database/boltstore"
+	"github.com/nicograef/cloudevents/database/cluster"
 	"github.com/nicograef/cloudevents/database/config"
 	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/database/namespace"
+	"github.com/nicograef/cloudevents/database/projection"
+	"github.com/nicograef/cloudevents/database/replication"
+	"github.com/nicograef/cloudevents/database/shred"
+	"github.com/nicograef/cloudevents/event"
 )
 
 type App struct {
-	Database *database.Database
-	Server   *http.Server
-	Config   config.Config
-	router   *http.ServeMux
+	Database    *database.Database
+	Projections *projection.Runner
+	Retention   database.Retention
+	Replica     *replication.Replica
+	Cluster     *cluster.Node // nil unless clustered mode is enabled
+	Namespaces  *namespace.Registry
+	Schemas     *event.SchemaRegistry
+	Upcasters   *event.Upcasters // Register upcasters before Run to serve GET /events?upcast=true
+	Server      *http.Server
+	Config      config.Config
+	router      *http.ServeMux
 }
 
 // NewApp creates a new application instance
 func NewApp(cfg config.Config) (*App, error) {
-	appDatabase, err := database.LoadFromJSONFile(cfg.DataDir)
+	appDatabase, err := LoadDatabase(cfg)
 	if err != nil {
-		fmt.Println("No existing database found, creating a new one.")
-		appDatabase = database.New()
-	} else {
-		fmt.Println("Loaded existing database from file.")
+		return nil, err
+	}
+
+	retention, err := parseRetention(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// The encryption keys are local to the instance, so followers could neither read nor erase the payloads.
+	if cfg.ReplicationLeader != "" && cfg.EncryptionKey != "" {
+		return nil, fmt.Errorf("REPLICATION_LEADER cannot be combined with ENCRYPTION_KEY")
+	}
+	if cfg.ClusterNodeID != "" && cfg.EncryptionKey != "" {
+		return nil, fmt.Errorf("ENCRYPTION_KEY cannot be combined with clustered mode")
+	}
+
+	replica, err := replication.NewReplica(appDatabase, cfg.ReplicationLeader, cfg.DataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var node *cluster.Node
+	if cfg.ClusterNodeID != "" {
+		if cfg.ReplicationLeader != "" {
+			return nil, fmt.Errorf("REPLICATION_LEADER cannot be combined with clustered mode")
+		}
+		node, err = cluster.NewNode(cluster.Config{
+			ID:        cfg.ClusterNodeID,
+			RaftAddr:  cfg.ClusterRaftAddr,
+			HTTPAddr:  cfg.ClusterHTTPAddr,
+			DataDir:   cfg.DataDir,
+			Bootstrap: cfg.ClusterBootstrap,
+		}, appDatabase)
+		if err != nil {
+			return nil, fmt.Errorf("cannot start cluster node: %w", err)
+		}
+	}
+
+	// Namespaces are opened like the default database, each in its own directory
+	namespaces, err := namespace.NewRegistry(cfg.DataDir, func(dir string) (*database.Database, error) {
+		nsConfig := cfg
+		nsConfig.DataDir = dir
+		return LoadDatabase(nsConfig)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if node != nil && len(namespaces.List()) > 0 {
+		log.Printf("WARN Namespaces are not supported in clustered mode and are not served")
+	}
+
+	schemas, err := event.OpenSchemaRegistry(filepath.Join(cfg.DataDir, "schemas.json"))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SchemaCompatibility != "" {
+		compatibility, err := event.ParseCompatibility(cfg.SchemaCompatibility)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SCHEMA_COMPATIBILITY: %w", err)
+		}
+		schemas.SetCompatibility(compatibility)
+	}
+
+	// The built-in projections keep their state in memory, so they are rebuilt from the first event on start.
+	// Projections with a durable read model should use projection.NewFileCheckpoints instead.
+	projections := projection.NewRunner(appDatabase, projection.NewMemoryCheckpoints())
+	if err := projections.Register(projection.NewTypeCounts()); err != nil {
+		return nil, err
 	}
 
 	server := &http.Server{
@@ -37,24 +118,169 @@ func NewApp(cfg config.Config) (*App, error) {
 	router := http.NewServeMux()
 
 	return &App{
-		Database: appDatabase,
-		Server:   server,
-		Config:   cfg,
-		router:   router,
+		Database:    appDatabase,
+		Projections: projections,
+		Retention:   retention,
+		Replica:     replica,
+		Cluster:     node,
+		Namespaces:  namespaces,
+		Schemas:     schemas,
+		Upcasters:   event.NewUpcasters(),
+		Server:      server,
+		Config:      cfg,
+		router:      router,
 	}, nil
 }
 
+// LoadDatabase loads the database from the data directory with the configured storage backend, or creates
+// a new one if there is none, and sets up payload encryption and data indexes as configured.
+func LoadDatabase(cfg config.Config) (*database.Database, error) {
+	appDatabase, err := openDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cipher must be set before the data indexes are created, so that they index the decrypted payloads.
+	if cfg.EncryptionKey != "" {
+		keys, err := shred.NewKeyStore(cfg.DataDir)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open key store: %w", err)
+		}
+		cipher, err := shred.NewCipher(keys, cfg.EncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ENCRYPTION_KEY: %w", err)
+		}
+		appDatabase.SetPayloadCipher(cipher)
+	}
+
+	for eventType, paths := range cfg.DataIndexes {
+		for _, path := range paths {
+			if err := appDatabase.CreateDataIndex(eventType, path); err != nil {
+				return nil, fmt.Errorf("invalid data index for %s: %w", eventType, err)
+			}
+		}
+	}
+
+	return appDatabase, nil
+}
+
+// openDatabase opens the database with the configured storage backend.
+func openDatabase(cfg config.Config) (*database.Database, error) {
+	switch cfg.Storage {
+	case "", "memory":
+		appDatabase, err := database.LoadFromJSONFile(cfg.DataDir)
+		if err != nil {
+			log.Println("INFO No existing database found, creating a new one.")
+			return database.New(), nil
+		}
+		log.Println("INFO Loaded existing database from file.")
+		return appDatabase, nil
+	case "bolt":
+		store, err := boltstore.Open(filepath.Join(cfg.DataDir, boltstore.FileName))
+		if err != nil {
+			return nil, err
+		}
+		appDatabase, err := database.LoadFromStore(store, cfg.DataDir)
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+		log.Printf("INFO Opened %s with %d events.", boltstore.FileName, appDatabase.Count())
+		return appDatabase, nil
+	default:
+		return nil, fmt.Errorf("invalid STORAGE %q: must be memory or bolt", cfg.Storage)
+	}
+}
+
 // SetupRoutes configures HTTP routes
 func (app *App) SetupRoutes() {
-	app.router.HandleFunc("POST /add", api.NewAddEventHandler(*app.Database))
-	app.router.HandleFunc("GET /health", api.NewHealthHandler())
-	app.Server.Handler = app.router
+	// Followers are read-only until they are promoted. In clustered mode, writes are committed via Raft
+	// and forwarded to the leader.
+	var writer api.EventWriter = app.Database
+	writes := func(h http.HandlerFunc) http.HandlerFunc { return api.RejectWritesOnFollower(app.Replica, h) }
+	if app.Cluster != nil {
+		writer = app.Cluster
+		writes = func(h http.HandlerFunc) http.HandlerFunc { return api.ForwardToLeader(app.Cluster, h) }
+
+		app.router.HandleFunc("GET /cluster", api.NewClusterStatusHandler(app.Cluster))
+		app.router.HandleFunc("POST /cluster/join", writes(api.NewJoinClusterHandler(app.Cluster)))
+		app.router.HandleFunc("POST /cluster/leave", writes(api.NewLeaveClusterHandler(app.Cluster)))
+	}
+
+	app.setupEventRoutes(app.router, app.Database, writer, writes, app.Config.DataDir, app.Retention)
+	app.router.HandleFunc("GET /projections", api.NewProjectionsHandler(app.Projections))
+	app.router.HandleFunc("GET /projections/{name}", api.NewProjectionHandler(app.Projections))
+	app.router.HandleFunc("POST /projections/{name}/rebuild", api.NewRebuildProjectionHandler(app.Projections))
+	app.router.HandleFunc("GET /replication", api.NewReplicationStatusHandler(app.Replica))
+	app.router.HandleFunc("GET /replication/log", api.NewReplicationLogHandler(app.Database))
+	app.router.HandleFunc("POST /admin/promote", api.NewPromoteHandler(app.Replica))
+	app.router.HandleFunc("POST /admin/backup", api.NewBackupHandler(app.Database, app.Namespaces, app.Config.BackupDir))
+	app.router.HandleFunc("GET /metrics", api.NewMetricsHandler(app.Database, app.Replica))
+	app.router.HandleFunc("GET /health", api.NewHealthHandler(app.Replica))
+
+	// Schemas are local to this instance and shared by all namespaces
+	schemaHandler := event.SchemaHandler(app.Schemas)
+	app.router.Handle("/schemas", schemaHandler)
+	app.router.Handle("/schemas/", schemaHandler)
+
+	// Namespaces are local to this instance, so they are neither replicated nor committed via Raft
+	if app.Cluster != nil {
+		app.Server.Handler = app.router
+		return
+	}
+
+	app.router.HandleFunc("GET /admin/namespaces", api.NewListNamespacesHandler(app.Namespaces))
+	app.router.HandleFunc("POST /admin/namespaces", writes(api.NewCreateNamespaceHandler(app.Namespaces)))
+	app.router.HandleFunc("DELETE /admin/namespaces/{name}", writes(api.NewDeleteNamespaceHandler(app.Namespaces)))
+	app.Server.Handler = api.WithNamespace(app.Namespaces, app.router, func(ns *namespace.Namespace) http.Handler {
+		router := http.NewServeMux()
+		app.setupEventRoutes(router, ns.DB, ns.DB, writes, ns.Dir, ns.Retention)
+		return router
+	})
+}
+
+// setupEventRoutes configures the routes to write and query the events of a database on the router.
+func (app *App) setupEventRoutes(router *http.ServeMux, db *database.Database, writer api.EventWriter, writes func(http.HandlerFunc) http.HandlerFunc, dataDir string, retention database.Retention) {
+	if app.Config.SchemaValidation {
+		writer = api.WithSchemaValidation(writer, app.Schemas)
+	}
+
+	router.HandleFunc("POST /add", writes(api.NewAddEventHandler(writer)))
+	router.HandleFunc("POST /append", writes(api.NewAppendEventHandler(writer)))
+	router.HandleFunc("GET /events", api.NewGetEventsHandler(db, app.Upcasters))
+	router.HandleFunc("GET /export", api.NewExportHandler(db))
+	router.HandleFunc("POST /import", writes(api.NewImportHandler(db, writer, dataDir)))
+	router.HandleFunc("GET /aggregate", api.NewAggregateHandler(db, database.SnapshotPolicy{Every: app.Config.SnapshotEvery}))
+	if app.Cluster == nil {
+		// Snapshots are not committed via Raft and erasures destroy keys of the local key store
+		router.HandleFunc("POST /snapshots", writes(api.NewSaveSnapshotHandler(db)))
+		router.HandleFunc("POST /erase", writes(api.NewEraseHandler(db, dataDir)))
+	}
+	router.HandleFunc("GET /retention/dry-run", api.NewRetentionDryRunHandler(db, retention))
 }
 
 // Run starts the application with graceful shutdown
 func (app *App) Run(ctx context.Context) error {
 	app.SetupRoutes()
 
+	// Feed historical and live events to the projections
+	go app.Projections.Run(ctx)
+
+	// Replicate the log of the leader if this instance is a follower
+	go app.Replica.Run(ctx)
+
+	// Remove expired events periodically. Removals are local and would let the nodes of a cluster diverge.
+	if app.Cluster == nil && app.Config.RetentionInterval > 0 {
+		go app.runRetention(ctx)
+	} else if app.Retention.Enabled() {
+		log.Printf("WARN Retention is not supported in clustered mode and is disabled")
+	}
+
+	// Join the cluster via an existing member
+	if app.Cluster != nil && app.Config.ClusterJoin != "" {
+		go app.joinCluster(ctx)
+	}
+
 	// Start server in goroutine
 	errChan := make(chan error, 1)
 	go func() {
@@ -74,6 +300,81 @@ func (app *App) Run(ctx context.Context) error {
 	}
 }
 
+// joinCluster adds this node to the cluster via the configured member unless it is a member already.
+func (app *App) joinCluster(ctx context.Context) {
+	for _, m := range app.Cluster.Status().Members {
+		if m.ID == app.Config.ClusterNodeID {
+			return
+		}
+	}
+
+	member := cluster.Member{ID: app.Config.ClusterNodeID, RaftAddr: app.Config.ClusterRaftAddr, HTTPAddr: app.Config.ClusterHTTPAddr}
+	if err := cluster.JoinVia(ctx, app.Config.ClusterJoin, member); err == nil {
+		log.Printf("INFO Joined the cluster via %s", app.Config.ClusterJoin)
+	}
+}
+
+// runRetention removes expired events every retention interval until the context is cancelled.
+// The database is persisted after every removal so that expired events are also removed from disk.
+func (app *App) runRetention(ctx context.Context) {
+	ticker := time.NewTicker(app.Config.RetentionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			app.applyRetention()
+		}
+	}
+}
+
+// applyRetention applies the retention of the default namespace and of every other namespace.
+func (app *App) applyRetention() {
+	applyRetention(namespace.Default, app.Database, app.Retention, app.Config.DataDir)
+	for _, ns := range app.Namespaces.List() {
+		applyRetention(ns.Name, ns.DB, ns.Retention, ns.Dir)
+	}
+}
+
+func applyRetention(name string, db *database.Database, retention database.Retention, dataDir string) {
+	if !retention.Enabled() {
+		return
+	}
+
+	expired := db.ApplyRetention(retention, time.Now().UTC())
+	if len(expired) == 0 {
+		return
+	}
+
+	log.Printf("INFO Retention removed %d expired events from namespace %s", len(expired), name)
+	if err := db.Persist(dataDir); err != nil {
+		log.Printf("ERROR Failed to persist namespace %s after retention: %v", name, err)
+	}
+}
+
+// parseRetention parses the default and per-type retention policies of the configuration.
+func parseRetention(cfg config.Config) (database.Retention, error) {
+	retention := database.Retention{Types: make(map[string]database.RetentionPolicy)}
+
+	policy, err := database.ParseRetentionPolicy(cfg.Retention)
+	if err != nil {
+		return database.Retention{}, fmt.Errorf("invalid RETENTION: %w", err)
+	}
+	retention.Default = policy
+
+	for eventType, s := range cfg.RetentionTypes {
+		policy, err := database.ParseRetentionPolicy(s)
+		if err != nil {
+			return database.Retention{}, fmt.Errorf("invalid RETENTION_TYPES entry for %s: %w", eventType, err)
+		}
+		retention.Types[eventType] = policy
+	}
+
+	return retention, nil
+}
+
 // Shutdown gracefully stops the application
 func (app *App) Shutdown() error {
 	// Create shutdown context with timeout
@@ -87,10 +388,29 @@ func (app *App) Shutdown() error {
 
 	// Persist database
 	fmt.Println("Persisting database...")
-	if err := app.Database.PersistToJsonFile(app.Config.DataDir); err != nil {
+	if err := app.Database.Persist(app.Config.DataDir); err != nil {
 		return fmt.Errorf("error persisting database: %w", err)
 	}
 
+	if err := app.Namespaces.Persist(); err != nil {
+		return fmt.Errorf("error persisting namespaces: %w", err)
+	}
+
+	// The replication position is stored after the events, so it never runs ahead of them
+	if err := app.Replica.Persist(); err != nil {
+		return fmt.Errorf("error persisting replication state: %w", err)
+	}
+
+	if app.Cluster != nil {
+		if err := app.Cluster.Shutdown(); err != nil {
+			return fmt.Errorf("error stopping cluster node: %w", err)
+		}
+	}
+
+	if err := errors.Join(app.Database.Close(), app.Namespaces.Close()); err != nil {
+		return fmt.Errorf("error closing database: %w", err)
+	}
+
 	fmt.Println("Shutdown complete")
 	return nil
 }
@@ -3,12 +3,16 @@ package app
 import (
 	"context"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/nicograef/cloudevents/database/config"
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/event"
 )
 
 func TestNewApp(t *testing.T) {
@@ -145,3 +149,232 @@ func TestRun_ContextCancellation(t *testing.T) {
 		t.Error("Database file was not created during graceful shutdown")
 	}
 }
+
+func TestNewApp_InvalidRetention(t *testing.T) {
+	cfg := config.Config{Port: 8080, DataDir: t.TempDir(), RetentionTypes: map[string]string{"user.login": "maxAge:forever"}}
+
+	if _, err := NewApp(cfg); err == nil {
+		t.Error("expected error for invalid retention policy")
+	}
+}
+
+func TestApplyRetention_PersistsDatabase(t *testing.T) {
+	tempDir := t.TempDir()
+	dbFile := filepath.Join(tempDir, "database.json")
+
+	// One event far in the past and one far in the future
+	sampleData := `[{"id":"123e4567-e89b-12d3-a456-426614174000","type":"test.event","time":"2000-01-01T00:00:00Z","source":"https://test.com","subject":"/test","data":{}},` +
+		`{"id":"123e4567-e89b-12d3-a456-426614174001","type":"test.event","time":"2100-01-01T00:00:00Z","source":"https://test.com","subject":"/test","data":{}}]`
+	if err := os.WriteFile(dbFile, []byte(sampleData), 0644); err != nil {
+		t.Fatalf("Failed to create test database file: %v", err)
+	}
+
+	app, err := NewApp(config.Config{Port: 8080, DataDir: tempDir, Retention: "maxAge:24h"})
+	if err != nil {
+		t.Fatalf("NewApp() failed: %v", err)
+	}
+
+	app.applyRetention()
+
+	loaded, err := database.LoadFromJSONFile(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to load persisted database: %v", err)
+	}
+	if events := loaded.GetEvents(); len(events) != 1 || events[0].Time.Year() != 2100 {
+		t.Errorf("expected only the recent event on disk, got %v", events)
+	}
+}
+
+func TestLoadDatabase_InvalidEncryptionKey(t *testing.T) {
+	cfg := config.Config{DataDir: t.TempDir(), EncryptionKey: "subject.id"}
+
+	if _, err := LoadDatabase(cfg); err == nil {
+		t.Error("expected error for invalid ENCRYPTION_KEY")
+	}
+}
+
+func TestNewApp_EncryptedFollower(t *testing.T) {
+	cfg := config.Config{DataDir: t.TempDir(), EncryptionKey: "subject", ReplicationLeader: "http://localhost:1"}
+
+	if _, err := NewApp(cfg); err == nil {
+		t.Error("expected error for REPLICATION_LEADER with ENCRYPTION_KEY")
+	}
+}
+
+func TestNewApp_EncryptedClusterNode(t *testing.T) {
+	cfg := config.Config{DataDir: t.TempDir(), EncryptionKey: "subject", ClusterNodeID: "node-1", ClusterRaftAddr: "127.0.0.1:0"}
+
+	if _, err := NewApp(cfg); err == nil {
+		t.Error("expected error for CLUSTER_NODE_ID with ENCRYPTION_KEY")
+	}
+}
+
+func TestReplication_InProcess(t *testing.T) {
+	leader, err := NewApp(config.Config{DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewApp() failed: %v", err)
+	}
+	leader.SetupRoutes()
+	leaderServer := httptest.NewServer(leader.router)
+	defer leaderServer.Close()
+
+	follower, err := NewApp(config.Config{DataDir: t.TempDir(), ReplicationLeader: leaderServer.URL})
+	if err != nil {
+		t.Fatalf("NewApp() failed: %v", err)
+	}
+	follower.SetupRoutes()
+	followerServer := httptest.NewServer(follower.router)
+	defer followerServer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go follower.Replica.Run(ctx)
+
+	body := `{"type":"user.login","source":"https://example.com","subject":"/users/1","data":{}}`
+	resp, err := http.Post(leaderServer.URL+"/add", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /add failed: %v", err)
+	}
+	resp.Body.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for follower.Database.Head() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("event was not replicated in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	resp, _ = http.Post(followerServer.URL+"/add", "application/json", strings.NewReader(body))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected follower to reject writes, got status %d", resp.StatusCode)
+	}
+
+	resp, _ = http.Post(followerServer.URL+"/admin/promote", "application/json", nil)
+	resp.Body.Close()
+	resp, _ = http.Post(followerServer.URL+"/add", "application/json", strings.NewReader(body))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || follower.Database.Head() != 2 {
+		t.Errorf("expected promoted follower to accept writes, got status %d", resp.StatusCode)
+	}
+}
+
+func TestNewApp_BoltStorage(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := config.Config{Port: 8080, DataDir: tempDir, Storage: "bolt"}
+
+	app, err := NewApp(cfg)
+	if err != nil {
+		t.Fatalf("NewApp() failed: %v", err)
+	}
+	added, err := app.Database.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}})
+	if err != nil {
+		t.Fatalf("AddEvent failed: %v", err)
+	}
+	if err := app.Shutdown(); err != nil {
+		t.Fatalf("Shutdown() failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "database.json")); !os.IsNotExist(err) {
+		t.Error("expected no database.json with the bolt storage")
+	}
+
+	app, err = NewApp(cfg)
+	if err != nil {
+		t.Fatalf("NewApp() failed: %v", err)
+	}
+	defer app.Database.Close()
+
+	if app.Database.GetEvent(added.Source, added.ID) == nil {
+		t.Error("expected the event to be loaded from the bolt store")
+	}
+}
+
+func TestNewApp_InvalidStorage(t *testing.T) {
+	if _, err := NewApp(config.Config{DataDir: t.TempDir(), Storage: "tape"}); err == nil {
+		t.Error("expected error for an unknown storage backend")
+	}
+}
+
+func TestNamespaces_IsolationAndQuota(t *testing.T) {
+	tempDir := t.TempDir()
+	app, err := NewApp(config.Config{Port: 8080, DataDir: tempDir})
+	if err != nil {
+		t.Fatalf("NewApp() failed: %v", err)
+	}
+	app.SetupRoutes()
+	server := httptest.NewServer(app.Server.Handler)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/admin/namespaces", "application/json", strings.NewReader(`{"name":"tenant","quota":{"maxEvents":1}}`))
+	if err != nil {
+		t.Fatalf("create namespace failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	body := `{"type":"user.login","source":"https://example.com","subject":"/users/1","data":{}}`
+	resp, _ = http.Post(server.URL+"/namespaces/tenant/add", "application/json", strings.NewReader(body))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	resp, _ = http.Post(server.URL+"/namespaces/tenant/add", "application/json", strings.NewReader(body))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInsufficientStorage {
+		t.Errorf("expected status 507 for an exceeded quota, got %d", resp.StatusCode)
+	}
+
+	ns, _ := app.Namespaces.Get("tenant")
+	if ns.DB.Count() != 1 || app.Database.Count() != 0 {
+		t.Errorf("expected 1 event in the namespace and none in the default namespace, got %d and %d", ns.DB.Count(), app.Database.Count())
+	}
+
+	if err := app.Shutdown(); err != nil {
+		t.Fatalf("Shutdown() failed: %v", err)
+	}
+
+	app, err = NewApp(config.Config{Port: 8080, DataDir: tempDir})
+	if err != nil {
+		t.Fatalf("NewApp() failed: %v", err)
+	}
+	if ns, exists := app.Namespaces.Get("tenant"); !exists || ns.DB.Count() != 1 {
+		t.Error("expected the namespace with its event to be loaded again")
+	}
+}
+
+func TestSchemaValidation(t *testing.T) {
+	tempDir := t.TempDir()
+	app, err := NewApp(config.Config{Port: 8080, DataDir: tempDir, SchemaValidation: true})
+	if err != nil {
+		t.Fatalf("NewApp() failed: %v", err)
+	}
+	app.SetupRoutes()
+	server := httptest.NewServer(app.Server.Handler)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPut, server.URL+"/schemas/com.library.book.borrowed:v1", strings.NewReader(`{"type":"object","required":["bookId"]}`))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("register schema failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	body := `{"type":"com.library.book.borrowed:v1","source":"https://library.example.com","subject":"/books/123","data":{}}`
+	resp, _ = http.Post(server.URL+"/add", "application/json", strings.NewReader(body))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity || app.Database.Count() != 0 {
+		t.Errorf("expected non-conforming event to be rejected, got status %d", resp.StatusCode)
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "schemas.json")); err != nil {
+		t.Errorf("expected the registry to be persisted: %v", err)
+	}
+}
@@ -0,0 +1,437 @@
+// Package boltstore stores the events of a database in a bbolt file, so that the events do not have to fit
+// into memory. Every append is committed to disk before it returns.
+package boltstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/event"
+	bolt "go.etcd.io/bbolt"
+)
+
+// FileName is the name of the store file in the data directory.
+const FileName = "events.db"
+
+// Buckets of the store. Positions are 8-byte big-endian integers and time keys sort by event time,
+// so cursors return the events in append or time order.
+var (
+	// logBucket maps positions to the JSON-encoded events.
+	logBucket = []byte("log")
+	// keysBucket maps event keys (the 16-byte ID followed by the source) to positions.
+	keysBucket = []byte("keys")
+	// legacyIDsBucket mapped event IDs to positions before events were keyed by source and ID.
+	legacyIDsBucket = []byte("ids")
+	// timeBucket holds the time keys of all events.
+	timeBucket = []byte("time")
+	// typesBucket holds a nested bucket of time keys per event type.
+	typesBucket = []byte("types")
+	// subjectsBucket holds a nested bucket of time keys per subject.
+	subjectsBucket = []byte("subjects")
+	// subjectLogBucket holds a nested bucket of positions per subject.
+	subjectLogBucket = []byte("subjectlog")
+	// metaBucket holds the head position and the number of events.
+	metaBucket = []byte("meta")
+
+	headKey  = []byte("head")
+	countKey = []byte("count")
+)
+
+var buckets = [][]byte{logBucket, keysBucket, timeBucket, typesBucket, subjectsBucket, subjectLogBucket, metaBucket}
+
+// Store is a database.Store backed by a bbolt file.
+type Store struct {
+	db *bolt.DB
+}
+
+var _ database.Store = (*Store)(nil)
+
+// Open opens the store file at the path and creates it if it does not exist.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if err := createBuckets(tx); err != nil {
+			return err
+		}
+		return migrateIDs(tx)
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+func createBuckets(tx *bolt.Tx) error {
+	for _, name := range buckets {
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateIDs replaces the ids bucket of older store files with the keys bucket.
+func migrateIDs(tx *bolt.Tx) error {
+	if tx.Bucket(legacyIDsBucket) == nil {
+		return nil
+	}
+
+	keys := tx.Bucket(keysBucket)
+	err := tx.Bucket(logBucket).ForEach(func(pos, data []byte) error {
+		var e event.Event
+		if err := json.Unmarshal(data, &e); err != nil {
+			return fmt.Errorf("invalid event at position %d: %w", readInt(pos), err)
+		}
+		return keys.Put(eventKey(database.KeyOf(e)), pos)
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.DeleteBucket(legacyIDsBucket)
+}
+
+func (s *Store) Append(e event.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		keys := tx.Bucket(keysBucket)
+		key := eventKey(database.KeyOf(e))
+		if keys.Get(key) != nil {
+			return errors.New("event is already stored")
+		}
+
+		meta := tx.Bucket(metaBucket)
+		position := readInt(meta.Get(headKey)) + 1
+		pos := encodeInt(position)
+		tk := timeKey(e.Time, position)
+
+		if err := tx.Bucket(logBucket).Put(pos, data); err != nil {
+			return err
+		}
+		if err := keys.Put(key, pos); err != nil {
+			return err
+		}
+		if err := tx.Bucket(timeBucket).Put(tk, nil); err != nil {
+			return err
+		}
+		if err := putNested(tx.Bucket(typesBucket), e.Type, tk); err != nil {
+			return err
+		}
+		if err := putNested(tx.Bucket(subjectsBucket), e.Subject, tk); err != nil {
+			return err
+		}
+		if err := putNested(tx.Bucket(subjectLogBucket), e.Subject, pos); err != nil {
+			return err
+		}
+
+		if err := meta.Put(headKey, pos); err != nil {
+			return err
+		}
+		return meta.Put(countKey, encodeInt(readInt(meta.Get(countKey))+1))
+	})
+}
+
+func (s *Store) SkipTo(position int) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(metaBucket)
+		if position <= readInt(meta.Get(headKey)) {
+			return nil
+		}
+
+		return meta.Put(headKey, encodeInt(position))
+	})
+}
+
+func (s *Store) Get(key database.EventKey) (event.Event, bool, error) {
+	var e event.Event
+	found := false
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		pos := tx.Bucket(keysBucket).Get(eventKey(key))
+		if pos == nil {
+			return nil
+		}
+
+		found = true
+		return decodeEvent(tx, pos, &e)
+	})
+
+	return e, found, err
+}
+
+func (s *Store) Scan(f database.Filter, fn func(event.Event) bool) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		index := tx.Bucket(timeBucket)
+		if f.Subject != "" {
+			index = tx.Bucket(subjectsBucket).Bucket([]byte(f.Subject))
+		} else if f.Type != "" {
+			index = tx.Bucket(typesBucket).Bucket([]byte(f.Type))
+		}
+		if index == nil {
+			return nil
+		}
+
+		c := index.Cursor()
+		k, _ := c.First()
+		if !f.Range.From.IsZero() {
+			k, _ = c.Seek(timeKey(f.Range.From, 0))
+		}
+
+		var end []byte
+		if !f.Range.To.IsZero() {
+			end = timeKey(f.Range.To, -1)
+		}
+
+		for ; k != nil; k, _ = c.Next() {
+			if end != nil && bytes.Compare(k, end) > 0 {
+				return nil
+			}
+
+			var e event.Event
+			if err := decodeEvent(tx, k[12:], &e); err != nil {
+				return err
+			}
+			if (f.Type == "" || e.Type == f.Type) && !fn(e) {
+				return nil
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *Store) ScanSubject(subject string, skip int, fn func(event.Event) bool) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		positions := tx.Bucket(subjectLogBucket).Bucket([]byte(subject))
+		if positions == nil {
+			return nil
+		}
+
+		c := positions.Cursor()
+		for pos, _ := c.First(); pos != nil; pos, _ = c.Next() {
+			if skip > 0 {
+				skip--
+				continue
+			}
+
+			var e event.Event
+			if err := decodeEvent(tx, pos, &e); err != nil {
+				return err
+			}
+			if !fn(e) {
+				return nil
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *Store) ScanLog(position int, fn func(database.Record) bool) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(logBucket).Cursor()
+		for pos, data := c.Seek(encodeInt(max(position, 0) + 1)); pos != nil; pos, data = c.Next() {
+			record := database.Record{Position: readInt(pos)}
+			if err := json.Unmarshal(data, &record.Event); err != nil {
+				return fmt.Errorf("invalid event at position %d: %w", record.Position, err)
+			}
+			if !fn(record) {
+				return nil
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *Store) CountSubject(subject string) (int, error) {
+	count := 0
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if positions := tx.Bucket(subjectLogBucket).Bucket([]byte(subject)); positions != nil {
+			count = positions.Stats().KeyN
+		}
+		return nil
+	})
+
+	return count, err
+}
+
+func (s *Store) Subjects() ([]string, error) {
+	subjects := []string{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(subjectLogBucket).ForEachBucket(func(name []byte) error {
+			subjects = append(subjects, string(name))
+			return nil
+		})
+	})
+
+	return subjects, err
+}
+
+func (s *Store) Head() (int, error) {
+	return s.readMeta(headKey)
+}
+
+func (s *Store) Count() (int, error) {
+	return s.readMeta(countKey)
+}
+
+func (s *Store) Delete(keys []database.EventKey) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		deleted := 0
+		for _, k := range keys {
+			pos := tx.Bucket(keysBucket).Get(eventKey(k))
+			if pos == nil {
+				continue
+			}
+			pos = bytes.Clone(pos)
+
+			var e event.Event
+			if err := decodeEvent(tx, pos, &e); err != nil {
+				return err
+			}
+			key := timeKey(e.Time, readInt(pos))
+
+			if err := tx.Bucket(logBucket).Delete(pos); err != nil {
+				return err
+			}
+			if err := tx.Bucket(keysBucket).Delete(eventKey(k)); err != nil {
+				return err
+			}
+			if err := tx.Bucket(timeBucket).Delete(key); err != nil {
+				return err
+			}
+			if err := deleteNested(tx.Bucket(typesBucket), e.Type, key); err != nil {
+				return err
+			}
+			if err := deleteNested(tx.Bucket(subjectsBucket), e.Subject, key); err != nil {
+				return err
+			}
+			if err := deleteNested(tx.Bucket(subjectLogBucket), e.Subject, pos); err != nil {
+				return err
+			}
+			deleted++
+		}
+
+		meta := tx.Bucket(metaBucket)
+		return meta.Put(countKey, encodeInt(readInt(meta.Get(countKey))-deleted))
+	})
+}
+
+func (s *Store) Reset() error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range buckets {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+
+		return createBuckets(tx)
+	})
+}
+
+// Sync flushes the store file to disk.
+func (s *Store) Sync() error {
+	return s.db.Sync()
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) readMeta(key []byte) (int, error) {
+	value := 0
+	err := s.db.View(func(tx *bolt.Tx) error {
+		value = readInt(tx.Bucket(metaBucket).Get(key))
+		return nil
+	})
+
+	return value, err
+}
+
+// decodeEvent reads the event at the position from the log.
+func decodeEvent(tx *bolt.Tx, pos []byte, e *event.Event) error {
+	data := tx.Bucket(logBucket).Get(pos)
+	if data == nil {
+		return fmt.Errorf("missing event at position %d", readInt(pos))
+	}
+	if err := json.Unmarshal(data, e); err != nil {
+		return fmt.Errorf("invalid event at position %d: %w", readInt(pos), err)
+	}
+
+	return nil
+}
+
+// putNested adds the key to the nested bucket with the name.
+func putNested(parent *bolt.Bucket, name string, key []byte) error {
+	b, err := parent.CreateBucketIfNotExists([]byte(name))
+	if err != nil {
+		return err
+	}
+
+	return b.Put(key, nil)
+}
+
+// deleteNested removes the key from the nested bucket with the name and removes the bucket once it is empty.
+func deleteNested(parent *bolt.Bucket, name string, key []byte) error {
+	b := parent.Bucket([]byte(name))
+	if b == nil {
+		return nil
+	}
+	if err := b.Delete(key); err != nil {
+		return err
+	}
+	if k, _ := b.Cursor().First(); k == nil {
+		return parent.DeleteBucket([]byte(name))
+	}
+
+	return nil
+}
+
+// eventKey encodes the key of an event as its ID followed by its source.
+func eventKey(k database.EventKey) []byte {
+	return append(bytes.Clone(k.ID[:]), k.Source...)
+}
+
+// timeKey encodes the time and the position so that keys sort by time and then by position.
+// The seconds are stored with their sign bit flipped, so times before 1970 sort first.
+// A position of -1 returns the largest key of the time, which is used as an inclusive upper bound.
+func timeKey(t time.Time, position int) []byte {
+	key := make([]byte, 20)
+	binary.BigEndian.PutUint64(key, uint64(t.Unix())^(1<<63))
+	binary.BigEndian.PutUint32(key[8:], uint32(t.Nanosecond()))
+	binary.BigEndian.PutUint64(key[12:], uint64(position))
+
+	return key
+}
+
+func encodeInt(n int) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(n))
+
+	return b
+}
+
+func readInt(b []byte) int {
+	if len(b) != 8 {
+		return 0
+	}
+
+	return int(binary.BigEndian.Uint64(b))
+}
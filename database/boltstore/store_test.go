@@ -0,0 +1,154 @@
+package boltstore
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/database/storetest"
+	"github.com/nicograef/cloudevents/event"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) database.Store {
+		s, err := Open(filepath.Join(t.TempDir(), FileName))
+		if err != nil {
+			t.Fatalf("Open failed: %v", err)
+		}
+		return s
+	})
+}
+
+func TestStore_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	db, _ := database.Open(s)
+	added, err := db.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{"k": "v"}})
+	if err != nil {
+		t.Fatalf("AddEvent failed: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	db, err = database.Open(s)
+	if err != nil {
+		t.Fatalf("database.Open failed: %v", err)
+	}
+	defer db.Close()
+
+	if got := db.GetEvent(added.Source, added.ID); got == nil || !got.Time.Equal(added.Time) {
+		t.Fatalf("expected event to survive reopening, got %+v", got)
+	}
+	if db.Head() != 1 || len(db.GetEventsMatchingSubject("/users/*", database.TimeRange{})) != 1 {
+		t.Error("expected head and subject trie to be restored")
+	}
+	if _, duplicate, _ := db.AppendEvent(*added); !duplicate {
+		t.Error("expected appending the event again to be a duplicate")
+	}
+}
+
+func TestStore_AppendDuplicateID(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer s.Close()
+
+	e, _ := event.New(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}})
+	if err := s.Append(*e); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if err := s.Append(*e); err == nil {
+		t.Error("expected error for an event ID that is already stored")
+	}
+}
+
+func TestStore_MigratesLegacyIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	e, _ := event.New(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}})
+	if err := s.Append(*e); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	// Rewrite the file in the format of older versions, which mapped the event IDs to positions.
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		ids, err := tx.CreateBucket(legacyIDsBucket)
+		if err != nil {
+			return err
+		}
+		if err := ids.Put(e.ID[:], encodeInt(1)); err != nil {
+			return err
+		}
+		if err := tx.DeleteBucket(keysBucket); err != nil {
+			return err
+		}
+		_, err = tx.CreateBucket(keysBucket)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("cannot write the legacy format: %v", err)
+	}
+	s.Close()
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer s.Close()
+
+	if _, exists, err := s.Get(database.KeyOf(*e)); !exists || err != nil {
+		t.Errorf("expected the event to be found by its key, got exists=%v and error %v", exists, err)
+	}
+	if err := s.Append(*e); err == nil {
+		t.Error("expected error for an event that is already stored")
+	}
+}
+
+func TestStore_RestoreBackup(t *testing.T) {
+	source := database.New()
+	for _, subject := range []string{"/users/1", "/users/2"} {
+		source.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: subject, Data: map[string]any{}})
+	}
+	backupDir := filepath.Join(t.TempDir(), "backup")
+	if _, err := source.Backup(backupDir); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+
+	dataDir := t.TempDir()
+	s, err := Open(filepath.Join(dataDir, FileName))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	db, err := database.LoadFromStore(s, dataDir)
+	if err != nil {
+		t.Fatalf("LoadFromStore failed: %v", err)
+	}
+	defer db.Close()
+	for range 3 {
+		db.AddEvent(event.Candidate{Type: "user.logout", Source: "https://example.com", Subject: "/users/9", Data: map[string]any{}})
+	}
+
+	if _, err := db.RestoreBackup(backupDir, dataDir); err != nil {
+		t.Fatalf("RestoreBackup failed: %v", err)
+	}
+	if db.Count() != 2 || db.Head() != 2 || len(db.GetEventsByType("user.logout")) != 0 {
+		t.Errorf("expected the store to hold the backup, got %d events up to position %d", db.Count(), db.Head())
+	}
+	if head, _ := s.Head(); head != 2 {
+		t.Errorf("expected the store head at 2, got %d", head)
+	}
+}
@@ -0,0 +1,163 @@
+package cluster
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/hashicorp/raft"
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/event"
+)
+
+// Operations of the commands in the Raft log.
+const (
+	opAppend = "append"
+	opJoin   = "join"
+	opLeave  = "leave"
+)
+
+// command is an entry of the Raft log. Appends carry the complete event, so that every node stores it
+// with the same ID and time. Joins and leaves keep the HTTP addresses of the members in sync.
+type command struct {
+	Op     string       `json:"op"`
+	Event  *event.Event `json:"event,omitempty"`
+	Member *Member      `json:"member,omitempty"`
+}
+
+// applyResult is the result of applying an append on the leader.
+type applyResult struct {
+	event     *event.Event
+	duplicate bool
+	err       error
+}
+
+// fsm is the replicated state machine: the database plus the HTTP addresses of the members.
+type fsm struct {
+	db *database.Database
+
+	mu      sync.RWMutex
+	members map[string]Member
+}
+
+func newFSM(db *database.Database) *fsm {
+	return &fsm{db: db, members: make(map[string]Member)}
+}
+
+// Apply applies a committed command. It runs on every node in log order.
+func (f *fsm) Apply(entry *raft.Log) any {
+	var cmd command
+	if err := json.Unmarshal(entry.Data, &cmd); err != nil {
+		return applyResult{err: fmt.Errorf("invalid command: %w", err)}
+	}
+
+	switch cmd.Op {
+	case opAppend:
+		if cmd.Event == nil {
+			return applyResult{err: fmt.Errorf("append without event")}
+		}
+		stored, duplicate, err := f.db.AppendEvent(*cmd.Event)
+		return applyResult{event: stored, duplicate: duplicate, err: err}
+	case opJoin:
+		f.mu.Lock()
+		f.members[cmd.Member.ID] = *cmd.Member
+		f.mu.Unlock()
+	case opLeave:
+		f.mu.Lock()
+		delete(f.members, cmd.Member.ID)
+		f.mu.Unlock()
+	default:
+		return applyResult{err: fmt.Errorf("unknown command %q", cmd.Op)}
+	}
+
+	return applyResult{}
+}
+
+// member returns the member with the given ID.
+func (f *fsm) member(id string) (Member, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	m, exists := f.members[id]
+	return m, exists
+}
+
+// Snapshot captures the members and the events as stored, in append order.
+func (f *fsm) Snapshot() (raft.FSMSnapshot, error) {
+	f.mu.RLock()
+	members := make([]Member, 0, len(f.members))
+	for _, m := range f.members {
+		members = append(members, m)
+	}
+	f.mu.RUnlock()
+
+	return &fsmSnapshot{members: members, records: f.db.ReadSealedFrom(0, 0)}, nil
+}
+
+// Restore replaces the state with a snapshot. The snapshot holds the members in the first line
+// and one event per line after it.
+func (f *fsm) Restore(snapshot io.ReadCloser) error {
+	defer snapshot.Close()
+
+	reader := bufio.NewReader(snapshot)
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("cannot read members: %w", err)
+	}
+
+	var members []Member
+	if err := json.Unmarshal(line, &members); err != nil {
+		return fmt.Errorf("invalid members: %w", err)
+	}
+
+	if err := f.db.Reset(); err != nil {
+		return err
+	}
+	summary, err := f.db.Import(reader)
+	if err != nil {
+		return err
+	}
+	if summary.Invalid > 0 {
+		return fmt.Errorf("snapshot holds %d invalid events", summary.Invalid)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.members = make(map[string]Member, len(members))
+	for _, m := range members {
+		f.members[m.ID] = m
+	}
+
+	return nil
+}
+
+// fsmSnapshot is a point-in-time copy of the state machine.
+type fsmSnapshot struct {
+	members []Member
+	records []database.Record
+}
+
+// Persist writes the members and the events as NDJSON.
+func (s *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
+	encoder := json.NewEncoder(sink)
+
+	err := encoder.Encode(s.members)
+	for _, record := range s.records {
+		if err != nil {
+			break
+		}
+		err = encoder.Encode(record.Event)
+	}
+
+	if err != nil {
+		sink.Cancel()
+		return err
+	}
+
+	return sink.Close()
+}
+
+func (s *fsmSnapshot) Release() {}
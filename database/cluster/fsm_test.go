@@ -0,0 +1,110 @@
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/hashicorp/raft"
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/database/shred"
+	"github.com/nicograef/cloudevents/event"
+)
+
+type bufferSink struct {
+	bytes.Buffer
+	cancelled bool
+}
+
+func (s *bufferSink) ID() string    { return "test" }
+func (s *bufferSink) Cancel() error { s.cancelled = true; return nil }
+func (s *bufferSink) Close() error  { return nil }
+
+func applyCommand(t *testing.T, f *fsm, cmd command) applyResult {
+	t.Helper()
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		t.Fatalf("failed to encode command: %v", err)
+	}
+	return f.Apply(&raft.Log{Data: data}).(applyResult)
+}
+
+func TestFSM_Apply(t *testing.T) {
+	f := newFSM(database.New())
+
+	e, _ := event.New(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}})
+	if result := applyCommand(t, f, command{Op: opAppend, Event: e}); result.err != nil || result.duplicate || result.event.ID != e.ID {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if result := applyCommand(t, f, command{Op: opAppend, Event: e}); !result.duplicate {
+		t.Error("expected second append to be a duplicate")
+	}
+
+	applyCommand(t, f, command{Op: opJoin, Member: &Member{ID: "node-2", HTTPAddr: "http://node-2"}})
+	if m, _ := f.member("node-2"); m.HTTPAddr != "http://node-2" {
+		t.Error("expected member to be recorded")
+	}
+	applyCommand(t, f, command{Op: opLeave, Member: &Member{ID: "node-2"}})
+	if _, exists := f.member("node-2"); exists {
+		t.Error("expected member to be removed")
+	}
+
+	if result := applyCommand(t, f, command{Op: "unknown"}); result.err == nil {
+		t.Error("expected error for unknown command")
+	}
+}
+
+func TestFSM_SnapshotRestore(t *testing.T) {
+	source := newFSM(database.New())
+	for _, subject := range []string{"/users/1", "/users/2"} {
+		e, _ := event.New(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: subject, Data: map[string]any{}})
+		applyCommand(t, source, command{Op: opAppend, Event: e})
+	}
+	applyCommand(t, source, command{Op: opJoin, Member: &Member{ID: "node-1", RaftAddr: "a:7000", HTTPAddr: "http://a"}})
+
+	snapshot, err := source.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	sink := &bufferSink{}
+	if err := snapshot.Persist(sink); err != nil || sink.cancelled {
+		t.Fatalf("Persist failed: %v", err)
+	}
+
+	targetDB := database.New()
+	targetDB.AddEvent(event.Candidate{Type: "user.stale", Source: "https://example.com", Subject: "/users/9", Data: map[string]any{}})
+	target := newFSM(targetDB)
+	if err := target.Restore(io.NopCloser(&sink.Buffer)); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	if targetDB.Head() != 2 || len(targetDB.GetEventsByType("user.stale")) != 0 {
+		t.Errorf("expected restored database to hold exactly the snapshot events, got head %d", targetDB.Head())
+	}
+	for i, record := range source.db.ReadFrom(0, 0) {
+		if got := targetDB.ReadFrom(0, 0)[i]; got.Event.ID != record.Event.ID || got.Position != record.Position {
+			t.Errorf("expected event %s at position %d", record.Event.ID, record.Position)
+		}
+	}
+	if m, _ := target.member("node-1"); m.HTTPAddr != "http://a" {
+		t.Error("expected members to be restored")
+	}
+}
+
+func TestFSM_SnapshotKeepsPayloadsSealed(t *testing.T) {
+	keys, _ := shred.NewKeyStore("")
+	cipher, _ := shred.NewCipher(keys, shred.KeyBySubject)
+	db := database.New()
+	db.SetPayloadCipher(cipher)
+	db.AddEvent(event.Candidate{Type: "user.registered", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{"email": "john@example.com"}})
+
+	snapshot, _ := newFSM(db).Snapshot()
+	sink := &bufferSink{}
+	if err := snapshot.Persist(sink); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+	if bytes.Contains(sink.Bytes(), []byte("john@example.com")) {
+		t.Error("expected the snapshot to hold the encrypted payload")
+	}
+}
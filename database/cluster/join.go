@@ -0,0 +1,65 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// joinRetryDelay is the pause between attempts to join a cluster.
+const joinRetryDelay = 2 * time.Second
+
+// JoinVia asks the cluster member at the given base URL to add the member. Followers forward the request
+// to the leader. It retries until the member was added or the context is cancelled.
+func JoinVia(ctx context.Context, baseURL string, m Member) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	for {
+		err := join(ctx, strings.TrimSuffix(baseURL, "/")+"/cluster/join", body)
+		if err == nil {
+			return nil
+		}
+		log.Printf("WARN Cannot join the cluster via %s: %v", baseURL, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(joinRetryDelay):
+		}
+	}
+}
+
+func join(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Ok    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("unexpected response with status %d", resp.StatusCode)
+	}
+	if !result.Ok {
+		return fmt.Errorf("join rejected: %s", result.Error)
+	}
+
+	return nil
+}
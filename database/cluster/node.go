@@ -0,0 +1,338 @@
+package cluster
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/event"
+)
+
+// ErrNotLeader is returned for writes to a node that is not the leader. Such writes should be forwarded
+// to the leader, see Node.LeaderURL.
+var ErrNotLeader = errors.New("node is not the cluster leader")
+
+// applyTimeout limits how long a write waits for the commit by a quorum.
+const applyTimeout = 10 * time.Second
+
+// Config configures a cluster node.
+type Config struct {
+	// ID identifies the node in the cluster. It must not change across restarts.
+	ID string
+	// RaftAddr is the TCP address for Raft traffic, e.g. 10.0.0.1:7000.
+	RaftAddr string
+	// HTTPAddr is the base URL of the HTTP API of the node, used to forward writes to the leader.
+	HTTPAddr string
+	// DataDir holds the Raft log and snapshots in the raft subdirectory.
+	DataDir string
+	// Bootstrap starts a new cluster with this node as the only member. It has no effect if the node has state.
+	Bootstrap bool
+}
+
+// Member is a node of the cluster.
+type Member struct {
+	ID       string `json:"id"`
+	RaftAddr string `json:"raftAddr"`
+	HTTPAddr string `json:"httpAddr"`
+}
+
+// MemberStatus is a member together with its voting state and whether it is the leader.
+type MemberStatus struct {
+	Member
+	Leader bool `json:"leader"`
+	Voter  bool `json:"voter"`
+}
+
+// Status reports the Raft state of the node and the members of the cluster.
+type Status struct {
+	ID      string         `json:"id"`
+	State   string         `json:"state"`
+	Leader  string         `json:"leader"`
+	Members []MemberStatus `json:"members"`
+}
+
+// Node is a database instance in a Raft cluster. Appends are committed to a quorum of the nodes before
+// they are applied to the database of every node, so a committed event survives the loss of a minority.
+// Only the leader accepts writes; the other nodes serve reads and report ErrNotLeader for writes.
+type Node struct {
+	config Config
+	raft   *raft.Raft
+	fsm    *fsm
+	closer func() error
+	done   chan struct{}
+}
+
+// NewNode starts a cluster node that replicates the database over TCP and keeps its Raft log in BoltDB.
+func NewNode(cfg Config, db *database.Database) (*Node, error) {
+	raftDir := filepath.Join(cfg.DataDir, "raft")
+	if err := os.MkdirAll(raftDir, 0755); err != nil {
+		return nil, err
+	}
+
+	addr, err := net.ResolveTCPAddr("tcp", cfg.RaftAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid raft address: %w", err)
+	}
+	transport, err := raft.NewTCPTransport(cfg.RaftAddr, addr, 3, 10*time.Second, os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := raftboltdb.NewBoltStore(filepath.Join(raftDir, "raft.db"))
+	if err != nil {
+		transport.Close()
+		return nil, err
+	}
+
+	snapshots, err := raft.NewFileSnapshotStore(raftDir, 2, os.Stderr)
+	if err != nil {
+		transport.Close()
+		store.Close()
+		return nil, err
+	}
+
+	node, err := newNode(cfg, db, raftConfig(cfg.ID), transport, store, store, snapshots)
+	if err != nil {
+		transport.Close()
+		store.Close()
+		return nil, err
+	}
+	node.closer = func() error {
+		return errors.Join(transport.Close(), store.Close())
+	}
+
+	return node, nil
+}
+
+// raftConfig returns the Raft configuration of a node with warnings and errors logged to stderr.
+func raftConfig(id string) *raft.Config {
+	c := raft.DefaultConfig()
+	c.LocalID = raft.ServerID(id)
+	c.Logger = hclog.New(&hclog.LoggerOptions{Name: "raft", Level: hclog.Warn, Output: os.Stderr})
+
+	return c
+}
+
+func newNode(cfg Config, db *database.Database, c *raft.Config, transport raft.Transport, logs raft.LogStore, stable raft.StableStore, snapshots raft.SnapshotStore) (*Node, error) {
+	if cfg.ID == "" {
+		return nil, errors.New("node ID is required")
+	}
+	// The Raft log and snapshots carry the payloads, which the node-local keys could not erase from them
+	if db.Encrypted() {
+		return nil, errors.New("payload encryption is not supported in clustered mode")
+	}
+
+	f := newFSM(db)
+	r, err := raft.NewRaft(c, f, logs, stable, snapshots, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &Node{config: cfg, raft: r, fsm: f, done: make(chan struct{})}
+
+	if cfg.Bootstrap {
+		hasState, err := raft.HasExistingState(logs, stable, snapshots)
+		if err != nil {
+			return nil, err
+		}
+		if !hasState {
+			bootstrap := raft.Configuration{Servers: []raft.Server{{ID: c.LocalID, Address: transport.LocalAddr()}}}
+			if err := r.BootstrapCluster(bootstrap).Error(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// The first leader registers the HTTP address of the bootstrap node, which did not join via Join.
+	go node.registerSelf()
+
+	return node, nil
+}
+
+// registerSelf records the HTTP address of the node once it becomes leader and is not yet a known member.
+func (n *Node) registerSelf() {
+	for {
+		select {
+		case <-n.done:
+			return
+		case <-n.raft.LeaderCh():
+		}
+
+		if _, known := n.fsm.member(n.config.ID); known || !n.IsLeader() {
+			continue
+		}
+
+		member := Member{ID: n.config.ID, RaftAddr: string(n.transportAddr()), HTTPAddr: n.config.HTTPAddr}
+		if err := n.apply(command{Op: opJoin, Member: &member}); err != nil {
+			log.Printf("WARN Cannot register the HTTP address of the leader: %v", err)
+		}
+	}
+}
+
+// AddEvent creates an event from the candidate and appends it to the cluster.
+func (n *Node) AddEvent(candidate event.Candidate) (*event.Event, error) {
+	e, err := event.New(candidate)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, _, err := n.AppendEvent(*e)
+	return stored, err
+}
+
+// AppendEvent commits the event to a quorum and returns once the leader has applied it.
+// It has the same semantics as Database.AppendEvent and returns ErrNotLeader on other nodes.
+func (n *Node) AppendEvent(e event.Event) (*event.Event, bool, error) {
+	if err := e.Validate(); err != nil {
+		return nil, false, err
+	}
+
+	data, err := json.Marshal(command{Op: opAppend, Event: &e})
+	if err != nil {
+		return nil, false, err
+	}
+
+	result, err := n.applyData(data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return result.event, result.duplicate, result.err
+}
+
+// Join adds the member as a voter. It must be called on the leader.
+func (n *Node) Join(m Member) error {
+	if m.ID == "" || m.RaftAddr == "" || m.HTTPAddr == "" {
+		return errors.New("member ID, raft address and HTTP address are required")
+	}
+	if !n.IsLeader() {
+		return ErrNotLeader
+	}
+
+	if err := n.raft.AddVoter(raft.ServerID(m.ID), raft.ServerAddress(m.RaftAddr), 0, applyTimeout).Error(); err != nil {
+		return err
+	}
+
+	return n.apply(command{Op: opJoin, Member: &m})
+}
+
+// Leave removes the member with the given ID from the cluster. It must be called on the leader.
+func (n *Node) Leave(id string) error {
+	if !n.IsLeader() {
+		return ErrNotLeader
+	}
+
+	if err := n.raft.RemoveServer(raft.ServerID(id), 0, applyTimeout).Error(); err != nil {
+		return err
+	}
+
+	return n.apply(command{Op: opLeave, Member: &Member{ID: id}})
+}
+
+// IsLeader reports whether the node is the leader.
+func (n *Node) IsLeader() bool {
+	return n.raft.State() == raft.Leader
+}
+
+// LeaderURL returns the HTTP base URL of the leader, or an empty string if there is no known leader.
+func (n *Node) LeaderURL() string {
+	_, id := n.raft.LeaderWithID()
+	if id == "" {
+		return ""
+	}
+
+	m, _ := n.fsm.member(string(id))
+	return m.HTTPAddr
+}
+
+// Status returns the Raft state of the node and the members of the cluster.
+func (n *Node) Status() Status {
+	_, leaderID := n.raft.LeaderWithID()
+	status := Status{ID: n.config.ID, State: n.raft.State().String(), Leader: string(leaderID), Members: []MemberStatus{}}
+
+	future := n.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return status
+	}
+
+	for _, server := range future.Configuration().Servers {
+		m, _ := n.fsm.member(string(server.ID))
+		m.ID, m.RaftAddr = string(server.ID), string(server.Address)
+		status.Members = append(status.Members, MemberStatus{
+			Member: m,
+			Leader: server.ID == leaderID,
+			Voter:  server.Suffrage == raft.Voter,
+		})
+	}
+
+	sort.Slice(status.Members, func(i, j int) bool {
+		return status.Members[i].ID < status.Members[j].ID
+	})
+
+	return status
+}
+
+// Shutdown stops the node. The Raft log is kept, so the node rejoins the cluster when it starts again.
+func (n *Node) Shutdown() error {
+	close(n.done)
+	err := n.raft.Shutdown().Error()
+	if n.closer != nil {
+		err = errors.Join(err, n.closer())
+	}
+
+	return err
+}
+
+func (n *Node) apply(cmd command) error {
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return err
+	}
+
+	result, err := n.applyData(data)
+	if err != nil {
+		return err
+	}
+
+	return result.err
+}
+
+func (n *Node) applyData(data []byte) (applyResult, error) {
+	if !n.IsLeader() {
+		return applyResult{}, ErrNotLeader
+	}
+
+	future := n.raft.Apply(data, applyTimeout)
+	if err := future.Error(); err != nil {
+		if errors.Is(err, raft.ErrNotLeader) || errors.Is(err, raft.ErrLeadershipLost) {
+			return applyResult{}, ErrNotLeader
+		}
+		return applyResult{}, err
+	}
+
+	result, _ := future.Response().(applyResult)
+	return result, nil
+}
+
+func (n *Node) transportAddr() raft.ServerAddress {
+	future := n.raft.GetConfiguration()
+	if future.Error() == nil {
+		for _, server := range future.Configuration().Servers {
+			if string(server.ID) == n.config.ID {
+				return server.Address
+			}
+		}
+	}
+
+	return raft.ServerAddress(n.config.RaftAddr)
+}
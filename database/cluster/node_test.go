@@ -0,0 +1,207 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/raft"
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/database/shred"
+	"github.com/nicograef/cloudevents/event"
+)
+
+type testNode struct {
+	*Node
+	db        *database.Database
+	transport *raft.InmemTransport
+}
+
+// newTestCluster starts n in-process nodes connected by in-memory transports. The first node bootstraps
+// the cluster and adds the others.
+func newTestCluster(t *testing.T, n int) []*testNode {
+	t.Helper()
+
+	nodes := make([]*testNode, n)
+	for i := range nodes {
+		id := fmt.Sprintf("node-%d", i+1)
+		_, transport := raft.NewInmemTransport(raft.ServerAddress(id))
+
+		c := raftConfig(id)
+		c.HeartbeatTimeout = 50 * time.Millisecond
+		c.ElectionTimeout = 50 * time.Millisecond
+		c.LeaderLeaseTimeout = 50 * time.Millisecond
+		c.CommitTimeout = 5 * time.Millisecond
+		c.Logger = hclog.New(&hclog.LoggerOptions{Name: id, Level: hclog.Off, Output: io.Discard})
+
+		db := database.New()
+		store := raft.NewInmemStore()
+		cfg := Config{ID: id, RaftAddr: id, HTTPAddr: "http://" + id, Bootstrap: i == 0}
+		node, err := newNode(cfg, db, c, transport, store, store, raft.NewInmemSnapshotStore())
+		if err != nil {
+			t.Fatalf("newNode failed: %v", err)
+		}
+		nodes[i] = &testNode{Node: node, db: db, transport: transport}
+	}
+
+	for _, a := range nodes {
+		for _, b := range nodes {
+			if a != b {
+				a.transport.Connect(b.transport.LocalAddr(), b.transport)
+			}
+		}
+	}
+
+	t.Cleanup(func() {
+		for _, node := range nodes {
+			select {
+			case <-node.done:
+			default:
+				node.Shutdown()
+			}
+		}
+	})
+
+	waitFor(t, nodes[0].IsLeader)
+	for _, node := range nodes[1:] {
+		if err := nodes[0].Join(Member{ID: node.config.ID, RaftAddr: node.config.RaftAddr, HTTPAddr: node.config.HTTPAddr}); err != nil {
+			t.Fatalf("Join failed: %v", err)
+		}
+	}
+
+	return nodes
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func leaderOf(nodes []*testNode) *testNode {
+	for _, node := range nodes {
+		select {
+		case <-node.done:
+			continue
+		default:
+		}
+		if node.IsLeader() {
+			return node
+		}
+	}
+	return nil
+}
+
+func TestCluster_ReplicatesCommittedAppends(t *testing.T) {
+	nodes := newTestCluster(t, 3)
+	leader := nodes[0]
+
+	added, err := leader.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{"k": "v"}})
+	if err != nil {
+		t.Fatalf("AddEvent failed: %v", err)
+	}
+
+	// The leader has applied the event when AddEvent returns
+	if leader.db.GetEvent(added.Source, added.ID) == nil {
+		t.Error("expected event to be applied on the leader")
+	}
+	for _, node := range nodes[1:] {
+		waitFor(t, func() bool { return node.db.GetEvent(added.Source, added.ID) != nil })
+		if got := node.db.GetEvent(added.Source, added.ID); !got.Time.Equal(added.Time) {
+			t.Errorf("expected %s to store the event with the same time", node.config.ID)
+		}
+	}
+
+	stored, duplicate, err := leader.AppendEvent(*added)
+	if err != nil || !duplicate || stored.ID != added.ID {
+		t.Errorf("expected duplicate append, got duplicate=%v and error %v", duplicate, err)
+	}
+	if _, _, err := leader.AppendEvent(event.Event{}); err == nil {
+		t.Error("expected invalid event to be rejected")
+	}
+}
+
+func TestCluster_FollowersRejectWrites(t *testing.T) {
+	nodes := newTestCluster(t, 3)
+	follower := nodes[1]
+
+	_, err := follower.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}})
+	if !errors.Is(err, ErrNotLeader) {
+		t.Errorf("expected ErrNotLeader, got %v", err)
+	}
+
+	waitFor(t, func() bool { return follower.LeaderURL() == "http://node-1" })
+}
+
+func TestCluster_Status(t *testing.T) {
+	nodes := newTestCluster(t, 3)
+
+	status := nodes[0].Status()
+	if status.ID != "node-1" || status.State != "Leader" || status.Leader != "node-1" || len(status.Members) != 3 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if !status.Members[0].Leader || !status.Members[1].Voter || status.Members[2].HTTPAddr != "http://node-3" {
+		t.Errorf("unexpected members %+v", status.Members)
+	}
+}
+
+func TestCluster_LeaderLoss(t *testing.T) {
+	nodes := newTestCluster(t, 3)
+
+	before, err := nodes[0].AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}})
+	if err != nil {
+		t.Fatalf("AddEvent failed: %v", err)
+	}
+
+	if err := nodes[0].Shutdown(); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	var leader *testNode
+	waitFor(t, func() bool {
+		leader = leaderOf(nodes)
+		return leader != nil
+	})
+
+	// The new leader applies the committed entries of the previous term after its election
+	waitFor(t, func() bool { return leader.db.GetEvent(before.Source, before.ID) != nil })
+	if _, err := leader.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/2", Data: map[string]any{}}); err != nil {
+		t.Fatalf("expected the new leader to accept writes: %v", err)
+	}
+
+	if err := leader.Leave("node-1"); err != nil {
+		t.Fatalf("Leave failed: %v", err)
+	}
+	if members := leader.Status().Members; len(members) != 2 {
+		t.Errorf("expected 2 members after leave, got %+v", members)
+	}
+}
+
+func TestJoin_RequiresAddresses(t *testing.T) {
+	nodes := newTestCluster(t, 1)
+
+	if err := nodes[0].Join(Member{ID: "node-9"}); err == nil {
+		t.Error("expected error for member without addresses")
+	}
+}
+
+func TestNewNode_RejectsEncryptedDatabase(t *testing.T) {
+	keys, _ := shred.NewKeyStore("")
+	cipher, _ := shred.NewCipher(keys, shred.KeyBySubject)
+	db := database.New()
+	db.SetPayloadCipher(cipher)
+
+	_, transport := raft.NewInmemTransport("node-1")
+	store := raft.NewInmemStore()
+	if _, err := newNode(Config{ID: "node-1"}, db, raftConfig("node-1"), transport, store, store, raft.NewInmemSnapshotStore()); err == nil {
+		t.Error("expected error for an encrypted database")
+	}
+}
@@ -0,0 +1,189 @@
+// Command dbctl exports and imports the events of the database in the data directory as NDJSON
+// and restores backups written by POST /admin/backup.
+// It reads DATA_DIR, STORAGE, ENCRYPTION_KEY and DATA_INDEXES like the database service. Stop the service before
+// importing, because it overwrites database.json on shutdown; use POST /import on a running service instead.
+//
+//	dbctl export [-type t] [-subject s] [-from time] [-to time] [-o file]
+//	dbctl import [-i file]
+//	dbctl restore [-dry-run] backup-dir
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/nicograef/cloudevents/database/app"
+	"github.com/nicograef/cloudevents/database/config"
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/database/namespace"
+	"github.com/nicograef/cloudevents/event"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "export":
+		err = runExport(os.Args[2:])
+	case "import":
+		err = runImport(os.Args[2:])
+	case "restore":
+		err = runRestore(os.Args[2:])
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: dbctl export|import|restore [flags]")
+	os.Exit(2)
+}
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	eventType := flags.String("type", "", "export only events of this type")
+	subject := flags.String("subject", "", "export only events of this subject or subject pattern")
+	from := flags.String("from", "", "export only events at or after this RFC 3339 time")
+	to := flags.String("to", "", "export only events at or before this RFC 3339 time")
+	output := flags.String("o", "", "output file (default stdout)")
+	flags.Parse(args)
+
+	filter := database.ExportFilter{Type: *eventType, Subject: *subject}
+	if filter.Subject != "" {
+		if err := event.ValidateSubjectPattern(filter.Subject); err != nil {
+			return err
+		}
+	}
+	var err error
+	if filter.Range.From, err = parseTime(*from); err != nil {
+		return fmt.Errorf("invalid from time: %w", err)
+	}
+	if filter.Range.To, err = parseTime(*to); err != nil {
+		return fmt.Errorf("invalid to time: %w", err)
+	}
+
+	db, err := app.LoadDatabase(config.Load())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	exported, err := db.Export(w, filter)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d events\n", exported)
+	return nil
+}
+
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	input := flags.String("i", "", "input file (default stdin)")
+	flags.Parse(args)
+
+	var r io.Reader = os.Stdin
+	if *input != "" {
+		file, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	cfg := config.Load()
+	db, err := app.LoadDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	summary, err := db.Import(r)
+	if err != nil {
+		return err
+	}
+
+	for _, lineErr := range summary.Errors {
+		fmt.Fprintf(os.Stderr, "line %d: %s\n", lineErr.Line, lineErr.Error)
+	}
+	fmt.Fprintf(os.Stderr, "Imported %d events, skipped %d, invalid %d\n", summary.Imported, summary.Skipped, summary.Invalid)
+
+	if summary.Imported == 0 {
+		return nil
+	}
+
+	return db.Persist(cfg.DataDir)
+}
+
+func runRestore(args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "only verify the backup")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("expected the backup directory as argument")
+	}
+
+	if *dryRun {
+		manifest, err := database.VerifyBackup(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Backup is valid: %d events up to position %d\n", manifest.EventCount, manifest.LastPosition)
+		return nil
+	}
+
+	cfg := config.Load()
+	// Backups only cover the default namespace, so restoring one would leave the namespaces at another point in time
+	names, err := namespace.Names(cfg.DataDir)
+	if err != nil {
+		return err
+	}
+	if len(names) > 0 {
+		return fmt.Errorf("DATA_DIR holds the namespaces %v, which backups do not cover", names)
+	}
+
+	db, err := app.LoadDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	manifest, err := db.RestoreBackup(flags.Arg(0), cfg.DataDir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Restored %d events up to position %d into %s\n", manifest.EventCount, manifest.LastPosition, cfg.DataDir)
+	return nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339Nano, value)
+}
@@ -3,24 +3,90 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds application configuration values loaded from environment variables.
 type Config struct {
-	Port    int    // Port for the HTTP server
-	DataDir string // Directory for data persistence
+	Port          int    // Port for the HTTP server
+	DataDir       string // Directory for data persistence
+	Storage       string // Storage backend for the events: memory or bolt
+	BackupDir     string // Directory for online backups
+	SnapshotEvery int    // Number of events after the latest snapshot that make a new aggregate snapshot due
+	// Data paths with a secondary index per event type
+	DataIndexes map[string][]string
+	// Default retention policy, e.g. maxAge:720h;maxPerSubject:100;maxBytes:1048576 (keep all events if empty)
+	Retention string
+	// Retention policies per event type in the same format, overriding the default policy
+	RetentionTypes    map[string]string
+	RetentionInterval time.Duration // How often expired events are removed
+	// Base URL of the leader to replicate from, e.g. http://leader:5000 (leader if empty)
+	ReplicationLeader string
+	// Data subject key for payload encryption: "subject" or a data path such as data.userId (no encryption if empty)
+	EncryptionKey string
+	// Validate the data of added and appended events against the JSON Schema registered for their type
+	SchemaValidation bool
+	// Compatibility rule for new schema versions: none, backward, forward or full
+	SchemaCompatibility string
+	// Raft cluster membership; clustered mode is enabled if the node ID is set
+	ClusterNodeID    string
+	ClusterRaftAddr  string // TCP address for Raft traffic
+	ClusterHTTPAddr  string // Base URL of this node for forwarded writes
+	ClusterBootstrap bool   // Start a new cluster with this node
+	ClusterJoin      string // Base URL of a cluster member to join on start
 }
 
 // Load reads configuration from environment variables and returns a Config struct.
-// Defaults: PORT=5000, DATA_DIR=current directory
+// Defaults: PORT=5000, DATA_DIR=current directory, STORAGE=memory, BACKUP_DIR=backups in DATA_DIR, SNAPSHOT_EVERY=100, DATA_INDEXES=none,
+// RETENTION=none, RETENTION_TYPES=none, RETENTION_INTERVAL_MINUTES=10, REPLICATION_LEADER=none, ENCRYPTION_KEY=none,
+// SCHEMA_VALIDATION=false, SCHEMA_COMPATIBILITY=backward, CLUSTER_NODE_ID=none, CLUSTER_RAFT_ADDR=127.0.0.1:7000, CLUSTER_HTTP_ADDR=http://localhost:PORT,
+// CLUSTER_BOOTSTRAP=false, CLUSTER_JOIN=none
 func Load() Config {
 	port := parseEnvInt("PORT", 5000)
 	dataDir := parseEnvString("DATA_DIR", ".")
+	storage := parseEnvString("STORAGE", "memory")
+	backupDir := parseEnvString("BACKUP_DIR", filepath.Join(dataDir, "backups"))
+	snapshotEvery := parseEnvInt("SNAPSHOT_EVERY", 100)
+	dataIndexes := parseEnvDataIndexes("DATA_INDEXES")
+	retention := parseEnvString("RETENTION", "")
+	retentionTypes := parseEnvRetentionTypes("RETENTION_TYPES")
+	retentionInterval := parseEnvInt("RETENTION_INTERVAL_MINUTES", 10)
+	replicationLeader := parseEnvString("REPLICATION_LEADER", "")
+	encryptionKey := parseEnvString("ENCRYPTION_KEY", "")
+	schemaValidation := parseEnvBool("SCHEMA_VALIDATION", false)
+	schemaCompatibility := parseEnvString("SCHEMA_COMPATIBILITY", "backward")
+	clusterNodeID := parseEnvString("CLUSTER_NODE_ID", "")
+	clusterRaftAddr := parseEnvString("CLUSTER_RAFT_ADDR", "127.0.0.1:7000")
+	clusterHTTPAddr := parseEnvString("CLUSTER_HTTP_ADDR", fmt.Sprintf("http://localhost:%d", port))
+	clusterBootstrap := parseEnvBool("CLUSTER_BOOTSTRAP", false)
+	clusterJoin := parseEnvString("CLUSTER_JOIN", "")
 
 	return Config{
-		Port:    port,
-		DataDir: dataDir,
+		Port:          port,
+		DataDir:       dataDir,
+		Storage:       storage,
+		BackupDir:     backupDir,
+		SnapshotEvery: snapshotEvery,
+		DataIndexes:   dataIndexes,
+
+		Retention:         retention,
+		RetentionTypes:    retentionTypes,
+		RetentionInterval: time.Duration(retentionInterval) * time.Minute,
+
+		ReplicationLeader: replicationLeader,
+		EncryptionKey:     encryptionKey,
+		SchemaValidation:  schemaValidation,
+
+		SchemaCompatibility: schemaCompatibility,
+
+		ClusterNodeID:    clusterNodeID,
+		ClusterRaftAddr:  clusterRaftAddr,
+		ClusterHTTPAddr:  clusterHTTPAddr,
+		ClusterBootstrap: clusterBootstrap,
+		ClusterJoin:      clusterJoin,
 	}
 }
 
@@ -34,6 +100,71 @@ func parseEnvString(name, defaultValue string) string {
 	return v
 }
 
+// parseEnvBool reads an environment variable by name and converts it to bool.
+// If conversion fails, logs an error and returns the provided default value.
+func parseEnvBool(name string, defaultValue bool) bool {
+	v := os.Getenv(name)
+	if v == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid %s value: %v\n", name, err)
+		return defaultValue
+	}
+
+	return b
+}
+
+// parseEnvDataIndexes reads comma-separated secondary index declarations of the form type=path,
+// e.g. book.borrowed=data.memberId. Malformed entries are logged and skipped.
+func parseEnvDataIndexes(name string) map[string][]string {
+	indexes := make(map[string][]string)
+
+	for entry := range strings.SplitSeq(os.Getenv(name), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		eventType, path, found := strings.Cut(entry, "=")
+		eventType, path = strings.TrimSpace(eventType), strings.TrimSpace(path)
+		if !found || eventType == "" || path == "" {
+			fmt.Fprintf(os.Stderr, "Invalid %s entry %q: expected type=path\n", name, entry)
+			continue
+		}
+
+		indexes[eventType] = append(indexes[eventType], path)
+	}
+
+	return indexes
+}
+
+// parseEnvRetentionTypes reads comma-separated retention policies per event type of the form type=policy,
+// e.g. book.borrowed=maxAge:720h;maxPerSubject:100. Malformed entries are logged and skipped.
+func parseEnvRetentionTypes(name string) map[string]string {
+	policies := make(map[string]string)
+
+	for entry := range strings.SplitSeq(os.Getenv(name), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		eventType, policy, found := strings.Cut(entry, "=")
+		eventType = strings.TrimSpace(eventType)
+		if !found || eventType == "" {
+			fmt.Fprintf(os.Stderr, "Invalid %s entry %q: expected type=policy\n", name, entry)
+			continue
+		}
+
+		policies[eventType] = strings.TrimSpace(policy)
+	}
+
+	return policies
+}
+
 // parseEnvInt reads an environment variable by name and converts it to int.
 // If conversion fails, logs an error and returns the provided default value.
 func parseEnvInt(name string, defaultValue int) int {
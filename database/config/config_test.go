@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoad_Defaults(t *testing.T) {
@@ -16,6 +17,12 @@ func TestLoad_Defaults(t *testing.T) {
 	if cfg.DataDir != "." {
 		t.Errorf("expected default data directory '.', got %s", cfg.DataDir)
 	}
+	if cfg.SnapshotEvery != 100 {
+		t.Errorf("expected default snapshot interval 100, got %d", cfg.SnapshotEvery)
+	}
+	if cfg.BackupDir != "backups" {
+		t.Errorf("expected default backup directory 'backups', got %s", cfg.BackupDir)
+	}
 }
 
 func TestLoad_EnvValues(t *testing.T) {
@@ -27,6 +34,9 @@ func TestLoad_EnvValues(t *testing.T) {
 	if err := os.Setenv("DATA_DIR", "/tmp/testdata"); err != nil {
 		t.Fatalf("Failed to set DATA_DIR: %v", err)
 	}
+	if err := os.Setenv("SNAPSHOT_EVERY", "25"); err != nil {
+		t.Fatalf("Failed to set SNAPSHOT_EVERY: %v", err)
+	}
 
 	cfg := Load()
 
@@ -36,6 +46,9 @@ func TestLoad_EnvValues(t *testing.T) {
 	if cfg.DataDir != "/tmp/testdata" {
 		t.Errorf("expected data directory '/tmp/testdata', got %s", cfg.DataDir)
 	}
+	if cfg.SnapshotEvery != 25 {
+		t.Errorf("expected snapshot interval 25, got %d", cfg.SnapshotEvery)
+	}
 }
 
 func TestLoad_InvalidIntAndLowValues(t *testing.T) {
@@ -101,3 +114,111 @@ func TestLoad_ValidPortEdgeCase(t *testing.T) {
 		t.Errorf("expected port 1, got %d", cfg.Port)
 	}
 }
+
+func TestLoad_DataIndexes(t *testing.T) {
+	os.Clearenv()
+
+	if err := os.Setenv("DATA_INDEXES", "book.borrowed=data.memberId, book.borrowed=data.isbn,invalid,order.placed=data.customer.id"); err != nil {
+		t.Fatalf("Failed to set DATA_INDEXES: %v", err)
+	}
+
+	cfg := Load()
+
+	if got := cfg.DataIndexes["book.borrowed"]; len(got) != 2 || got[0] != "data.memberId" || got[1] != "data.isbn" {
+		t.Errorf("expected two indexes for book.borrowed, got %v", got)
+	}
+	if got := cfg.DataIndexes["order.placed"]; len(got) != 1 || got[0] != "data.customer.id" {
+		t.Errorf("expected one index for order.placed, got %v", got)
+	}
+	if len(cfg.DataIndexes) != 2 {
+		t.Errorf("expected malformed entry to be skipped, got %v", cfg.DataIndexes)
+	}
+}
+
+func TestLoad_Retention(t *testing.T) {
+	os.Clearenv()
+
+	if err := os.Setenv("RETENTION", "maxAge:720h"); err != nil {
+		t.Fatalf("Failed to set RETENTION: %v", err)
+	}
+	if err := os.Setenv("RETENTION_TYPES", "audit.entry=,user.login=maxPerSubject:10;maxAge:24h,invalid"); err != nil {
+		t.Fatalf("Failed to set RETENTION_TYPES: %v", err)
+	}
+	if err := os.Setenv("RETENTION_INTERVAL_MINUTES", "5"); err != nil {
+		t.Fatalf("Failed to set RETENTION_INTERVAL_MINUTES: %v", err)
+	}
+
+	cfg := Load()
+
+	if cfg.Retention != "maxAge:720h" {
+		t.Errorf("expected default retention policy, got %q", cfg.Retention)
+	}
+	if len(cfg.RetentionTypes) != 2 || cfg.RetentionTypes["audit.entry"] != "" || cfg.RetentionTypes["user.login"] != "maxPerSubject:10;maxAge:24h" {
+		t.Errorf("unexpected retention policies per type %v", cfg.RetentionTypes)
+	}
+	if cfg.RetentionInterval != 5*time.Minute {
+		t.Errorf("expected retention interval of 5 minutes, got %v", cfg.RetentionInterval)
+	}
+}
+
+func TestLoad_EncryptionKey(t *testing.T) {
+	os.Clearenv()
+
+	if cfg := Load(); cfg.EncryptionKey != "" {
+		t.Errorf("expected encryption to be disabled by default, got %q", cfg.EncryptionKey)
+	}
+
+	if err := os.Setenv("ENCRYPTION_KEY", "data.userId"); err != nil {
+		t.Fatalf("Failed to set ENCRYPTION_KEY: %v", err)
+	}
+	if cfg := Load(); cfg.EncryptionKey != "data.userId" {
+		t.Errorf("expected data subject key data.userId, got %q", cfg.EncryptionKey)
+	}
+}
+
+func TestLoad_SchemaValidation(t *testing.T) {
+	os.Clearenv()
+
+	if cfg := Load(); cfg.SchemaValidation || cfg.SchemaCompatibility != "backward" {
+		t.Errorf("expected schema validation to be disabled and backward compatibility by default, got %+v", cfg)
+	}
+
+	if err := os.Setenv("SCHEMA_VALIDATION", "true"); err != nil {
+		t.Fatalf("Failed to set SCHEMA_VALIDATION: %v", err)
+	}
+	if err := os.Setenv("SCHEMA_COMPATIBILITY", "full"); err != nil {
+		t.Fatalf("Failed to set SCHEMA_COMPATIBILITY: %v", err)
+	}
+	if cfg := Load(); !cfg.SchemaValidation || cfg.SchemaCompatibility != "full" {
+		t.Errorf("expected schema validation with full compatibility, got %+v", cfg)
+	}
+}
+
+func TestLoad_Cluster(t *testing.T) {
+	os.Clearenv()
+
+	cfg := Load()
+	if cfg.ClusterNodeID != "" || cfg.ClusterBootstrap || cfg.ClusterRaftAddr != "127.0.0.1:7000" || cfg.ClusterHTTPAddr != "http://localhost:5000" {
+		t.Errorf("unexpected cluster defaults %+v", cfg)
+	}
+
+	for name, value := range map[string]string{
+		"CLUSTER_NODE_ID":   "node-2",
+		"CLUSTER_RAFT_ADDR": "10.0.0.2:7000",
+		"CLUSTER_HTTP_ADDR": "http://10.0.0.2:5000",
+		"CLUSTER_BOOTSTRAP": "yes",
+		"CLUSTER_JOIN":      "http://10.0.0.1:5000",
+	} {
+		if err := os.Setenv(name, value); err != nil {
+			t.Fatalf("Failed to set %s: %v", name, err)
+		}
+	}
+
+	cfg = Load()
+	if cfg.ClusterNodeID != "node-2" || cfg.ClusterRaftAddr != "10.0.0.2:7000" || cfg.ClusterHTTPAddr != "http://10.0.0.2:5000" || cfg.ClusterJoin != "http://10.0.0.1:5000" {
+		t.Errorf("unexpected cluster config %+v", cfg)
+	}
+	if cfg.ClusterBootstrap {
+		t.Error("expected invalid CLUSTER_BOOTSTRAP to fall back to false")
+	}
+}
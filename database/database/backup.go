@@ -0,0 +1,191 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// BackupFormatVersion is the version of the backup layout written by Backup.
+const BackupFormatVersion = 1
+
+// backupFiles are the files of a backup besides the manifest.
+var backupFiles = []string{"database.json", "snapshots.json"}
+
+// BackupManifest describes a backup. It is stored as manifest.json next to the backed up files.
+// LastPosition is the head of the append log at the time of the backup; Files maps each file to its SHA-256 checksum.
+type BackupManifest struct {
+	FormatVersion int               `json:"formatVersion"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	LastPosition  int               `json:"lastPosition"`
+	EventCount    int               `json:"eventCount"`
+	Files         map[string]string `json:"files"`
+}
+
+// Backup writes a consistent point-in-time copy of the events and snapshots to the target directory.
+// The state is copied under the read lock and written after releasing it, so writes continue during the backup.
+// The manifest is written last; a directory without a manifest is an incomplete backup.
+// Encryption keys are not part of the backup. An error is returned if the directory already holds a backup.
+func (db *Database) Backup(targetDir string) (BackupManifest, error) {
+	if _, err := os.Stat(filepath.Join(targetDir, "manifest.json")); err == nil {
+		return BackupManifest{}, errors.New("target directory already holds a backup")
+	}
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return BackupManifest{}, err
+	}
+
+	db.mu.RLock()
+	entries, err := db.logEntries()
+	if err != nil {
+		db.mu.RUnlock()
+		return BackupManifest{}, err
+	}
+	count := 0
+	for _, e := range entries {
+		if e != nil {
+			count++
+		}
+	}
+	snapshots := make([]Snapshot, 0, len(db.Snapshots))
+	for _, s := range db.Snapshots {
+		snapshots = append(snapshots, s)
+	}
+	manifest := BackupManifest{
+		FormatVersion: BackupFormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		LastPosition:  len(entries),
+		EventCount:    count,
+		Files:         make(map[string]string),
+	}
+	db.mu.RUnlock()
+
+	for name, content := range map[string]any{"database.json": entries, "snapshots.json": snapshots} {
+		data, err := json.Marshal(content)
+		if err != nil {
+			return BackupManifest{}, err
+		}
+		if err := os.WriteFile(filepath.Join(targetDir, name), data, 0644); err != nil {
+			return BackupManifest{}, err
+		}
+		manifest.Files[name] = checksum(data)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return BackupManifest{}, err
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "manifest.json"), data, 0644); err != nil {
+		return BackupManifest{}, err
+	}
+
+	return manifest, nil
+}
+
+// VerifyBackup checks the format version and checksums of the backup in the directory, loads it and
+// rebuilds its indexes, and compares the number of events and the last position with the manifest.
+func VerifyBackup(backupDir string) (BackupManifest, error) {
+	manifest, _, err := loadBackup(backupDir)
+	return manifest, err
+}
+
+// loadBackup verifies the backup in the directory like VerifyBackup and returns the loaded backup.
+func loadBackup(backupDir string) (BackupManifest, *Database, error) {
+	data, err := os.ReadFile(filepath.Join(backupDir, "manifest.json"))
+	if err != nil {
+		return BackupManifest{}, nil, fmt.Errorf("cannot read manifest: %w", err)
+	}
+
+	var manifest BackupManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return BackupManifest{}, nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.FormatVersion != BackupFormatVersion {
+		return manifest, nil, fmt.Errorf("unsupported backup format version %d", manifest.FormatVersion)
+	}
+
+	for _, name := range backupFiles {
+		data, err := os.ReadFile(filepath.Join(backupDir, name))
+		if err != nil {
+			return manifest, nil, err
+		}
+		if checksum(data) != manifest.Files[name] {
+			return manifest, nil, fmt.Errorf("checksum mismatch of %s", name)
+		}
+	}
+
+	db, err := LoadFromJSONFile(backupDir)
+	if err != nil {
+		return manifest, nil, fmt.Errorf("cannot load backup: %w", err)
+	}
+	if err := manifest.check(db); err != nil {
+		return manifest, nil, err
+	}
+
+	return manifest, db, nil
+}
+
+// RestoreBackup verifies the backup and replaces the events and snapshots of the database with it. The events
+// are appended to the store of the database at their positions, so a backup can be restored into every storage
+// backend. The restored database is compared with the manifest and persisted to the data directory.
+// A failed restore leaves the database incomplete and must be repeated.
+// The database service must be stopped during a restore, because it overwrites the files on shutdown.
+func (db *Database) RestoreBackup(backupDir, dataDir string) (BackupManifest, error) {
+	manifest, backup, err := loadBackup(backupDir)
+	if err != nil {
+		return manifest, err
+	}
+
+	if err := db.restore(backup); err != nil {
+		return manifest, fmt.Errorf("cannot restore backup: %w", err)
+	}
+	if err := manifest.check(db); err != nil {
+		return manifest, fmt.Errorf("restored database does not match the manifest: %w", err)
+	}
+
+	return manifest, db.Persist(dataDir)
+}
+
+// restore replaces the events and snapshots with the ones of the backup database.
+func (db *Database) restore(backup *Database) error {
+	entries, err := backup.logEntries()
+	if err != nil {
+		return err
+	}
+
+	if err := db.Reset(); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := appendLogEntries(db.events, entries); err != nil {
+		return err
+	}
+	db.Snapshots = backup.Snapshots
+
+	return db.rebuildIndexes()
+}
+
+// check compares the number of events, indexed events and the head position of the database with the manifest.
+func (m BackupManifest) check(db *Database) error {
+	count, indexed := db.Count(), len(db.GetEvents())
+	if count != m.EventCount || indexed != m.EventCount {
+		return fmt.Errorf("database holds %d events and %d indexed events, manifest expects %d", count, indexed, m.EventCount)
+	}
+	if head := db.Head(); head != m.LastPosition {
+		return fmt.Errorf("database ends at position %d, manifest expects %d", head, m.LastPosition)
+	}
+
+	return nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
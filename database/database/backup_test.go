@@ -0,0 +1,141 @@
+package database
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+func newBackupDatabase(t *testing.T) *Database {
+	t.Helper()
+	db := New()
+	for _, subject := range []string{"/users/1", "/users/1", "/users/2"} {
+		if _, err := db.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: subject, Data: user{"k": "v"}}); err != nil {
+			t.Fatalf("AddEvent failed: %v", err)
+		}
+	}
+	db.SaveSnapshot(Snapshot{Subject: "/users/1", Version: 2, State: json.RawMessage(`{}`)})
+	return db
+}
+
+func TestBackup(t *testing.T) {
+	db := newBackupDatabase(t)
+	backupDir := filepath.Join(t.TempDir(), "backup")
+
+	manifest, err := db.Backup(backupDir)
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	if manifest.FormatVersion != BackupFormatVersion || manifest.LastPosition != 3 || manifest.EventCount != 3 || len(manifest.Files) != 2 {
+		t.Errorf("unexpected manifest %+v", manifest)
+	}
+
+	// Writes after the backup are not part of it
+	db.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/3", Data: user{}})
+
+	verified, err := VerifyBackup(backupDir)
+	if err != nil {
+		t.Fatalf("VerifyBackup failed: %v", err)
+	}
+	if verified.EventCount != 3 {
+		t.Errorf("expected 3 events in backup, got %d", verified.EventCount)
+	}
+
+	if _, err := db.Backup(backupDir); err == nil {
+		t.Error("expected error when backing up into an existing backup")
+	}
+}
+
+func TestVerifyBackup_Corrupted(t *testing.T) {
+	backupDir := t.TempDir()
+	if _, err := newBackupDatabase(t).Backup(backupDir); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+
+	path := filepath.Join(backupDir, "database.json")
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, []byte(strings.Replace(string(data), "/users/2", "/users/9", 1)), 0644)
+
+	if _, err := VerifyBackup(backupDir); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("expected checksum error, got %v", err)
+	}
+
+	if _, err := VerifyBackup(t.TempDir()); err == nil {
+		t.Error("expected error for directory without manifest")
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	backupDir := t.TempDir()
+	if _, err := newBackupDatabase(t).Backup(backupDir); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+
+	dataDir := t.TempDir()
+	db := New()
+	db.AddEvent(event.Candidate{Type: "user.logout", Source: "https://example.com", Subject: "/users/9", Data: user{}})
+
+	if _, err := db.RestoreBackup(backupDir, dataDir); err != nil {
+		t.Fatalf("RestoreBackup failed: %v", err)
+	}
+	if db.Count() != 3 || len(db.GetEventsByType("user.logout")) != 0 {
+		t.Errorf("expected the events of the database to be replaced, got %d events", db.Count())
+	}
+
+	restored, err := LoadFromJSONFile(dataDir)
+	if err != nil {
+		t.Fatalf("LoadFromJSONFile failed: %v", err)
+	}
+	if restored.Count() != 3 || restored.GetSnapshot("/users/1") == nil {
+		t.Errorf("expected 3 restored events and a snapshot, got %d events", restored.Count())
+	}
+}
+
+func TestBackupAndRestore_KeepsPositions(t *testing.T) {
+	db := New()
+	for _, age := range []time.Duration{48 * time.Hour, time.Hour, 48 * time.Hour} {
+		appendAt(t, db, "user.update", "/users/1", age)
+	}
+	db.ApplyRetention(Retention{Default: RetentionPolicy{MaxAge: 24 * time.Hour}}, retentionNow)
+
+	backupDir := filepath.Join(t.TempDir(), "backup")
+	manifest, err := db.Backup(backupDir)
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	if manifest.LastPosition != 3 || manifest.EventCount != 1 {
+		t.Errorf("unexpected manifest %+v", manifest)
+	}
+
+	restored := New()
+	if _, err := restored.RestoreBackup(backupDir, t.TempDir()); err != nil {
+		t.Fatalf("RestoreBackup failed: %v", err)
+	}
+	if records := restored.ReadFrom(0, 0); restored.Head() != 3 || len(records) != 1 || records[0].Position != 2 {
+		t.Errorf("expected head 3 with the event at position 2, got head %d and %+v", restored.Head(), records)
+	}
+}
+
+func TestVerifyBackup_LastPositionMismatch(t *testing.T) {
+	backupDir := t.TempDir()
+	if _, err := newBackupDatabase(t).Backup(backupDir); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+
+	path := filepath.Join(backupDir, "manifest.json")
+	var manifest BackupManifest
+	data, _ := os.ReadFile(path)
+	json.Unmarshal(data, &manifest)
+	manifest.LastPosition = 5
+	data, _ = json.Marshal(manifest)
+	os.WriteFile(path, data, 0644)
+
+	if _, err := VerifyBackup(backupDir); err == nil || !strings.Contains(err.Error(), "position") {
+		t.Errorf("expected position mismatch error, got %v", err)
+	}
+}
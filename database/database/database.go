@@ -1,24 +1,69 @@
 package database
 
 import (
+	"log"
 	"sort"
+	"sync"
 
 	"github.com/google/uuid"
 	"github.com/nicograef/cloudevents/event"
 )
 
 type Database struct {
-	Events       map[uuid.UUID]event.Event
-	TypeIndex    map[string][]uuid.UUID
-	SubjectIndex map[string][]uuid.UUID
+	// Snapshots holds the latest aggregate snapshot per subject.
+	Snapshots map[string]Snapshot
+
+	// events holds the events and the indexes by type, subject and time.
+	events Store
+	// subjectTrie holds all subjects by their segments for subject pattern queries.
+	subjectTrie *subjectTrie
+	// dataIndexes holds the declared secondary indexes on data paths.
+	dataIndexes map[DataIndex]*dataIndex
+	// cipher encrypts the event payloads if set.
+	cipher PayloadCipher
+	// quota limits appends; size is the total size of the stored events if the quota limits the bytes.
+	quota Quota
+	size  int
+
+	mu     sync.RWMutex
+	notify chan struct{}
+}
+
+// Record is an event together with its position in the append log.
+type Record struct {
+	Position int         `json:"position"`
+	Event    event.Event `json:"event"`
 }
 
+// New creates an empty database that keeps its events in memory.
 func New() *Database {
-	return &Database{
-		Events:       make(map[uuid.UUID]event.Event),
-		TypeIndex:    make(map[string][]uuid.UUID),
-		SubjectIndex: make(map[string][]uuid.UUID),
+	db, _ := Open(NewMemoryStore())
+	return db
+}
+
+// Open creates a database on the store and builds the subject trie from the stored events.
+func Open(store Store) (*Database, error) {
+	db := &Database{
+		Snapshots:   make(map[string]Snapshot),
+		events:      store,
+		subjectTrie: newSubjectTrie(),
+		dataIndexes: make(map[DataIndex]*dataIndex),
+		notify:      make(chan struct{}),
 	}
+
+	if err := db.rebuildIndexes(); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Close closes the store of the database.
+func (db *Database) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.events.Close()
 }
 
 // AddEvent adds a new event to the database and updates the indexes
@@ -28,30 +73,125 @@ func (db *Database) AddEvent(candidate event.Candidate) (*event.Event, error) {
 		return nil, err
 	}
 
-	db.Events[event.ID] = *event
-	db.TypeIndex[event.Type] = append(db.TypeIndex[event.Type], event.ID)
-	db.SubjectIndex[event.Subject] = append(db.SubjectIndex[event.Subject], event.ID)
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	sealed, err := db.seal(*event)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.store(sealed); err != nil {
+		return nil, err
+	}
 
 	return event, nil
 }
 
-// GetEvent retrieves an event by its ID
-func (db *Database) GetEvent(id uuid.UUID) *event.Event {
-	event, exists := db.Events[id]
+// AppendEvent stores an event with a producer-chosen ID and time and updates the indexes.
+// Events are unique by source and ID: appending an event again returns the originally stored
+// event and duplicate set to true, which makes retries safe. The stored event is not modified.
+// Events of different sources may share an ID. An error is returned if the event is invalid or exceeds the quota.
+func (db *Database) AppendEvent(e event.Event) (stored *event.Event, duplicate bool, err error) {
+	if err := e.Validate(); err != nil {
+		return nil, false, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
 
+	existing, exists, err := db.events.Get(KeyOf(e))
+	if err != nil {
+		return nil, false, err
+	}
+	if exists {
+		existing = db.open(existing)
+		return &existing, true, nil
+	}
+
+	sealed, err := db.seal(e)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := db.store(sealed); err != nil {
+		return nil, false, err
+	}
+
+	return &e, false, nil
+}
+
+// GetEvent retrieves an event by its source and ID
+func (db *Database) GetEvent(source string, id uuid.UUID) *event.Event {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	event, exists, err := db.events.Get(EventKey{Source: source, ID: id})
+	if err != nil {
+		log.Printf("ERROR Cannot read event %s: %v", id, err)
+	}
 	if !exists {
 		return nil
 	}
 
+	event = db.open(event)
 	return &event
 }
 
 // GetEvents returns all events sorted by their timestamp
 func (db *Database) GetEvents() []event.Event {
-	events := make([]event.Event, 0, len(db.Events))
+	return db.GetEventsInRange(TimeRange{})
+}
+
+// GetEventsByType returns all events of a specific type sorted by their timestamp
+func (db *Database) GetEventsByType(eventType string) []event.Event {
+	return db.GetEventsByTypeInRange(eventType, TimeRange{})
+}
+
+// GetEventsBySubject returns all events for a specific subject sorted by their timestamp
+func (db *Database) GetEventsBySubject(subject string) []event.Event {
+	return db.GetEventsBySubjectInRange(subject, TimeRange{})
+}
+
+// GetEventsInRange returns all events within the time range sorted by their timestamp
+func (db *Database) GetEventsInRange(r TimeRange) []event.Event {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.scan(Filter{Range: r})
+}
+
+// GetEventsByTypeInRange returns all events of a specific type within the time range sorted by their timestamp
+func (db *Database) GetEventsByTypeInRange(eventType string, r TimeRange) []event.Event {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if eventType == "" {
+		return []event.Event{}
+	}
+
+	return db.scan(Filter{Type: eventType, Range: r})
+}
+
+// GetEventsBySubjectInRange returns all events for a specific subject within the time range sorted by their timestamp
+func (db *Database) GetEventsBySubjectInRange(subject string, r TimeRange) []event.Event {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if subject == "" {
+		return []event.Event{}
+	}
+
+	return db.scan(Filter{Subject: subject, Range: r})
+}
+
+// GetEventsMatchingSubject returns all events whose subject matches the pattern within the time range,
+// sorted by their timestamp. See event.MatchSubject for the pattern syntax.
+func (db *Database) GetEventsMatchingSubject(pattern string, r TimeRange) []event.Event {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
 
-	for _, event := range db.Events {
-		events = append(events, event)
+	events := []event.Event{}
+	for _, subject := range db.subjectTrie.match(pattern) {
+		events = append(events, db.scan(Filter{Subject: subject, Range: r})...)
 	}
 
 	sortEventsByTime(events)
@@ -59,61 +199,185 @@ func (db *Database) GetEvents() []event.Event {
 	return events
 }
 
-// GetEventsByType returns all events of a specific type sorted by their timestamp
-func (db *Database) GetEventsByType(eventType string) []event.Event {
-	eventIDs, exists := db.TypeIndex[eventType]
+// Head returns the position of the most recently appended event, or 0 if the database is empty.
+func (db *Database) Head() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
 
-	if !exists || len(eventIDs) == 0 {
-		return []event.Event{}
+	head, err := db.events.Head()
+	if err != nil {
+		log.Printf("ERROR Cannot read the head position: %v", err)
 	}
 
-	events := make([]event.Event, 0, len(eventIDs))
-	for _, id := range eventIDs {
-		if event, exists := db.Events[id]; exists {
-			events = append(events, event)
+	return head
+}
+
+// ReadFrom returns up to limit events that were appended after the given position, in append order.
+// A limit of 0 or less returns all remaining events.
+func (db *Database) ReadFrom(position, limit int) []Record {
+	return db.readFrom(position, limit, db.open)
+}
+
+// ReadSealedFrom returns the events like ReadFrom, but as stored, with encrypted payloads still sealed.
+func (db *Database) ReadSealedFrom(position, limit int) []Record {
+	return db.readFrom(position, limit, func(e event.Event) event.Event { return e })
+}
+
+func (db *Database) readFrom(position, limit int, open func(event.Event) event.Event) []Record {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	records := []Record{}
+	err := db.events.ScanLog(position, func(record Record) bool {
+		if limit > 0 && len(records) >= limit {
+			return false
 		}
+		record.Event = open(record.Event)
+		records = append(records, record)
+		return true
+	})
+	if err != nil {
+		log.Printf("ERROR Cannot read events after position %d: %v", position, err)
 	}
 
-	sortEventsByTime(events)
+	return records
+}
 
-	return events
+// Count returns the number of stored events.
+func (db *Database) Count() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	count, err := db.events.Count()
+	if err != nil {
+		log.Printf("ERROR Cannot count the events: %v", err)
+	}
 
+	return count
 }
 
-// GetEventsBySubject returns all events for a specific subject sorted by their timestamp
-func (db *Database) GetEventsBySubject(subject string) []event.Event {
-	eventIDs, exists := db.SubjectIndex[subject]
+// Changed returns a channel that is closed as soon as the next event is appended.
+func (db *Database) Changed() <-chan struct{} {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
 
-	if !exists || len(eventIDs) == 0 {
-		return []event.Event{}
+	return db.notify
+}
+
+// RebuildIndexes reconstructs the subject trie and the data indexes from the stored events.
+func (db *Database) RebuildIndexes() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.rebuildIndexes()
+}
+
+// Reset removes all events and snapshots. Declared data indexes and the payload cipher are kept.
+// Readers waiting for changes are notified.
+func (db *Database) Reset() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.events.Reset(); err != nil {
+		return err
+	}
+	db.Snapshots = make(map[string]Snapshot)
+
+	if db.notify != nil {
+		close(db.notify)
 	}
+	db.notify = make(chan struct{})
 
-	events := make([]event.Event, 0, len(eventIDs))
-	for _, id := range eventIDs {
-		if event, exists := db.Events[id]; exists {
-			events = append(events, event)
+	return db.rebuildIndexes()
+}
+
+// rebuildIndexes reconstructs the subject trie and the data indexes. The caller must hold the write lock.
+func (db *Database) rebuildIndexes() error {
+	db.subjectTrie = newSubjectTrie()
+	for key, idx := range db.dataIndexes {
+		db.dataIndexes[key] = &dataIndex{path: idx.path, values: make(map[string][]EventKey)}
+	}
+
+	subjects, err := db.events.Subjects()
+	if err != nil {
+		return err
+	}
+	for _, subject := range subjects {
+		db.subjectTrie.insert(subject)
+	}
+
+	db.size = 0
+	if len(db.dataIndexes) == 0 && db.quota.MaxBytes == 0 {
+		return nil
+	}
+
+	return db.events.ScanLog(0, func(record Record) bool {
+		db.indexData(record.Event)
+		if db.quota.MaxBytes > 0 {
+			db.size += eventSize(record.Event)
 		}
+		return true
+	})
+}
+
+// store appends the event to the store, updates the indexes and notifies waiting readers.
+// ErrQuotaExceeded is returned if the event does not fit into the quota. The caller must hold the write lock.
+func (db *Database) store(e event.Event) error {
+	size, err := db.checkQuota(e)
+	if err != nil {
+		return err
 	}
+	if err := db.events.Append(e); err != nil {
+		return err
+	}
+	db.size += size
+	db.subjectTrie.insert(e.Subject)
+	db.indexData(e)
 
-	sortEventsByTime(events)
+	if db.notify != nil {
+		close(db.notify)
+	}
+	db.notify = make(chan struct{})
 
-	return events
+	return nil
 }
 
-// RebuildIndexes reconstructs the indexes from the current events in the database
-func (db *Database) RebuildIndexes() {
-	db.TypeIndex = make(map[string][]uuid.UUID)
-	db.SubjectIndex = make(map[string][]uuid.UUID)
+// scan returns the events matching the filter in time order with their payloads decrypted.
+// Read errors of the store are logged and end the scan. The caller must hold the read lock.
+func (db *Database) scan(f Filter) []event.Event {
+	events := []event.Event{}
+	err := db.events.Scan(f, func(e event.Event) bool {
+		events = append(events, db.open(e))
+		return true
+	})
+	if err != nil {
+		log.Printf("ERROR Cannot read events: %v", err)
+	}
+
+	return events
+}
 
-	for id, event := range db.Events {
-		db.TypeIndex[event.Type] = append(db.TypeIndex[event.Type], id)
-		db.SubjectIndex[event.Subject] = append(db.SubjectIndex[event.Subject], id)
+// getEventsByKeys returns the events with the given keys in the given order with their payloads decrypted.
+// The caller must hold the read lock.
+func (db *Database) getEventsByKeys(keys []EventKey) []event.Event {
+	events := make([]event.Event, 0, len(keys))
+	for _, key := range keys {
+		event, exists, err := db.events.Get(key)
+		if err != nil {
+			log.Printf("ERROR Cannot read event %s: %v", key.ID, err)
+			break
+		}
+		if exists {
+			events = append(events, db.open(event))
+		}
 	}
+
+	return events
 }
 
-// sortEventsByTime sorts events by their timestamp
+// sortEventsByTime sorts events by their timestamp, keeping the order of events with the same timestamp
 func sortEventsByTime(events []event.Event) {
-	sort.Slice(events, func(i, j int) bool {
+	sort.SliceStable(events, func(i, j int) bool {
 		return events[i].Time.Before(events[j].Time)
 	})
 }
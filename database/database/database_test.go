@@ -2,6 +2,7 @@ package database
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/nicograef/cloudevents/event"
@@ -27,10 +28,10 @@ func TestDatabaseCreation(t *testing.T) {
 	if db == nil {
 		t.Fatal("Failed to create database")
 	}
-	if db.Events == nil {
+	if db.events == nil {
 		t.Fatal("Failed to create events")
 	}
-	if len(db.Events) != 0 {
+	if db.Count() != 0 {
 		t.Fatal("Failed to create events")
 	}
 }
@@ -46,13 +47,13 @@ func TestAddAndGetEvents(t *testing.T) {
 		t.Fatalf("AddEvent failed: %v", err)
 	}
 
-	if len(db.Events) != 2 {
+	if db.Count() != 2 {
 		t.Fatal("Failed to add events")
 	}
-	if len(db.TypeIndex) != 2 {
+	if len(db.GetEventsByType("user.new")) != 1 || len(db.GetEventsByType("user.update")) != 1 {
 		t.Fatal("Failed to create type index")
 	}
-	if len(db.SubjectIndex) != 1 {
+	if len(db.GetEventsBySubject("/users/1")) != 2 {
 		t.Fatal("Failed to create entity index")
 	}
 
@@ -90,11 +91,11 @@ func TestGetEventByID(t *testing.T) {
 		t.Fatalf("AddEvent failed: %v", err)
 	}
 
-	if nonExistingEvent := db.GetEvent(uuid.New()); nonExistingEvent != nil {
+	if nonExistingEvent := db.GetEvent(event1.Source, uuid.New()); nonExistingEvent != nil {
 		t.Fatal("Expected no event to be found")
 	}
 
-	event := db.GetEvent(event1.ID)
+	event := db.GetEvent(event1.Source, event1.ID)
 	if event == nil {
 		t.Fatal("Failed to get event by ID")
 	}
@@ -169,3 +170,162 @@ func TestGetEventsBySubject(t *testing.T) {
 		t.Fatal("Event retrieved is not the same as the one created")
 	}
 }
+
+func TestAppendEvent(t *testing.T) {
+	db := New()
+	e := event.Event{
+		ID:      uuid.New(),
+		Type:    "user.new",
+		Time:    time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		Source:  "https://example.com",
+		Subject: "/users/1",
+		Data:    user{"ID": "1", "Name": "John Doe"},
+	}
+
+	stored, duplicate, err := db.AppendEvent(e)
+	if err != nil {
+		t.Fatalf("AppendEvent failed: %v", err)
+	}
+	if duplicate {
+		t.Fatal("Expected first append not to be a duplicate")
+	}
+	if stored.ID != e.ID || !stored.Time.Equal(e.Time) {
+		t.Fatal("Expected producer-chosen ID and time to be preserved")
+	}
+	if len(db.GetEventsByType("user.new")) != 1 || len(db.GetEventsBySubject("/users/1")) != 1 {
+		t.Fatal("Failed to update indexes")
+	}
+}
+
+func TestAppendEvent_Duplicate(t *testing.T) {
+	db := New()
+	e := event.Event{ID: uuid.New(), Type: "user.new", Time: time.Now().UTC(), Source: "https://example.com", Subject: "/users/1", Data: user{"ID": "1"}}
+
+	if _, _, err := db.AppendEvent(e); err != nil {
+		t.Fatalf("AppendEvent failed: %v", err)
+	}
+
+	retry := e
+	retry.Data = user{"ID": "changed"}
+	stored, duplicate, err := db.AppendEvent(retry)
+	if err != nil {
+		t.Fatalf("AppendEvent failed: %v", err)
+	}
+	if !duplicate {
+		t.Fatal("Expected retry to be detected as duplicate")
+	}
+	if !equalUser(stored.Data.(user), user{"ID": "1"}) {
+		t.Fatal("Expected the originally stored event to be returned")
+	}
+	if db.Count() != 1 || len(db.GetEventsByType("user.new")) != 1 {
+		t.Fatal("Expected duplicate not to be stored")
+	}
+}
+
+func TestAppendEvent_SameIDFromAnotherSource(t *testing.T) {
+	db := New()
+	e := event.Event{ID: uuid.New(), Type: "user.new", Time: time.Now().UTC(), Source: "https://example.com", Subject: "/users/1", Data: user{"ID": "1"}}
+	other := e
+	other.Source = "https://other.example.com"
+	other.Subject = "/users/2"
+
+	for _, appended := range []event.Event{e, other} {
+		if _, duplicate, err := db.AppendEvent(appended); err != nil || duplicate {
+			t.Fatalf("AppendEvent failed: duplicate %v, error %v", duplicate, err)
+		}
+	}
+	if _, duplicate, err := db.AppendEvent(other); err != nil || !duplicate {
+		t.Fatalf("Expected the retried event of the other source to be a duplicate, got %v, %v", duplicate, err)
+	}
+
+	if db.Count() != 2 {
+		t.Fatalf("Expected 2 events, got %d", db.Count())
+	}
+	if got := db.GetEvent(e.Source, e.ID); got == nil || got.Subject != "/users/1" {
+		t.Errorf("Expected the event of the first source, got %+v", got)
+	}
+	if got := db.GetEvent(other.Source, other.ID); got == nil || got.Subject != "/users/2" {
+		t.Errorf("Expected the event of the other source, got %+v", got)
+	}
+}
+
+func TestAppendEvent_Errors(t *testing.T) {
+	db := New()
+	e := event.Event{ID: uuid.New(), Type: "user.new", Time: time.Now().UTC(), Source: "https://example.com", Subject: "/users/1", Data: user{"ID": "1"}}
+
+	if _, _, err := db.AppendEvent(e); err != nil {
+		t.Fatalf("AppendEvent failed: %v", err)
+	}
+
+	invalid := e
+	invalid.ID = uuid.New()
+	invalid.Type = "abc"
+	if _, _, err := db.AppendEvent(invalid); err == nil {
+		t.Fatal("Expected validation error")
+	}
+}
+
+func TestReadFrom(t *testing.T) {
+	db := New()
+	first, _ := db.AddEvent(event.Candidate{Type: "user.new", Source: "https://example.com", Subject: "/users/1", Data: user{"ID": "1"}})
+	second, _ := db.AddEvent(event.Candidate{Type: "user.update", Source: "https://example.com", Subject: "/users/1", Data: user{"ID": "1"}})
+	third, _ := db.AddEvent(event.Candidate{Type: "user.new", Source: "https://example.com", Subject: "/users/2", Data: user{"ID": "2"}})
+
+	if head := db.Head(); head != 3 {
+		t.Fatalf("Expected head 3, got %d", head)
+	}
+
+	records := db.ReadFrom(1, 0)
+	if len(records) != 2 || records[0].Event.ID != second.ID || records[1].Event.ID != third.ID {
+		t.Fatal("Expected events after position 1 in append order")
+	}
+	if records[0].Position != 2 || records[1].Position != 3 {
+		t.Fatalf("Unexpected positions: %d, %d", records[0].Position, records[1].Position)
+	}
+
+	if records := db.ReadFrom(0, 1); len(records) != 1 || records[0].Event.ID != first.ID {
+		t.Fatal("Expected limit to be honoured")
+	}
+	if records := db.ReadFrom(3, 0); len(records) != 0 {
+		t.Fatal("Expected no events after head")
+	}
+}
+
+func TestChanged(t *testing.T) {
+	db := New()
+	changed := db.Changed()
+
+	select {
+	case <-changed:
+		t.Fatal("Expected no change notification before append")
+	default:
+	}
+
+	db.AddEvent(event.Candidate{Type: "user.new", Source: "https://example.com", Subject: "/users/1", Data: user{"ID": "1"}})
+
+	select {
+	case <-changed:
+	default:
+		t.Fatal("Expected change notification after append")
+	}
+}
+
+func TestReset(t *testing.T) {
+	db := New()
+	db.AddEvent(event.Candidate{Type: "user.new", Source: "https://example.com", Subject: "/users/1", Data: user{"ID": "1"}})
+
+	if err := db.Reset(); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+
+	if db.Count() != 0 || db.Head() != 0 || len(db.GetEventsBySubject("/users/1")) != 0 {
+		t.Fatal("expected Reset to remove all events")
+	}
+
+	if _, err := db.AddEvent(event.Candidate{Type: "user.new", Source: "https://example.com", Subject: "/users/2", Data: user{"ID": "2"}}); err != nil {
+		t.Fatalf("AddEvent after Reset failed: %v", err)
+	}
+	if db.Head() != 1 {
+		t.Errorf("expected head 1, got %d", db.Head())
+	}
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/nicograef/cloudevents/database/query"
+	"github.com/nicograef/cloudevents/event"
+)
+
+// DataIndex is a secondary index on a path in the data payload of all events of one type.
+// Queries for that type with an equality predicate on the path use the index instead of scanning the events.
+type DataIndex struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+// dataIndex maps the JSON-encoded values at the path to the keys of the events holding them, in append order.
+type dataIndex struct {
+	path   query.Path
+	values map[string][]EventKey
+}
+
+// CreateDataIndex declares a secondary index on the data path for events of the given type
+// and indexes all stored events of that type. Creating an existing index has no effect.
+func (db *Database) CreateDataIndex(eventType, path string) error {
+	p, err := query.ParsePath(path)
+	if err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	key := DataIndex{Type: eventType, Path: p.String()}
+	if _, exists := db.dataIndexes[key]; exists {
+		return nil
+	}
+
+	idx := &dataIndex{path: p, values: make(map[string][]EventKey)}
+	err = db.events.ScanLog(0, func(record Record) bool {
+		if record.Event.Type == eventType {
+			idx.add(db.open(record.Event))
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	db.dataIndexes[key] = idx
+
+	return nil
+}
+
+// DataIndexes returns the declared secondary indexes sorted by type and path.
+func (db *Database) DataIndexes() []DataIndex {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	indexes := make([]DataIndex, 0, len(db.dataIndexes))
+	for key := range db.dataIndexes {
+		indexes = append(indexes, key)
+	}
+
+	sort.Slice(indexes, func(i, j int) bool {
+		if indexes[i].Type != indexes[j].Type {
+			return indexes[i].Type < indexes[j].Type
+		}
+		return indexes[i].Path < indexes[j].Path
+	})
+
+	return indexes
+}
+
+// GetEventsWhere returns all events of the type within the time range whose data payload matches the query,
+// sorted by their timestamp. An empty type queries events of all types.
+// If the type has a secondary index on the path of an equality predicate, only the indexed events are scanned.
+func (db *Database) GetEventsWhere(eventType string, q query.Query, r TimeRange) []event.Event {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var candidates []event.Event
+	if keys, indexed := db.lookupDataIndex(eventType, q); indexed {
+		for _, e := range db.getEventsByKeys(keys) {
+			if r.contains(e.Time) {
+				candidates = append(candidates, e)
+			}
+		}
+		sortEventsByTime(candidates)
+	} else {
+		candidates = db.scan(Filter{Type: eventType, Range: r})
+	}
+
+	events := []event.Event{}
+	for _, e := range candidates {
+		if q.Match(e.Data) {
+			events = append(events, e)
+		}
+	}
+
+	return events
+}
+
+// lookupDataIndex returns the keys of the events matching the first equality predicate with a secondary index.
+// The caller must hold the read lock.
+func (db *Database) lookupDataIndex(eventType string, q query.Query) ([]EventKey, bool) {
+	if eventType == "" {
+		return nil, false
+	}
+
+	for _, p := range q.Predicates {
+		if p.Op != query.OpEqual {
+			continue
+		}
+		if idx, exists := db.dataIndexes[DataIndex{Type: eventType, Path: p.Path.String()}]; exists {
+			return idx.values[dataIndexKey(p.Value)], true
+		}
+	}
+
+	return nil, false
+}
+
+// indexData adds the event to the secondary indexes of its type. The caller must hold the write lock.
+func (db *Database) indexData(e event.Event) {
+	for key, idx := range db.dataIndexes {
+		if key.Type == e.Type {
+			idx.add(db.open(e))
+		}
+	}
+}
+
+func (idx *dataIndex) add(e event.Event) {
+	if value, exists := idx.path.Lookup(e.Data); exists {
+		key := dataIndexKey(value)
+		idx.values[key] = append(idx.values[key], KeyOf(e))
+	}
+}
+
+// dataIndexKey encodes a JSON value so that equal values share a key.
+func dataIndexKey(value any) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+
+	return string(b)
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nicograef/cloudevents/database/query"
+	"github.com/nicograef/cloudevents/event"
+)
+
+func newBorrowDatabase(t *testing.T) *Database {
+	t.Helper()
+
+	db := New()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, memberID := range []int{99, 7, 99, 12} {
+		db.AppendEvent(event.Event{ID: uuid.New(), Type: "book.borrowed", Time: base.Add(time.Duration(i) * time.Hour), Source: "https://example.com", Subject: "/books/1", Data: map[string]any{"memberId": memberID, "days": 7 * (i + 1)}})
+	}
+	db.AppendEvent(event.Event{ID: uuid.New(), Type: "book.returned", Time: base, Source: "https://example.com", Subject: "/books/1", Data: map[string]any{"memberId": 99}})
+
+	return db
+}
+
+func mustParse(t *testing.T, s string) query.Query {
+	t.Helper()
+	q, err := query.Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q) failed: %v", s, err)
+	}
+	return q
+}
+
+func TestGetEventsWhere_Scan(t *testing.T) {
+	db := newBorrowDatabase(t)
+
+	if got := db.GetEventsWhere("book.borrowed", mustParse(t, "data.memberId = 99"), TimeRange{}); len(got) != 2 {
+		t.Errorf("expected 2 borrowed events of member 99, got %d", len(got))
+	}
+	if got := db.GetEventsWhere("", mustParse(t, "data.memberId = 99"), TimeRange{}); len(got) != 3 {
+		t.Errorf("expected 3 events of member 99 across types, got %d", len(got))
+	}
+	if got := db.GetEventsWhere("book.borrowed", mustParse(t, "data.days > 7 AND data.days <= 21"), TimeRange{}); len(got) != 2 {
+		t.Errorf("expected 2 events in the days range, got %d", len(got))
+	}
+}
+
+func TestGetEventsWhere_Index(t *testing.T) {
+	db := newBorrowDatabase(t)
+	if err := db.CreateDataIndex("book.borrowed", "data.memberId"); err != nil {
+		t.Fatalf("CreateDataIndex failed: %v", err)
+	}
+
+	// Events appended after the index was created are indexed as well
+	db.AppendEvent(event.Event{ID: uuid.New(), Type: "book.borrowed", Time: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Source: "https://example.com", Subject: "/books/2", Data: map[string]any{"memberId": 99, "days": 1}})
+
+	got := db.GetEventsWhere("book.borrowed", mustParse(t, "data.memberId = 99"), TimeRange{})
+	if len(got) != 3 {
+		t.Fatalf("expected 3 events from the index, got %d", len(got))
+	}
+	if got[0].Subject != "/books/2" {
+		t.Errorf("expected indexed events sorted by time, got %+v", got[0])
+	}
+
+	got = db.GetEventsWhere("book.borrowed", mustParse(t, "data.memberId = 99 AND data.days > 7"), TimeRange{From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+	if len(got) != 1 || got[0].Data.(map[string]any)["days"] != 21 {
+		t.Errorf("expected remaining predicates and range to apply to indexed events, got %+v", got)
+	}
+
+	if idx, indexed := db.lookupDataIndex("book.borrowed", mustParse(t, "data.memberId = 7")); !indexed || len(idx) != 1 {
+		t.Errorf("expected query to use the index, got %v (%v)", idx, indexed)
+	}
+}
+
+func TestCreateDataIndex(t *testing.T) {
+	db := New()
+
+	if err := db.CreateDataIndex("book.borrowed", "memberId"); err == nil {
+		t.Error("expected error for path without data prefix")
+	}
+
+	db.CreateDataIndex("book.borrowed", "data.memberId")
+	db.CreateDataIndex("book.borrowed", "data.memberId")
+	db.CreateDataIndex("book.borrowed", "data.isbn")
+
+	indexes := db.DataIndexes()
+	if len(indexes) != 2 || indexes[0].Path != "data.isbn" || indexes[1].Path != "data.memberId" {
+		t.Errorf("unexpected indexes %v", indexes)
+	}
+}
+
+func TestDataIndex_RebuiltWithIndexes(t *testing.T) {
+	db := newBorrowDatabase(t)
+	db.CreateDataIndex("book.borrowed", "data.memberId")
+
+	db.RebuildIndexes()
+
+	if got := db.GetEventsWhere("book.borrowed", mustParse(t, "data.memberId = 99"), TimeRange{}); len(got) != 2 {
+		t.Errorf("expected 2 events after rebuilding the indexes, got %d", len(got))
+	}
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"errors"
+	"log"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+// PayloadCipher encrypts the data payload of events before they are stored and decrypts it when they are read.
+// Events stay encrypted in memory and on disk; only their metadata is stored in plain text.
+type PayloadCipher interface {
+	// Seal returns the event with its payload encrypted.
+	Seal(e event.Event) (event.Event, error)
+	// Open returns the event with its payload decrypted, or redacted if its key was erased.
+	Open(e event.Event) event.Event
+	// KeyOf returns the key the stored event is encrypted with, or an empty string.
+	KeyOf(e event.Event) string
+	// Erase destroys the key, so the payloads encrypted with it can no longer be read.
+	Erase(key string) error
+}
+
+// SetPayloadCipher sets the cipher for event payloads. Events stored before are not encrypted,
+// so the cipher should be set right after the database is created or loaded.
+func (db *Database) SetPayloadCipher(c PayloadCipher) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.cipher = c
+	if err := db.rebuildIndexes(); err != nil {
+		log.Printf("ERROR Cannot rebuild the indexes: %v", err)
+	}
+}
+
+// Encrypted reports whether a payload cipher is set.
+func (db *Database) Encrypted() bool {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.cipher != nil
+}
+
+// EraseKey destroys the key and returns the number of events encrypted with it. The events keep their
+// metadata and positions but are returned with redacted data. Snapshots of their subjects are removed,
+// because they may hold the erased data.
+func (db *Database) EraseKey(key string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.cipher == nil {
+		return 0, errors.New("payload encryption is not enabled")
+	}
+
+	if err := db.cipher.Erase(key); err != nil {
+		return 0, err
+	}
+
+	erased := 0
+	err := db.events.ScanLog(0, func(record Record) bool {
+		if db.cipher.KeyOf(record.Event) == key {
+			erased++
+			delete(db.Snapshots, record.Event.Subject)
+		}
+		return true
+	})
+	if err != nil {
+		return erased, err
+	}
+
+	// The data indexes still hold the erased values.
+	return erased, db.rebuildIndexes()
+}
+
+// seal encrypts the payload of the event if a cipher is set.
+func (db *Database) seal(e event.Event) (event.Event, error) {
+	if db.cipher == nil {
+		return e, nil
+	}
+
+	return db.cipher.Seal(e)
+}
+
+// open decrypts the payload of the stored event if a cipher is set.
+func (db *Database) open(e event.Event) event.Event {
+	if db.cipher == nil {
+		return e
+	}
+
+	return db.cipher.Open(e)
+}
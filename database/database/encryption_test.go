@@ -0,0 +1,97 @@
+package database
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nicograef/cloudevents/database/query"
+	"github.com/nicograef/cloudevents/database/shred"
+	"github.com/nicograef/cloudevents/event"
+)
+
+func newEncryptedDatabase(t *testing.T) *Database {
+	t.Helper()
+	keys, _ := shred.NewKeyStore("")
+	cipher, err := shred.NewCipher(keys, shred.KeyBySubject)
+	if err != nil {
+		t.Fatalf("NewCipher failed: %v", err)
+	}
+
+	db := New()
+	db.SetPayloadCipher(cipher)
+	return db
+}
+
+func TestEncryption_StoresCiphertext(t *testing.T) {
+	db := newEncryptedDatabase(t)
+	e, err := db.AddEvent(event.Candidate{Type: "user.registered", Source: "https://example.com", Subject: "/users/1", Data: user{"email": "john@example.com"}})
+	if err != nil {
+		t.Fatalf("AddEvent failed: %v", err)
+	}
+
+	stored, _, _ := db.events.Get(KeyOf(*e))
+	b, _ := json.Marshal(stored)
+	if strings.Contains(string(b), "john@example.com") {
+		t.Error("expected stored event to be encrypted")
+	}
+
+	got := db.GetEvent(e.Source, e.ID)
+	if data, ok := got.Data.(map[string]any); !ok || data["email"] != "john@example.com" {
+		t.Errorf("expected decrypted data, got %v", got.Data)
+	}
+	if data, ok := db.ReadFrom(0, 0)[0].Event.Data.(map[string]any); !ok || data["email"] != "john@example.com" {
+		t.Error("expected ReadFrom to decrypt the data")
+	}
+	if b, _ := json.Marshal(db.ReadSealedFrom(0, 0)); strings.Contains(string(b), "john@example.com") {
+		t.Error("expected ReadSealedFrom to keep the data encrypted")
+	}
+	if !db.Encrypted() || New().Encrypted() {
+		t.Error("expected only the database with a cipher to be encrypted")
+	}
+}
+
+func TestEraseKey(t *testing.T) {
+	db := newEncryptedDatabase(t)
+	if err := db.CreateDataIndex("user.registered", "data.email"); err != nil {
+		t.Fatalf("CreateDataIndex failed: %v", err)
+	}
+
+	e1, _ := db.AddEvent(event.Candidate{Type: "user.registered", Source: "https://example.com", Subject: "/users/1", Data: user{"email": "john@example.com"}})
+	db.AddEvent(event.Candidate{Type: "user.registered", Source: "https://example.com", Subject: "/users/2", Data: user{"email": "jane@example.com"}})
+	db.SaveSnapshot(Snapshot{Subject: "/users/1", Version: 1, State: json.RawMessage(`{}`)})
+
+	q, _ := query.Parse("data.email = 'john@example.com'")
+	if got := db.GetEventsWhere("user.registered", q, TimeRange{}); len(got) != 1 {
+		t.Fatalf("expected indexed query to find 1 event, got %d", len(got))
+	}
+
+	erased, err := db.EraseKey("/users/1")
+	if err != nil || erased != 1 {
+		t.Fatalf("expected 1 erased event, got %d and error %v", erased, err)
+	}
+
+	got := db.GetEvent(e1.Source, e1.ID)
+	if marker, ok := got.Data.(shred.Erased); !ok || !marker.Erased {
+		t.Errorf("expected erased marker, got %v", got.Data)
+	}
+	if got.Subject != "/users/1" || db.Head() != 2 || db.ReadFrom(0, 0)[0].Position != 1 {
+		t.Error("expected metadata and positions to stay intact")
+	}
+	if db.GetSnapshot("/users/1") != nil {
+		t.Error("expected snapshot of erased subject to be removed")
+	}
+	if got := db.GetEventsWhere("user.registered", q, TimeRange{}); len(got) != 0 {
+		t.Errorf("expected erased data to be removed from the data index, got %d events", len(got))
+	}
+
+	if _, err := db.AddEvent(event.Candidate{Type: "user.updated", Source: "https://example.com", Subject: "/users/1", Data: user{}}); err == nil {
+		t.Error("expected adding events with an erased key to fail")
+	}
+}
+
+func TestEraseKey_WithoutCipher(t *testing.T) {
+	if _, err := New().EraseKey("/users/1"); err == nil {
+		t.Error("expected error without payload cipher")
+	}
+}
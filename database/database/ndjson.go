@@ -0,0 +1,147 @@
+package database
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+// maxImportErrors limits the number of line errors reported by an import.
+const maxImportErrors = 100
+
+// ExportFilter selects the events of an export. Empty fields match all events.
+// Subject may be a pattern such as /libraries/7/** (see event.MatchSubject).
+type ExportFilter struct {
+	Type    string
+	Subject string
+	Range   TimeRange
+}
+
+// ImportSummary reports the result of an import. Skipped lines hold events that were already stored,
+// invalid lines could not be parsed or validated. Errors holds the first line errors.
+type ImportSummary struct {
+	Imported int           `json:"imported"`
+	Skipped  int           `json:"skipped"`
+	Invalid  int           `json:"invalid"`
+	Errors   []ImportError `json:"errors,omitempty"`
+}
+
+// ImportError describes an invalid line of an import by its line number, starting at 1.
+type ImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// Export writes the events matching the filter as NDJSON (one JSON event per line) sorted by their timestamp
+// and returns the number of written events.
+func (db *Database) Export(w io.Writer, f ExportFilter) (int, error) {
+	encoder := json.NewEncoder(w)
+	return db.export(f, func(e event.Event) error { return encoder.Encode(e) })
+}
+
+// ExportAvro writes the events matching the filter as an Avro object container file in the CloudEvents Avro
+// format, sorted by their timestamp, and returns the number of written events.
+func (db *Database) ExportAvro(w io.Writer, f ExportFilter) (int, error) {
+	aw, err := event.NewAvroWriter(w)
+	if err != nil {
+		return 0, err
+	}
+
+	written, err := db.export(f, aw.Write)
+	if err != nil {
+		return written, err
+	}
+
+	return written, aw.Close()
+}
+
+// export calls write for each event matching the filter, sorted by timestamp, and returns the number of
+// written events. The events are streamed from the store one at a time, so an export does not hold all events
+// in memory. Writes to the database wait until the export is done.
+func (db *Database) export(f ExportFilter, write func(event.Event) error) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	filter := Filter{Type: f.Type, Subject: f.Subject, Range: f.Range}
+	pattern := ""
+	if event.IsSubjectPattern(f.Subject) {
+		filter.Subject, pattern = "", f.Subject
+	}
+
+	written := 0
+	var writeErr error
+	err := db.events.Scan(filter, func(e event.Event) bool {
+		if pattern != "" && !event.MatchSubject(pattern, e.Subject) {
+			return true
+		}
+		if writeErr = write(db.open(e)); writeErr != nil {
+			return false
+		}
+		written++
+		return true
+	})
+	if writeErr != nil {
+		return written, writeErr
+	}
+
+	return written, err
+}
+
+// Import reads NDJSON events line by line and appends them with their IDs and times.
+// Events that are already stored are skipped, so an import can be repeated safely.
+// Invalid lines are counted and reported but do not stop the import; only read errors do.
+func (db *Database) Import(r io.Reader) (ImportSummary, error) {
+	return ImportEvents(r, db.AppendEvent)
+}
+
+// ImportEvents reads NDJSON events like Database.Import and appends them with the given function,
+// e.g. to commit them to a cluster.
+func ImportEvents(r io.Reader, appendEvent func(event.Event) (*event.Event, bool, error)) (ImportSummary, error) {
+	summary := ImportSummary{}
+	reader := bufio.NewReader(r)
+
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return summary, fmt.Errorf("cannot read line %d: %w", line, err)
+		}
+
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			if lineErr := importLine(data, appendEvent, &summary); lineErr != nil {
+				summary.Invalid++
+				if len(summary.Errors) < maxImportErrors {
+					summary.Errors = append(summary.Errors, ImportError{Line: line, Error: lineErr.Error()})
+				}
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return summary, nil
+		}
+	}
+}
+
+func importLine(data []byte, appendEvent func(event.Event) (*event.Event, bool, error), summary *ImportSummary) error {
+	var e event.Event
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+
+	_, duplicate, err := appendEvent(e)
+	if err != nil {
+		return err
+	}
+
+	if duplicate {
+		summary.Skipped++
+	} else {
+		summary.Imported++
+	}
+
+	return nil
+}
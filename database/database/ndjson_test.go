@@ -0,0 +1,161 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nicograef/cloudevents/event"
+)
+
+func TestExport(t *testing.T) {
+	db := New()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	db.AppendEvent(event.Event{ID: uuid.New(), Type: "book.borrowed", Time: base, Source: "https://example.com", Subject: "/libraries/1/books/1", Data: user{}})
+	db.AppendEvent(event.Event{ID: uuid.New(), Type: "book.returned", Time: base.Add(time.Hour), Source: "https://example.com", Subject: "/libraries/1/books/1", Data: user{}})
+	db.AppendEvent(event.Event{ID: uuid.New(), Type: "book.borrowed", Time: base.Add(2 * time.Hour), Source: "https://example.com", Subject: "/libraries/2/books/7", Data: user{}})
+
+	tests := []struct {
+		name   string
+		filter ExportFilter
+		want   int
+	}{
+		{"all", ExportFilter{}, 3},
+		{"type", ExportFilter{Type: "book.borrowed"}, 2},
+		{"subject", ExportFilter{Subject: "/libraries/1/books/1"}, 2},
+		{"subject pattern and type", ExportFilter{Type: "book.borrowed", Subject: "/libraries/*/books/*"}, 2},
+		{"time range", ExportFilter{Range: TimeRange{From: base.Add(time.Hour)}}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := db.Export(&buf, tt.filter)
+			if err != nil {
+				t.Fatalf("Export failed: %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if n != tt.want || len(lines) != tt.want {
+				t.Errorf("expected %d events, got %d and %d lines", tt.want, n, len(lines))
+			}
+			for _, line := range lines {
+				if !json.Valid([]byte(line)) {
+					t.Errorf("expected a JSON event per line, got %q", line)
+				}
+			}
+		})
+	}
+}
+
+// failingWriter accepts limit writes and fails after that.
+type failingWriter struct {
+	writes, limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.writes >= w.limit {
+		return 0, errors.New("connection closed")
+	}
+	w.writes++
+	return len(p), nil
+}
+
+func TestExport_StopsOnWriteError(t *testing.T) {
+	db := New()
+	for range 3 {
+		db.AddEvent(event.Candidate{Type: "book.borrowed", Source: "https://example.com", Subject: "/libraries/1/books/1", Data: user{}})
+	}
+
+	w := &failingWriter{limit: 1}
+	n, err := db.Export(w, ExportFilter{})
+	if err == nil || n != 1 || w.writes != 1 {
+		t.Errorf("expected the export to stop after the failed write, got %d events and %v", n, err)
+	}
+}
+
+func TestExportAvro(t *testing.T) {
+	db := New()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := event.Event{ID: uuid.New(), Type: "book.borrowed", Time: base, Source: "https://example.com", Subject: "/libraries/1/books/1", Data: user{"name": "Ada"}}
+	db.AppendEvent(first)
+	db.AppendEvent(event.Event{ID: uuid.New(), Type: "book.returned", Time: base.Add(time.Hour), Source: "https://example.com", Subject: "/libraries/1/books/1", Data: user{}})
+
+	var buf bytes.Buffer
+	n, err := db.ExportAvro(&buf, ExportFilter{Type: "book.borrowed"})
+	if err != nil {
+		t.Fatalf("ExportAvro failed: %v", err)
+	}
+
+	events, err := event.ReadAvroFile(&buf)
+	if err != nil {
+		t.Fatalf("ReadAvroFile failed: %v", err)
+	}
+	if n != 1 || len(events) != 1 || events[0].ID != first.ID || !events[0].Time.Equal(base) {
+		t.Fatalf("expected the borrowed event, got %d %+v", n, events)
+	}
+	if data, err := event.DecodeData[user](events[0]); err != nil || data["name"] != "Ada" {
+		t.Errorf("expected the event data, got %+v, %v", data, err)
+	}
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	source := New()
+	for i := range 3 {
+		source.AppendEvent(event.Event{ID: uuid.New(), Type: "user.login", Time: time.Date(2024, 1, 1, i, 0, 0, 0, time.UTC), Source: "https://example.com", Subject: "/users/1", Data: user{"n": "v"}})
+	}
+
+	var buf bytes.Buffer
+	if _, err := source.Export(&buf, ExportFilter{}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	target := New()
+	summary, err := target.Import(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if summary.Imported != 3 || summary.Skipped != 0 || summary.Invalid != 0 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+
+	for _, e := range source.GetEvents() {
+		got := target.GetEvent(e.Source, e.ID)
+		if got == nil || !got.Time.Equal(e.Time) {
+			t.Errorf("expected event %s to be imported with its time", e.ID)
+		}
+	}
+
+	summary, _ = target.Import(bytes.NewReader(buf.Bytes()))
+	if summary.Imported != 0 || summary.Skipped != 3 {
+		t.Errorf("expected repeated import to skip all events, got %+v", summary)
+	}
+}
+
+func TestImport_InvalidLines(t *testing.T) {
+	valid := `{"id":"f8ceae97-5a98-473d-a075-c1f0a530da2c","type":"user.new","time":"2025-09-01T17:09:53Z","source":"https://example.com","subject":"/users/1","data":{}}`
+	input := strings.Join([]string{
+		valid,
+		"",
+		"not json",
+		`{"id":"9b2b6bd0-2a7f-4b0b-9d36-5f7f3e9b4c11","type":"","time":"2025-09-01T17:09:53Z","source":"https://example.com","subject":"/users/1","data":{}}`,
+		strings.Replace(valid, "https://example.com", "https://other.com", 1), // same ID, other source
+		valid,
+	}, "\n")
+
+	summary, err := New().Import(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if summary.Imported != 2 || summary.Skipped != 1 || summary.Invalid != 2 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if len(summary.Errors) != 2 || summary.Errors[0].Line != 3 || summary.Errors[1].Line != 4 {
+		t.Errorf("unexpected line errors %+v", summary.Errors)
+	}
+}
@@ -2,17 +2,19 @@ package database
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 
-	"github.com/google/uuid"
 	"github.com/nicograef/cloudevents/event"
 )
 
 // LoadFromJSONFile loads the database state from a JSON file on disk.
 // If the file does not exist or cannot be read, an error is returned.
-// The indexes are rebuilt after loading the events.
+// The events keep their positions in the log. The indexes are rebuilt after loading the events.
+// Snapshots are loaded from snapshots.json if it exists.
 func LoadFromJSONFile(dataDir string) (*Database, error) {
 	filePath := filepath.Join(dataDir, "database.json")
 	file, err := os.Open(filePath)
@@ -26,28 +28,35 @@ func LoadFromJSONFile(dataDir string) (*Database, error) {
 		}
 	}()
 
-	// Decode the JSON data into a slice of events
-	var events []event.Event
+	// Decode the JSON data into a slice of log entries
+	var entries []*event.Event
 	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&events); err != nil {
+	if err := decoder.Decode(&entries); err != nil {
 		return nil, err
 	}
 
-	// Rebuild the events map from the loaded events slice
-	eventsMap := make(map[uuid.UUID]event.Event)
-	for _, e := range events {
-		eventsMap[e.ID] = e
+	// Append the loaded events at their positions; the events are stored as they are, so encrypted payloads stay sealed
+	db := New()
+	if err := appendLogEntries(db.events, entries); err != nil {
+		return nil, err
 	}
 
-	db := Database{Events: eventsMap}
-	db.RebuildIndexes()
+	if err := db.RebuildIndexes(); err != nil {
+		return nil, err
+	}
+
+	if err := db.loadSnapshots(dataDir); err != nil {
+		return nil, err
+	}
 
-	return &db, nil
+	return db, nil
 }
 
 // PersistToJsonFile saves the current state of the database to the disk.
-// The events are stored as an array in a JSON format for easy parsing.
+// The events are stored as an array in append order in a JSON format for easy parsing. The index of an event
+// in the array is its position minus one; removed events are stored as null, so the positions are kept.
 // The indexes are not persisted to save space and can be rebuilt on load.
+// Snapshots are stored next to the events in snapshots.json.
 func (db *Database) PersistToJsonFile(dataDir string) error {
 	filePath := filepath.Join(dataDir, "database.json")
 	file, err := os.Create(filePath)
@@ -61,17 +70,161 @@ func (db *Database) PersistToJsonFile(dataDir string) error {
 		}
 	}()
 
-	// convert map to slice for easier JSON encoding
-	events := make([]event.Event, 0, len(db.Events))
-	for _, e := range db.Events {
-		events = append(events, e)
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	// collect the events in append order for easier JSON encoding
+	entries, err := db.logEntries()
+	if err != nil {
+		return err
 	}
 
-	// Encode the events slice to JSON and write to file
+	// Encode the entries slice to JSON and write to file
 	encoder := json.NewEncoder(file)
-	if err := encoder.Encode(events); err != nil {
+	if err := encoder.Encode(entries); err != nil {
 		return err
 	}
 
+	return db.persistSnapshots(dataDir)
+}
+
+// LoadFromStore opens a database on the store and loads the snapshots from snapshots.json in the data directory.
+// If the store is empty and the directory holds a database.json, e.g. after switching from the in-memory store
+// or restoring a backup, its events are appended to the store at their positions and the file is renamed to
+// database.json.imported.
+func LoadFromStore(store Store, dataDir string) (*Database, error) {
+	db, err := Open(store)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := store.Head()
+	if err != nil {
+		return nil, err
+	}
+	filePath := filepath.Join(dataDir, "database.json")
+	if _, err := os.Stat(filePath); head > 0 || err != nil {
+		return db, db.loadSnapshots(dataDir)
+	}
+
+	source, err := LoadFromJSONFile(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := source.logEntries()
+	if err != nil {
+		return nil, err
+	}
+	if err := appendLogEntries(store, entries); err != nil {
+		return nil, fmt.Errorf("cannot import database.json: %w", err)
+	}
+	imported, err := store.Count()
+	if err != nil {
+		return nil, err
+	}
+	db.Snapshots = source.Snapshots
+	if err := db.RebuildIndexes(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("INFO Imported %d events from database.json into the store", imported)
+
+	return db, os.Rename(filePath, filePath+".imported")
+}
+
+// Persist saves the database to the data directory. Events in a store that writes them to disk are only synced;
+// otherwise they are written to database.json. Snapshots are always written to snapshots.json.
+func (db *Database) Persist(dataDir string) error {
+	durable, ok := db.events.(durableStore)
+	if !ok {
+		return db.PersistToJsonFile(dataDir)
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if err := durable.Sync(); err != nil {
+		return err
+	}
+
+	return db.persistSnapshots(dataDir)
+}
+
+// logEntries returns the entries of the append log up to the head: the entry at index i holds the event at
+// position i+1, or nil if the event was removed. The caller must hold the read lock.
+func (db *Database) logEntries() ([]*event.Event, error) {
+	head, err := db.events.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*event.Event, head)
+	err = db.events.ScanLog(0, func(record Record) bool {
+		entries[record.Position-1] = &record.Event
+		return true
+	})
+
+	return entries, err
+}
+
+// appendLogEntries appends the events of the log entries to the store at their positions and moves the head
+// to the end of the entries. Removed and duplicate events leave their positions empty.
+func appendLogEntries(store Store, entries []*event.Event) error {
+	for i, e := range entries {
+		if e == nil {
+			continue
+		}
+		_, exists, err := store.Get(KeyOf(*e))
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if err := store.SkipTo(i); err != nil {
+			return err
+		}
+		if err := store.Append(*e); err != nil {
+			return err
+		}
+	}
+
+	return store.SkipTo(len(entries))
+}
+
+// loadSnapshots reads the snapshots from snapshots.json. A missing file is not an error.
+func (db *Database) loadSnapshots(dataDir string) error {
+	data, err := os.ReadFile(filepath.Join(dataDir, "snapshots.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var snapshots []Snapshot
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		return err
+	}
+
+	for _, s := range snapshots {
+		db.Snapshots[s.Subject] = s
+	}
+
 	return nil
 }
+
+// persistSnapshots writes the snapshots to snapshots.json. The caller must hold the read lock.
+func (db *Database) persistSnapshots(dataDir string) error {
+	snapshots := make([]Snapshot, 0, len(db.Snapshots))
+	for _, s := range db.Snapshots {
+		snapshots = append(snapshots, s)
+	}
+
+	data, err := json.Marshal(snapshots)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dataDir, "snapshots.json"), data, 0644)
+}
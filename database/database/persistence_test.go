@@ -1,8 +1,11 @@
 package database
 
 import (
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/nicograef/cloudevents/event"
@@ -10,6 +13,7 @@ import (
 
 func TestPersistToJsonFile(t *testing.T) {
 	defer os.Remove("database.json") // Clean up after test
+	defer os.Remove("snapshots.json")
 
 	db := New()
 	if db == nil {
@@ -49,7 +53,7 @@ func TestLoadDatabaseFromJsonFile(t *testing.T) {
 		t.Fatal("Loaded database is nil")
 	}
 
-	if len(db.Events) != 1 {
+	if db.Count() != 1 {
 		t.Fatal("Failed to load events")
 	}
 
@@ -57,15 +61,14 @@ func TestLoadDatabaseFromJsonFile(t *testing.T) {
 	if err != nil {
 		t.Fatal("Failed to parse UUID:", err)
 	}
-	event := db.GetEvent(id)
-	if event == nil {
+	e := db.GetEvent("https://example.com", id)
+	if e == nil {
 		t.Fatal("Failed to get event by ID")
 	}
 
-	// Convert the event data to user map for comparison
-	eventData, ok := event.Data.(map[string]interface{})
-	if !ok {
-		t.Fatal("Event data is not a map")
+	eventData, err := event.DecodeData[user](*e)
+	if err != nil {
+		t.Fatal("Failed to decode event data:", err)
 	}
 
 	expectedUser := user{"ID": "1", "Name": "John Doe"}
@@ -73,3 +76,144 @@ func TestLoadDatabaseFromJsonFile(t *testing.T) {
 		t.Fatalf("Event data mismatch. Got: %v, Expected: %v", eventData, expectedUser)
 	}
 }
+
+func TestPersistAndLoad_KeepsAppendOrder(t *testing.T) {
+	dataDir := t.TempDir()
+
+	db := New()
+	var ids []uuid.UUID
+	for i := range 10 {
+		e, _, err := db.AppendEvent(event.Event{
+			ID:      uuid.New(),
+			Type:    "user.new",
+			Time:    time.Date(2024, 1, 1, 0, 0, 10-i, 0, time.UTC),
+			Source:  "https://example.com",
+			Subject: "/users/1",
+			Data:    user{"ID": "1"},
+		})
+		if err != nil {
+			t.Fatal("Failed to append event:", err)
+		}
+		ids = append(ids, e.ID)
+	}
+
+	if err := db.PersistToJsonFile(dataDir); err != nil {
+		t.Fatal("Failed to persist to JSON file:", err)
+	}
+
+	loaded, err := LoadFromJSONFile(dataDir)
+	if err != nil {
+		t.Fatal("Failed to load database from JSON file:", err)
+	}
+
+	records := loaded.ReadFrom(0, 0)
+	if len(records) != len(ids) {
+		t.Fatalf("Expected %d records, got %d", len(ids), len(records))
+	}
+	for i, r := range records {
+		if r.Event.ID != ids[i] || r.Position != i+1 {
+			t.Fatalf("Expected event %s at position %d, got %s at %d", ids[i], i+1, r.Event.ID, r.Position)
+		}
+	}
+}
+
+func TestPersistAndLoad_KeepsPositionsAfterRetention(t *testing.T) {
+	dataDir := t.TempDir()
+
+	db := New()
+	for _, age := range []time.Duration{48 * time.Hour, time.Hour, 48 * time.Hour, time.Hour, 48 * time.Hour} {
+		appendAt(t, db, "user.update", "/users/1", age)
+	}
+	db.ApplyRetention(Retention{Default: RetentionPolicy{MaxAge: 24 * time.Hour}}, retentionNow)
+
+	if err := db.PersistToJsonFile(dataDir); err != nil {
+		t.Fatal("Failed to persist to JSON file:", err)
+	}
+	loaded, err := LoadFromJSONFile(dataDir)
+	if err != nil {
+		t.Fatal("Failed to load database from JSON file:", err)
+	}
+
+	expectPositions := func(name string, db *Database) {
+		t.Helper()
+		records := db.ReadFrom(0, 0)
+		if db.Head() != 5 || len(records) != 2 || records[0].Position != 2 || records[1].Position != 4 {
+			t.Errorf("%s: expected head 5 with events at positions 2 and 4, got head %d and %+v", name, db.Head(), records)
+		}
+	}
+	expectPositions("loaded", loaded)
+
+	if err := loaded.PersistToJsonFile(dataDir); err != nil {
+		t.Fatal("Failed to persist to JSON file:", err)
+	}
+	imported, err := LoadFromStore(NewMemoryStore(), dataDir)
+	if err != nil {
+		t.Fatal("LoadFromStore failed:", err)
+	}
+	expectPositions("imported", imported)
+
+	imported.AddEvent(event.Candidate{Type: "user.update", Source: "https://example.com", Subject: "/users/1", Data: user{"k": "v"}})
+	if records := imported.ReadFrom(5, 0); len(records) != 1 || records[0].Position != 6 {
+		t.Errorf("expected the next event at position 6, got %+v", records)
+	}
+}
+
+func TestPersistAndLoad_Snapshots(t *testing.T) {
+	dataDir := t.TempDir()
+
+	db := New()
+	db.AddEvent(event.Candidate{Type: "user.new", Source: "https://example.com", Subject: "/users/1", Data: user{"ID": "1"}})
+	if _, err := db.SaveSnapshot(Snapshot{Subject: "/users/1", Version: 1, ReducerVersion: "v1", State: json.RawMessage(`{"name":"John"}`)}); err != nil {
+		t.Fatal("Failed to save snapshot:", err)
+	}
+
+	if err := db.PersistToJsonFile(dataDir); err != nil {
+		t.Fatal("Failed to persist to JSON file:", err)
+	}
+
+	loaded, err := LoadFromJSONFile(dataDir)
+	if err != nil {
+		t.Fatal("Failed to load database from JSON file:", err)
+	}
+
+	s := loaded.GetSnapshot("/users/1")
+	if s == nil || s.Version != 1 || s.ReducerVersion != "v1" || string(s.State) != `{"name":"John"}` {
+		t.Errorf("Expected snapshot to be loaded, got %+v", s)
+	}
+}
+
+func TestLoadFromStore_ImportsDatabaseJSON(t *testing.T) {
+	dir := t.TempDir()
+	source := New()
+	added, _ := source.AddEvent(event.Candidate{Type: "user.new", Source: "https://example.com", Subject: "/users/1", Data: user{"ID": "1"}})
+	source.SaveSnapshot(Snapshot{Subject: "/users/1", Version: 1, ReducerVersion: "v1", State: json.RawMessage(`{}`)})
+	if err := source.PersistToJsonFile(dir); err != nil {
+		t.Fatalf("PersistToJsonFile failed: %v", err)
+	}
+
+	db, err := LoadFromStore(NewMemoryStore(), dir)
+	if err != nil {
+		t.Fatalf("LoadFromStore failed: %v", err)
+	}
+
+	if db.GetEvent(added.Source, added.ID) == nil || db.GetSnapshot("/users/1") == nil {
+		t.Error("expected events and snapshots to be imported")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "database.json.imported")); err != nil {
+		t.Errorf("expected database.json to be renamed: %v", err)
+	}
+
+	// A store with events is not imported into again
+	if err := db.Persist(dir); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+	store := NewMemoryStore()
+	store.Append(event.Event{ID: uuid.New(), Type: "user.new", Source: "https://example.com", Subject: "/users/2", Time: time.Now(), Data: user{}})
+	db, err = LoadFromStore(store, dir)
+	if err != nil {
+		t.Fatalf("LoadFromStore failed: %v", err)
+	}
+	if db.Count() != 1 || db.GetEvent(added.Source, added.ID) != nil {
+		t.Error("expected a store with events to be used as it is")
+	}
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+// ErrQuotaExceeded is returned for appends that would exceed the quota of the database.
+var ErrQuotaExceeded = errors.New("event quota exceeded")
+
+// Quota limits the stored events of a database. Zero values disable a limit.
+// MaxBytes limits the total size of the JSON-encoded events as they are stored.
+type Quota struct {
+	MaxEvents int `json:"maxEvents,omitempty"`
+	MaxBytes  int `json:"maxBytes,omitempty"`
+}
+
+// SetQuota sets the quota for appends. Events stored before are kept even if they exceed the quota.
+func (db *Database) SetQuota(q Quota) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.quota = q
+	return db.rebuildIndexes()
+}
+
+// Quota returns the quota of the database.
+func (db *Database) Quota() Quota {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.quota
+}
+
+// checkQuota returns the size of the stored event, or ErrQuotaExceeded if the event does not fit into the quota.
+// The size is only computed if the quota limits the bytes. The caller must hold the write lock.
+func (db *Database) checkQuota(e event.Event) (int, error) {
+	if db.quota.MaxEvents > 0 {
+		count, err := db.events.Count()
+		if err != nil {
+			return 0, err
+		}
+		if count >= db.quota.MaxEvents {
+			return 0, ErrQuotaExceeded
+		}
+	}
+
+	if db.quota.MaxBytes == 0 {
+		return 0, nil
+	}
+
+	size := eventSize(e)
+	if db.size+size > db.quota.MaxBytes {
+		return 0, ErrQuotaExceeded
+	}
+
+	return size, nil
+}
+
+// eventSize returns the size of the JSON-encoded event.
+func eventSize(e event.Event) int {
+	b, _ := json.Marshal(e)
+	return len(b)
+}
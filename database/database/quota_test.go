@@ -0,0 +1,54 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+func TestQuota_MaxEvents(t *testing.T) {
+	db := New()
+	if err := db.SetQuota(Quota{MaxEvents: 2}); err != nil {
+		t.Fatalf("SetQuota failed: %v", err)
+	}
+
+	var added *event.Event
+	for i := range 2 {
+		e, err := db.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: user{"n": i}})
+		if err != nil {
+			t.Fatalf("AddEvent failed: %v", err)
+		}
+		added = e
+	}
+
+	if _, err := db.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: user{}}); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded, got %v", err)
+	}
+	if _, duplicate, err := db.AppendEvent(*added); err != nil || !duplicate {
+		t.Errorf("expected retries of stored events to succeed, got duplicate=%v and error %v", duplicate, err)
+	}
+	if db.Count() != 2 {
+		t.Errorf("expected 2 events, got %d", db.Count())
+	}
+}
+
+func TestQuota_MaxBytes(t *testing.T) {
+	db := New()
+	e, _ := db.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: user{}})
+	size := eventSize(*e)
+
+	// The existing event is counted when the quota is set
+	if err := db.SetQuota(Quota{MaxBytes: 2*size + size/2}); err != nil {
+		t.Fatalf("SetQuota failed: %v", err)
+	}
+	if _, err := db.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: user{}}); err != nil {
+		t.Fatalf("AddEvent failed: %v", err)
+	}
+	if _, err := db.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: user{}}); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded, got %v", err)
+	}
+	if db.Quota().MaxBytes != 2*size+size/2 {
+		t.Errorf("unexpected quota %+v", db.Quota())
+	}
+}
@@ -0,0 +1,264 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nicograef/cloudevents/event"
+)
+
+// RetentionPolicy limits how many events are kept. Zero values disable a limit.
+type RetentionPolicy struct {
+	// MaxAge removes events whose time is older than the given duration.
+	MaxAge time.Duration `json:"maxAge,omitempty"`
+	// MaxPerSubject keeps only the newest events of each subject.
+	MaxPerSubject int `json:"maxPerSubject,omitempty"`
+	// MaxBytes removes the oldest events once the JSON size of the covered events exceeds the limit.
+	MaxBytes int `json:"maxBytes,omitempty"`
+}
+
+// Retention holds the default retention policy and the policies of event types that override it.
+// MaxAge and MaxPerSubject of the default policy apply to all event types without an own policy.
+// MaxBytes of the default policy limits the size of all events, MaxBytes of a type policy the size of its events.
+type Retention struct {
+	Default RetentionPolicy            `json:"default"`
+	Types   map[string]RetentionPolicy `json:"types,omitempty"`
+}
+
+// Reasons for the removal of an event by the retention.
+const (
+	ReasonMaxAge        = "max-age"
+	ReasonMaxPerSubject = "max-per-subject"
+	ReasonMaxBytes      = "max-bytes"
+)
+
+// ExpiredEvent describes an event that is removed by the retention.
+type ExpiredEvent struct {
+	ID      uuid.UUID `json:"id"`
+	Source  string    `json:"source"`
+	Type    string    `json:"type"`
+	Subject string    `json:"subject"`
+	Time    time.Time `json:"time"`
+	Reason  string    `json:"reason"`
+}
+
+// ParseRetentionPolicy parses a policy of the form maxAge:720h;maxPerSubject:100;maxBytes:1048576.
+// All parts are optional; an empty string disables all limits.
+func ParseRetentionPolicy(s string) (RetentionPolicy, error) {
+	policy := RetentionPolicy{}
+
+	for part := range strings.SplitSeq(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(part, ":")
+		if !found {
+			return RetentionPolicy{}, fmt.Errorf("invalid retention limit %q: expected key:value", part)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		var err error
+		switch key {
+		case "maxAge":
+			policy.MaxAge, err = time.ParseDuration(value)
+		case "maxPerSubject":
+			policy.MaxPerSubject, err = strconv.Atoi(value)
+		case "maxBytes":
+			policy.MaxBytes, err = strconv.Atoi(value)
+		default:
+			return RetentionPolicy{}, fmt.Errorf("unknown retention limit %q", key)
+		}
+		if err != nil {
+			return RetentionPolicy{}, fmt.Errorf("invalid retention limit %s: %w", key, err)
+		}
+		if policy.MaxAge < 0 || policy.MaxPerSubject < 0 || policy.MaxBytes < 0 {
+			return RetentionPolicy{}, fmt.Errorf("invalid retention limit %s: must not be negative", key)
+		}
+	}
+
+	return policy, nil
+}
+
+// Enabled reports whether any limit of the retention is set.
+func (r Retention) Enabled() bool {
+	if r.Default != (RetentionPolicy{}) {
+		return true
+	}
+	for _, policy := range r.Types {
+		if policy != (RetentionPolicy{}) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// policyFor returns the policy of the event type and the key under which events share limits.
+func (r Retention) policyFor(eventType string) (RetentionPolicy, string) {
+	if policy, exists := r.Types[eventType]; exists {
+		return policy, eventType
+	}
+
+	return r.Default, ""
+}
+
+// ExpiredEvents returns the events that the retention would remove at the given time, without removing them.
+func (db *Database) ExpiredEvents(r Retention, now time.Time) []ExpiredEvent {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.expiredEvents(r, now)
+}
+
+// ApplyRetention removes the events expired by the retention at the given time from the events and all indexes.
+// Positions in the log are kept, so removed events are skipped by ReadFrom.
+// Snapshots of affected subjects are moved to the new version of their subject.
+func (db *Database) ApplyRetention(r Retention, now time.Time) []ExpiredEvent {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	expired := db.expiredEvents(r, now)
+	if len(expired) == 0 {
+		return expired
+	}
+
+	removed := make(map[EventKey]bool, len(expired))
+	for _, e := range expired {
+		removed[EventKey{Source: e.Source, ID: e.ID}] = true
+	}
+
+	// A snapshot covers the first Version events of its subject, so it moves back by the removed events among them.
+	for subject, s := range db.Snapshots {
+		removedBefore, version := 0, 0
+		err := db.events.ScanSubject(subject, 0, func(e event.Event) bool {
+			if version++; version > s.Version {
+				return false
+			}
+			if removed[KeyOf(e)] {
+				removedBefore++
+			}
+			return true
+		})
+		if err != nil {
+			log.Printf("ERROR Cannot read the events of %s: %v", subject, err)
+		}
+
+		s.Version -= removedBefore
+		if s.Version < 1 {
+			delete(db.Snapshots, subject)
+			continue
+		}
+		db.Snapshots[subject] = s
+	}
+
+	keys := make([]EventKey, 0, len(removed))
+	for key := range removed {
+		keys = append(keys, key)
+	}
+	if err := db.events.Delete(keys); err != nil {
+		log.Printf("ERROR Cannot remove expired events: %v", err)
+	}
+	if err := db.rebuildIndexes(); err != nil {
+		log.Printf("ERROR Cannot rebuild the indexes: %v", err)
+	}
+
+	return expired
+}
+
+// expiredEvents applies the limits in the order max age, max per subject and max bytes.
+// The caller must hold the read lock.
+func (db *Database) expiredEvents(r Retention, now time.Time) []ExpiredEvent {
+	expired := []ExpiredEvent{}
+	removed := make(map[EventKey]bool)
+	expire := func(e event.Event, reason string) {
+		removed[KeyOf(e)] = true
+		expired = append(expired, ExpiredEvent{ID: e.ID, Source: e.Source, Type: e.Type, Subject: e.Subject, Time: e.Time, Reason: reason})
+	}
+
+	// Oldest events first
+	events := db.scan(Filter{})
+
+	for _, e := range events {
+		if policy, _ := r.policyFor(e.Type); policy.MaxAge > 0 && now.Sub(e.Time) > policy.MaxAge {
+			expire(e, ReasonMaxAge)
+		}
+	}
+
+	subjects, err := db.events.Subjects()
+	if err != nil {
+		log.Printf("ERROR Cannot read the subjects: %v", err)
+	}
+	for _, subject := range subjects {
+		subjectEvents := db.scan(Filter{Subject: subject})
+		kept := make(map[string]int)
+		for i := len(subjectEvents) - 1; i >= 0; i-- {
+			e := subjectEvents[i]
+			if removed[KeyOf(e)] {
+				continue
+			}
+
+			policy, key := r.policyFor(e.Type)
+			if policy.MaxPerSubject == 0 {
+				continue
+			}
+			if kept[key] >= policy.MaxPerSubject {
+				expire(e, ReasonMaxPerSubject)
+				continue
+			}
+			kept[key]++
+		}
+	}
+
+	if r.Default.MaxBytes == 0 && !hasMaxBytes(r.Types) {
+		return expired
+	}
+
+	sizes := make(map[EventKey]int, len(events))
+	total := 0
+	typeTotals := make(map[string]int)
+	for _, e := range events {
+		if removed[KeyOf(e)] {
+			continue
+		}
+		b, _ := json.Marshal(e)
+		sizes[KeyOf(e)] = len(b)
+		total += len(b)
+		typeTotals[e.Type] += len(b)
+	}
+
+	for _, e := range events {
+		if removed[KeyOf(e)] {
+			continue
+		}
+
+		policy, exists := r.Types[e.Type]
+		typeOver := exists && policy.MaxBytes > 0 && typeTotals[e.Type] > policy.MaxBytes
+		totalOver := r.Default.MaxBytes > 0 && total > r.Default.MaxBytes
+		if !typeOver && !totalOver {
+			continue
+		}
+
+		expire(e, ReasonMaxBytes)
+		total -= sizes[KeyOf(e)]
+		typeTotals[e.Type] -= sizes[KeyOf(e)]
+	}
+
+	return expired
+}
+
+func hasMaxBytes(types map[string]RetentionPolicy) bool {
+	for _, policy := range types {
+		if policy.MaxBytes > 0 {
+			return true
+		}
+	}
+
+	return false
+}
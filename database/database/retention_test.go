@@ -0,0 +1,155 @@
+package database
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nicograef/cloudevents/event"
+)
+
+var retentionNow = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+func appendAt(t *testing.T, db *Database, eventType, subject string, age time.Duration) event.Event {
+	t.Helper()
+	e, _, err := db.AppendEvent(event.Event{ID: uuid.New(), Type: eventType, Time: retentionNow.Add(-age), Source: "https://example.com", Subject: subject, Data: user{"k": "v"}})
+	if err != nil {
+		t.Fatalf("AppendEvent failed: %v", err)
+	}
+	return *e
+}
+
+func reasons(expired []ExpiredEvent) map[uuid.UUID]string {
+	result := make(map[uuid.UUID]string)
+	for _, e := range expired {
+		result[e.ID] = e.Reason
+	}
+	return result
+}
+
+func TestParseRetentionPolicy(t *testing.T) {
+	policy, err := ParseRetentionPolicy("maxAge:720h; maxPerSubject:100;maxBytes:1048576")
+	if err != nil {
+		t.Fatalf("ParseRetentionPolicy failed: %v", err)
+	}
+	if policy.MaxAge != 720*time.Hour || policy.MaxPerSubject != 100 || policy.MaxBytes != 1048576 {
+		t.Errorf("unexpected policy %+v", policy)
+	}
+
+	if policy, err := ParseRetentionPolicy(""); err != nil || policy != (RetentionPolicy{}) {
+		t.Errorf("expected empty policy, got %+v (%v)", policy, err)
+	}
+
+	for _, s := range []string{"maxAge", "maxAge:forever", "maxCount:1", "maxPerSubject:-1"} {
+		if _, err := ParseRetentionPolicy(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestExpiredEvents_MaxAge(t *testing.T) {
+	db := New()
+	old := appendAt(t, db, "user.login", "/users/1", 48*time.Hour)
+	appendAt(t, db, "user.login", "/users/1", time.Hour)
+	oldAudit := appendAt(t, db, "audit.entry", "/users/1", 48*time.Hour)
+
+	r := Retention{
+		Default: RetentionPolicy{MaxAge: 24 * time.Hour},
+		Types:   map[string]RetentionPolicy{"audit.entry": {}},
+	}
+
+	got := reasons(db.ExpiredEvents(r, retentionNow))
+	if len(got) != 1 || got[old.ID] != ReasonMaxAge {
+		t.Errorf("expected only the old login to expire, got %v", got)
+	}
+	if _, expired := got[oldAudit.ID]; expired {
+		t.Error("expected the type policy to override the default policy")
+	}
+	if db.Count() != 3 {
+		t.Error("expected dry run not to remove events")
+	}
+}
+
+func TestExpiredEvents_MaxPerSubject(t *testing.T) {
+	db := New()
+	oldest := appendAt(t, db, "user.update", "/users/1", 3*time.Hour)
+	appendAt(t, db, "user.update", "/users/1", 2*time.Hour)
+	appendAt(t, db, "user.update", "/users/1", time.Hour)
+	appendAt(t, db, "user.update", "/users/2", 3*time.Hour)
+
+	got := reasons(db.ExpiredEvents(Retention{Default: RetentionPolicy{MaxPerSubject: 2}}, retentionNow))
+	if len(got) != 1 || got[oldest.ID] != ReasonMaxPerSubject {
+		t.Errorf("expected only the oldest event of /users/1 to expire, got %v", got)
+	}
+}
+
+func TestExpiredEvents_MaxBytes(t *testing.T) {
+	db := New()
+	first := appendAt(t, db, "user.update", "/users/1", 3*time.Hour)
+	second := appendAt(t, db, "user.update", "/users/2", 2*time.Hour)
+	appendAt(t, db, "user.update", "/users/3", time.Hour)
+	appendAt(t, db, "order.placed", "/orders/1", 4*time.Hour)
+
+	b, _ := json.Marshal(first)
+	size := len(b)
+
+	// The type limit leaves room for one event of its type
+	r := Retention{Types: map[string]RetentionPolicy{"user.update": {MaxBytes: size + 10}}}
+	got := reasons(db.ExpiredEvents(r, retentionNow))
+	if len(got) != 2 || got[first.ID] != ReasonMaxBytes || got[second.ID] != ReasonMaxBytes {
+		t.Errorf("expected the two oldest user updates to expire, got %v", got)
+	}
+
+	// The default limit covers all events and removes the oldest first
+	got = reasons(db.ExpiredEvents(Retention{Default: RetentionPolicy{MaxBytes: 3*size + 10}}, retentionNow))
+	if len(got) != 1 {
+		t.Errorf("expected one event to expire, got %v", got)
+	}
+	if _, expired := got[first.ID]; expired {
+		t.Error("expected the oldest event overall to expire instead of the oldest user update")
+	}
+}
+
+func TestApplyRetention(t *testing.T) {
+	db := New()
+	old := appendAt(t, db, "user.update", "/users/1", 48*time.Hour)
+	recent := appendAt(t, db, "user.update", "/users/1", time.Hour)
+	db.CreateDataIndex("user.update", "data.k")
+	db.SaveSnapshot(Snapshot{Subject: "/users/1", Version: 2, ReducerVersion: "v1", State: json.RawMessage(`{}`)})
+
+	expired := db.ApplyRetention(Retention{Default: RetentionPolicy{MaxAge: 24 * time.Hour}}, retentionNow)
+	if len(expired) != 1 || expired[0].ID != old.ID {
+		t.Fatalf("expected the old event to be removed, got %v", expired)
+	}
+
+	if db.GetEvent(old.Source, old.ID) != nil {
+		t.Error("expected the old event to be removed from the events")
+	}
+	if len(db.GetEventsByType("user.update")) != 1 || len(db.GetEventsBySubject("/users/1")) != 1 {
+		t.Error("expected the old event to be removed from the indexes")
+	}
+	if got := db.GetEventsInRange(TimeRange{}); len(got) != 1 || got[0].ID != recent.ID {
+		t.Errorf("expected the old event to be removed from the time index, got %v", got)
+	}
+
+	// Positions of the remaining events are kept
+	if records := db.ReadFrom(0, 0); len(records) != 1 || records[0].Position != 2 {
+		t.Errorf("expected the remaining event at position 2, got %+v", records)
+	}
+
+	// The snapshot now covers the single remaining event
+	aggregate := db.LoadAggregate("/users/1", "v1")
+	if aggregate.Snapshot == nil || aggregate.Snapshot.Version != 1 || len(aggregate.Events) != 0 {
+		t.Errorf("expected snapshot to move to version 1, got %+v", aggregate)
+	}
+}
+
+func TestRetentionEnabled(t *testing.T) {
+	if (Retention{}).Enabled() {
+		t.Error("expected empty retention to be disabled")
+	}
+	if !(Retention{Types: map[string]RetentionPolicy{"a.b.c": {MaxPerSubject: 1}}}).Enabled() {
+		t.Error("expected type policy to enable the retention")
+	}
+}
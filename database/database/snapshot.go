@@ -0,0 +1,123 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+// Snapshot is the folded state of an aggregate (all events of a subject) up to a version.
+// The version is the number of events of the subject that are included in the state.
+// The state is opaque to the database; it is only valid for the reducer version that produced it.
+type Snapshot struct {
+	Subject        string          `json:"subject"`
+	Version        int             `json:"version"`
+	ReducerVersion string          `json:"reducerVersion"`
+	State          json.RawMessage `json:"state"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
+
+// Aggregate is the latest valid snapshot of a subject plus all events appended after it.
+// Without a valid snapshot, Events holds all events of the subject.
+type Aggregate struct {
+	Subject  string        `json:"subject"`
+	Version  int           `json:"version"`
+	Snapshot *Snapshot     `json:"snapshot,omitempty"`
+	Events   []event.Event `json:"events"`
+}
+
+// SnapshotPolicy decides when a new snapshot should be taken.
+// Every is the number of events after the latest snapshot that make a new snapshot due; 0 disables snapshots.
+type SnapshotPolicy struct {
+	Every int
+}
+
+// Due reports whether a new snapshot should be taken for the aggregate.
+func (p SnapshotPolicy) Due(a Aggregate) bool {
+	return p.Every > 0 && len(a.Events) >= p.Every
+}
+
+// SaveSnapshot stores the snapshot as the latest snapshot of its subject.
+// A snapshot older than the stored one is ignored unless it was produced by a different reducer version,
+// in which case it replaces the stored snapshot. An error is returned if the version exceeds the number of
+// events of the subject.
+func (db *Database) SaveSnapshot(s Snapshot) (*Snapshot, error) {
+	if s.Subject == "" {
+		return nil, errors.New("snapshot subject is required")
+	}
+	if s.Version < 1 {
+		return nil, errors.New("snapshot version must be at least 1")
+	}
+	if len(s.State) == 0 || !json.Valid(s.State) {
+		return nil, errors.New("snapshot state must be valid JSON")
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	count, err := db.events.CountSubject(s.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if s.Version > count {
+		return nil, errors.New("snapshot version is ahead of the events of the subject")
+	}
+
+	if existing, exists := db.Snapshots[s.Subject]; exists && existing.ReducerVersion == s.ReducerVersion && existing.Version >= s.Version {
+		return &existing, nil
+	}
+
+	s.CreatedAt = time.Now().UTC()
+	db.Snapshots[s.Subject] = s
+
+	return &s, nil
+}
+
+// GetSnapshot returns the latest snapshot of the subject, or nil if there is none.
+func (db *Database) GetSnapshot(subject string) *Snapshot {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	s, exists := db.Snapshots[subject]
+	if !exists {
+		return nil
+	}
+
+	return &s
+}
+
+// LoadAggregate returns the latest snapshot of the subject plus the events appended after it, in append order.
+// A snapshot taken with another reducer version is invalid and removed; all events are returned instead.
+func (db *Database) LoadAggregate(subject, reducerVersion string) Aggregate {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	count, err := db.events.CountSubject(subject)
+	if err != nil {
+		log.Printf("ERROR Cannot count the events of %s: %v", subject, err)
+	}
+	aggregate := Aggregate{Subject: subject, Version: count, Events: []event.Event{}}
+
+	from := 0
+	if s, exists := db.Snapshots[subject]; exists {
+		if s.ReducerVersion == reducerVersion {
+			aggregate.Snapshot = &s
+			from = min(s.Version, count)
+		} else {
+			delete(db.Snapshots, subject)
+		}
+	}
+
+	err = db.events.ScanSubject(subject, from, func(e event.Event) bool {
+		aggregate.Events = append(aggregate.Events, db.open(e))
+		return true
+	})
+	if err != nil {
+		log.Printf("ERROR Cannot read the events of %s: %v", subject, err)
+	}
+
+	return aggregate
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+func addUserEvents(t *testing.T, db *Database, subject string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if _, err := db.AddEvent(event.Candidate{Type: "user.update", Source: "https://example.com", Subject: subject, Data: user{"n": i}}); err != nil {
+			t.Fatalf("AddEvent failed: %v", err)
+		}
+	}
+}
+
+func TestLoadAggregate_WithoutSnapshot(t *testing.T) {
+	db := New()
+	addUserEvents(t, db, "/users/1", 3)
+	addUserEvents(t, db, "/users/2", 1)
+
+	aggregate := db.LoadAggregate("/users/1", "v1")
+
+	if aggregate.Snapshot != nil {
+		t.Errorf("expected no snapshot, got %+v", aggregate.Snapshot)
+	}
+	if aggregate.Version != 3 || len(aggregate.Events) != 3 {
+		t.Errorf("expected version 3 with 3 events, got version %d with %d events", aggregate.Version, len(aggregate.Events))
+	}
+}
+
+func TestLoadAggregate_WithSnapshot(t *testing.T) {
+	db := New()
+	addUserEvents(t, db, "/users/1", 5)
+
+	if _, err := db.SaveSnapshot(Snapshot{Subject: "/users/1", Version: 3, ReducerVersion: "v1", State: json.RawMessage(`{"n":2}`)}); err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+
+	aggregate := db.LoadAggregate("/users/1", "v1")
+
+	if aggregate.Snapshot == nil || aggregate.Snapshot.Version != 3 {
+		t.Fatalf("expected snapshot at version 3, got %+v", aggregate.Snapshot)
+	}
+	if aggregate.Version != 5 || len(aggregate.Events) != 2 {
+		t.Fatalf("expected version 5 with 2 events, got version %d with %d events", aggregate.Version, len(aggregate.Events))
+	}
+	if aggregate.Events[0].Data.(user)["n"] != 3 {
+		t.Errorf("expected the events after the snapshot, got %+v", aggregate.Events[0])
+	}
+}
+
+func TestLoadAggregate_ReducerVersionChanged(t *testing.T) {
+	db := New()
+	addUserEvents(t, db, "/users/1", 2)
+	db.SaveSnapshot(Snapshot{Subject: "/users/1", Version: 2, ReducerVersion: "v1", State: json.RawMessage(`{}`)})
+
+	aggregate := db.LoadAggregate("/users/1", "v2")
+
+	if aggregate.Snapshot != nil || len(aggregate.Events) != 2 {
+		t.Errorf("expected all events without snapshot, got %+v", aggregate)
+	}
+	if db.GetSnapshot("/users/1") != nil {
+		t.Error("expected outdated snapshot to be removed")
+	}
+}
+
+func TestSaveSnapshot_KeepsNewest(t *testing.T) {
+	db := New()
+	addUserEvents(t, db, "/users/1", 4)
+
+	db.SaveSnapshot(Snapshot{Subject: "/users/1", Version: 4, ReducerVersion: "v1", State: json.RawMessage(`4`)})
+	stored, err := db.SaveSnapshot(Snapshot{Subject: "/users/1", Version: 2, ReducerVersion: "v1", State: json.RawMessage(`2`)})
+	if err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+	if stored.Version != 4 {
+		t.Errorf("expected older snapshot to be ignored, got version %d", stored.Version)
+	}
+
+	// A new reducer version replaces the snapshot even if it is older
+	stored, _ = db.SaveSnapshot(Snapshot{Subject: "/users/1", Version: 2, ReducerVersion: "v2", State: json.RawMessage(`2`)})
+	if stored.Version != 2 || stored.ReducerVersion != "v2" {
+		t.Errorf("expected snapshot of new reducer version, got %+v", stored)
+	}
+}
+
+func TestSaveSnapshot_Errors(t *testing.T) {
+	db := New()
+	addUserEvents(t, db, "/users/1", 1)
+
+	tests := []struct {
+		name     string
+		snapshot Snapshot
+	}{
+		{"missing subject", Snapshot{Version: 1, State: json.RawMessage(`{}`)}},
+		{"version zero", Snapshot{Subject: "/users/1", State: json.RawMessage(`{}`)}},
+		{"version ahead", Snapshot{Subject: "/users/1", Version: 2, State: json.RawMessage(`{}`)}},
+		{"missing state", Snapshot{Subject: "/users/1", Version: 1}},
+		{"invalid state", Snapshot{Subject: "/users/1", Version: 1, State: json.RawMessage(`{`)}},
+	}
+
+	for _, tt := range tests {
+		if _, err := db.SaveSnapshot(tt.snapshot); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestSnapshotPolicy(t *testing.T) {
+	aggregate := Aggregate{Events: make([]event.Event, 3)}
+
+	if !(SnapshotPolicy{Every: 3}).Due(aggregate) {
+		t.Error("expected snapshot to be due after 3 events")
+	}
+	if (SnapshotPolicy{Every: 4}).Due(aggregate) {
+		t.Error("expected no snapshot to be due before 4 events")
+	}
+	if (SnapshotPolicy{}).Due(aggregate) {
+		t.Error("expected disabled policy to never be due")
+	}
+}
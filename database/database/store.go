@@ -0,0 +1,231 @@
+package database
+
+import (
+	"github.com/google/uuid"
+	"github.com/nicograef/cloudevents/event"
+)
+
+// Store holds the events of a database in append order together with the indexes to query them by type,
+// subject and time. The database serializes writes and keeps derived state such as the subject trie,
+// data indexes and snapshots itself, so a store must only support concurrent reads.
+// Stored events are passed through as they are; payload encryption is handled by the database.
+type Store interface {
+	// Append stores the event at the next position. The key of the event must not be stored yet.
+	Append(e event.Event) error
+	// SkipTo moves the head forward to the position without storing events, so the positions after the
+	// current head stay empty, e.g. to load events whose predecessors were removed. A position at or
+	// before the head is ignored.
+	SkipTo(position int) error
+	// Get returns the event with the key and whether it is stored.
+	Get(key EventKey) (event.Event, bool, error)
+	// Scan calls fn for the events matching the filter in time order until fn returns false.
+	// Events with the same time are passed in append order.
+	Scan(f Filter, fn func(event.Event) bool) error
+	// ScanSubject calls fn for the events of the subject in append order, skipping the first skip events,
+	// until fn returns false.
+	ScanSubject(subject string, skip int, fn func(event.Event) bool) error
+	// ScanLog calls fn for the events appended after the position in append order until fn returns false.
+	ScanLog(position int, fn func(Record) bool) error
+	// CountSubject returns the number of stored events of the subject.
+	CountSubject(subject string) (int, error)
+	// Subjects returns the subjects of all stored events.
+	Subjects() ([]string, error)
+	// Head returns the position of the most recently appended event, or 0 if no event was appended.
+	Head() (int, error)
+	// Count returns the number of stored events.
+	Count() (int, error)
+	// Delete removes the events with the keys. Their positions are not reused.
+	Delete(keys []EventKey) error
+	// Reset removes all events and starts the positions at 0 again.
+	Reset() error
+	// Close releases the resources of the store.
+	Close() error
+}
+
+// Filter selects events of a store scan. Empty fields match all events.
+type Filter struct {
+	Type    string
+	Subject string
+	Range   TimeRange
+}
+
+// matches reports whether the event matches the filter.
+func (f Filter) matches(e event.Event) bool {
+	return (f.Type == "" || e.Type == f.Type) && (f.Subject == "" || e.Subject == f.Subject) && f.Range.contains(e.Time)
+}
+
+// durableStore is implemented by stores that write every append to disk, so the database does not
+// need to be persisted to database.json.
+type durableStore interface {
+	Store
+	// Sync flushes all writes to disk.
+	Sync() error
+}
+
+// EventKey identifies a stored event. Events are unique by source and ID, so different sources may use the same ID.
+type EventKey struct {
+	Source string
+	ID     uuid.UUID
+}
+
+// KeyOf returns the key of the event.
+func KeyOf(e event.Event) EventKey {
+	return EventKey{Source: e.Source, ID: e.ID}
+}
+
+// memoryStore keeps all events and indexes in memory. It is persisted with PersistToJsonFile.
+type memoryStore struct {
+	events       map[EventKey]event.Event
+	subjectIndex map[string][]EventKey
+	// log holds the event keys in append order. The position of an event is its index in the log plus one.
+	// Positions of deleted or skipped events hold the zero key.
+	log []EventKey
+
+	// The time indexes hold the event keys sorted by event time for range queries.
+	timeIndex        timeIndex
+	typeTimeIndex    map[string]timeIndex
+	subjectTimeIndex map[string]timeIndex
+}
+
+// NewMemoryStore creates an empty store that keeps all events in memory.
+func NewMemoryStore() Store {
+	s := &memoryStore{events: make(map[EventKey]event.Event)}
+	s.rebuild()
+
+	return s
+}
+
+func (s *memoryStore) Append(e event.Event) error {
+	key := KeyOf(e)
+	s.events[key] = e
+	s.log = append(s.log, key)
+	s.index(e)
+
+	return nil
+}
+
+func (s *memoryStore) SkipTo(position int) error {
+	for len(s.log) < position {
+		s.log = append(s.log, EventKey{})
+	}
+
+	return nil
+}
+
+func (s *memoryStore) Get(key EventKey) (event.Event, bool, error) {
+	e, exists := s.events[key]
+	return e, exists, nil
+}
+
+func (s *memoryStore) Scan(f Filter, fn func(event.Event) bool) error {
+	idx := s.timeIndex
+	if f.Subject != "" {
+		idx = s.subjectTimeIndex[f.Subject]
+	} else if f.Type != "" {
+		idx = s.typeTimeIndex[f.Type]
+	}
+
+	for _, key := range idx.between(f.Range) {
+		if e, exists := s.events[key]; exists && f.matches(e) && !fn(e) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (s *memoryStore) ScanSubject(subject string, skip int, fn func(event.Event) bool) error {
+	keys := s.subjectIndex[subject]
+	for _, key := range keys[min(max(skip, 0), len(keys)):] {
+		if !fn(s.events[key]) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (s *memoryStore) ScanLog(position int, fn func(Record) bool) error {
+	for i := max(position, 0); i < len(s.log); i++ {
+		if e, exists := s.events[s.log[i]]; exists && !fn(Record{Position: i + 1, Event: e}) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (s *memoryStore) CountSubject(subject string) (int, error) {
+	return len(s.subjectIndex[subject]), nil
+}
+
+func (s *memoryStore) Subjects() ([]string, error) {
+	subjects := make([]string, 0, len(s.subjectIndex))
+	for subject := range s.subjectIndex {
+		subjects = append(subjects, subject)
+	}
+
+	return subjects, nil
+}
+
+func (s *memoryStore) Head() (int, error) {
+	return len(s.log), nil
+}
+
+func (s *memoryStore) Count() (int, error) {
+	return len(s.events), nil
+}
+
+func (s *memoryStore) Delete(keys []EventKey) error {
+	deleted := make(map[EventKey]bool, len(keys))
+	for _, key := range keys {
+		if _, exists := s.events[key]; exists {
+			deleted[key] = true
+			delete(s.events, key)
+		}
+	}
+
+	// The positions stay empty, so an event that is appended again is not found at its old position too
+	for i, key := range s.log {
+		if deleted[key] {
+			s.log[i] = EventKey{}
+		}
+	}
+	s.rebuild()
+
+	return nil
+}
+
+func (s *memoryStore) Reset() error {
+	s.events = make(map[EventKey]event.Event)
+	s.log = nil
+	s.rebuild()
+
+	return nil
+}
+
+func (s *memoryStore) Close() error {
+	return nil
+}
+
+// rebuild reconstructs the indexes from the events in the log.
+func (s *memoryStore) rebuild() {
+	s.subjectIndex = make(map[string][]EventKey)
+	s.timeIndex = nil
+	s.typeTimeIndex = make(map[string]timeIndex)
+	s.subjectTimeIndex = make(map[string]timeIndex)
+
+	for _, key := range s.log {
+		if e, exists := s.events[key]; exists {
+			s.index(e)
+		}
+	}
+}
+
+// index adds the event to the subject and time indexes.
+func (s *memoryStore) index(e event.Event) {
+	s.subjectIndex[e.Subject] = append(s.subjectIndex[e.Subject], KeyOf(e))
+	s.timeIndex = s.timeIndex.insert(e)
+	s.typeTimeIndex[e.Type] = s.typeTimeIndex[e.Type].insert(e)
+	s.subjectTimeIndex[e.Subject] = s.subjectTimeIndex[e.Subject].insert(e)
+}
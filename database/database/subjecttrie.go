@@ -0,0 +1,72 @@
+package database
+
+import (
+	"slices"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+// subjectTrie stores the known subjects by their segments so that subject patterns
+// can be resolved without comparing the pattern against every subject.
+type subjectTrie struct {
+	children map[string]*subjectTrie
+	// subjects holds the subjects that end at this node. Subjects that only differ
+	// in leading or trailing slashes share a node.
+	subjects []string
+}
+
+func newSubjectTrie() *subjectTrie {
+	return &subjectTrie{children: make(map[string]*subjectTrie)}
+}
+
+// insert adds the subject to the trie.
+func (t *subjectTrie) insert(subject string) {
+	node := t
+	for _, segment := range event.SubjectSegments(subject) {
+		child, exists := node.children[segment]
+		if !exists {
+			child = newSubjectTrie()
+			node.children[segment] = child
+		}
+		node = child
+	}
+	if !slices.Contains(node.subjects, subject) {
+		node.subjects = append(node.subjects, subject)
+	}
+}
+
+// match returns all known subjects that match the pattern.
+func (t *subjectTrie) match(pattern string) []string {
+	seen := make(map[*subjectTrie]bool)
+	var subjects []string
+	t.walk(event.SubjectSegments(pattern), seen, &subjects)
+
+	return subjects
+}
+
+func (t *subjectTrie) walk(pattern []string, seen map[*subjectTrie]bool, subjects *[]string) {
+	if len(pattern) == 0 {
+		if !seen[t] {
+			seen[t] = true
+			*subjects = append(*subjects, t.subjects...)
+		}
+		return
+	}
+
+	switch pattern[0] {
+	case event.SubjectRecursiveWildcard:
+		// "**" matches no segment here or one more segment below
+		t.walk(pattern[1:], seen, subjects)
+		for _, child := range t.children {
+			child.walk(pattern, seen, subjects)
+		}
+	case event.SubjectWildcard:
+		for _, child := range t.children {
+			child.walk(pattern[1:], seen, subjects)
+		}
+	default:
+		if child, exists := t.children[pattern[0]]; exists {
+			child.walk(pattern[1:], seen, subjects)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nicograef/cloudevents/event"
+)
+
+func TestSubjectTrie_Match(t *testing.T) {
+	trie := newSubjectTrie()
+	for _, subject := range []string{"/libraries/7", "/libraries/7/books/123", "/libraries/8/books/123", "/libraries/7/books/124", "/users/1"} {
+		trie.insert(subject)
+	}
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"/libraries/7/**", []string{"/libraries/7", "/libraries/7/books/123", "/libraries/7/books/124"}},
+		{"/libraries/*/books/123", []string{"/libraries/7/books/123", "/libraries/8/books/123"}},
+		{"/**/books/123", []string{"/libraries/7/books/123", "/libraries/8/books/123"}},
+		{"/users/1", []string{"/users/1"}},
+		{"/users/2", nil},
+	}
+
+	for _, tt := range tests {
+		got := trie.match(tt.pattern)
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.pattern, tt.want, got)
+		}
+	}
+}
+
+func TestGetEventsMatchingSubject(t *testing.T) {
+	db := New()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, subject := range []string{"/libraries/7/books/123", "/libraries/8/books/123", "/libraries/7", "/libraries/7/books/124"} {
+		db.AppendEvent(event.Event{ID: uuid.New(), Type: "book.added", Time: base.Add(time.Duration(i) * time.Minute), Source: "https://example.com", Subject: subject, Data: user{}})
+	}
+
+	events := db.GetEventsMatchingSubject("/libraries/7/**", TimeRange{})
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Time.Before(events[i-1].Time) {
+			t.Errorf("expected events sorted by time, got %v before %v", events[i-1].Time, events[i].Time)
+		}
+	}
+
+	events = db.GetEventsMatchingSubject("/libraries/*/books/123", TimeRange{From: base.Add(time.Minute)})
+	if len(events) != 1 || events[0].Subject != "/libraries/8/books/123" {
+		t.Errorf("expected only the event of library 8, got %+v", events)
+	}
+}
@@ -0,0 +1,72 @@
+package database
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+// TimeRange bounds a query by event time. Both bounds are inclusive; a zero bound leaves that side open.
+// A range with only To set returns the events as of that point in time.
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// contains reports whether the time lies within the range.
+func (r TimeRange) contains(t time.Time) bool {
+	return (r.From.IsZero() || !t.Before(r.From)) && (r.To.IsZero() || !t.After(r.To))
+}
+
+// timeIndex holds event keys sorted by event time. Events with the same time keep their append order.
+type timeIndex []timeIndexEntry
+
+type timeIndexEntry struct {
+	time time.Time
+	key  EventKey
+}
+
+// insert adds the event to the index. Events usually arrive in time order, so this is an append in most cases.
+func (idx timeIndex) insert(e event.Event) timeIndex {
+	entry := timeIndexEntry{time: e.Time, key: KeyOf(e)}
+
+	if len(idx) == 0 || !idx[len(idx)-1].time.After(e.Time) {
+		return append(idx, entry)
+	}
+
+	i := sort.Search(len(idx), func(i int) bool {
+		return idx[i].time.After(e.Time)
+	})
+
+	return slices.Insert(idx, i, entry)
+}
+
+// between returns the keys of the events within the range in time order using binary search.
+func (idx timeIndex) between(r TimeRange) []EventKey {
+	lo := 0
+	if !r.From.IsZero() {
+		lo = sort.Search(len(idx), func(i int) bool {
+			return !idx[i].time.Before(r.From)
+		})
+	}
+
+	hi := len(idx)
+	if !r.To.IsZero() {
+		hi = sort.Search(len(idx), func(i int) bool {
+			return idx[i].time.After(r.To)
+		})
+	}
+
+	if lo >= hi {
+		return nil
+	}
+
+	keys := make([]EventKey, 0, hi-lo)
+	for _, entry := range idx[lo:hi] {
+		keys = append(keys, entry.key)
+	}
+
+	return keys
+}
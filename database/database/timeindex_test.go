@@ -0,0 +1,125 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nicograef/cloudevents/event"
+)
+
+func at(minute int) time.Time {
+	return time.Date(2024, 1, 1, 0, minute, 0, 0, time.UTC)
+}
+
+// newRangeDatabase appends events out of time order for two subjects and types.
+func newRangeDatabase(t *testing.T) *Database {
+	t.Helper()
+
+	db := New()
+	for _, e := range []struct {
+		minute  int
+		typ     string
+		subject string
+	}{
+		{30, "order.placed", "/orders/42"},
+		{10, "order.placed", "/orders/1"},
+		{20, "order.paid", "/orders/42"},
+		{40, "order.shipped", "/orders/42"},
+		{20, "order.paid", "/orders/1"},
+	} {
+		if _, _, err := db.AppendEvent(event.Event{ID: uuid.New(), Type: e.typ, Time: at(e.minute), Source: "https://example.com", Subject: e.subject, Data: user{}}); err != nil {
+			t.Fatalf("AppendEvent failed: %v", err)
+		}
+	}
+
+	return db
+}
+
+func minutes(events []event.Event) []int {
+	result := make([]int, 0, len(events))
+	for _, e := range events {
+		result = append(result, e.Time.Minute())
+	}
+	return result
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGetEventsInRange(t *testing.T) {
+	db := newRangeDatabase(t)
+
+	tests := []struct {
+		name string
+		r    TimeRange
+		want []int
+	}{
+		{"unbounded", TimeRange{}, []int{10, 20, 20, 30, 40}},
+		{"inclusive bounds", TimeRange{From: at(20), To: at(30)}, []int{20, 20, 30}},
+		{"from only", TimeRange{From: at(25)}, []int{30, 40}},
+		{"as of", TimeRange{To: at(20)}, []int{10, 20, 20}},
+		{"empty", TimeRange{From: at(41)}, []int{}},
+		{"reversed", TimeRange{From: at(30), To: at(10)}, []int{}},
+	}
+
+	for _, tt := range tests {
+		if got := minutes(db.GetEventsInRange(tt.r)); !equalInts(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestGetEventsByTypeAndSubjectInRange(t *testing.T) {
+	db := newRangeDatabase(t)
+
+	if got := minutes(db.GetEventsBySubjectInRange("/orders/42", TimeRange{From: at(20), To: at(35)})); !equalInts(got, []int{20, 30}) {
+		t.Errorf("expected events of /orders/42 at minutes 20 and 30, got %v", got)
+	}
+	if got := minutes(db.GetEventsByTypeInRange("order.paid", TimeRange{To: at(20)})); !equalInts(got, []int{20, 20}) {
+		t.Errorf("expected two order.paid events, got %v", got)
+	}
+	if got := db.GetEventsByTypeInRange("unknown", TimeRange{}); got == nil || len(got) != 0 {
+		t.Errorf("expected empty slice for unknown type, got %v", got)
+	}
+}
+
+func TestTimeIndex_KeepsAppendOrderForEqualTimes(t *testing.T) {
+	db := New()
+	var ids []uuid.UUID
+	for range 3 {
+		e, _, _ := db.AppendEvent(event.Event{ID: uuid.New(), Type: "order.placed", Time: at(5), Source: "https://example.com", Subject: "/orders/1", Data: user{}})
+		ids = append(ids, e.ID)
+	}
+
+	for i, e := range db.GetEvents() {
+		if e.ID != ids[i] {
+			t.Fatalf("expected append order for equal times, got %s at %d", e.ID, i)
+		}
+	}
+}
+
+func TestTimeIndex_RebuiltOnLoad(t *testing.T) {
+	dataDir := t.TempDir()
+	if err := newRangeDatabase(t).PersistToJsonFile(dataDir); err != nil {
+		t.Fatalf("PersistToJsonFile failed: %v", err)
+	}
+
+	db, err := LoadFromJSONFile(dataDir)
+	if err != nil {
+		t.Fatalf("LoadFromJSONFile failed: %v", err)
+	}
+
+	if got := minutes(db.GetEventsBySubjectInRange("/orders/1", TimeRange{From: at(15)})); !equalInts(got, []int{20}) {
+		t.Errorf("expected rebuilt time index, got %v", got)
+	}
+}
@@ -0,0 +1,307 @@
+// Package namespace manages isolated databases of tenants that share one database service.
+package namespace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/nicograef/cloudevents/database/database"
+)
+
+// Default is the name of the namespace of the database in the data directory itself.
+// It always exists and is not managed by the registry.
+const Default = "default"
+
+var (
+	// ErrNotFound is returned for namespaces that do not exist.
+	ErrNotFound = errors.New("namespace not found")
+	// ErrExists is returned when creating a namespace that already exists.
+	ErrExists = errors.New("namespace already exists")
+)
+
+// namePattern restricts names to lowercase letters, digits and dashes, so that they are safe as directory names.
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// Settings configures a namespace.
+type Settings struct {
+	// Default retention policy in the format of RETENTION, e.g. maxAge:720h (keep all events if empty)
+	Retention string `json:"retention,omitempty"`
+	// Retention policies per event type, overriding the default policy
+	RetentionTypes map[string]string `json:"retentionTypes,omitempty"`
+	// Quota for appends
+	Quota database.Quota `json:"quota"`
+}
+
+// Namespace is a database with its own events, indexes, positions, retention and quota.
+type Namespace struct {
+	Name      string
+	Settings  Settings
+	Retention database.Retention
+	DB        *database.Database
+	// Dir is the data directory of the namespace.
+	Dir string
+}
+
+// OpenFunc opens the database in the data directory of a namespace.
+type OpenFunc func(dir string) (*database.Database, error)
+
+// Registry holds the namespaces besides the default namespace. Each namespace is stored in its own directory
+// below namespaces/ in the data directory; their settings are persisted in namespaces.json.
+type Registry struct {
+	dir  string
+	open OpenFunc
+
+	mu         sync.RWMutex
+	namespaces map[string]*Namespace
+}
+
+// NewRegistry opens the namespaces listed in namespaces.json in the data directory with the open function.
+func NewRegistry(dataDir string, open OpenFunc) (*Registry, error) {
+	r := &Registry{dir: filepath.Join(dataDir, "namespaces"), open: open, namespaces: make(map[string]*Namespace)}
+
+	settings, err := r.readSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	for name, s := range settings {
+		ns, err := r.openNamespace(name, s)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("cannot open namespace %s: %w", name, err)
+		}
+		r.namespaces[name] = ns
+	}
+
+	return r, nil
+}
+
+// Names returns the sorted names of the namespaces in the data directory without opening them.
+func Names(dataDir string) ([]string, error) {
+	r := &Registry{dir: filepath.Join(dataDir, "namespaces")}
+	settings, err := r.readSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// ValidateName returns an error if the name is not a valid namespace name.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return errors.New("namespace name must consist of up to 63 lowercase letters, digits and dashes")
+	}
+
+	return nil
+}
+
+// Get returns the namespace with the name.
+func (r *Registry) Get(name string) (*Namespace, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ns, exists := r.namespaces[name]
+	return ns, exists
+}
+
+// List returns the namespaces sorted by name.
+func (r *Registry) List() []*Namespace {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	namespaces := make([]*Namespace, 0, len(r.namespaces))
+	for _, ns := range r.namespaces {
+		namespaces = append(namespaces, ns)
+	}
+
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].Name < namespaces[j].Name
+	})
+
+	return namespaces
+}
+
+// Create creates an empty namespace with the settings.
+func (r *Registry) Create(name string, s Settings) (*Namespace, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	if name == Default {
+		return nil, ErrExists
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.namespaces[name]; exists {
+		return nil, ErrExists
+	}
+
+	// Left-over files of a deleted namespace must not reappear
+	if err := os.RemoveAll(filepath.Join(r.dir, name)); err != nil {
+		return nil, err
+	}
+
+	ns, err := r.openNamespace(name, s)
+	if err != nil {
+		return nil, err
+	}
+	r.namespaces[name] = ns
+
+	if err := r.persistSettings(); err != nil {
+		delete(r.namespaces, name)
+		ns.DB.Close()
+		return nil, err
+	}
+
+	return ns, nil
+}
+
+// Delete removes the namespace with all its events.
+func (r *Registry) Delete(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ns, exists := r.namespaces[name]
+	if !exists {
+		return ErrNotFound
+	}
+
+	delete(r.namespaces, name)
+	if err := r.persistSettings(); err != nil {
+		r.namespaces[name] = ns
+		return err
+	}
+
+	if err := ns.DB.Close(); err != nil {
+		log.Printf("WARN Cannot close the database of namespace %s: %v", name, err)
+	}
+
+	return os.RemoveAll(ns.Dir)
+}
+
+// Persist persists the databases of all namespaces.
+func (r *Registry) Persist() error {
+	var errs []error
+	for _, ns := range r.List() {
+		if err := ns.DB.Persist(ns.Dir); err != nil {
+			errs = append(errs, fmt.Errorf("namespace %s: %w", ns.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close closes the databases of all namespaces.
+func (r *Registry) Close() error {
+	var errs []error
+	for _, ns := range r.List() {
+		errs = append(errs, ns.DB.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// openNamespace opens the database of the namespace and applies the settings.
+func (r *Registry) openNamespace(name string, s Settings) (*Namespace, error) {
+	retention, err := ParseRetention(s)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(r.dir, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	db, err := r.open(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.SetQuota(s.Quota); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Namespace{Name: name, Settings: s, Retention: retention, DB: db, Dir: dir}, nil
+}
+
+// persistSettings writes the settings of all namespaces to namespaces.json. The caller must hold the lock.
+func (r *Registry) persistSettings() error {
+	settings := make(map[string]Settings, len(r.namespaces))
+	for name, ns := range r.namespaces {
+		settings[name] = ns.Settings
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return err
+	}
+
+	tmpPath := r.settingsPath() + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, r.settingsPath())
+}
+
+// readSettings reads the settings of the namespaces from namespaces.json. A missing file holds no namespaces.
+func (r *Registry) readSettings() (map[string]Settings, error) {
+	data, err := os.ReadFile(r.settingsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var settings map[string]Settings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("invalid namespaces.json: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (r *Registry) settingsPath() string {
+	return filepath.Join(r.dir, "namespaces.json")
+}
+
+// ParseRetention parses the retention policies of the settings.
+func ParseRetention(s Settings) (database.Retention, error) {
+	retention := database.Retention{Types: make(map[string]database.RetentionPolicy)}
+
+	policy, err := database.ParseRetentionPolicy(s.Retention)
+	if err != nil {
+		return database.Retention{}, fmt.Errorf("invalid retention: %w", err)
+	}
+	retention.Default = policy
+
+	for eventType, value := range s.RetentionTypes {
+		policy, err := database.ParseRetentionPolicy(value)
+		if err != nil {
+			return database.Retention{}, fmt.Errorf("invalid retention for %s: %w", eventType, err)
+		}
+		retention.Types[eventType] = policy
+	}
+
+	return retention, nil
+}
@@ -0,0 +1,154 @@
+package namespace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/event"
+)
+
+// openMemory opens the database.json in the directory like the memory storage of the service.
+func openMemory(dir string) (*database.Database, error) {
+	db, err := database.LoadFromJSONFile(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return database.New(), nil
+	}
+
+	return db, err
+}
+
+func newCandidate() event.Candidate {
+	return event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}}
+}
+
+func TestRegistry_CreateListDelete(t *testing.T) {
+	dataDir := t.TempDir()
+	r, err := NewRegistry(dataDir, openMemory)
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+
+	if _, err := r.Create("tenant-b", Settings{}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	a, err := r.Create("tenant-a", Settings{Retention: "maxAge:24h"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if !a.Retention.Enabled() || a.Dir != filepath.Join(dataDir, "namespaces", "tenant-a") {
+		t.Errorf("unexpected namespace %+v", a)
+	}
+
+	namespaces := r.List()
+	if len(namespaces) != 2 || namespaces[0].Name != "tenant-a" || namespaces[1].Name != "tenant-b" {
+		t.Fatalf("expected sorted namespaces, got %v", namespaces)
+	}
+
+	if err := r.Delete("tenant-a"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, exists := r.Get("tenant-a"); exists {
+		t.Error("expected deleted namespace to be gone")
+	}
+	if _, err := os.Stat(a.Dir); !os.IsNotExist(err) {
+		t.Error("expected the directory of the deleted namespace to be removed")
+	}
+	if err := r.Delete("tenant-a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRegistry_CreateInvalid(t *testing.T) {
+	r, err := NewRegistry(t.TempDir(), openMemory)
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+
+	for _, name := range []string{"", "Tenant", "-tenant", "a/b", "../etc"} {
+		if _, err := r.Create(name, Settings{}); err == nil {
+			t.Errorf("expected error for name %q", name)
+		}
+	}
+	if _, err := r.Create("tenant", Settings{Retention: "forever"}); err == nil {
+		t.Error("expected error for an invalid retention")
+	}
+	if _, err := r.Create(Default, Settings{}); !errors.Is(err, ErrExists) {
+		t.Errorf("expected ErrExists for the default namespace, got %v", err)
+	}
+	if _, err := r.Create("tenant", Settings{}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := r.Create("tenant", Settings{}); !errors.Is(err, ErrExists) {
+		t.Errorf("expected ErrExists, got %v", err)
+	}
+}
+
+func TestRegistry_Reopen(t *testing.T) {
+	dataDir := t.TempDir()
+	r, err := NewRegistry(dataDir, openMemory)
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+
+	ns, err := r.Create("tenant", Settings{Quota: database.Quota{MaxEvents: 5}})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := ns.DB.AddEvent(newCandidate()); err != nil {
+		t.Fatalf("AddEvent failed: %v", err)
+	}
+	if err := r.Persist(); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+	r.Close()
+
+	r, err = NewRegistry(dataDir, openMemory)
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	ns, exists := r.Get("tenant")
+	if !exists {
+		t.Fatal("expected namespace to be reopened")
+	}
+	if ns.DB.Count() != 1 || ns.DB.Quota().MaxEvents != 5 {
+		t.Errorf("expected 1 event and the quota, got %d events and %+v", ns.DB.Count(), ns.DB.Quota())
+	}
+}
+
+func TestNames(t *testing.T) {
+	dataDir := t.TempDir()
+	if names, err := Names(dataDir); err != nil || len(names) != 0 {
+		t.Errorf("expected no namespaces, got %v, %v", names, err)
+	}
+
+	r, _ := NewRegistry(dataDir, openMemory)
+	r.Create("tenant-b", Settings{})
+	r.Create("tenant-a", Settings{})
+	defer r.Close()
+
+	if names, err := Names(dataDir); err != nil || !slices.Equal(names, []string{"tenant-a", "tenant-b"}) {
+		t.Errorf("expected both namespaces, got %v, %v", names, err)
+	}
+}
+
+func TestRegistry_Quota(t *testing.T) {
+	r, err := NewRegistry(t.TempDir(), openMemory)
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+
+	ns, err := r.Create("tenant", Settings{Quota: database.Quota{MaxEvents: 1}})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := ns.DB.AddEvent(newCandidate()); err != nil {
+		t.Fatalf("AddEvent failed: %v", err)
+	}
+	if _, err := ns.DB.AddEvent(newCandidate()); !errors.Is(err, database.ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded, got %v", err)
+	}
+}
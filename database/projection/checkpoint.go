@@ -0,0 +1,109 @@
+package projection
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CheckpointStore persists the position up to which each projection has applied events.
+type CheckpointStore interface {
+	Load(name string) (int, error)
+	Save(name string, position int) error
+}
+
+// MemoryCheckpoints keeps checkpoints in memory only. Projections start from the beginning after a restart.
+type MemoryCheckpoints struct {
+	mu          sync.Mutex
+	checkpoints map[string]int
+}
+
+// NewMemoryCheckpoints creates an empty in-memory checkpoint store.
+func NewMemoryCheckpoints() *MemoryCheckpoints {
+	return &MemoryCheckpoints{checkpoints: make(map[string]int)}
+}
+
+// Load returns the checkpoint of the projection, or 0 if none was saved.
+func (m *MemoryCheckpoints) Load(name string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.checkpoints[name], nil
+}
+
+// Save stores the checkpoint of the projection.
+func (m *MemoryCheckpoints) Save(name string, position int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.checkpoints[name] = position
+	return nil
+}
+
+// FileCheckpoints keeps the checkpoints of all projections in a JSON file (projections.json) in the data directory.
+type FileCheckpoints struct {
+	mu       sync.Mutex
+	filePath string
+}
+
+// NewFileCheckpoints creates a checkpoint store backed by projections.json in the data directory.
+func NewFileCheckpoints(dataDir string) *FileCheckpoints {
+	return &FileCheckpoints{filePath: filepath.Join(dataDir, "projections.json")}
+}
+
+// Load returns the checkpoint of the projection, or 0 if none was saved.
+func (f *FileCheckpoints) Load(name string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	checkpoints, err := f.read()
+	if err != nil {
+		return 0, err
+	}
+
+	return checkpoints[name], nil
+}
+
+// Save stores the checkpoint of the projection. The file is replaced atomically.
+func (f *FileCheckpoints) Save(name string, position int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	checkpoints, err := f.read()
+	if err != nil {
+		return err
+	}
+	checkpoints[name] = position
+
+	data, err := json.Marshal(checkpoints)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := f.filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, f.filePath)
+}
+
+func (f *FileCheckpoints) read() (map[string]int, error) {
+	checkpoints := make(map[string]int)
+
+	data, err := os.ReadFile(f.filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoints, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &checkpoints); err != nil {
+		return nil, err
+	}
+
+	return checkpoints, nil
+}
@@ -0,0 +1,58 @@
+package projection
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemoryCheckpoints(t *testing.T) {
+	checkpoints := NewMemoryCheckpoints()
+
+	if position, _ := checkpoints.Load("a"); position != 0 {
+		t.Errorf("expected position 0 for unknown projection, got %d", position)
+	}
+
+	checkpoints.Save("a", 7)
+	if position, _ := checkpoints.Load("a"); position != 7 {
+		t.Errorf("expected position 7, got %d", position)
+	}
+}
+
+func TestFileCheckpoints(t *testing.T) {
+	dir := t.TempDir()
+	checkpoints := NewFileCheckpoints(dir)
+
+	if position, err := checkpoints.Load("a"); err != nil || position != 0 {
+		t.Fatalf("expected position 0 without file, got %d (err %v)", position, err)
+	}
+
+	if err := checkpoints.Save("a", 3); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := checkpoints.Save("b", 5); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// A new store reads the checkpoints written before
+	reopened := NewFileCheckpoints(dir)
+	if position, _ := reopened.Load("a"); position != 3 {
+		t.Errorf("expected position 3 for a, got %d", position)
+	}
+	if position, _ := reopened.Load("b"); position != 5 {
+		t.Errorf("expected position 5 for b, got %d", position)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "projections.json.tmp")); !os.IsNotExist(err) {
+		t.Error("expected temporary file to be renamed")
+	}
+}
+
+func TestFileCheckpoints_Corrupt(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "projections.json"), []byte("{"), 0644)
+
+	if _, err := NewFileCheckpoints(dir).Load("a"); err == nil {
+		t.Error("expected error for corrupt checkpoint file")
+	}
+}
@@ -0,0 +1,55 @@
+package projection
+
+import (
+	"sync"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+// TypeCounts is a built-in projection that counts the stored events per event type.
+type TypeCounts struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// NewTypeCounts creates an empty TypeCounts projection.
+func NewTypeCounts() *TypeCounts {
+	return &TypeCounts{counts: make(map[string]int)}
+}
+
+func (c *TypeCounts) Name() string {
+	return "type-counts"
+}
+
+func (c *TypeCounts) Filter() Filter {
+	return Filter{}
+}
+
+func (c *TypeCounts) Apply(e event.Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts[e.Type]++
+	return nil
+}
+
+func (c *TypeCounts) Reset() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts = make(map[string]int)
+	return nil
+}
+
+// State returns a copy of the event counts keyed by event type.
+func (c *TypeCounts) State() any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := make(map[string]int, len(c.counts))
+	for t, n := range c.counts {
+		counts[t] = n
+	}
+
+	return counts
+}
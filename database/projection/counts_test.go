@@ -0,0 +1,24 @@
+package projection
+
+import (
+	"testing"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+func TestTypeCounts(t *testing.T) {
+	counts := NewTypeCounts()
+	counts.Apply(event.Event{Type: "user.new"})
+	counts.Apply(event.Event{Type: "user.new"})
+	counts.Apply(event.Event{Type: "user.update"})
+
+	state := counts.State().(map[string]int)
+	if state["user.new"] != 2 || state["user.update"] != 1 {
+		t.Errorf("unexpected counts %v", state)
+	}
+
+	counts.Reset()
+	if state := counts.State().(map[string]int); len(state) != 0 {
+		t.Errorf("expected empty counts after reset, got %v", state)
+	}
+}
@@ -0,0 +1,44 @@
+package projection
+
+import (
+	"slices"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+// Projection folds events from the database into a read model.
+type Projection interface {
+	// Name identifies the projection and its checkpoint. It must be unique within a Runner.
+	Name() string
+	// Filter selects the events that are passed to Apply.
+	Filter() Filter
+	// Apply folds a single event into the read model. Events are applied in append order.
+	// If Apply returns an error, the projection stops at this event and retries it later.
+	Apply(e event.Event) error
+	// Reset discards the read model so that it can be rebuilt from the first event.
+	Reset() error
+	// State returns the current read model. It is exposed via the HTTP API and must be JSON-serialisable.
+	State() any
+}
+
+// Filter selects events by type and subject. Subjects may be patterns such as /libraries/7/**
+// (see event.MatchSubject). Empty lists match every event.
+type Filter struct {
+	Types    []string `json:"types,omitempty"`
+	Subjects []string `json:"subjects,omitempty"`
+}
+
+// Match reports whether the event passes the filter.
+func (f Filter) Match(e event.Event) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, e.Type) {
+		return false
+	}
+
+	if len(f.Subjects) > 0 && !slices.ContainsFunc(f.Subjects, func(pattern string) bool {
+		return event.MatchSubject(pattern, e.Subject)
+	}) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,32 @@
+package projection
+
+import (
+	"testing"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+func TestFilterMatch(t *testing.T) {
+	e := event.Event{Type: "user.new", Subject: "/users/1"}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty filter", Filter{}, true},
+		{"matching type", Filter{Types: []string{"user.update", "user.new"}}, true},
+		{"other type", Filter{Types: []string{"user.update"}}, false},
+		{"matching subject", Filter{Subjects: []string{"/users/1"}}, true},
+		{"other subject", Filter{Subjects: []string{"/users/2"}}, false},
+		{"subject pattern", Filter{Subjects: []string{"/users/*"}}, true},
+		{"other subject pattern", Filter{Subjects: []string{"/groups/**"}}, false},
+		{"type and subject", Filter{Types: []string{"user.new"}, Subjects: []string{"/users/2"}}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.filter.Match(e); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
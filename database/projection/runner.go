@@ -0,0 +1,202 @@
+package projection
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/nicograef/cloudevents/database/database"
+)
+
+// batchSize is the maximum number of events read from the database at once.
+const batchSize = 500
+
+// Status describes the progress of a projection.
+type Status struct {
+	Name      string `json:"name"`
+	Filter    Filter `json:"filter"`
+	Position  int    `json:"position"`
+	Head      int    `json:"head"`
+	Lag       int    `json:"lag"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+// Runner feeds the events of a database to the registered projections.
+// Each projection first catches up on the historical events from its checkpoint and then
+// receives live appends. Checkpoints are saved after every batch of applied events.
+type Runner struct {
+	db          *database.Database
+	checkpoints CheckpointStore
+	mu          sync.Mutex
+	projections map[string]*running
+	wake        chan struct{}
+}
+
+type running struct {
+	projection Projection
+	position   int
+	lastError  string
+}
+
+// NewRunner creates a Runner for the database that persists checkpoints in the given store.
+func NewRunner(db *database.Database, checkpoints CheckpointStore) *Runner {
+	return &Runner{
+		db:          db,
+		checkpoints: checkpoints,
+		projections: make(map[string]*running),
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Register adds a projection to the runner. It resumes from its saved checkpoint.
+func (r *Runner) Register(p Projection) error {
+	position, err := r.checkpoints.Load(p.Name())
+	if err != nil {
+		return fmt.Errorf("failed to load checkpoint of projection %s: %w", p.Name(), err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.projections[p.Name()]; exists {
+		return fmt.Errorf("projection %s is already registered", p.Name())
+	}
+	r.projections[p.Name()] = &running{projection: p, position: position}
+
+	r.notify()
+	return nil
+}
+
+// Run applies events to the projections until the context is cancelled.
+func (r *Runner) Run(ctx context.Context) {
+	for {
+		changed := r.db.Changed()
+		r.CatchUp()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-changed:
+		case <-r.wake:
+		}
+	}
+}
+
+// CatchUp applies all events appended since their checkpoint to every projection.
+func (r *Runner) CatchUp() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, p := range r.projections {
+		r.catchUp(p)
+	}
+}
+
+// Rebuild resets the projection and replays all events from the beginning.
+func (r *Runner) Rebuild(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, exists := r.projections[name]
+	if !exists {
+		return fmt.Errorf("projection %s not found", name)
+	}
+
+	if err := p.projection.Reset(); err != nil {
+		return fmt.Errorf("failed to reset projection %s: %w", name, err)
+	}
+
+	p.position = 0
+	p.lastError = ""
+	if err := r.checkpoints.Save(name, 0); err != nil {
+		return fmt.Errorf("failed to save checkpoint of projection %s: %w", name, err)
+	}
+
+	log.Printf("INFO Rebuilding projection %s", name)
+	r.notify()
+	return nil
+}
+
+// Statuses returns the status of all projections sorted by name.
+func (r *Runner) Statuses() []Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	head := r.db.Head()
+	statuses := make([]Status, 0, len(r.projections))
+	for _, p := range r.projections {
+		statuses = append(statuses, p.status(head))
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
+
+// Get returns the status and the current state of the projection.
+func (r *Runner) Get(name string) (Status, any, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, exists := r.projections[name]
+	if !exists {
+		return Status{}, nil, false
+	}
+
+	return p.status(r.db.Head()), p.projection.State(), true
+}
+
+// catchUp applies the events after the projection's position in batches. The caller must hold the lock.
+func (r *Runner) catchUp(p *running) {
+	for {
+		records := r.db.ReadFrom(p.position, batchSize)
+		if len(records) == 0 {
+			return
+		}
+
+		filter := p.projection.Filter()
+		for _, record := range records {
+			if filter.Match(record.Event) {
+				if err := p.projection.Apply(record.Event); err != nil {
+					log.Printf("ERROR Projection %s failed at position %d: %v", p.projection.Name(), record.Position, err)
+					p.lastError = err.Error()
+					r.saveCheckpoint(p)
+					return
+				}
+			}
+			p.position = record.Position
+		}
+
+		p.lastError = ""
+		r.saveCheckpoint(p)
+	}
+}
+
+func (r *Runner) saveCheckpoint(p *running) {
+	if err := r.checkpoints.Save(p.projection.Name(), p.position); err != nil {
+		log.Printf("ERROR Failed to save checkpoint of projection %s: %v", p.projection.Name(), err)
+	}
+}
+
+// notify wakes up the Run loop without blocking.
+func (r *Runner) notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (p *running) status(head int) Status {
+	return Status{
+		Name:      p.projection.Name(),
+		Filter:    p.projection.Filter(),
+		Position:  p.position,
+		Head:      head,
+		Lag:       head - p.position,
+		LastError: p.lastError,
+	}
+}
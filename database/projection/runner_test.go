@@ -0,0 +1,201 @@
+package projection
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/event"
+)
+
+// subjects is a test projection that records the subjects of the applied events.
+type subjects struct {
+	mu      sync.Mutex
+	filter  Filter
+	applied []string
+	fail    bool
+}
+
+func (s *subjects) Name() string   { return "subjects" }
+func (s *subjects) Filter() Filter { return s.filter }
+
+func (s *subjects) Apply(e event.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fail {
+		return errors.New("apply failed")
+	}
+	s.applied = append(s.applied, e.Subject)
+	return nil
+}
+
+func (s *subjects) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.applied = nil
+	return nil
+}
+
+func (s *subjects) State() any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.applied...)
+}
+
+func (s *subjects) setFail(fail bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fail = fail
+}
+
+func addEvent(t *testing.T, db *database.Database, eventType, subject string) {
+	t.Helper()
+	if _, err := db.AddEvent(event.Candidate{Type: eventType, Source: "https://example.com", Subject: subject, Data: map[string]any{}}); err != nil {
+		t.Fatalf("AddEvent failed: %v", err)
+	}
+}
+
+func TestRunner_CatchUp(t *testing.T) {
+	db := database.New()
+	addEvent(t, db, "user.new", "/users/1")
+	addEvent(t, db, "user.update", "/users/1")
+	addEvent(t, db, "user.new", "/users/2")
+
+	checkpoints := NewMemoryCheckpoints()
+	runner := NewRunner(db, checkpoints)
+	p := &subjects{filter: Filter{Types: []string{"user.new"}}}
+	if err := runner.Register(p); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	runner.CatchUp()
+
+	state := p.State().([]string)
+	if len(state) != 2 || state[0] != "/users/1" || state[1] != "/users/2" {
+		t.Errorf("unexpected applied subjects %v", state)
+	}
+
+	// Filtered events advance the position as well
+	if position, _ := checkpoints.Load("subjects"); position != 3 {
+		t.Errorf("expected checkpoint 3, got %d", position)
+	}
+	status := runner.Statuses()[0]
+	if status.Position != 3 || status.Head != 3 || status.Lag != 0 {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
+
+func TestRunner_ResumesFromCheckpoint(t *testing.T) {
+	db := database.New()
+	addEvent(t, db, "user.new", "/users/1")
+	addEvent(t, db, "user.new", "/users/2")
+
+	checkpoints := NewMemoryCheckpoints()
+	checkpoints.Save("subjects", 1)
+
+	runner := NewRunner(db, checkpoints)
+	p := &subjects{}
+	runner.Register(p)
+	runner.CatchUp()
+
+	state := p.State().([]string)
+	if len(state) != 1 || state[0] != "/users/2" {
+		t.Errorf("expected only the event after the checkpoint, got %v", state)
+	}
+}
+
+func TestRunner_DuplicateRegistration(t *testing.T) {
+	runner := NewRunner(database.New(), NewMemoryCheckpoints())
+	runner.Register(&subjects{})
+
+	if err := runner.Register(&subjects{}); err == nil {
+		t.Error("expected error for duplicate projection name")
+	}
+}
+
+func TestRunner_ApplyError(t *testing.T) {
+	db := database.New()
+	addEvent(t, db, "user.new", "/users/1")
+
+	runner := NewRunner(db, NewMemoryCheckpoints())
+	p := &subjects{fail: true}
+	runner.Register(p)
+	runner.CatchUp()
+
+	status, _, _ := runner.Get("subjects")
+	if status.Position != 0 || status.Lag != 1 || status.LastError != "apply failed" {
+		t.Errorf("unexpected status after failed apply %+v", status)
+	}
+
+	// The failed event is retried on the next catch up
+	p.setFail(false)
+	runner.CatchUp()
+
+	status, _, _ = runner.Get("subjects")
+	if status.Position != 1 || status.LastError != "" {
+		t.Errorf("unexpected status after retry %+v", status)
+	}
+}
+
+func TestRunner_Rebuild(t *testing.T) {
+	db := database.New()
+	addEvent(t, db, "user.new", "/users/1")
+	addEvent(t, db, "user.new", "/users/2")
+
+	runner := NewRunner(db, NewMemoryCheckpoints())
+	p := &subjects{}
+	runner.Register(p)
+	runner.CatchUp()
+
+	if err := runner.Rebuild("subjects"); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	if status, _, _ := runner.Get("subjects"); status.Position != 0 {
+		t.Errorf("expected position 0 after rebuild, got %d", status.Position)
+	}
+
+	runner.CatchUp()
+	if state := p.State().([]string); len(state) != 2 {
+		t.Errorf("expected 2 applied events after rebuild, got %v", state)
+	}
+
+	if err := runner.Rebuild("unknown"); err == nil {
+		t.Error("expected error for unknown projection")
+	}
+}
+
+func TestRunner_RunAppliesLiveEvents(t *testing.T) {
+	db := database.New()
+	addEvent(t, db, "user.new", "/users/1")
+
+	runner := NewRunner(db, NewMemoryCheckpoints())
+	p := &subjects{}
+	runner.Register(p)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runner.Run(ctx)
+		close(done)
+	}()
+
+	addEvent(t, db, "user.new", "/users/2")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(p.State().([]string)) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("live event was not applied, got %v", p.State())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	<-done
+}
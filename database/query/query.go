@@ -0,0 +1,326 @@
+package query
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Op is a comparison operator of a predicate.
+type Op string
+
+const (
+	OpEqual        Op = "="
+	OpNotEqual     Op = "!="
+	OpLess         Op = "<"
+	OpLessEqual    Op = "<="
+	OpGreater      Op = ">"
+	OpGreaterEqual Op = ">="
+	OpExists       Op = "EXISTS"
+)
+
+// Query is a conjunction of predicates over the data payload of an event, e.g.
+//
+//	data.memberId = 99 AND data.due >= '2025-01-01' AND EXISTS data.renewals
+//
+// Paths start with "data" and select nested fields with dots; array elements are selected by their index
+// (data.items.0.price). Values are numbers, strings in single or double quotes, true, false or null.
+// Numbers and strings support all comparison operators, the other values only = and !=.
+type Query struct {
+	Predicates []Predicate
+}
+
+// Predicate compares the value at a path in the data payload with a literal.
+type Predicate struct {
+	Path  Path
+	Op    Op
+	Value any
+}
+
+// Path selects a value inside the data payload. It holds the segments after the leading "data".
+type Path []string
+
+// ParsePath parses a dotted path such as data.member.id.
+func ParsePath(s string) (Path, error) {
+	segments := strings.Split(strings.TrimSpace(s), ".")
+	if len(segments) < 2 || segments[0] != "data" {
+		return nil, fmt.Errorf("invalid path %q: must start with data.", s)
+	}
+
+	for _, segment := range segments[1:] {
+		if segment == "" {
+			return nil, fmt.Errorf("invalid path %q: empty segment", s)
+		}
+	}
+
+	return Path(segments[1:]), nil
+}
+
+// String returns the dotted form of the path including the leading "data".
+func (p Path) String() string {
+	return "data." + strings.Join(p, ".")
+}
+
+// Lookup returns the value at the path in the data payload and whether it exists.
+// Numbers are returned as float64; payloads that are not plain JSON values are normalised via JSON first.
+func (p Path) Lookup(data any) (any, bool) {
+	value := data
+	for _, segment := range p {
+		switch v := normalize(value).(type) {
+		case map[string]any:
+			next, exists := v[segment]
+			if !exists {
+				return nil, false
+			}
+			value = next
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return normalize(value), true
+}
+
+// Match reports whether the data payload satisfies all predicates.
+func (q Query) Match(data any) bool {
+	for _, p := range q.Predicates {
+		if !p.Match(data) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Match reports whether the data payload satisfies the predicate.
+func (p Predicate) Match(data any) bool {
+	value, exists := p.Path.Lookup(data)
+	if p.Op == OpExists {
+		return exists
+	}
+	if !exists {
+		return false
+	}
+
+	c, comparable := compare(value, p.Value)
+	switch p.Op {
+	case OpEqual:
+		return comparable && c == 0
+	case OpNotEqual:
+		return !comparable || c != 0
+	case OpLess:
+		return comparable && c < 0 && ordered(p.Value)
+	case OpLessEqual:
+		return comparable && c <= 0 && ordered(p.Value)
+	case OpGreater:
+		return comparable && c > 0 && ordered(p.Value)
+	case OpGreaterEqual:
+		return comparable && c >= 0 && ordered(p.Value)
+	}
+
+	return false
+}
+
+// Parse parses a query string. See Query for the syntax.
+func Parse(s string) (Query, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return Query{}, err
+	}
+	if len(tokens) == 0 {
+		return Query{}, errors.New("query cannot be empty")
+	}
+
+	q := Query{}
+	for len(tokens) > 0 {
+		var p Predicate
+		p, tokens, err = parsePredicate(tokens)
+		if err != nil {
+			return Query{}, err
+		}
+		q.Predicates = append(q.Predicates, p)
+
+		if len(tokens) == 0 {
+			break
+		}
+		if !strings.EqualFold(tokens[0].text, "AND") || tokens[0].quoted {
+			return Query{}, fmt.Errorf("expected AND, got %q", tokens[0].text)
+		}
+		tokens = tokens[1:]
+		if len(tokens) == 0 {
+			return Query{}, errors.New("expected predicate after AND")
+		}
+	}
+
+	return q, nil
+}
+
+func parsePredicate(tokens []token) (Predicate, []token, error) {
+	if strings.EqualFold(tokens[0].text, "EXISTS") && !tokens[0].quoted {
+		if len(tokens) < 2 {
+			return Predicate{}, nil, errors.New("expected path after EXISTS")
+		}
+		path, err := ParsePath(tokens[1].text)
+		if err != nil {
+			return Predicate{}, nil, err
+		}
+		return Predicate{Path: path, Op: OpExists}, tokens[2:], nil
+	}
+
+	if len(tokens) < 3 {
+		return Predicate{}, nil, fmt.Errorf("incomplete predicate starting at %q", tokens[0].text)
+	}
+
+	path, err := ParsePath(tokens[0].text)
+	if err != nil {
+		return Predicate{}, nil, err
+	}
+
+	op := Op(tokens[1].text)
+	switch op {
+	case OpEqual, OpNotEqual, OpLess, OpLessEqual, OpGreater, OpGreaterEqual:
+	default:
+		return Predicate{}, nil, fmt.Errorf("unknown operator %q", tokens[1].text)
+	}
+
+	value, err := parseValue(tokens[2])
+	if err != nil {
+		return Predicate{}, nil, err
+	}
+	if op != OpEqual && op != OpNotEqual && !ordered(value) {
+		return Predicate{}, nil, fmt.Errorf("operator %s requires a number or string", op)
+	}
+
+	return Predicate{Path: path, Op: op, Value: value}, tokens[3:], nil
+}
+
+func parseValue(t token) (any, error) {
+	if t.quoted {
+		return t.text, nil
+	}
+
+	switch t.text {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+
+	n, err := strconv.ParseFloat(t.text, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q: strings must be quoted", t.text)
+	}
+
+	return n, nil
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+// tokenize splits the query into paths, operators and values.
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, token{text: s[i+1 : i+1+end], quoted: true})
+			i += end + 2
+		case strings.ContainsRune("=!<>", rune(c)):
+			j := i + 1
+			if j < len(s) && s[j] == '=' {
+				j++
+			}
+			tokens = append(tokens, token{text: s[i:j]})
+			i = j
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t\n'\"=!<>", rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, token{text: s[i:j]})
+			i = j
+		}
+	}
+
+	return tokens, nil
+}
+
+// compare orders two JSON values of the same kind. It returns false if they cannot be compared.
+func compare(a, b any) (int, bool) {
+	switch a := a.(type) {
+	case float64:
+		if b, ok := b.(float64); ok {
+			switch {
+			case a < b:
+				return -1, true
+			case a > b:
+				return 1, true
+			}
+			return 0, true
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), true
+		}
+	case bool:
+		if b, ok := b.(bool); ok && a == b {
+			return 0, true
+		}
+		if _, ok := b.(bool); ok {
+			return 1, true
+		}
+	case nil:
+		if b == nil {
+			return 0, true
+		}
+	}
+
+	return 0, false
+}
+
+func ordered(v any) bool {
+	switch v.(type) {
+	case float64, string:
+		return true
+	}
+	return false
+}
+
+// normalize converts a value into a plain JSON value (map, slice, float64, string, bool, nil).
+// Nested values are normalised lazily by Lookup.
+func normalize(data any) any {
+	switch data.(type) {
+	case map[string]any, []any, float64, string, bool, nil:
+		return data
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil
+	}
+
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil
+	}
+
+	return v
+}
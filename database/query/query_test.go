@@ -0,0 +1,93 @@
+package query
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func payload(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	return v
+}
+
+func TestParseAndMatch(t *testing.T) {
+	data := payload(t, `{"memberId": 99, "title": "Dune", "due": "2025-03-01", "renewed": false, "note": null, "member": {"tier": "gold"}, "items": [{"price": 12.5}]}`)
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"data.memberId = 99", true},
+		{"data.memberId = 98", false},
+		{"data.memberId != 98", true},
+		{"data.memberId >= 99 AND data.memberId < 100", true},
+		{"data.memberId > 99", false},
+		{"data.title = 'Dune'", true},
+		{`data.title = "Dune"`, true},
+		{"data.due <= '2025-03-01'", true},
+		{"data.due > '2025-03-01'", false},
+		{"data.renewed = false", true},
+		{"data.note = null", true},
+		{"data.member.tier = 'gold'", true},
+		{"data.items.0.price < 20", true},
+		{"data.items.1.price < 20", false},
+		{"EXISTS data.member.tier", true},
+		{"exists data.coupon", false},
+		{"data.coupon != 'x'", false},
+		{"data.title = 99", false},
+		{"data.title != 99", true},
+		{"data.memberId = 99 AND EXISTS data.coupon", false},
+	}
+
+	for _, tt := range tests {
+		q, err := Parse(tt.query)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.query, err)
+			continue
+		}
+		if got := q.Match(data); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.query, tt.want, got)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"memberId = 99",
+		"data.memberId",
+		"data.memberId == 99",
+		"data.memberId = abc",
+		"data.title = 'Dune",
+		"data.memberId = 99 OR data.memberId = 98",
+		"data.memberId = 99 AND",
+		"data.renewed > true",
+		"EXISTS",
+		"data..id = 1",
+	} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestLookup_GoValues(t *testing.T) {
+	type member struct {
+		ID int `json:"id"`
+	}
+	data := map[string]any{"memberId": 99, "member": member{ID: 7}}
+
+	path, _ := ParsePath("data.member.id")
+	if v, ok := path.Lookup(data); !ok || v != float64(7) {
+		t.Errorf("expected 7 from struct field, got %v (%v)", v, ok)
+	}
+
+	q, _ := Parse("data.memberId = 99")
+	if !q.Match(data) {
+		t.Error("expected int payload value to match number literal")
+	}
+}
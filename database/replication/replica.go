@@ -0,0 +1,287 @@
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nicograef/cloudevents/database/database"
+)
+
+// Role is the replication role of a database instance.
+type Role string
+
+const (
+	// RoleLeader accepts writes and ships its log to followers.
+	RoleLeader Role = "leader"
+	// RoleFollower replicates the log of a leader and serves read-only queries.
+	RoleFollower Role = "follower"
+)
+
+const (
+	// batchSize is the number of records requested from the leader at once.
+	batchSize = 500
+	// pollWait is how long the leader holds a request open when there are no new records.
+	pollWait = 10 * time.Second
+	// retryDelay is the pause after a failed request to the leader.
+	retryDelay = time.Second
+)
+
+// LogResponse is the response of the replication log endpoint of the leader.
+// Head is the position of the most recent event of the leader.
+type LogResponse struct {
+	Ok      bool              `json:"ok"`
+	Head    int               `json:"head"`
+	Records []database.Record `json:"records"`
+}
+
+// Status reports the replication role and, for followers, the replication progress.
+// Position is the last leader position the follower has applied; Lag is the number of positions it is behind.
+type Status struct {
+	Role        Role      `json:"role"`
+	Leader      string    `json:"leader,omitempty"`
+	Position    int       `json:"position"`
+	LeaderHead  int       `json:"leaderHead"`
+	Lag         int       `json:"lag"`
+	LastContact time.Time `json:"lastContact,omitzero"`
+	LastError   string    `json:"lastError,omitempty"`
+}
+
+// Replica is the replication side of a database instance. A follower pulls the log of the leader from its
+// last known position and appends the events with their IDs and times; events it already has are skipped.
+// A follower can be promoted to leader manually, which stops the replication and allows writes.
+type Replica struct {
+	db        *database.Database
+	leader    string
+	statePath string
+	client    *http.Client
+
+	mu          sync.Mutex
+	role        Role
+	position    int
+	leaderHead  int
+	lastContact time.Time
+	lastError   string
+	stop        context.CancelFunc
+}
+
+// state is the persisted replication state in replication.json.
+type state struct {
+	Leader   string `json:"leader"`
+	Position int    `json:"position"`
+	Promoted bool   `json:"promoted"`
+}
+
+// NewReplica creates a follower of the leader at the given base URL, or a leader if the URL is empty.
+// The replication position is loaded from replication.json in the data directory. A follower that was
+// promoted before stays leader. The position of another leader is discarded.
+func NewReplica(db *database.Database, leaderURL, dataDir string) (*Replica, error) {
+	r := &Replica{
+		db:        db,
+		leader:    strings.TrimSuffix(leaderURL, "/"),
+		statePath: filepath.Join(dataDir, "replication.json"),
+		client:    &http.Client{Timeout: pollWait + 10*time.Second},
+		role:      RoleLeader,
+	}
+	if r.leader == "" {
+		return r, nil
+	}
+	r.role = RoleFollower
+
+	data, err := os.ReadFile(r.statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s state
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid replication state: %w", err)
+	}
+	if s.Promoted {
+		r.role = RoleLeader
+	} else if s.Leader == r.leader {
+		r.position = s.Position
+	}
+
+	return r, nil
+}
+
+// Role returns the current replication role.
+func (r *Replica) Role() Role {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.role
+}
+
+// ReadOnly reports whether writes must be rejected because the instance is a follower.
+func (r *Replica) ReadOnly() bool {
+	return r.Role() == RoleFollower
+}
+
+// Status returns the replication status.
+func (r *Replica) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.role == RoleLeader {
+		head := r.db.Head()
+		return Status{Role: RoleLeader, Position: head, LeaderHead: head}
+	}
+
+	return Status{
+		Role:        r.role,
+		Leader:      r.leader,
+		Position:    r.position,
+		LeaderHead:  r.leaderHead,
+		Lag:         max(r.leaderHead-r.position, 0),
+		LastContact: r.lastContact,
+		LastError:   r.lastError,
+	}
+}
+
+// Run replicates the log of the leader until the context is cancelled or the follower is promoted.
+// It returns immediately for a leader.
+func (r *Replica) Run(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	r.mu.Lock()
+	if r.role != RoleFollower {
+		r.mu.Unlock()
+		return
+	}
+	r.stop = cancel
+	r.mu.Unlock()
+
+	log.Printf("INFO Replicating from %s at position %d", r.leader, r.Status().Position)
+
+	for ctx.Err() == nil {
+		if err := r.pull(ctx); err != nil && ctx.Err() == nil {
+			r.mu.Lock()
+			r.lastError = err.Error()
+			r.mu.Unlock()
+			log.Printf("WARN Replication from %s failed: %v", r.leader, err)
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(retryDelay):
+			}
+		}
+	}
+}
+
+// Promote makes the follower a leader. The replication stops and the promotion is persisted,
+// so the instance stays leader after a restart.
+func (r *Replica) Promote() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.role == RoleLeader {
+		return errors.New("instance is already leader")
+	}
+
+	r.role = RoleLeader
+	if r.stop != nil {
+		r.stop()
+	}
+
+	log.Printf("INFO Promoted to leader at position %d of %s", r.position, r.leader)
+
+	return r.persist(true)
+}
+
+// Persist stores the replication position in replication.json. It must only be called after the database
+// was persisted, so that the stored position never runs ahead of the stored events.
+func (r *Replica) Persist() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.leader == "" {
+		return nil
+	}
+
+	return r.persist(r.role == RoleLeader)
+}
+
+// persist writes the replication state. The caller must hold the lock.
+func (r *Replica) persist(promoted bool) error {
+	data, err := json.Marshal(state{Leader: r.leader, Position: r.position, Promoted: promoted})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.statePath, data, 0644)
+}
+
+// pull requests the next records from the leader and appends them.
+func (r *Replica) pull(ctx context.Context) error {
+	r.mu.Lock()
+	from := r.position
+	r.mu.Unlock()
+
+	query := url.Values{}
+	query.Set("from", strconv.Itoa(from))
+	query.Set("limit", strconv.Itoa(batchSize))
+	query.Set("wait", pollWait.String())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.leader+"/replication/log?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("leader responded with status %d", resp.StatusCode)
+	}
+
+	var body LogResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("invalid response from leader: %w", err)
+	}
+
+	var appendErr error
+	position := from
+	for _, record := range body.Records {
+		if _, _, err := r.db.AppendEvent(record.Event); err != nil {
+			// The leader validated the event, so it can only be rejected by the local state, e.g. an erased key.
+			appendErr = fmt.Errorf("cannot append event %s at position %d: %w", record.Event.ID, record.Position, err)
+			log.Printf("ERROR Replication: %v", appendErr)
+		}
+		position = record.Position
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.role != RoleFollower {
+		return nil
+	}
+	r.position = position
+	r.leaderHead = max(body.Head, position)
+	r.lastContact = time.Now().UTC()
+	r.lastError = ""
+	if appendErr != nil {
+		r.lastError = appendErr.Error()
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/event"
+)
+
+// newLeader serves the replication log of the database without holding requests open.
+func newLeader(t *testing.T, db *database.Database) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		from, _ := strconv.Atoi(r.URL.Query().Get("from"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		json.NewEncoder(w).Encode(LogResponse{Ok: true, Head: db.Head(), Records: db.ReadFrom(from, limit)})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func addEvents(t *testing.T, db *database.Database, n int) {
+	t.Helper()
+	for range n {
+		if _, err := db.AddEvent(event.Candidate{Type: "user.login", Source: "https://example.com", Subject: "/users/1", Data: map[string]any{}}); err != nil {
+			t.Fatalf("AddEvent failed: %v", err)
+		}
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReplica_Leader(t *testing.T) {
+	db := database.New()
+	addEvents(t, db, 2)
+
+	replica, err := NewReplica(db, "", t.TempDir())
+	if err != nil {
+		t.Fatalf("NewReplica failed: %v", err)
+	}
+
+	if replica.ReadOnly() {
+		t.Error("expected leader to accept writes")
+	}
+	if status := replica.Status(); status.Role != RoleLeader || status.Position != 2 || status.Lag != 0 {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if err := replica.Promote(); err == nil {
+		t.Error("expected error when promoting a leader")
+	}
+}
+
+func TestReplica_Follow(t *testing.T) {
+	leaderDB := database.New()
+	addEvents(t, leaderDB, 3)
+	leader := newLeader(t, leaderDB)
+
+	followerDB := database.New()
+	replica, err := NewReplica(followerDB, leader.URL, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewReplica failed: %v", err)
+	}
+	if !replica.ReadOnly() {
+		t.Error("expected follower to be read-only")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go replica.Run(ctx)
+
+	waitFor(t, func() bool { return followerDB.Head() == 3 })
+
+	addEvents(t, leaderDB, 2)
+	waitFor(t, func() bool { return followerDB.Head() == 5 })
+
+	status := replica.Status()
+	if status.Role != RoleFollower || status.Position != 5 || status.LeaderHead != 5 || status.Lag != 0 || status.LastContact.IsZero() {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	for _, e := range leaderDB.GetEvents() {
+		if got := followerDB.GetEvent(e.Source, e.ID); got == nil || !got.Time.Equal(e.Time) {
+			t.Errorf("expected event %s to be replicated with its time", e.ID)
+		}
+	}
+}
+
+func TestReplica_LeaderUnavailable(t *testing.T) {
+	leader := httptest.NewServer(http.NotFoundHandler())
+	defer leader.Close()
+
+	replica, _ := NewReplica(database.New(), leader.URL, t.TempDir())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go replica.Run(ctx)
+
+	waitFor(t, func() bool { return replica.Status().LastError != "" })
+}
+
+func TestReplica_PromoteAndPersist(t *testing.T) {
+	leaderDB := database.New()
+	addEvents(t, leaderDB, 2)
+	leader := newLeader(t, leaderDB)
+
+	dataDir := t.TempDir()
+	followerDB := database.New()
+	replica, _ := NewReplica(followerDB, leader.URL, dataDir)
+
+	done := make(chan struct{})
+	go func() {
+		replica.Run(context.Background())
+		close(done)
+	}()
+	waitFor(t, func() bool { return followerDB.Head() == 2 })
+
+	if err := replica.Persist(); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+	restarted, _ := NewReplica(database.New(), leader.URL, dataDir)
+	if restarted.Status().Position != 2 {
+		t.Errorf("expected restarted follower to resume at position 2, got %d", restarted.Status().Position)
+	}
+	other, _ := NewReplica(database.New(), "http://other:5000", dataDir)
+	if other.Status().Position != 0 {
+		t.Error("expected position of another leader to be discarded")
+	}
+
+	if err := replica.Promote(); err != nil {
+		t.Fatalf("Promote failed: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected replication to stop after promotion")
+	}
+	if replica.ReadOnly() {
+		t.Error("expected promoted replica to accept writes")
+	}
+
+	if _, err := os.Stat(filepath.Join(dataDir, "replication.json")); err != nil {
+		t.Fatalf("expected replication state to be persisted: %v", err)
+	}
+	restarted, _ = NewReplica(database.New(), leader.URL, dataDir)
+	if restarted.Role() != RoleLeader {
+		t.Error("expected promoted replica to stay leader after restart")
+	}
+}
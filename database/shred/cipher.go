@@ -0,0 +1,206 @@
+package shred
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nicograef/cloudevents/database/query"
+	"github.com/nicograef/cloudevents/event"
+)
+
+// EncryptedField is the key of the data envelope that holds an encrypted payload:
+//
+//	{"$encrypted": {"key": "/users/123", "data": "<base64 nonce and ciphertext>"}}
+const EncryptedField = "$encrypted"
+
+// KeyBySubject selects the event subject as the data subject key.
+const KeyBySubject = "subject"
+
+// Erased is the data of an event whose key was erased. Metadata and position of the event are unchanged.
+type Erased struct {
+	Erased   bool      `json:"erased"`
+	Key      string    `json:"key"`
+	ErasedAt time.Time `json:"erasedAt,omitzero"`
+}
+
+type envelope struct {
+	Key  string `json:"key"`
+	Data string `json:"data"`
+}
+
+// Cipher encrypts event payloads with AES-256-GCM using the key of their data subject.
+// The data subject is the event subject or the value at a path in the data payload.
+// Events without a data subject key are stored unencrypted.
+type Cipher struct {
+	keys    *KeyStore
+	keyPath query.Path
+}
+
+// NewCipher creates a Cipher that takes keys from the key store. keyBy is KeyBySubject or a data path
+// such as data.userId that holds the data subject key.
+func NewCipher(keys *KeyStore, keyBy string) (*Cipher, error) {
+	c := &Cipher{keys: keys}
+	if keyBy == KeyBySubject {
+		return c, nil
+	}
+
+	path, err := query.ParsePath(keyBy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data subject key: %w", err)
+	}
+	c.keyPath = path
+
+	return c, nil
+}
+
+// Seal returns the event with its payload encrypted with the key of its data subject.
+// It fails if the key of the data subject was erased.
+func (c *Cipher) Seal(e event.Event) (event.Event, error) {
+	id := c.dataSubject(e)
+	if id == "" {
+		return e, nil
+	}
+
+	key, err := c.keys.Key(id, true)
+	if err != nil {
+		return e, fmt.Errorf("cannot encrypt data of %s: %w", id, err)
+	}
+
+	plaintext, err := e.DataBytes()
+	if err != nil {
+		return e, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return e, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return e, err
+	}
+	// The event ID is authenticated so that ciphertexts cannot be moved between events.
+	sealed := gcm.Seal(nonce, nonce, plaintext, e.ID[:])
+
+	e.Data = map[string]any{EncryptedField: map[string]any{
+		"key":  id,
+		"data": base64.StdEncoding.EncodeToString(sealed),
+	}}
+
+	return e, nil
+}
+
+// Open returns the event with its payload decrypted. Events whose key was erased are returned
+// with Erased as data. Events that are not encrypted are returned unchanged.
+func (c *Cipher) Open(e event.Event) event.Event {
+	env, encrypted := parseEnvelope(e.Data)
+	if !encrypted {
+		return e
+	}
+
+	data, err := c.decrypt(e, env)
+	if err != nil {
+		erasedAt, _ := c.keys.ErasedAt(env.Key)
+		e.Data = Erased{Erased: true, Key: env.Key, ErasedAt: erasedAt}
+		return e
+	}
+
+	e.Data = data
+	return e
+}
+
+// KeyOf returns the data subject key the stored event is encrypted with, or an empty string.
+func (c *Cipher) KeyOf(e event.Event) string {
+	env, _ := parseEnvelope(e.Data)
+	return env.Key
+}
+
+// Erase destroys the key of the data subject.
+func (c *Cipher) Erase(id string) error {
+	_, err := c.keys.Erase(id)
+	return err
+}
+
+func (c *Cipher) dataSubject(e event.Event) string {
+	if c.keyPath == nil {
+		return e.Subject
+	}
+
+	value, exists := c.keyPath.Lookup(e.Data)
+	if !exists || value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+
+	b, _ := json.Marshal(value)
+	return string(b)
+}
+
+func (c *Cipher) decrypt(e event.Event, env envelope) (any, error) {
+	key, err := c.keys.Key(env.Key, false)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(env.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], e.ID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.SetDataBytes(e.DataContentType, plaintext); err != nil {
+		return nil, err
+	}
+
+	return e.Data, nil
+}
+
+// parseEnvelope extracts the envelope from an encrypted payload, both from sealed and from loaded events.
+func parseEnvelope(data any) (envelope, bool) {
+	m, ok := data.(map[string]any)
+	if !ok || len(m) != 1 {
+		return envelope{}, false
+	}
+
+	inner, ok := m[EncryptedField].(map[string]any)
+	if !ok {
+		return envelope{}, false
+	}
+
+	key, _ := inner["key"].(string)
+	ciphertext, _ := inner["data"].(string)
+	if key == "" || ciphertext == "" {
+		return envelope{}, false
+	}
+
+	return envelope{Key: key, Data: ciphertext}, true
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
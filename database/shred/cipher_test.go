@@ -0,0 +1,125 @@
+package shred
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nicograef/cloudevents/event"
+)
+
+func newEvent(subject string, data any) event.Event {
+	return event.Event{ID: uuid.New(), Type: "user.registered", Time: time.Now(), Source: "https://example.com", Subject: subject, Data: data}
+}
+
+func TestCipher_SealAndOpen(t *testing.T) {
+	ks, _ := NewKeyStore("")
+	c, err := NewCipher(ks, KeyBySubject)
+	if err != nil {
+		t.Fatalf("NewCipher failed: %v", err)
+	}
+
+	e := newEvent("/users/1", map[string]any{"email": "john@example.com"})
+	sealed, err := c.Seal(e)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+
+	b, _ := json.Marshal(sealed)
+	if strings.Contains(string(b), "john@example.com") {
+		t.Error("expected sealed event not to contain the plaintext")
+	}
+	if c.KeyOf(sealed) != "/users/1" {
+		t.Errorf("expected key /users/1, got %q", c.KeyOf(sealed))
+	}
+
+	// Round trip through JSON like a persisted event
+	var loaded event.Event
+	json.Unmarshal(b, &loaded)
+
+	opened := c.Open(loaded)
+	data, ok := opened.Data.(map[string]any)
+	if !ok || data["email"] != "john@example.com" {
+		t.Errorf("unexpected opened data %v", opened.Data)
+	}
+	if opened.ID != e.ID || opened.Subject != e.Subject {
+		t.Error("expected metadata to be unchanged")
+	}
+}
+
+func TestCipher_SealAndOpenBinary(t *testing.T) {
+	ks, _ := NewKeyStore("")
+	c, _ := NewCipher(ks, KeyBySubject)
+
+	e := newEvent("/users/1", []byte{0x89, 0x50, 0x4e, 0x47})
+	e.DataContentType = "image/png"
+	sealed, err := c.Seal(e)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+
+	b, _ := json.Marshal(sealed)
+	var loaded event.Event
+	json.Unmarshal(b, &loaded)
+
+	opened := c.Open(loaded)
+	if data, ok := opened.Data.([]byte); !ok || string(data) != "\x89PNG" || opened.DataContentType != "image/png" {
+		t.Errorf("unexpected opened data %T %v", opened.Data, opened.Data)
+	}
+}
+
+func TestCipher_Erase(t *testing.T) {
+	ks, _ := NewKeyStore("")
+	c, _ := NewCipher(ks, KeyBySubject)
+
+	sealed, _ := c.Seal(newEvent("/users/1", map[string]any{"email": "john@example.com"}))
+	if err := c.Erase("/users/1"); err != nil {
+		t.Fatalf("Erase failed: %v", err)
+	}
+
+	erased, ok := c.Open(sealed).Data.(Erased)
+	if !ok || !erased.Erased || erased.Key != "/users/1" || erased.ErasedAt.IsZero() {
+		t.Errorf("expected erased marker, got %v", c.Open(sealed).Data)
+	}
+
+	if _, err := c.Seal(newEvent("/users/1", map[string]any{})); err == nil {
+		t.Error("expected Seal to fail for an erased key")
+	}
+}
+
+func TestCipher_KeyByDataPath(t *testing.T) {
+	ks, _ := NewKeyStore("")
+	c, err := NewCipher(ks, "data.userId")
+	if err != nil {
+		t.Fatalf("NewCipher failed: %v", err)
+	}
+
+	sealed, _ := c.Seal(newEvent("/orders/1", map[string]any{"userId": "u-1", "total": 10}))
+	if c.KeyOf(sealed) != "u-1" {
+		t.Errorf("expected key u-1, got %q", c.KeyOf(sealed))
+	}
+
+	plain, _ := c.Seal(newEvent("/orders/2", map[string]any{"total": 10}))
+	if c.KeyOf(plain) != "" {
+		t.Error("expected event without data subject key to stay unencrypted")
+	}
+
+	if _, err := NewCipher(ks, "subject.x"); err == nil {
+		t.Error("expected error for invalid data path")
+	}
+}
+
+func TestCipher_RejectsMovedCiphertext(t *testing.T) {
+	ks, _ := NewKeyStore("")
+	c, _ := NewCipher(ks, KeyBySubject)
+
+	sealed, _ := c.Seal(newEvent("/users/1", map[string]any{"email": "john@example.com"}))
+	other := newEvent("/users/1", nil)
+	other.Data = sealed.Data
+
+	if _, ok := c.Open(other).Data.(Erased); !ok {
+		t.Error("expected ciphertext of another event not to open")
+	}
+}
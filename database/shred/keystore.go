@@ -0,0 +1,144 @@
+package shred
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrErased is returned for keys that were destroyed by an erasure request.
+var ErrErased = errors.New("key was erased")
+
+// KeyStore holds one AES-256 key per data subject. Erasing a key destroys it for good and
+// records when it was erased. With a data directory the keys are kept in keys.json, which must
+// be protected and backed up separately from the events.
+type KeyStore struct {
+	mu       sync.Mutex
+	filePath string
+	keys     map[string][]byte
+	erased   map[string]time.Time
+}
+
+// keyStoreFile is the persisted form of the key store.
+type keyStoreFile struct {
+	Keys   map[string][]byte    `json:"keys"`
+	Erased map[string]time.Time `json:"erased"`
+}
+
+// NewKeyStore opens the key store in keys.json in the data directory, or creates it if it does not exist.
+// An empty data directory creates a key store that only lives in memory.
+func NewKeyStore(dataDir string) (*KeyStore, error) {
+	ks := &KeyStore{keys: make(map[string][]byte), erased: make(map[string]time.Time)}
+	if dataDir == "" {
+		return ks, nil
+	}
+
+	ks.filePath = filepath.Join(dataDir, "keys.json")
+	data, err := os.ReadFile(ks.filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return ks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file keyStoreFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	for id, key := range file.Keys {
+		ks.keys[id] = key
+	}
+	for id, t := range file.Erased {
+		ks.erased[id] = t
+	}
+
+	return ks, nil
+}
+
+// Key returns the key of the data subject. If create is true, a missing key is generated and stored.
+// It returns ErrErased if the key was erased.
+func (ks *KeyStore) Key(id string, create bool) ([]byte, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if _, erased := ks.erased[id]; erased {
+		return nil, ErrErased
+	}
+
+	if key, exists := ks.keys[id]; exists {
+		return key, nil
+	}
+	if !create {
+		return nil, errors.New("key not found")
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	ks.keys[id] = key
+
+	if err := ks.persist(); err != nil {
+		delete(ks.keys, id)
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// Erase destroys the key of the data subject. Erasing a key that does not exist records the erasure,
+// so no key can be created for the data subject afterwards.
+func (ks *KeyStore) Erase(id string) (time.Time, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if t, erased := ks.erased[id]; erased {
+		return t, nil
+	}
+
+	key := ks.keys[id]
+	erasedAt := time.Now().UTC()
+	delete(ks.keys, id)
+	ks.erased[id] = erasedAt
+
+	if err := ks.persist(); err != nil {
+		ks.keys[id] = key
+		delete(ks.erased, id)
+		return time.Time{}, err
+	}
+
+	return erasedAt, nil
+}
+
+// ErasedAt returns when the key of the data subject was erased.
+func (ks *KeyStore) ErasedAt(id string) (time.Time, bool) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	t, erased := ks.erased[id]
+	return t, erased
+}
+
+// persist replaces keys.json atomically. The caller must hold the lock.
+func (ks *KeyStore) persist() error {
+	if ks.filePath == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(keyStoreFile{Keys: ks.keys, Erased: ks.erased})
+	if err != nil {
+		return err
+	}
+
+	tmpPath := ks.filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, ks.filePath)
+}
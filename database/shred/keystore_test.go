@@ -0,0 +1,70 @@
+package shred
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestKeyStore_CreateAndReload(t *testing.T) {
+	dataDir := t.TempDir()
+	ks, err := NewKeyStore(dataDir)
+	if err != nil {
+		t.Fatalf("NewKeyStore failed: %v", err)
+	}
+
+	if _, err := ks.Key("/users/1", false); err == nil {
+		t.Error("expected error for missing key without create")
+	}
+
+	key, err := ks.Key("/users/1", true)
+	if err != nil || len(key) != 32 {
+		t.Fatalf("expected 32 byte key, got %d bytes and error %v", len(key), err)
+	}
+
+	reloaded, err := NewKeyStore(dataDir)
+	if err != nil {
+		t.Fatalf("NewKeyStore failed: %v", err)
+	}
+	again, err := reloaded.Key("/users/1", false)
+	if err != nil || string(again) != string(key) {
+		t.Errorf("expected reloaded key to match, got error %v", err)
+	}
+}
+
+func TestKeyStore_Erase(t *testing.T) {
+	dataDir := t.TempDir()
+	ks, _ := NewKeyStore(dataDir)
+	ks.Key("/users/1", true)
+
+	erasedAt, err := ks.Erase("/users/1")
+	if err != nil || erasedAt.IsZero() {
+		t.Fatalf("Erase failed: %v", err)
+	}
+
+	if _, err := ks.Key("/users/1", true); !errors.Is(err, ErrErased) {
+		t.Errorf("expected ErrErased, got %v", err)
+	}
+
+	again, _ := ks.Erase("/users/1")
+	if !again.Equal(erasedAt) {
+		t.Error("expected erasing twice to keep the first erasure time")
+	}
+
+	reloaded, _ := NewKeyStore(dataDir)
+	if _, erased := reloaded.ErasedAt("/users/1"); !erased {
+		t.Error("expected erasure to be persisted")
+	}
+	if _, err := reloaded.Key("/users/1", false); !errors.Is(err, ErrErased) {
+		t.Errorf("expected ErrErased after reload, got %v", err)
+	}
+}
+
+func TestKeyStore_InMemory(t *testing.T) {
+	ks, err := NewKeyStore("")
+	if err != nil {
+		t.Fatalf("NewKeyStore failed: %v", err)
+	}
+	if _, err := ks.Key("/users/1", true); err != nil {
+		t.Errorf("Key failed: %v", err)
+	}
+}
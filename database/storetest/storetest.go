@@ -0,0 +1,381 @@
+// Package storetest provides a conformance test suite for implementations of database.Store.
+package storetest
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nicograef/cloudevents/database/database"
+	"github.com/nicograef/cloudevents/event"
+)
+
+// Run tests the store returned by newStore against the contract of database.Store.
+// newStore must return a new, empty store for every call; Run closes it at the end of each test.
+func Run(t *testing.T, newStore func(t *testing.T) database.Store) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s database.Store)
+	}{
+		{"AppendAndGet", testAppendAndGet},
+		{"ScanInTimeOrder", testScanInTimeOrder},
+		{"ScanByTypeAndSubject", testScanByTypeAndSubject},
+		{"ScanTimeRange", testScanTimeRange},
+		{"ScanStops", testScanStops},
+		{"ScanSubject", testScanSubject},
+		{"ScanLog", testScanLog},
+		{"SkipTo", testSkipTo},
+		{"Subjects", testSubjects},
+		{"Delete", testDelete},
+		{"AppendAfterDelete", testAppendAfterDelete},
+		{"Reset", testReset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(t)
+			defer func() {
+				if err := s.Close(); err != nil {
+					t.Errorf("Close failed: %v", err)
+				}
+			}()
+
+			tt.test(t, s)
+		})
+	}
+}
+
+var base = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// newEvent returns a valid event at base plus the given number of minutes.
+func newEvent(eventType, subject string, minutes int) event.Event {
+	return event.Event{
+		ID:      uuid.New(),
+		Source:  "https://example.com",
+		Type:    eventType,
+		Subject: subject,
+		Time:    base.Add(time.Duration(minutes) * time.Minute),
+		Data:    map[string]any{"minutes": float64(minutes)},
+	}
+}
+
+func appendAll(t *testing.T, s database.Store, events ...event.Event) {
+	t.Helper()
+	for _, e := range events {
+		if err := s.Append(e); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+}
+
+func scan(t *testing.T, s database.Store, f database.Filter) []uuid.UUID {
+	t.Helper()
+	ids := []uuid.UUID{}
+	if err := s.Scan(f, func(e event.Event) bool {
+		ids = append(ids, e.ID)
+		return true
+	}); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	return ids
+}
+
+func scanSubject(t *testing.T, s database.Store, subject string, skip int) []uuid.UUID {
+	t.Helper()
+	ids := []uuid.UUID{}
+	if err := s.ScanSubject(subject, skip, func(e event.Event) bool {
+		ids = append(ids, e.ID)
+		return true
+	}); err != nil {
+		t.Fatalf("ScanSubject failed: %v", err)
+	}
+	return ids
+}
+
+func scanLog(t *testing.T, s database.Store, position int) []database.Record {
+	t.Helper()
+	records := []database.Record{}
+	if err := s.ScanLog(position, func(r database.Record) bool {
+		records = append(records, r)
+		return true
+	}); err != nil {
+		t.Fatalf("ScanLog failed: %v", err)
+	}
+	return records
+}
+
+func ids(events ...event.Event) []uuid.UUID {
+	ids := make([]uuid.UUID, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func expectIDs(t *testing.T, name string, got, want []uuid.UUID) {
+	t.Helper()
+	if !slices.Equal(got, want) {
+		t.Errorf("%s: expected %v, got %v", name, want, got)
+	}
+}
+
+func expectCounts(t *testing.T, s database.Store, head, count int) {
+	t.Helper()
+	if got, err := s.Head(); err != nil || got != head {
+		t.Errorf("expected head %d, got %d (error %v)", head, got, err)
+	}
+	if got, err := s.Count(); err != nil || got != count {
+		t.Errorf("expected count %d, got %d (error %v)", count, got, err)
+	}
+}
+
+func testAppendAndGet(t *testing.T, s database.Store) {
+	expectCounts(t, s, 0, 0)
+
+	e := newEvent("user.registered", "/users/1", 0)
+	appendAll(t, s, e)
+	expectCounts(t, s, 1, 1)
+
+	got, exists, err := s.Get(database.KeyOf(e))
+	if err != nil || !exists {
+		t.Fatalf("expected event to be stored, got exists=%v and error %v", exists, err)
+	}
+	if got.ID != e.ID || got.Source != e.Source || got.Type != e.Type || got.Subject != e.Subject || !got.Time.Equal(e.Time) {
+		t.Errorf("expected %+v, got %+v", e, got)
+	}
+	if data, ok := got.Data.(map[string]any); !ok || data["minutes"] != float64(0) {
+		t.Errorf("expected data to be stored, got %#v", got.Data)
+	}
+
+	if _, exists, err := s.Get(database.EventKey{Source: e.Source, ID: uuid.New()}); exists || err != nil {
+		t.Errorf("expected unknown ID not to exist, got exists=%v and error %v", exists, err)
+	}
+	if _, exists, err := s.Get(database.EventKey{Source: "https://other.example.com", ID: e.ID}); exists || err != nil {
+		t.Errorf("expected the ID of another source not to exist, got exists=%v and error %v", exists, err)
+	}
+
+	// Events are unique by source and ID, so another source may use the same ID.
+	other := newEvent("user.registered", "/users/2", 1)
+	other.ID = e.ID
+	other.Source = "https://other.example.com"
+	appendAll(t, s, other)
+	expectCounts(t, s, 2, 2)
+
+	if got, exists, err := s.Get(database.KeyOf(other)); err != nil || !exists || got.Subject != other.Subject {
+		t.Errorf("expected the event of the other source, got %+v, exists=%v and error %v", got, exists, err)
+	}
+	if got, _, _ := s.Get(database.KeyOf(e)); got.Subject != e.Subject {
+		t.Errorf("expected the first event to be unchanged, got %+v", got)
+	}
+}
+
+func testScanInTimeOrder(t *testing.T, s database.Store) {
+	late := newEvent("user.login", "/users/1", 10)
+	early := newEvent("user.login", "/users/1", 0)
+	sameAsLate := newEvent("user.login", "/users/1", 10)
+	appendAll(t, s, late, early, sameAsLate)
+
+	expectIDs(t, "all events", scan(t, s, database.Filter{}), ids(early, late, sameAsLate))
+}
+
+func testScanByTypeAndSubject(t *testing.T, s database.Store) {
+	a := newEvent("user.registered", "/users/1", 0)
+	b := newEvent("user.login", "/users/1", 1)
+	c := newEvent("user.login", "/users/2", 2)
+	appendAll(t, s, a, b, c)
+
+	expectIDs(t, "by type", scan(t, s, database.Filter{Type: "user.login"}), ids(b, c))
+	expectIDs(t, "by subject", scan(t, s, database.Filter{Subject: "/users/1"}), ids(a, b))
+	expectIDs(t, "by type and subject", scan(t, s, database.Filter{Type: "user.login", Subject: "/users/1"}), ids(b))
+	expectIDs(t, "unknown type", scan(t, s, database.Filter{Type: "user.deleted"}), []uuid.UUID{})
+}
+
+func testScanTimeRange(t *testing.T, s database.Store) {
+	a := newEvent("user.login", "/users/1", 0)
+	b := newEvent("user.login", "/users/1", 5)
+	c := newEvent("user.login", "/users/2", 10)
+	appendAll(t, s, a, b, c)
+
+	r := database.TimeRange{From: b.Time, To: c.Time}
+	expectIDs(t, "inclusive range", scan(t, s, database.Filter{Range: r}), ids(b, c))
+	expectIDs(t, "open start", scan(t, s, database.Filter{Range: database.TimeRange{To: b.Time}}), ids(a, b))
+	expectIDs(t, "range by type", scan(t, s, database.Filter{Type: "user.login", Range: database.TimeRange{From: c.Time}}), ids(c))
+	expectIDs(t, "range by subject", scan(t, s, database.Filter{Subject: "/users/1", Range: r}), ids(b))
+	expectIDs(t, "empty range", scan(t, s, database.Filter{Range: database.TimeRange{From: c.Time.Add(time.Minute)}}), []uuid.UUID{})
+}
+
+func testScanStops(t *testing.T, s database.Store) {
+	appendAll(t, s, newEvent("user.login", "/users/1", 0), newEvent("user.login", "/users/1", 1), newEvent("user.login", "/users/1", 2))
+
+	calls := 0
+	stop := func(event.Event) bool { calls++; return false }
+	s.Scan(database.Filter{}, stop)
+	s.ScanSubject("/users/1", 0, stop)
+	s.ScanLog(0, func(database.Record) bool { calls++; return false })
+
+	if calls != 3 {
+		t.Errorf("expected every scan to stop after the first event, got %d calls", calls)
+	}
+}
+
+func testScanSubject(t *testing.T, s database.Store) {
+	// Append order differs from time order
+	a := newEvent("user.login", "/users/1", 10)
+	b := newEvent("user.login", "/users/2", 5)
+	c := newEvent("user.login", "/users/1", 0)
+	d := newEvent("user.login", "/users/1", 20)
+	appendAll(t, s, a, b, c, d)
+
+	expectIDs(t, "append order", scanSubject(t, s, "/users/1", 0), ids(a, c, d))
+	expectIDs(t, "skip", scanSubject(t, s, "/users/1", 2), ids(d))
+	expectIDs(t, "skip all", scanSubject(t, s, "/users/1", 5), []uuid.UUID{})
+	expectIDs(t, "unknown subject", scanSubject(t, s, "/users/3", 0), []uuid.UUID{})
+
+	if count, err := s.CountSubject("/users/1"); err != nil || count != 3 {
+		t.Errorf("expected 3 events of the subject, got %d (error %v)", count, err)
+	}
+	if count, err := s.CountSubject("/users/3"); err != nil || count != 0 {
+		t.Errorf("expected no events of an unknown subject, got %d (error %v)", count, err)
+	}
+}
+
+func testScanLog(t *testing.T, s database.Store) {
+	a := newEvent("user.login", "/users/1", 10)
+	b := newEvent("user.login", "/users/2", 0)
+	c := newEvent("user.login", "/users/1", 5)
+	appendAll(t, s, a, b, c)
+
+	records := scanLog(t, s, 0)
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	for i, e := range []event.Event{a, b, c} {
+		if records[i].Position != i+1 || records[i].Event.ID != e.ID {
+			t.Errorf("expected %s at position %d, got %s at %d", e.ID, i+1, records[i].Event.ID, records[i].Position)
+		}
+	}
+
+	if records := scanLog(t, s, 2); len(records) != 1 || records[0].Position != 3 {
+		t.Errorf("expected the record at position 3, got %+v", records)
+	}
+	if records := scanLog(t, s, 3); len(records) != 0 {
+		t.Errorf("expected no records after the head, got %+v", records)
+	}
+}
+
+func testSkipTo(t *testing.T, s database.Store) {
+	a := newEvent("user.login", "/users/1", 0)
+	b := newEvent("user.login", "/users/1", 1)
+	appendAll(t, s, a)
+
+	if err := s.SkipTo(3); err != nil {
+		t.Fatalf("SkipTo failed: %v", err)
+	}
+	appendAll(t, s, b)
+	if err := s.SkipTo(2); err != nil {
+		t.Fatalf("SkipTo failed: %v", err)
+	}
+
+	expectCounts(t, s, 4, 2)
+	records := scanLog(t, s, 0)
+	if len(records) != 2 || records[0].Position != 1 || records[1].Position != 4 {
+		t.Errorf("expected the events at positions 1 and 4, got %+v", records)
+	}
+	expectIDs(t, "by subject", scanSubject(t, s, "/users/1", 0), ids(a, b))
+
+	if err := s.SkipTo(6); err != nil {
+		t.Fatalf("SkipTo failed: %v", err)
+	}
+	expectCounts(t, s, 6, 2)
+}
+
+func testSubjects(t *testing.T, s database.Store) {
+	appendAll(t, s, newEvent("user.login", "/users/1", 0), newEvent("user.login", "/users/2", 1), newEvent("user.login", "/users/1", 2))
+
+	subjects, err := s.Subjects()
+	if err != nil {
+		t.Fatalf("Subjects failed: %v", err)
+	}
+	slices.Sort(subjects)
+	if !slices.Equal(subjects, []string{"/users/1", "/users/2"}) {
+		t.Errorf("expected both subjects once, got %v", subjects)
+	}
+}
+
+func testDelete(t *testing.T, s database.Store) {
+	a := newEvent("user.registered", "/users/1", 0)
+	b := newEvent("user.login", "/users/2", 1)
+	c := newEvent("user.login", "/users/1", 2)
+	appendAll(t, s, a, b, c)
+
+	if err := s.Delete([]database.EventKey{database.KeyOf(a), database.KeyOf(b)}); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	// Positions are kept
+	expectCounts(t, s, 3, 1)
+	if records := scanLog(t, s, 0); len(records) != 1 || records[0].Position != 3 {
+		t.Errorf("expected the remaining event at position 3, got %+v", records)
+	}
+
+	if _, exists, _ := s.Get(database.KeyOf(a)); exists {
+		t.Error("expected deleted event not to exist")
+	}
+	expectIDs(t, "all events", scan(t, s, database.Filter{}), ids(c))
+	expectIDs(t, "by type", scan(t, s, database.Filter{Type: "user.registered"}), []uuid.UUID{})
+	expectIDs(t, "by subject", scanSubject(t, s, "/users/1", 0), ids(c))
+	if subjects, _ := s.Subjects(); !slices.Equal(subjects, []string{"/users/1"}) {
+		t.Errorf("expected only the subject with events, got %v", subjects)
+	}
+
+	// New events get the next position
+	d := newEvent("user.login", "/users/2", 3)
+	appendAll(t, s, d)
+	if records := scanLog(t, s, 3); len(records) != 1 || records[0].Position != 4 {
+		t.Errorf("expected the new event at position 4, got %+v", records)
+	}
+}
+
+func testAppendAfterDelete(t *testing.T, s database.Store) {
+	a := newEvent("user.login", "/users/1", 0)
+	b := newEvent("user.login", "/users/1", 1)
+	appendAll(t, s, a, b)
+
+	if err := s.Delete([]database.EventKey{database.KeyOf(a)}); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	appendAll(t, s, a)
+
+	expectCounts(t, s, 3, 2)
+	records := scanLog(t, s, 0)
+	if len(records) != 2 || records[0].Position != 2 || records[1].Position != 3 || records[1].Event.ID != a.ID {
+		t.Errorf("expected the event appended again only at position 3, got %+v", records)
+	}
+	expectIDs(t, "all events", scan(t, s, database.Filter{}), ids(a, b))
+	expectIDs(t, "by subject", scanSubject(t, s, "/users/1", 0), ids(b, a))
+	if n, _ := s.CountSubject("/users/1"); n != 2 {
+		t.Errorf("expected 2 events of the subject, got %d", n)
+	}
+}
+
+func testReset(t *testing.T, s database.Store) {
+	e := newEvent("user.login", "/users/1", 0)
+	appendAll(t, s, e, newEvent("user.login", "/users/2", 1))
+
+	if err := s.Reset(); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+
+	expectCounts(t, s, 0, 0)
+	expectIDs(t, "all events", scan(t, s, database.Filter{}), []uuid.UUID{})
+	if subjects, _ := s.Subjects(); len(subjects) != 0 {
+		t.Errorf("expected no subjects, got %v", subjects)
+	}
+
+	// The same event can be appended again at position 1
+	appendAll(t, s, e)
+	if records := scanLog(t, s, 0); len(records) != 1 || records[0].Position != 1 {
+		t.Errorf("expected the event at position 1, got %+v", records)
+	}
+}
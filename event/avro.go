@@ -0,0 +1,695 @@
+package event
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ContentTypeAvro is the media type of events in the CloudEvents Avro format.
+const ContentTypeAvro = "application/cloudevents+avro"
+
+// AvroSchema is the Avro schema of the CloudEvents Avro format. It is written into Avro container files.
+const AvroSchema = `{"namespace":"io.cloudevents","type":"record","name":"CloudEvent","version":"1.0","doc":"Avro Event Format for CloudEvents","fields":[{"name":"attribute","type":{"type":"map","values":["null","boolean","int","string","bytes"]}},{"name":"data","type":["bytes","null","boolean",{"type":"map","values":["null","boolean",{"type":"record","name":"CloudEventData","doc":"Representation of a JSON Value","fields":[{"name":"value","type":{"type":"map","values":["null","boolean",{"type":"map","values":"CloudEventData"},{"type":"array","items":"CloudEventData"},"double","string"]}}]},"double","string"]},{"type":"array","items":"CloudEventData"},"double","string"]}]}`
+
+// Branches of the union of the attribute values.
+const (
+	avroAttrNull    = 0
+	avroAttrBoolean = 1
+	avroAttrInt     = 2
+	avroAttrString  = 3
+	avroAttrBytes   = 4
+)
+
+// Branches of the union of the data field.
+const (
+	avroDataBytes   = 0
+	avroDataNull    = 1
+	avroDataBoolean = 2
+	avroDataMap     = 3
+	avroDataArray   = 4
+	avroDataDouble  = 5
+	avroDataString  = 6
+)
+
+// Branches of the union of the values of a JSON object in the data field.
+const (
+	avroMemberNull    = 0
+	avroMemberBoolean = 1
+	avroMemberRecord  = 2
+	avroMemberDouble  = 3
+	avroMemberString  = 4
+)
+
+// Branches of the union of the value of a CloudEventData record.
+const (
+	avroValueNull    = 0
+	avroValueBoolean = 1
+	avroValueMap     = 2
+	avroValueArray   = 3
+	avroValueDouble  = 4
+	avroValueString  = 5
+)
+
+// MarshalAvro encodes the event as a CloudEvent record of the Avro format in the Avro binary encoding.
+// All attributes are entries of the attribute map: time, URIs and timestamps as strings, extensions of the
+// other types as boolean, int, string or bytes. Binary payloads ([]byte or ProtoData) are encoded as bytes,
+// text payloads as string and JSON payloads as the JSON value (see AvroSchema). A CloudEventData record
+// holds a single JSON value under the empty key of its value map.
+func MarshalAvro(e Event) ([]byte, error) {
+	attributes := map[string]any{
+		"specversion": SpecVersion,
+		"id":          e.ID.String(),
+		"type":        e.Type,
+		"source":      e.Source,
+	}
+	if !e.Time.IsZero() {
+		attributes["time"] = e.Time.Format(time.RFC3339Nano)
+	}
+	if e.Subject != "" {
+		attributes["subject"] = e.Subject
+	}
+	if e.DataContentType != "" {
+		attributes["datacontenttype"] = e.DataContentType
+	}
+	if e.DataSchema != "" {
+		attributes["dataschema"] = e.DataSchema
+	}
+	for name, value := range e.Extensions {
+		if reservedAttributes[name] {
+			return nil, fmt.Errorf("invalid event extension name %q", name)
+		}
+		v, err := normalizeExtension(value)
+		if err != nil {
+			return nil, fmt.Errorf("event extension %s: %w", name, err)
+		}
+		switch v.(type) {
+		case bool, int32, []byte:
+			attributes[name] = v
+		default:
+			attributes[name] = extensionString(v)
+		}
+	}
+
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b := appendAvroLong(nil, int64(len(names)))
+	for _, name := range names {
+		b = appendAvroString(b, name)
+		switch v := attributes[name].(type) {
+		case bool:
+			b = appendAvroBoolean(appendAvroLong(b, avroAttrBoolean), v)
+		case int32:
+			b = appendAvroLong(appendAvroLong(b, avroAttrInt), int64(v))
+		case []byte:
+			b = appendAvroBytes(appendAvroLong(b, avroAttrBytes), v)
+		case string:
+			b = appendAvroString(appendAvroLong(b, avroAttrString), v)
+		}
+	}
+	b = appendAvroLong(b, 0)
+
+	if data, binary := binaryData(e.Data); binary {
+		return appendAvroBytes(appendAvroLong(b, avroDataBytes), data), nil
+	}
+	if text, ok := e.Data.(string); ok && !IsJSONContentType(e.DataContentType) {
+		return appendAvroString(appendAvroLong(b, avroDataString), text), nil
+	}
+
+	raw, err := e.DataBytes()
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("invalid event data: %w", err)
+	}
+
+	switch v := value.(type) {
+	case nil:
+		b = appendAvroLong(b, avroDataNull)
+	case bool:
+		b = appendAvroBoolean(appendAvroLong(b, avroDataBoolean), v)
+	case float64:
+		b = appendAvroDouble(appendAvroLong(b, avroDataDouble), v)
+	case string:
+		b = appendAvroString(appendAvroLong(b, avroDataString), v)
+	case []any:
+		b = appendAvroLong(b, avroDataArray)
+		b = appendAvroArray(b, v)
+	case map[string]any:
+		b = appendAvroLong(b, avroDataMap)
+		b = appendAvroLong(b, int64(len(v)))
+		for _, key := range sortedKeys(v) {
+			b = appendAvroString(b, key)
+			switch member := v[key].(type) {
+			case nil:
+				b = appendAvroLong(b, avroMemberNull)
+			case bool:
+				b = appendAvroBoolean(appendAvroLong(b, avroMemberBoolean), member)
+			case float64:
+				b = appendAvroDouble(appendAvroLong(b, avroMemberDouble), member)
+			case string:
+				b = appendAvroString(appendAvroLong(b, avroMemberString), member)
+			default:
+				b = appendAvroRecord(appendAvroLong(b, avroMemberRecord), member)
+			}
+		}
+		if len(v) > 0 {
+			b = appendAvroLong(b, 0)
+		}
+	}
+
+	return b, nil
+}
+
+// appendAvroRecord appends a CloudEventData record holding the JSON value under the empty key.
+func appendAvroRecord(b []byte, value any) []byte {
+	b = appendAvroLong(b, 1)
+	b = appendAvroString(b, "")
+	switch v := value.(type) {
+	case nil:
+		b = appendAvroLong(b, avroValueNull)
+	case bool:
+		b = appendAvroBoolean(appendAvroLong(b, avroValueBoolean), v)
+	case float64:
+		b = appendAvroDouble(appendAvroLong(b, avroValueDouble), v)
+	case string:
+		b = appendAvroString(appendAvroLong(b, avroValueString), v)
+	case []any:
+		b = appendAvroArray(appendAvroLong(b, avroValueArray), v)
+	case map[string]any:
+		b = appendAvroLong(b, avroValueMap)
+		b = appendAvroLong(b, int64(len(v)))
+		for _, key := range sortedKeys(v) {
+			b = appendAvroRecord(appendAvroString(b, key), v[key])
+		}
+		if len(v) > 0 {
+			b = appendAvroLong(b, 0)
+		}
+	}
+
+	return appendAvroLong(b, 0)
+}
+
+// appendAvroArray appends an array of CloudEventData records.
+func appendAvroArray(b []byte, items []any) []byte {
+	b = appendAvroLong(b, int64(len(items)))
+	for _, item := range items {
+		b = appendAvroRecord(b, item)
+	}
+	if len(items) > 0 {
+		b = appendAvroLong(b, 0)
+	}
+
+	return b
+}
+
+// UnmarshalAvro decodes an event from a CloudEvent record of the Avro format in the Avro binary encoding.
+// Attributes that are not standard attributes become extensions. bytes data is decoded as a []byte payload,
+// string data as a string and JSON values as by encoding/json. The event is not validated.
+func UnmarshalAvro(b []byte) (*Event, error) {
+	r := &avroReader{b: b}
+	e, err := r.event()
+	if err != nil {
+		return nil, err
+	}
+	if len(r.b) > 0 {
+		return nil, errors.New("trailing bytes after the Avro event")
+	}
+
+	return e, nil
+}
+
+// event reads a CloudEvent record.
+func (r *avroReader) event() (*Event, error) {
+	var e Event
+	var specVersion string
+
+	err := r.mapEntries(func(name string) error {
+		var value any
+		switch branch := r.long(); branch {
+		case avroAttrNull:
+			return nil
+		case avroAttrBoolean:
+			value = r.boolean()
+		case avroAttrInt:
+			n := r.long()
+			if n < math.MinInt32 || n > math.MaxInt32 {
+				return fmt.Errorf("event attribute %s is out of the int range", name)
+			}
+			value = int32(n)
+		case avroAttrString:
+			value = r.string()
+		case avroAttrBytes:
+			value = r.bytes()
+		default:
+			return fmt.Errorf("invalid union branch %d of event attribute %s", branch, name)
+		}
+		if r.err != nil {
+			return r.err
+		}
+
+		s, isString := value.(string)
+		switch name {
+		case "specversion", "id", "time", "type", "source", "subject", "datacontenttype", "dataschema":
+			if !isString {
+				return fmt.Errorf("event attribute %s must be a string", name)
+			}
+		}
+		switch name {
+		case "specversion":
+			specVersion = s
+		case "id":
+			id, err := uuid.Parse(s)
+			if err != nil {
+				return fmt.Errorf("invalid event id: %w", err)
+			}
+			e.ID = id
+		case "time":
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return errors.New("invalid event time: must be an RFC 3339 timestamp")
+			}
+			e.Time = t
+		case "type":
+			e.Type = s
+		case "source":
+			e.Source = s
+		case "subject":
+			e.Subject = s
+		case "datacontenttype":
+			e.DataContentType = s
+		case "dataschema":
+			e.DataSchema = s
+		default:
+			if err := validateExtensionName(name); err != nil {
+				return err
+			}
+			if e.Extensions == nil {
+				e.Extensions = make(map[string]any)
+			}
+			e.Extensions[name] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if specVersion != SpecVersion {
+		return nil, fmt.Errorf("unsupported event specversion %q", specVersion)
+	}
+
+	switch branch := r.long(); branch {
+	case avroDataBytes:
+		e.Data = r.bytes()
+	case avroDataNull:
+	case avroDataBoolean:
+		e.Data = r.boolean()
+	case avroDataDouble:
+		e.Data = r.double()
+	case avroDataString:
+		e.Data = r.string()
+	case avroDataArray:
+		e.Data, err = r.array()
+	case avroDataMap:
+		object := map[string]any{}
+		err = r.mapEntries(func(key string) error {
+			var member any
+			switch branch := r.long(); branch {
+			case avroMemberNull:
+			case avroMemberBoolean:
+				member = r.boolean()
+			case avroMemberDouble:
+				member = r.double()
+			case avroMemberString:
+				member = r.string()
+			case avroMemberRecord:
+				var err error
+				if member, err = r.record(); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("invalid union branch %d of event data member %s", branch, key)
+			}
+			object[key] = member
+			return r.err
+		})
+		e.Data = object
+	default:
+		err = fmt.Errorf("invalid union branch %d of event data", branch)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return &e, nil
+}
+
+// record reads a CloudEventData record. A record with only the empty key holds a single JSON value,
+// any other record is read as a JSON object with the entries as members.
+func (r *avroReader) record() (any, error) {
+	entries := map[string]any{}
+	err := r.mapEntries(func(key string) error {
+		var value any
+		var err error
+		switch branch := r.long(); branch {
+		case avroValueNull:
+		case avroValueBoolean:
+			value = r.boolean()
+		case avroValueDouble:
+			value = r.double()
+		case avroValueString:
+			value = r.string()
+		case avroValueArray:
+			value, err = r.array()
+		case avroValueMap:
+			object := map[string]any{}
+			err = r.mapEntries(func(key string) error {
+				member, err := r.record()
+				object[key] = member
+				return err
+			})
+			value = object
+		default:
+			return fmt.Errorf("invalid union branch %d of event data value", branch)
+		}
+		entries[key] = value
+		if err != nil {
+			return err
+		}
+		return r.err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if value, single := entries[""]; single && len(entries) == 1 {
+		return value, nil
+	}
+
+	return entries, nil
+}
+
+// array reads an array of CloudEventData records.
+func (r *avroReader) array() ([]any, error) {
+	items := []any{}
+	err := r.blocks(func() error {
+		item, err := r.record()
+		items = append(items, item)
+		return err
+	})
+
+	return items, err
+}
+
+// avroReader reads values of the Avro binary encoding. The first error is kept in err;
+// after an error all reads return zero values.
+type avroReader struct {
+	b   []byte
+	err error
+}
+
+func (r *avroReader) fail(msg string) {
+	if r.err == nil {
+		r.err = errors.New(msg)
+	}
+	r.b = nil
+}
+
+func (r *avroReader) long() int64 {
+	if r.err != nil {
+		return 0
+	}
+	u, size := binary.Uvarint(r.b)
+	if size <= 0 {
+		r.fail("malformed Avro long")
+		return 0
+	}
+	r.b = r.b[size:]
+
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+func (r *avroReader) boolean() bool {
+	if r.err != nil {
+		return false
+	}
+	if len(r.b) < 1 || r.b[0] > 1 {
+		r.fail("malformed Avro boolean")
+		return false
+	}
+	v := r.b[0] == 1
+	r.b = r.b[1:]
+
+	return v
+}
+
+func (r *avroReader) double() float64 {
+	if r.err != nil {
+		return 0
+	}
+	if len(r.b) < 8 {
+		r.fail("malformed Avro double")
+		return 0
+	}
+	v := math.Float64frombits(binary.LittleEndian.Uint64(r.b))
+	r.b = r.b[8:]
+
+	return v
+}
+
+func (r *avroReader) bytes() []byte {
+	n := r.long()
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > int64(len(r.b)) {
+		r.fail("malformed Avro bytes length")
+		return nil
+	}
+	v := append([]byte{}, r.b[:n]...)
+	r.b = r.b[n:]
+
+	return v
+}
+
+func (r *avroReader) string() string {
+	return string(r.bytes())
+}
+
+// blocks calls fn for each item of an Avro array or map, which are encoded in blocks of items.
+func (r *avroReader) blocks(fn func() error) error {
+	for {
+		n := r.long()
+		if r.err != nil {
+			return r.err
+		}
+		if n == 0 {
+			return nil
+		}
+		if n < 0 {
+			// A negative count is followed by the size of the block in bytes
+			n = -n
+			r.long()
+		}
+		if n > int64(len(r.b)) {
+			r.fail("malformed Avro block count")
+			return r.err
+		}
+		for range n {
+			if err := fn(); err != nil {
+				return err
+			}
+			if r.err != nil {
+				return r.err
+			}
+		}
+	}
+}
+
+// mapEntries calls fn with the key of each entry of an Avro map; fn reads the value.
+func (r *avroReader) mapEntries(fn func(key string) error) error {
+	return r.blocks(func() error {
+		key := r.string()
+		if r.err != nil {
+			return r.err
+		}
+		return fn(key)
+	})
+}
+
+func appendAvroLong(b []byte, n int64) []byte {
+	return binary.AppendUvarint(b, uint64(n<<1)^uint64(n>>63))
+}
+
+func appendAvroBoolean(b []byte, v bool) []byte {
+	if v {
+		return append(b, 1)
+	}
+	return append(b, 0)
+}
+
+func appendAvroDouble(b []byte, v float64) []byte {
+	return binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+}
+
+func appendAvroBytes(b []byte, v []byte) []byte {
+	return append(appendAvroLong(b, int64(len(v))), v...)
+}
+
+func appendAvroString(b []byte, s string) []byte {
+	return append(appendAvroLong(b, int64(len(s))), s...)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// avroMagic starts every Avro object container file.
+var avroMagic = []byte{'O', 'b', 'j', 1}
+
+// avroBlockSize is the size in bytes after which AvroWriter writes a block.
+const avroBlockSize = 64 * 1024
+
+// AvroWriter writes events to an Avro object container file with the schema AvroSchema and no compression.
+// Events are buffered and written in blocks; Close writes the last block.
+type AvroWriter struct {
+	w     io.Writer
+	sync  [16]byte
+	block []byte
+	count int
+}
+
+// NewAvroWriter writes the header of an Avro object container file to w and returns a writer for its events.
+func NewAvroWriter(w io.Writer) (*AvroWriter, error) {
+	aw := &AvroWriter{w: w}
+	if _, err := rand.Read(aw.sync[:]); err != nil {
+		return nil, err
+	}
+
+	header := append([]byte{}, avroMagic...)
+	header = appendAvroLong(header, 2)
+	header = appendAvroBytes(appendAvroString(header, "avro.codec"), []byte("null"))
+	header = appendAvroBytes(appendAvroString(header, "avro.schema"), []byte(AvroSchema))
+	header = appendAvroLong(header, 0)
+	header = append(header, aw.sync[:]...)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return aw, nil
+}
+
+// Write adds the event to the current block and writes the block once it is large enough.
+func (aw *AvroWriter) Write(e Event) error {
+	b, err := MarshalAvro(e)
+	if err != nil {
+		return err
+	}
+	aw.block = append(aw.block, b...)
+	aw.count++
+
+	if len(aw.block) >= avroBlockSize {
+		return aw.Flush()
+	}
+
+	return nil
+}
+
+// Flush writes the buffered events as a block.
+func (aw *AvroWriter) Flush() error {
+	if aw.count == 0 {
+		return nil
+	}
+
+	b := appendAvroLong(nil, int64(aw.count))
+	b = appendAvroLong(b, int64(len(aw.block)))
+	b = append(b, aw.block...)
+	b = append(b, aw.sync[:]...)
+	aw.block, aw.count = aw.block[:0], 0
+
+	_, err := aw.w.Write(b)
+	return err
+}
+
+// Close writes the buffered events. It does not close the underlying writer.
+func (aw *AvroWriter) Close() error {
+	return aw.Flush()
+}
+
+// ReadAvroFile reads the events of an Avro object container file written with the schema AvroSchema.
+// Only uncompressed files (codec null) are supported.
+func ReadAvroFile(rd io.Reader) ([]Event, error) {
+	b, err := io.ReadAll(rd)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < len(avroMagic) || string(b[:len(avroMagic)]) != string(avroMagic) {
+		return nil, errors.New("not an Avro object container file")
+	}
+
+	r := &avroReader{b: b[len(avroMagic):]}
+	err = r.mapEntries(func(key string) error {
+		value := r.bytes()
+		if key == "avro.codec" && string(value) != "null" {
+			return fmt.Errorf("unsupported Avro codec %q", value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(r.b) < 16 {
+		return nil, errors.New("malformed Avro file header")
+	}
+	sync := string(r.b[:16])
+	r.b = r.b[16:]
+
+	events := []Event{}
+	for len(r.b) > 0 {
+		count, size := r.long(), r.long()
+		if r.err != nil {
+			return nil, r.err
+		}
+		if count < 0 || size < 0 || size+16 > int64(len(r.b)) {
+			return nil, errors.New("malformed Avro block")
+		}
+		block := &avroReader{b: r.b[:size]}
+		for range count {
+			e, err := block.event()
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, *e)
+		}
+		if len(block.b) > 0 || string(r.b[size:size+16]) != sync {
+			return nil, errors.New("malformed Avro block")
+		}
+		r.b = r.b[size+16:]
+	}
+
+	return events, nil
+}
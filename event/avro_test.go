@@ -0,0 +1,185 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAvro_RoundTripJSONData(t *testing.T) {
+	data := map[string]any{
+		"bookId":  "b-123",
+		"copies":  2.0,
+		"lent":    true,
+		"note":    nil,
+		"tags":    []any{"novel", 1.5, nil, []any{"nested"}},
+		"library": map[string]any{"id": 7.0, "branch": map[string]any{"name": "main"}, "empty": map[string]any{}},
+	}
+	e := newBookBorrowed(data)
+	e.DataSchema = "https://library.example.com/schemas/book-borrowed.json"
+
+	b, err := MarshalAvro(e)
+	if err != nil {
+		t.Fatalf("MarshalAvro failed: %v", err)
+	}
+	decoded, err := UnmarshalAvro(b)
+	if err != nil {
+		t.Fatalf("UnmarshalAvro failed: %v", err)
+	}
+
+	if decoded.ID != e.ID || !decoded.Time.Equal(e.Time) || decoded.Type != e.Type || decoded.Source != e.Source || decoded.Subject != e.Subject || decoded.DataSchema != e.DataSchema {
+		t.Errorf("expected %+v, got %+v", e, decoded)
+	}
+	if !reflect.DeepEqual(decoded.Data, data) {
+		t.Errorf("expected data %v, got %v", data, decoded.Data)
+	}
+	if err := decoded.Validate(); err != nil {
+		t.Errorf("expected a valid event, got %v", err)
+	}
+
+	// Scalar and array payloads
+	for _, data := range []any{"text", 3.0, false, []any{map[string]any{"a": 1.0}}} {
+		e.Data = data
+		b, _ := MarshalAvro(e)
+		if decoded, err := UnmarshalAvro(b); err != nil || !reflect.DeepEqual(decoded.Data, data) {
+			t.Errorf("expected data %v, got %+v, %v", data, decoded, err)
+		}
+	}
+}
+
+func TestAvro_RoundTripBinaryAndTextData(t *testing.T) {
+	e := newBookBorrowed([]byte{0x00, 0xff, 0x10})
+	e.DataContentType = "application/octet-stream"
+	b, err := MarshalAvro(e)
+	if err != nil {
+		t.Fatalf("MarshalAvro failed: %v", err)
+	}
+	if decoded, err := UnmarshalAvro(b); err != nil || !bytes.Equal(decoded.Data.([]byte), e.Data.([]byte)) || decoded.DataContentType != e.DataContentType {
+		t.Errorf("expected binary data, got %+v, %v", decoded, err)
+	}
+
+	e.Data, e.DataContentType = `<book id="b-123"/>`, "application/xml"
+	b, _ = MarshalAvro(e)
+	if decoded, err := UnmarshalAvro(b); err != nil || decoded.Data != e.Data {
+		t.Errorf("expected text data, got %+v, %v", decoded, err)
+	}
+
+	// Protobuf payloads are bytes
+	e.Data, e.DataContentType = ProtoData{TypeURL: "type.googleapis.com/library.BookBorrowed", Value: []byte{0x0a}}, "application/protobuf"
+	b, _ = MarshalAvro(e)
+	if decoded, err := UnmarshalAvro(b); err != nil || !bytes.Equal(decoded.Data.([]byte), []byte{0x0a}) {
+		t.Errorf("expected binary data, got %+v, %v", decoded, err)
+	}
+}
+
+func TestAvro_Extensions(t *testing.T) {
+	e := newBookBorrowed(map[string]any{"bookId": "b-123"})
+	e.Extensions = map[string]any{
+		"sampled":  true,
+		"priority": -3,
+		"trace":    "00-abc-01",
+		"blob":     []byte{1, 2, 3},
+		"origin":   &url.URL{Scheme: "https", Host: "library.example.com"},
+		"expires":  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := MarshalAvro(e)
+	if err != nil {
+		t.Fatalf("MarshalAvro failed: %v", err)
+	}
+	decoded, err := UnmarshalAvro(b)
+	if err != nil {
+		t.Fatalf("UnmarshalAvro failed: %v", err)
+	}
+
+	want := map[string]any{
+		"sampled":  true,
+		"priority": int32(-3),
+		"trace":    "00-abc-01",
+		"blob":     []byte{1, 2, 3},
+		"origin":   "https://library.example.com",
+		"expires":  "2026-01-02T03:04:05Z",
+	}
+	if !reflect.DeepEqual(decoded.Extensions, want) {
+		t.Errorf("expected extensions %v, got %v", want, decoded.Extensions)
+	}
+}
+
+func TestAvro_FromJSON(t *testing.T) {
+	s := `{"id":"6ba7b810-9dad-11d1-80b4-00c04fd430c8","type":"com.library.book.borrowed:v1","time":"2026-01-02T03:04:05Z","source":"https://library.example.com","subject":"/books/123","data":{"bookId":"b-123"},"traceparent":"00-abc-01","priority":2}`
+	var e Event
+	if err := json.Unmarshal([]byte(s), &e); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	b, err := MarshalAvro(e)
+	if err != nil {
+		t.Fatalf("MarshalAvro failed: %v", err)
+	}
+	decoded, err := UnmarshalAvro(b)
+	if err != nil {
+		t.Fatalf("UnmarshalAvro failed: %v", err)
+	}
+
+	j, _ := json.Marshal(decoded)
+	var got, want map[string]any
+	json.Unmarshal(j, &got)
+	json.Unmarshal([]byte(s), &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v after the Avro round trip, got %v", want, got)
+	}
+}
+
+func TestUnmarshalAvro_Invalid(t *testing.T) {
+	valid, _ := MarshalAvro(newBookBorrowed(map[string]any{"bookId": "b-123"}))
+
+	for name, b := range map[string][]byte{
+		"truncated":    valid[:len(valid)-1],
+		"trailing":     append(append([]byte(nil), valid...), 0),
+		"spec version": bytes.Replace(valid, []byte("1.0"), []byte("0.3"), 1),
+		"empty":        {},
+	} {
+		if _, err := UnmarshalAvro(b); err == nil {
+			t.Errorf("expected error for %s", name)
+		}
+	}
+}
+
+func TestAvroWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewAvroWriter(&buf)
+	if err != nil {
+		t.Fatalf("NewAvroWriter failed: %v", err)
+	}
+
+	first := newBookBorrowed(map[string]any{"bookId": "b-1"})
+	second := newBookBorrowed([]byte("cover"))
+	second.DataContentType = "image/png"
+	for _, e := range []Event{first, second} {
+		if err := w.Write(e); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if !bytes.HasPrefix(buf.Bytes(), []byte("Obj\x01")) || !bytes.Contains(buf.Bytes(), []byte(AvroSchema)) {
+		t.Errorf("expected an Avro container file with the schema")
+	}
+
+	events, err := ReadAvroFile(&buf)
+	if err != nil {
+		t.Fatalf("ReadAvroFile failed: %v", err)
+	}
+	if len(events) != 2 || events[0].ID != first.ID || string(events[1].Data.([]byte)) != "cover" {
+		t.Errorf("unexpected events %+v", events)
+	}
+
+	if _, err := ReadAvroFile(bytes.NewReader([]byte("{}"))); err == nil {
+		t.Error("expected error for a file that is not an Avro container file")
+	}
+}
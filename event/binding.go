@@ -0,0 +1,177 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HTTP headers of the binary content mode of the CloudEvents HTTP binding.
+const (
+	HeaderSpecVersion = "Ce-Specversion"
+	HeaderID          = "Ce-Id"
+	HeaderType        = "Ce-Type"
+	HeaderTime        = "Ce-Time"
+	HeaderSource      = "Ce-Source"
+	HeaderSubject     = "Ce-Subject"
+	HeaderDataSchema  = "Ce-Dataschema"
+)
+
+// SpecVersion is the CloudEvents specification version announced in binary content mode.
+const SpecVersion = "1.0"
+
+// EncodeHTTP returns the headers and body of an HTTP message carrying the event. Events with JSON data are sent
+// in structured content mode: the body is the JSON event and the Content-Type is application/json. Events with
+// binary or text data are sent in binary content mode: the body is the payload, the Content-Type is the
+// datacontenttype and the other attributes and extensions are Ce- headers.
+func EncodeHTTP(e Event) (http.Header, []byte, error) {
+	header := make(http.Header)
+
+	_, binary := binaryData(e.Data)
+	if IsJSONContentType(e.DataContentType) && !binary {
+		body, err := json.Marshal(e)
+		if err != nil {
+			return nil, nil, err
+		}
+		header.Set("Content-Type", "application/json")
+		return header, body, nil
+	}
+
+	body, err := e.DataBytes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	contentType := e.DataContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header.Set("Content-Type", contentType)
+	header.Set(HeaderSpecVersion, SpecVersion)
+	header.Set(HeaderID, e.ID.String())
+	header.Set(HeaderType, encodeHeaderValue(e.Type))
+	header.Set(HeaderTime, e.Time.Format(time.RFC3339Nano))
+	header.Set(HeaderSource, encodeHeaderValue(e.Source))
+	header.Set(HeaderSubject, encodeHeaderValue(e.Subject))
+	if e.DataSchema != "" {
+		header.Set(HeaderDataSchema, encodeHeaderValue(e.DataSchema))
+	}
+	for name, value := range e.Extensions {
+		if err := validateExtensionName(name); err != nil {
+			return nil, nil, err
+		}
+		v, err := normalizeExtension(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("event extension %s: %w", name, err)
+		}
+		header.Set("Ce-"+name, encodeHeaderValue(extensionString(v)))
+	}
+
+	return header, body, nil
+}
+
+// IsBinaryHTTP reports whether the headers carry an event in binary content mode.
+func IsBinaryHTTP(header http.Header) bool {
+	return header.Get(HeaderSpecVersion) != "" || header.Get(HeaderID) != ""
+}
+
+// IsJSONHTTP reports whether the headers carry an event in the structured content mode of the JSON format,
+// i.e. the message is not in binary content mode and its Content-Type is JSON or missing.
+func IsJSONHTTP(header http.Header) bool {
+	return !IsBinaryHTTP(header) && IsJSONContentType(header.Get("Content-Type"))
+}
+
+// DecodeHTTP decodes an event from the headers and body of an HTTP message in structured content mode,
+// in the JSON, protobuf or Avro format, or in binary content mode. The event is not validated.
+func DecodeHTTP(header http.Header, body []byte) (*Event, error) {
+	if !IsBinaryHTTP(header) {
+		switch mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type")); mediaType {
+		case ContentTypeProtobuf:
+			return UnmarshalProto(body)
+		case ContentTypeAvro:
+			return UnmarshalAvro(body)
+		}
+		var e Event
+		if err := json.Unmarshal(body, &e); err != nil {
+			return nil, err
+		}
+		return &e, nil
+	}
+
+	if v := header.Get(HeaderSpecVersion); v != SpecVersion {
+		return nil, fmt.Errorf("unsupported %s %q", HeaderSpecVersion, v)
+	}
+
+	id, err := uuid.Parse(header.Get(HeaderID))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", HeaderID, err)
+	}
+	t, err := time.Parse(time.RFC3339Nano, header.Get(HeaderTime))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: must be an RFC 3339 timestamp", HeaderTime)
+	}
+
+	e := Event{ID: id, Time: t}
+	for _, attr := range []struct {
+		name string
+		dest *string
+	}{{HeaderType, &e.Type}, {HeaderSource, &e.Source}, {HeaderSubject, &e.Subject}, {HeaderDataSchema, &e.DataSchema}} {
+		if *attr.dest, err = url.PathUnescape(header.Get(attr.name)); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", attr.name, err)
+		}
+	}
+
+	for name := range header {
+		if !strings.HasPrefix(name, "Ce-") {
+			continue
+		}
+		ext := strings.ToLower(strings.TrimPrefix(name, "Ce-"))
+		if reservedAttributes[ext] {
+			continue
+		}
+		if err := validateExtensionName(ext); err != nil {
+			return nil, err
+		}
+		value, err := url.PathUnescape(header.Get(name))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		if e.Extensions == nil {
+			e.Extensions = make(map[string]any)
+		}
+		e.Extensions[ext] = value
+	}
+
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		return nil, errors.New("missing Content-Type of the event data")
+	}
+	if err := e.SetDataBytes(contentType, body); err != nil {
+		return nil, fmt.Errorf("invalid event data: %w", err)
+	}
+
+	return &e, nil
+}
+
+// encodeHeaderValue percent-encodes the characters of an attribute value that are not printable ASCII,
+// as well as '"' and '%'.
+func encodeHeaderValue(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 || c > 0x7E || c == '"' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,126 @@
+package event
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func TestEncodeDecodeHTTP_Structured(t *testing.T) {
+	e := newBookBorrowed(map[string]any{"bookId": "b-123"})
+
+	header, body, err := EncodeHTTP(e)
+	if err != nil {
+		t.Fatalf("EncodeHTTP failed: %v", err)
+	}
+	if header.Get("Content-Type") != "application/json" || IsBinaryHTTP(header) {
+		t.Errorf("expected structured content mode, got %v", header)
+	}
+
+	decoded, err := DecodeHTTP(header, body)
+	if err != nil {
+		t.Fatalf("DecodeHTTP failed: %v", err)
+	}
+	if decoded.ID != e.ID || decoded.Data.(map[string]any)["bookId"] != "b-123" {
+		t.Errorf("unexpected event %+v", decoded)
+	}
+}
+
+func TestEncodeDecodeHTTP_Binary(t *testing.T) {
+	e := newBookBorrowed([]byte{0x0a, 0x05})
+	e.DataContentType = "application/protobuf"
+	e.Subject = `/books/"ä"`
+	e.DataSchema = "https://library.example.com/schemas/book.proto"
+
+	header, body, err := EncodeHTTP(e)
+	if err != nil {
+		t.Fatalf("EncodeHTTP failed: %v", err)
+	}
+	if header.Get("Content-Type") != "application/protobuf" || !bytes.Equal(body, []byte{0x0a, 0x05}) {
+		t.Errorf("expected the payload as body, got %v %v", header, body)
+	}
+	if header.Get(HeaderID) != e.ID.String() || header.Get(HeaderSubject) != "/books/%22%C3%A4%22" {
+		t.Errorf("unexpected headers %v", header)
+	}
+
+	decoded, err := DecodeHTTP(header, body)
+	if err != nil {
+		t.Fatalf("DecodeHTTP failed: %v", err)
+	}
+	if decoded.ID != e.ID || !decoded.Time.Equal(e.Time) || decoded.Type != e.Type || decoded.Source != e.Source || decoded.Subject != e.Subject || decoded.DataSchema != e.DataSchema {
+		t.Errorf("expected %+v, got %+v", e, decoded)
+	}
+	if data, ok := decoded.Data.([]byte); !ok || !bytes.Equal(data, body) {
+		t.Errorf("expected binary data, got %T", decoded.Data)
+	}
+
+	// Text data is sent verbatim
+	e.Data, e.DataContentType = "<book/>", "application/xml"
+	header, body, _ = EncodeHTTP(e)
+	if string(body) != "<book/>" || header.Get("Content-Type") != "application/xml" {
+		t.Errorf("expected verbatim text body, got %s", body)
+	}
+	if decoded, err := DecodeHTTP(header, body); err != nil || decoded.Data != "<book/>" {
+		t.Errorf("unexpected event %+v, %v", decoded, err)
+	}
+}
+
+func TestDecodeHTTP_Invalid(t *testing.T) {
+	e := newBookBorrowed([]byte("x"))
+	header, body, _ := EncodeHTTP(e)
+
+	for _, mutate := range []func(){
+		func() { header.Set(HeaderSpecVersion, "0.3") },
+		func() { header.Set(HeaderID, "not-a-uuid") },
+		func() { header.Set(HeaderTime, "yesterday") },
+		func() { header.Del("Content-Type") },
+	} {
+		header, body, _ = EncodeHTTP(e)
+		mutate()
+		if _, err := DecodeHTTP(header, body); err == nil {
+			t.Errorf("expected error for headers %v", header)
+		}
+	}
+}
+
+func TestEncodeDecodeHTTP_Extensions(t *testing.T) {
+	e := newBookBorrowed([]byte("x"))
+	e.Extensions = map[string]any{"traceparent": "00-abc-01", "priority": 3}
+
+	header, body, err := EncodeHTTP(e)
+	if err != nil {
+		t.Fatalf("EncodeHTTP failed: %v", err)
+	}
+	if header.Get("Ce-Traceparent") != "00-abc-01" || header.Get("Ce-Priority") != "3" {
+		t.Errorf("expected extension headers, got %v", header)
+	}
+
+	decoded, err := DecodeHTTP(header, body)
+	if err != nil {
+		t.Fatalf("DecodeHTTP failed: %v", err)
+	}
+	if len(decoded.Extensions) != 2 || decoded.Extensions["traceparent"] != "00-abc-01" || decoded.Extensions["priority"] != "3" {
+		t.Errorf("expected the extensions as strings, got %v", decoded.Extensions)
+	}
+}
+
+func TestDecodeHTTP_Protobuf(t *testing.T) {
+	e := newBookBorrowed(map[string]any{"bookId": "b-123"})
+	body, err := MarshalProto(e)
+	if err != nil {
+		t.Fatalf("MarshalProto failed: %v", err)
+	}
+
+	header := http.Header{"Content-Type": {ContentTypeProtobuf}}
+	if IsJSONHTTP(header) {
+		t.Error("expected a protobuf event not to be JSON")
+	}
+
+	decoded, err := DecodeHTTP(header, body)
+	if err != nil {
+		t.Fatalf("DecodeHTTP failed: %v", err)
+	}
+	if decoded.ID != e.ID || decoded.Data.(map[string]any)["bookId"] != "b-123" {
+		t.Errorf("unexpected event %+v", decoded)
+	}
+}
@@ -0,0 +1,348 @@
+package event
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Compatibility is the rule that a new schema version of an event type must satisfy towards its neighbouring
+// versions. Schemas are compared structurally and conservatively: a change is only compatible if every value
+// that one schema accepts is provably accepted by the other.
+type Compatibility string
+
+const (
+	// CompatibilityNone accepts any new schema version.
+	CompatibilityNone Compatibility = "none"
+	// CompatibilityBackward requires that consumers using the new schema can read data written with the previous one.
+	CompatibilityBackward Compatibility = "backward"
+	// CompatibilityForward requires that consumers using the previous schema can read data written with the new one.
+	CompatibilityForward Compatibility = "forward"
+	// CompatibilityFull requires both backward and forward compatibility.
+	CompatibilityFull Compatibility = "full"
+)
+
+// ParseCompatibility parses none, backward, forward or full.
+func ParseCompatibility(s string) (Compatibility, error) {
+	switch c := Compatibility(strings.ToLower(strings.TrimSpace(s))); c {
+	case CompatibilityNone, CompatibilityBackward, CompatibilityForward, CompatibilityFull:
+		return c, nil
+	}
+
+	return "", fmt.Errorf("invalid compatibility %q: must be none, backward, forward or full", s)
+}
+
+// SchemaCompatibilityError is returned when registering a schema version that breaks the compatibility rule.
+type SchemaCompatibilityError struct {
+	Type          string        `json:"type"`
+	Other         string        `json:"other"`
+	Compatibility Compatibility `json:"compatibility"`
+	Issues        []string      `json:"issues"`
+}
+
+func (e *SchemaCompatibilityError) Error() string {
+	return fmt.Sprintf("schema of %s is not %s compatible with %s: %s", e.Type, e.Compatibility, e.Other, strings.Join(e.Issues, "; "))
+}
+
+// CheckCompatibility returns the reasons why the next version of a schema breaks the compatibility rule
+// towards the previous version, or nil if it does not.
+func CheckCompatibility(previous, next *Schema, c Compatibility) []string {
+	switch c {
+	case CompatibilityBackward:
+		return checkReadable(previous, next, "")
+	case CompatibilityForward:
+		return checkReadable(next, previous, "")
+	case CompatibilityFull:
+		return append(checkReadable(previous, next, ""), checkReadable(next, previous, "")...)
+	}
+
+	return nil
+}
+
+// checkReadable returns the reasons why the reader schema may reject a value that the writer schema accepts.
+// Keywords of the writer that narrow its values further, such as allOf or pattern, are only used where the
+// reader has the same keyword, so the check may report issues for schemas that are in fact compatible.
+func checkReadable(writer, reader *Schema, path string) []string {
+	if reader.always != nil && *reader.always {
+		return nil
+	}
+	if writer.always != nil {
+		if !*writer.always {
+			return nil
+		}
+		writer = &Schema{}
+	}
+
+	var issues []string
+	add := func(format string, args ...any) {
+		issue := fmt.Sprintf(format, args...)
+		if path != "" {
+			issue = path + ": " + issue
+		}
+		issues = append(issues, issue)
+	}
+
+	if reader.always != nil {
+		add("the reader accepts no value")
+		return issues
+	}
+
+	if reader.types != nil {
+		if writer.types == nil {
+			add("the writer allows any type, the reader only %s", strings.Join(reader.types, " or "))
+		}
+		for _, t := range writer.types {
+			if !matchesAnyType(typeExample(t), reader.types) {
+				add("the reader does not accept %s", t)
+			}
+		}
+	}
+
+	values := writer.enum
+	if writer.hasConst {
+		values = []any{writer.constant}
+	}
+	if reader.enum != nil {
+		if values == nil {
+			add("the reader only accepts the values %s", formatJSON(reader.enum))
+		}
+		for _, v := range values {
+			if !containsJSON(reader.enum, v) {
+				add("the reader does not accept the value %s", formatJSON(v))
+			}
+		}
+	}
+	if reader.hasConst {
+		constant := values != nil
+		for _, v := range values {
+			constant = constant && reflect.DeepEqual(v, reader.constant)
+		}
+		if !constant {
+			add("the reader only accepts %s", formatJSON(reader.constant))
+		}
+	}
+
+	if writer.allows("object") {
+		issues = append(issues, checkReadableObject(writer, reader, path, add)...)
+	}
+	if writer.allows("array") {
+		if reader.items != nil {
+			issues = append(issues, checkReadable(orTrue(writer.items), reader.items, path+"/*")...)
+		}
+		checkMinimum(add, "items", writer.minItems, reader.minItems)
+		checkMaximum(add, "items", writer.maxItems, reader.maxItems)
+		if reader.uniqueItems && !writer.uniqueItems {
+			add("the reader requires unique items")
+		}
+	}
+	if writer.allows("number") {
+		checkReadableNumber(writer, reader, add)
+	}
+	if writer.allows("string") {
+		checkMinimum(add, "characters", writer.minLength, reader.minLength)
+		checkMaximum(add, "characters", writer.maxLength, reader.maxLength)
+		if reader.pattern != nil && (writer.pattern == nil || writer.pattern.String() != reader.pattern.String()) {
+			add("the reader requires the pattern %s", reader.pattern.String())
+		}
+		if reader.format != "" && writer.format != reader.format {
+			add("the reader requires the format %s", reader.format)
+		}
+	}
+
+	for _, sub := range reader.allOf {
+		issues = append(issues, checkReadable(writer, sub, path)...)
+	}
+	if reader.anyOf != nil && !anyOfCovers(writer, reader.anyOf, path) {
+		add("the writer may produce values that match none of the reader's anyOf schemas")
+	}
+	if reader.oneOf != nil && (writer.oneOf == nil || !sameDocs(writer.oneOf, reader.oneOf)) {
+		add("the reader's oneOf differs from the writer's")
+	}
+	if reader.not != nil && (writer.not == nil || !reflect.DeepEqual(writer.not.doc, reader.not.doc)) {
+		add("the reader's not differs from the writer's")
+	}
+
+	return issues
+}
+
+func checkReadableObject(writer, reader *Schema, path string, add func(string, ...any)) []string {
+	var issues []string
+
+	for _, name := range reader.required {
+		if !slices.Contains(writer.required, name) {
+			add("the reader requires the property %s", name)
+		}
+	}
+	checkMinimum(add, "properties", writer.minProperties, reader.minProperties)
+	checkMaximum(add, "properties", writer.maxProperties, reader.maxProperties)
+
+	names := make(map[string]bool)
+	for name := range writer.properties {
+		names[name] = true
+	}
+	for name := range reader.properties {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		w, exists := writer.properties[name]
+		if !exists {
+			w = orTrue(writer.additionalProperties)
+		}
+		r, exists := reader.properties[name]
+		if !exists {
+			r = orTrue(reader.additionalProperties)
+			if r.always != nil && !*r.always && (w.always == nil || *w.always) {
+				add("the reader does not allow the property %s", name)
+				continue
+			}
+		}
+		issues = append(issues, checkReadable(w, r, path+"/"+escapePointer(name))...)
+	}
+
+	if reader.additionalProperties != nil {
+		r := reader.additionalProperties
+		w := orTrue(writer.additionalProperties)
+		if r.always != nil && !*r.always && (w.always == nil || *w.always) {
+			add("the reader does not allow additional properties")
+		} else {
+			issues = append(issues, checkReadable(w, r, path+"/*")...)
+		}
+	}
+
+	return issues
+}
+
+func checkReadableNumber(writer, reader *Schema, add func(string, ...any)) {
+	if m := reader.minimum; m != nil {
+		if !(writer.minimum != nil && *writer.minimum >= *m) && !(writer.exclusiveMinimum != nil && *writer.exclusiveMinimum >= *m) {
+			add("the reader requires numbers of at least %v", *m)
+		}
+	}
+	if m := reader.exclusiveMinimum; m != nil {
+		if !(writer.exclusiveMinimum != nil && *writer.exclusiveMinimum >= *m) && !(writer.minimum != nil && *writer.minimum > *m) {
+			add("the reader requires numbers greater than %v", *m)
+		}
+	}
+	if m := reader.maximum; m != nil {
+		if !(writer.maximum != nil && *writer.maximum <= *m) && !(writer.exclusiveMaximum != nil && *writer.exclusiveMaximum <= *m) {
+			add("the reader requires numbers of at most %v", *m)
+		}
+	}
+	if m := reader.exclusiveMaximum; m != nil {
+		if !(writer.exclusiveMaximum != nil && *writer.exclusiveMaximum <= *m) && !(writer.maximum != nil && *writer.maximum < *m) {
+			add("the reader requires numbers less than %v", *m)
+		}
+	}
+	if m := reader.multipleOf; m != nil {
+		if writer.multipleOf == nil {
+			add("the reader requires multiples of %v", *m)
+		} else if q := *writer.multipleOf / *m; math.Abs(q-math.Round(q)) > 1e-9 {
+			add("the reader requires multiples of %v", *m)
+		}
+	}
+}
+
+func checkMinimum(add func(string, ...any), unit string, writer, reader *int) {
+	if reader != nil && (writer == nil || *writer < *reader) {
+		add("the reader requires at least %d %s", *reader, unit)
+	}
+}
+
+func checkMaximum(add func(string, ...any), unit string, writer, reader *int) {
+	if reader != nil && (writer == nil || *writer > *reader) {
+		add("the reader allows at most %d %s", *reader, unit)
+	}
+}
+
+// anyOfCovers reports whether every value of the writer matches one of the schemas, either because one schema
+// accepts all of them or because every anyOf schema of the writer is accepted by one of the schemas.
+func anyOfCovers(writer *Schema, schemas []*Schema, path string) bool {
+	for _, s := range schemas {
+		if len(checkReadable(writer, s, path)) == 0 {
+			return true
+		}
+	}
+	if writer.anyOf == nil {
+		return false
+	}
+
+	for _, w := range writer.anyOf {
+		covered := false
+		for _, s := range schemas {
+			if len(checkReadable(w, s, path)) == 0 {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+
+	return true
+}
+
+// allows reports whether the schema accepts values of the JSON type, where number includes integer.
+func (s *Schema) allows(t string) bool {
+	if s.types == nil {
+		return true
+	}
+
+	for _, allowed := range s.types {
+		if allowed == t || (t == "number" && allowed == "integer") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// typeExample returns a value of the JSON Schema type.
+func typeExample(t string) any {
+	switch t {
+	case "boolean":
+		return true
+	case "object":
+		return map[string]any{}
+	case "array":
+		return []any{}
+	case "number":
+		return 0.5
+	case "integer":
+		return 1.0
+	case "string":
+		return ""
+	}
+
+	return nil
+}
+
+func orTrue(s *Schema) *Schema {
+	if s == nil {
+		return &Schema{}
+	}
+
+	return s
+}
+
+func sameDocs(a, b []*Schema) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !reflect.DeepEqual(a[i].doc, b[i].doc) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,100 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	v1 := `{"type":"object","properties":{"bookId":{"type":"string"},"memberId":{"type":"integer"}},"required":["bookId","memberId"]}`
+
+	cases := []struct {
+		name     string
+		next     string
+		backward bool
+		forward  bool
+	}{
+		{"identical", v1, true, true},
+		{"optional property added", `{"type":"object","properties":{"bookId":{"type":"string"},"memberId":{"type":"integer"},"note":{}},"required":["bookId","memberId"]}`, true, true},
+		{"required property added", `{"type":"object","properties":{"bookId":{"type":"string"},"memberId":{"type":"integer"},"dueDate":{"type":"string"}},"required":["bookId","memberId","dueDate"]}`, false, true},
+		{"required property removed", `{"type":"object","properties":{"bookId":{"type":"string"},"memberId":{"type":"integer"}},"required":["bookId"]}`, true, false},
+		{"type widened", `{"type":"object","properties":{"bookId":{"type":"string"},"memberId":{"type":"number"}},"required":["bookId","memberId"]}`, true, false},
+		{"type changed", `{"type":"object","properties":{"bookId":{"type":"integer"},"memberId":{"type":"integer"}},"required":["bookId","memberId"]}`, false, false},
+		{"closed", `{"type":"object","properties":{"bookId":{"type":"string"},"memberId":{"type":"integer"}},"required":["bookId","memberId"],"additionalProperties":false}`, false, true},
+	}
+
+	previous, err := CompileSchema([]byte(v1))
+	if err != nil {
+		t.Fatalf("CompileSchema failed: %v", err)
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			next, err := CompileSchema([]byte(tc.next))
+			if err != nil {
+				t.Fatalf("CompileSchema failed: %v", err)
+			}
+			if issues := CheckCompatibility(previous, next, CompatibilityBackward); (len(issues) == 0) != tc.backward {
+				t.Errorf("backward: expected compatible=%v, got issues %v", tc.backward, issues)
+			}
+			if issues := CheckCompatibility(previous, next, CompatibilityForward); (len(issues) == 0) != tc.forward {
+				t.Errorf("forward: expected compatible=%v, got issues %v", tc.forward, issues)
+			}
+			if issues := CheckCompatibility(previous, next, CompatibilityFull); (len(issues) == 0) != (tc.backward && tc.forward) {
+				t.Errorf("full: unexpected issues %v", issues)
+			}
+			if issues := CheckCompatibility(previous, next, CompatibilityNone); len(issues) != 0 {
+				t.Errorf("none: expected no issues, got %v", issues)
+			}
+		})
+	}
+}
+
+func TestParseCompatibility(t *testing.T) {
+	if c, err := ParseCompatibility(" Full "); err != nil || c != CompatibilityFull {
+		t.Errorf("expected full, got %q, %v", c, err)
+	}
+	if _, err := ParseCompatibility("sideways"); err == nil {
+		t.Error("expected error for invalid compatibility")
+	}
+}
+
+func TestSchemaRegistry_Compatibility(t *testing.T) {
+	r := NewSchemaRegistry()
+	if r.Compatibility() != CompatibilityBackward {
+		t.Fatalf("expected backward by default, got %s", r.Compatibility())
+	}
+
+	v1 := json.RawMessage(`{"type":"object","properties":{"bookId":{"type":"string"}},"required":["bookId"]}`)
+	v2 := json.RawMessage(`{"type":"object","properties":{"bookId":{"type":"string"},"dueDate":{"type":"string"}},"required":["bookId","dueDate"]}`)
+	if _, err := r.Register("com.library.book.borrowed:v1", v1); err != nil {
+		t.Fatalf("Register v1 failed: %v", err)
+	}
+
+	var compatErr *SchemaCompatibilityError
+	_, err := r.Register("com.library.book.borrowed:v2", v2)
+	if !errors.As(err, &compatErr) || compatErr.Other != "com.library.book.borrowed:v1" || len(compatErr.Issues) == 0 {
+		t.Fatalf("expected compatibility error, got %v", err)
+	}
+
+	// Unrelated types and unversioned types are not checked
+	if _, err := r.Register("com.library.book.returned:v1", v2); err != nil {
+		t.Errorf("Register of other type failed: %v", err)
+	}
+
+	// The rule can be overridden per registration
+	if _, err := r.RegisterWith("com.library.book.borrowed:v2", v2, CompatibilityForward); err != nil {
+		t.Errorf("RegisterWith forward failed: %v", err)
+	}
+
+	// A version in between is checked against the next version too
+	r.SetCompatibility(CompatibilityFull)
+	v3 := json.RawMessage(`{"type":"object","properties":{"bookId":{"type":"string"},"dueDate":{"type":"string"}},"required":["bookId","dueDate"]}`)
+	if _, err := r.Register("com.library.book.borrowed:v4", v3); err != nil {
+		t.Fatalf("Register v4 failed: %v", err)
+	}
+	_, err = r.Register("com.library.book.borrowed:v3", v1)
+	if !errors.As(err, &compatErr) {
+		t.Errorf("expected compatibility error, got %v", err)
+	}
+}
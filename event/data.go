@@ -0,0 +1,227 @@
+package event
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"mime"
+	"strings"
+)
+
+// IsJSONContentType reports whether the datacontenttype denotes JSON data: empty, application/json,
+// text/json or a media type with the +json suffix.
+func IsJSONContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// isTextContentType reports whether the datacontenttype denotes text data such as text/plain or application/xml.
+func isTextContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return strings.HasPrefix(mediaType, "text/") || mediaType == "application/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// binaryData returns the bytes of a binary payload: a []byte or the serialized message of a ProtoData.
+func binaryData(data any) ([]byte, bool) {
+	switch v := data.(type) {
+	case []byte:
+		return v, true
+	case ProtoData:
+		return v.Value, true
+	}
+
+	return nil, false
+}
+
+// DataBytes returns the payload of the event as bytes in its datacontenttype. Binary payloads ([]byte or ProtoData)
+// and text payloads (strings with a non-JSON datacontenttype) are returned as they are; JSON payloads are marshalled.
+func (e Event) DataBytes() ([]byte, error) {
+	if data, binary := binaryData(e.Data); binary {
+		return data, nil
+	}
+
+	switch data := e.Data.(type) {
+	case json.RawMessage:
+		return data, nil
+	case string:
+		if !IsJSONContentType(e.DataContentType) {
+			return []byte(data), nil
+		}
+	}
+
+	return json.Marshal(e.Data)
+}
+
+// SetDataBytes sets the datacontenttype and the payload of the event from bytes in that content type.
+// JSON data is unmarshalled, text data is kept as a string and any other data as []byte.
+func (e *Event) SetDataBytes(contentType string, data []byte) error {
+	switch {
+	case IsJSONContentType(contentType):
+		var v any
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		e.Data = v
+	case isTextContentType(contentType):
+		e.Data = string(data)
+	default:
+		e.Data = append([]byte(nil), data...)
+	}
+	e.DataContentType = contentType
+
+	return nil
+}
+
+// plainEvent has the fields of Event without its JSON methods.
+type plainEvent Event
+
+// MarshalJSON encodes the event in the CloudEvents JSON format. Binary payloads are encoded as data_base64.
+func (e Event) MarshalJSON() ([]byte, error) {
+	data, binary := binaryData(e.Data)
+	if !binary {
+		b, err := json.Marshal(plainEvent(e))
+		if err != nil {
+			return nil, err
+		}
+		return appendExtensions(b, e.Extensions)
+	}
+
+	e.Data = nil
+	b, err := json.Marshal(struct {
+		plainEvent
+		Data       any    `json:"data,omitempty"`
+		DataBase64 []byte `json:"data_base64"`
+	}{plainEvent(e), nil, data})
+	if err != nil {
+		return nil, err
+	}
+
+	return appendExtensions(b, e.Extensions)
+}
+
+// UnmarshalJSON decodes an event in the CloudEvents JSON format. data_base64 is decoded into a []byte payload
+// and string data of a non-JSON datacontenttype is kept as text. Unknown members are decoded as extensions.
+func (e *Event) UnmarshalJSON(b []byte) error {
+	var v struct {
+		plainEvent
+		Data       json.RawMessage `json:"data"`
+		DataBase64 *string         `json:"data_base64"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	data, err := decodeData(v.DataContentType, v.Data, v.DataBase64)
+	if err != nil {
+		return err
+	}
+
+	extensions, err := decodeExtensions(b)
+	if err != nil {
+		return err
+	}
+
+	*e = Event(v.plainEvent)
+	e.Data = data
+	e.Extensions = extensions
+
+	return nil
+}
+
+// MarshalJSON encodes the candidate like an event. Binary payloads are encoded as data_base64.
+func (c Candidate) MarshalJSON() ([]byte, error) {
+	type plainCandidate Candidate
+	data, binary := binaryData(c.Data)
+	if !binary {
+		b, err := json.Marshal(plainCandidate(c))
+		if err != nil {
+			return nil, err
+		}
+		return appendExtensions(b, c.Extensions)
+	}
+
+	c.Data = nil
+	b, err := json.Marshal(struct {
+		plainCandidate
+		Data       any    `json:"data,omitempty"`
+		DataBase64 []byte `json:"data_base64"`
+	}{plainCandidate(c), nil, data})
+	if err != nil {
+		return nil, err
+	}
+
+	return appendExtensions(b, c.Extensions)
+}
+
+// UnmarshalJSON decodes a candidate like an event.
+func (c *Candidate) UnmarshalJSON(b []byte) error {
+	type plainCandidate Candidate
+	var v struct {
+		plainCandidate
+		Data       json.RawMessage `json:"data"`
+		DataBase64 *string         `json:"data_base64"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	data, err := decodeData(v.DataContentType, v.Data, v.DataBase64)
+	if err != nil {
+		return err
+	}
+
+	extensions, err := decodeExtensions(b)
+	if err != nil {
+		return err
+	}
+
+	*c = Candidate(v.plainCandidate)
+	c.Data = data
+	c.Extensions = extensions
+
+	return nil
+}
+
+// decodeData decodes the data or data_base64 member of a JSON event.
+func decodeData(contentType string, data json.RawMessage, dataBase64 *string) (any, error) {
+	if dataBase64 != nil {
+		if len(data) > 0 && string(data) != "null" {
+			return nil, errors.New("event data and data_base64 must not both be set")
+		}
+		b, err := base64.StdEncoding.DecodeString(*dataBase64)
+		if err != nil {
+			return nil, errors.New("event data_base64 must be valid base64")
+		}
+		return b, nil
+	}
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	if !IsJSONContentType(contentType) {
+		var s string
+		if err := json.Unmarshal(data, &s); err == nil {
+			return s, nil
+		}
+	}
+
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
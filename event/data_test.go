@@ -0,0 +1,120 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEvent_JSONBinaryData(t *testing.T) {
+	e := newBookBorrowed([]byte{0x00, 0xff, 0x10})
+	e.DataContentType = "application/octet-stream"
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(b), `"data_base64":"AP8Q"`) || strings.Contains(string(b), `"data":`) {
+		t.Fatalf("expected data_base64 without data, got %s", b)
+	}
+
+	var parsed Event
+	if err := json.Unmarshal(b, &parsed); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if data, ok := parsed.Data.([]byte); !ok || !bytes.Equal(data, []byte{0x00, 0xff, 0x10}) {
+		t.Errorf("expected binary data, got %T %v", parsed.Data, parsed.Data)
+	}
+	if parsed.DataContentType != "application/octet-stream" || parsed.ID != e.ID {
+		t.Errorf("unexpected event %+v", parsed)
+	}
+}
+
+func TestEvent_JSONTextData(t *testing.T) {
+	xml := `<book id="b-123"/>`
+	e := newBookBorrowed(xml)
+	e.DataContentType = "application/xml"
+
+	b, _ := json.Marshal(e)
+	var parsed Event
+	if err := json.Unmarshal(b, &parsed); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if parsed.Data != xml {
+		t.Errorf("expected verbatim text data, got %v", parsed.Data)
+	}
+	raw, err := parsed.DataBytes()
+	if err != nil || string(raw) != xml {
+		t.Errorf("expected raw text bytes, got %q, %v", raw, err)
+	}
+
+	// JSON data keeps its generic form, strings stay JSON strings
+	json.Unmarshal([]byte(`{"type":"com.library.book.borrowed:v1","data":{"bookId":"b-123"}}`), &parsed)
+	if _, ok := parsed.Data.(map[string]any); !ok || parsed.DataContentType != "" {
+		t.Errorf("expected JSON object data, got %T", parsed.Data)
+	}
+	e = newBookBorrowed("b-123")
+	if raw, _ := e.DataBytes(); string(raw) != `"b-123"` {
+		t.Errorf("expected JSON string bytes, got %s", raw)
+	}
+}
+
+func TestEvent_JSONInvalidData(t *testing.T) {
+	for _, s := range []string{
+		`{"type":"com.library.book.borrowed:v1","data":{},"data_base64":"AP8Q"}`,
+		`{"type":"com.library.book.borrowed:v1","data_base64":"not base64!"}`,
+	} {
+		var e Event
+		if err := json.Unmarshal([]byte(s), &e); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+}
+
+func TestCandidate_JSONBinaryData(t *testing.T) {
+	c := Candidate{Type: "com.library.book.cover:v1", DataContentType: "image/png", Data: []byte{0x89, 0x50}}
+	b, _ := json.Marshal(c)
+
+	var parsed Candidate
+	if err := json.Unmarshal(b, &parsed); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if data, ok := parsed.Data.([]byte); !ok || !bytes.Equal(data, []byte{0x89, 0x50}) || parsed.DataContentType != "image/png" {
+		t.Errorf("unexpected candidate %+v", parsed)
+	}
+}
+
+func TestEvent_SetDataBytes(t *testing.T) {
+	cases := []struct {
+		contentType string
+		data        string
+		want        any
+	}{
+		{"application/json", `{"bookId":"b-123"}`, map[string]any{"bookId": "b-123"}},
+		{"application/cloudevents+json; charset=utf-8", `7`, 7.0},
+		{"text/plain; charset=utf-8", `hello`, "hello"},
+		{"application/atom+xml", `<feed/>`, "<feed/>"},
+		{"application/protobuf", "\x0a\x01", []byte("\x0a\x01")},
+	}
+
+	for _, tc := range cases {
+		var e Event
+		if err := e.SetDataBytes(tc.contentType, []byte(tc.data)); err != nil {
+			t.Fatalf("%s: SetDataBytes failed: %v", tc.contentType, err)
+		}
+		got, _ := json.Marshal(e.Data)
+		want, _ := json.Marshal(tc.want)
+		if string(got) != string(want) || e.DataContentType != tc.contentType {
+			t.Errorf("%s: expected %v, got %T %v", tc.contentType, tc.want, e.Data, e.Data)
+		}
+		if raw, _ := e.DataBytes(); tc.contentType != "application/cloudevents+json; charset=utf-8" && string(raw) != tc.data {
+			t.Errorf("%s: expected raw bytes %q, got %q", tc.contentType, tc.data, raw)
+		}
+	}
+
+	var e Event
+	if err := e.SetDataBytes("application/json", []byte("{")); err == nil {
+		t.Error("expected error for invalid JSON data")
+	}
+}
@@ -1,8 +1,9 @@
 package event
 
 import (
-	"encoding/json"
 	"errors"
+	"mime"
+	"net/url"
 	"strings"
 	"time"
 
@@ -21,8 +22,14 @@ type Event struct {
 	Source string `json:"source"`
 	// The subject of the event in the context of the event producer (identified by source). E.g. the entity to which the event is primarily related. E.g. /users/12345
 	Subject string `json:"subject"`
-	// The event payload.
+	// The media type of the data, e.g. application/xml. Optional; JSON if empty.
+	DataContentType string `json:"datacontenttype,omitempty"`
+	// Identifies the schema that the data adheres to. Optional; must be a URI if set.
+	DataSchema string `json:"dataschema,omitempty"`
+	// The event payload. Binary payloads are []byte and encoded as data_base64 in JSON (see DataBytes).
 	Data any `json:"data"`
+	// Extension attributes by name, e.g. traceparent. Encoded as top-level members in JSON (see Extensions).
+	Extensions map[string]any `json:"-"`
 }
 
 // Candidate represents the input required to create a new Event.
@@ -33,20 +40,29 @@ type Candidate struct {
 	Source string `json:"source"`
 	// The subject of the event in the context of the event producer (identified by source). E.g. the entity to which the event is primarily related. E.g. /users/12345"
 	Subject string `json:"subject"`
-	// The event payload.
+	// The media type of the data, e.g. application/xml. Optional; JSON if empty.
+	DataContentType string `json:"datacontenttype,omitempty"`
+	// Identifies the schema that the data adheres to. Optional; must be a URI if set.
+	DataSchema string `json:"dataschema,omitempty"`
+	// The event payload. Binary payloads are []byte and encoded as data_base64 in JSON (see DataBytes).
 	Data any `json:"data"`
+	// Extension attributes by name, e.g. traceparent. Encoded as top-level members in JSON (see Extensions).
+	Extensions map[string]any `json:"-"`
 }
 
 // New creates a new Event with the given parameters and automatically sets the ID and Time fields.
 // It returns an error if any of the required fields are invalid.
 func New(candidate Candidate) (*Event, error) {
 	event := Event{
-		ID:      uuid.New(),
-		Type:    candidate.Type,
-		Time:    time.Now().UTC(),
-		Source:  candidate.Source,
-		Subject: candidate.Subject,
-		Data:    candidate.Data,
+		ID:              uuid.New(),
+		Type:            candidate.Type,
+		Time:            time.Now().UTC(),
+		Source:          candidate.Source,
+		Subject:         candidate.Subject,
+		DataContentType: candidate.DataContentType,
+		DataSchema:      candidate.DataSchema,
+		Data:            candidate.Data,
+		Extensions:      candidate.Extensions,
 	}
 
 	if err := event.Validate(); err != nil {
@@ -56,19 +72,10 @@ func New(candidate Candidate) (*Event, error) {
 	return &event, nil
 }
 
-// FromJSON parses a JSON string into an Event and validates it.
+// FromJSON parses a JSON string into an Event and validates it. The payload of event types registered
+// in DefaultTypeRegistry is decoded into their Go type, other payloads keep their generic JSON form.
 func FromJSON(s string) (*Event, error) {
-	var event Event
-
-	if err := json.Unmarshal([]byte(s), &event); err != nil {
-		return nil, err
-	}
-
-	if err := event.Validate(); err != nil {
-		return nil, err
-	}
-
-	return &event, nil
+	return DefaultTypeRegistry.FromJSON(s)
 }
 
 // Validate checks the Event fields for validity according to the CNCF Cloudevents specification.
@@ -96,9 +103,25 @@ func (e *Event) Validate() error {
 		return errors.New("event subject must be at least 5 characters long")
 	}
 
+	if e.DataContentType != "" {
+		if mediaType, _, err := mime.ParseMediaType(e.DataContentType); err != nil || !strings.Contains(mediaType, "/") {
+			return errors.New("event datacontenttype must be a valid media type")
+		}
+	}
+
+	if e.DataSchema != "" {
+		if u, err := url.Parse(e.DataSchema); err != nil || !u.IsAbs() {
+			return errors.New("event dataschema must be an absolute URI")
+		}
+	}
+
 	if e.Data == nil {
 		return errors.New("event data cannot be nil")
 	}
 
+	if err := validateExtensions(e.Extensions); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -50,6 +50,8 @@ func TestValidate_Errors(t *testing.T) {
 		{"short source", func(e *Event) { e.Source = "abc" }, "event source must be at least 5 characters long"},
 		{"bad source scheme", func(e *Event) { e.Source = "ftp://example.com" }, "event source must be a valid URI starting with http:// or https://"},
 		{"short subject", func(e *Event) { e.Subject = "abc" }, "event subject must be at least 5 characters long"},
+		{"relative dataschema", func(e *Event) { e.DataSchema = "/schemas/event.json" }, "event dataschema must be an absolute URI"},
+		{"invalid datacontenttype", func(e *Event) { e.DataContentType = "xml" }, "event datacontenttype must be a valid media type"},
 		{"nil data", func(e *Event) { e.Data = nil }, "event data cannot be nil"},
 	}
 
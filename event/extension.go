@@ -0,0 +1,189 @@
+package event
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Extension attribute values have one of the CloudEvents attribute types:
+//
+//	Boolean    bool
+//	Integer    int32 (int and int64 within the int32 range are accepted)
+//	String     string
+//	Binary     []byte
+//	URI        *url.URL (absolute)
+//	URI-ref    *url.URL (relative)
+//	Timestamp  time.Time
+//
+// The JSON format and the HTTP binary content mode carry only strings, numbers and booleans,
+// so binary, URI and timestamp values are read back from them as strings.
+
+// reservedAttributes are the names of the attributes and members that cannot be used as extension names.
+var reservedAttributes = map[string]bool{
+	"id": true, "type": true, "time": true, "source": true, "subject": true, "specversion": true,
+	"datacontenttype": true, "dataschema": true, "data": true, "data_base64": true,
+}
+
+// validateExtensions checks that the extension names consist of lower-case letters and digits
+// and that their values have one of the CloudEvents attribute types.
+func validateExtensions(extensions map[string]any) error {
+	for name, value := range extensions {
+		if err := validateExtensionName(name); err != nil {
+			return err
+		}
+		if _, err := normalizeExtension(value); err != nil {
+			return fmt.Errorf("event extension %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func validateExtensionName(name string) error {
+	if name == "" || reservedAttributes[name] {
+		return fmt.Errorf("invalid event extension name %q", name)
+	}
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return fmt.Errorf("event extension name %q must consist of lower-case letters and digits", name)
+		}
+	}
+
+	return nil
+}
+
+// normalizeExtension returns the value of an extension attribute as one of the Go types of the attribute types.
+func normalizeExtension(value any) (any, error) {
+	switch v := value.(type) {
+	case bool, int32, string, []byte, time.Time:
+		return v, nil
+	case int:
+		if v >= math.MinInt32 && v <= math.MaxInt32 {
+			return int32(v), nil
+		}
+	case int64:
+		if v >= math.MinInt32 && v <= math.MaxInt32 {
+			return int32(v), nil
+		}
+	case float64:
+		// Numbers of parsed JSON extensions
+		if v == math.Trunc(v) && v >= math.MinInt32 && v <= math.MaxInt32 {
+			return int32(v), nil
+		}
+	case *url.URL:
+		if v != nil {
+			return v, nil
+		}
+	case url.URL:
+		return &v, nil
+	}
+
+	return nil, fmt.Errorf("unsupported value %v of type %T", value, value)
+}
+
+// extensionString returns the canonical string form of an extension value.
+func extensionString(value any) string {
+	switch v := value.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case *url.URL:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case string:
+		return v
+	}
+
+	return fmt.Sprint(value)
+}
+
+// extensionJSON returns the value of an extension in the JSON format: booleans and integers as they are,
+// all other types as strings.
+func extensionJSON(value any) (any, error) {
+	v, err := normalizeExtension(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v.(type) {
+	case bool, int32:
+		return v, nil
+	}
+
+	return extensionString(v), nil
+}
+
+// appendExtensions adds the extensions as members to the JSON object b, in lexical order of their names.
+func appendExtensions(b []byte, extensions map[string]any) ([]byte, error) {
+	if len(extensions) == 0 {
+		return b, nil
+	}
+
+	names := make([]string, 0, len(extensions))
+	for name := range extensions {
+		if reservedAttributes[name] {
+			return nil, fmt.Errorf("invalid event extension name %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.Write(b[:len(b)-1])
+	for _, name := range names {
+		value, err := extensionJSON(extensions[name])
+		if err != nil {
+			return nil, fmt.Errorf("event extension %s: %w", name, err)
+		}
+		member, err := json.Marshal(map[string]any{name: value})
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteByte(',')
+		buf.Write(member[1 : len(member)-1])
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// decodeExtensions returns the members of the JSON object b that are not attributes of the event
+// as extensions. It returns nil if there are none.
+func decodeExtensions(b []byte) (map[string]any, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(b, &members); err != nil {
+		return nil, err
+	}
+
+	var extensions map[string]any
+	for name, raw := range members {
+		if reservedAttributes[name] {
+			continue
+		}
+		var v any
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+		value, err := normalizeExtension(v)
+		if err != nil {
+			return nil, fmt.Errorf("event extension %s: %w", name, err)
+		}
+		if extensions == nil {
+			extensions = make(map[string]any)
+		}
+		extensions[name] = value
+	}
+
+	return extensions, nil
+}
@@ -0,0 +1,97 @@
+package event
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestValidate_Extensions(t *testing.T) {
+	valid := map[string]any{
+		"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		"sampled":     true,
+		"priority":    3,
+		"count":       int64(-7),
+		"blob":        []byte{1, 2},
+		"origin":      &url.URL{Scheme: "https", Host: "library.example.com"},
+		"expires":     time.Now(),
+	}
+	e := newBookBorrowed(map[string]any{"bookId": "b-123"})
+	e.Extensions = valid
+	if err := e.Validate(); err != nil {
+		t.Errorf("expected valid extensions, got %v", err)
+	}
+
+	for _, ext := range []map[string]any{
+		{"Trace": "x"},
+		{"trace-id": "x"},
+		{"subject": "x"},
+		{"": "x"},
+		{"ratio": 0.5},
+		{"big": int64(1) << 40},
+		{"nested": map[string]any{"a": 1}},
+	} {
+		e.Extensions = ext
+		if err := e.Validate(); err == nil {
+			t.Errorf("expected error for extensions %v", ext)
+		}
+	}
+}
+
+func TestEvent_JSONExtensions(t *testing.T) {
+	e := newBookBorrowed(map[string]any{"bookId": "b-123"})
+	e.Extensions = map[string]any{
+		"traceparent": "00-abc-01",
+		"sampled":     true,
+		"priority":    3,
+		"expires":     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var members map[string]any
+	if err := json.Unmarshal(b, &members); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if members["traceparent"] != "00-abc-01" || members["priority"] != 3.0 || members["expires"] != "2026-01-02T03:04:05Z" {
+		t.Errorf("expected extensions as top-level members, got %s", b)
+	}
+
+	var decoded Event
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	want := map[string]any{"traceparent": "00-abc-01", "sampled": true, "priority": int32(3), "expires": "2026-01-02T03:04:05Z"}
+	if len(decoded.Extensions) != len(want) {
+		t.Fatalf("expected extensions %v, got %v", want, decoded.Extensions)
+	}
+	for name, value := range want {
+		if decoded.Extensions[name] != value {
+			t.Errorf("expected extension %s %v (%T), got %v (%T)", name, value, value, decoded.Extensions[name], decoded.Extensions[name])
+		}
+	}
+
+	// Members with values that are not attribute values are rejected
+	if err := json.Unmarshal([]byte(`{"id":"`+e.ID.String()+`","nested":{"a":1}}`), &decoded); err == nil {
+		t.Error("expected error for an object extension")
+	}
+}
+
+func TestCandidate_JSONExtensions(t *testing.T) {
+	var c Candidate
+	if err := json.Unmarshal([]byte(`{"type":"com.library.book.borrowed:v1","source":"https://library.example.com","subject":"/books/123","data":{},"traceparent":"00-abc-01"}`), &c); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	e, err := New(c)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if e.Extensions["traceparent"] != "00-abc-01" {
+		t.Errorf("expected the extension to be copied to the event, got %v", e.Extensions)
+	}
+}
@@ -0,0 +1,120 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ContentTypeJSON is the media type of events in the CloudEvents JSON format.
+const ContentTypeJSON = "application/cloudevents+json"
+
+// Formats are the media types of the supported event formats.
+var Formats = []string{ContentTypeJSON, ContentTypeProtobuf, ContentTypeAvro}
+
+// ParseFormat checks that the media type denotes a supported event format. The empty string is
+// valid and stands for the default encoding of EncodeHTTP.
+func ParseFormat(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid event format %q: %w", s, err)
+	}
+	for _, format := range Formats {
+		if mediaType == format {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported event format %q, must be one of %s", s, strings.Join(Formats, ", "))
+}
+
+// EncodeHTTPFormat returns the headers and body of an HTTP message carrying the event in structured content mode
+// in the given format, one of Formats. An empty format encodes the event like EncodeHTTP.
+func EncodeHTTPFormat(e Event, format string) (http.Header, []byte, error) {
+	var body []byte
+	var err error
+	switch format {
+	case "":
+		return EncodeHTTP(e)
+	case ContentTypeJSON:
+		body, err = json.Marshal(e)
+	case ContentTypeProtobuf:
+		body, err = MarshalProto(e)
+	case ContentTypeAvro:
+		body, err = MarshalAvro(e)
+	default:
+		return nil, nil, fmt.Errorf("unsupported event format %q", format)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header := make(http.Header)
+	header.Set("Content-Type", format)
+
+	return header, body, nil
+}
+
+// NegotiateFormat returns the offered media type that the Accept header value prefers, taking the quality values
+// and the specificity of the media ranges into account. Ties go to the earlier offer. It returns the first offer
+// if accept is empty and "" if no offer is acceptable.
+func NegotiateFormat(accept string, offers ...string) string {
+	if strings.TrimSpace(accept) == "" {
+		if len(offers) == 0 {
+			return ""
+		}
+		return offers[0]
+	}
+
+	type mediaRange struct {
+		mediaType string
+		quality   float64
+	}
+	var ranges []mediaRange
+	for part := range strings.SplitSeq(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType, quality})
+	}
+
+	best, bestQuality := "", 0.0
+	for _, offer := range offers {
+		offerType, _, _ := strings.Cut(offer, "/")
+		quality, specificity := 0.0, -1
+		for _, r := range ranges {
+			s := -1
+			switch {
+			case r.mediaType == offer:
+				s = 2
+			case r.mediaType == offerType+"/*":
+				s = 1
+			case r.mediaType == "*/*":
+				s = 0
+			}
+			if s > specificity {
+				quality, specificity = r.quality, s
+			}
+		}
+		if quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,68 @@
+package event
+
+import (
+	"testing"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	offers := []string{"application/x-ndjson", ContentTypeAvro}
+
+	cases := []struct {
+		accept string
+		want   string
+	}{
+		{"", "application/x-ndjson"},
+		{"*/*", "application/x-ndjson"},
+		{ContentTypeAvro, ContentTypeAvro},
+		{"application/json", ""},
+		{"application/x-ndjson;q=0.5, application/cloudevents+avro", ContentTypeAvro},
+		{"application/*;q=0.8, application/x-ndjson;q=0.9", "application/x-ndjson"},
+		{"*/*, application/x-ndjson;q=0", ContentTypeAvro},
+		{"not a media type, application/cloudevents+avro;q=0.1", ContentTypeAvro},
+	}
+
+	for _, tc := range cases {
+		if got := NegotiateFormat(tc.accept, offers...); got != tc.want {
+			t.Errorf("NegotiateFormat(%q) = %q, want %q", tc.accept, got, tc.want)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, s := range []string{"", ContentTypeJSON, ContentTypeProtobuf, " application/cloudevents+avro "} {
+		if _, err := ParseFormat(s); err != nil {
+			t.Errorf("expected %q to be valid, got %v", s, err)
+		}
+	}
+	for _, s := range []string{"application/json", "avro", "application/cloudevents+xml"} {
+		if _, err := ParseFormat(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestEncodeHTTPFormat(t *testing.T) {
+	e := newBookBorrowed(map[string]any{"bookId": "b-123"})
+
+	for _, format := range Formats {
+		header, body, err := EncodeHTTPFormat(e, format)
+		if err != nil {
+			t.Fatalf("EncodeHTTPFormat(%s) failed: %v", format, err)
+		}
+		if header.Get("Content-Type") != format || IsBinaryHTTP(header) {
+			t.Errorf("expected structured content mode with %s, got %v", format, header)
+		}
+
+		decoded, err := DecodeHTTP(header, body)
+		if err != nil {
+			t.Fatalf("DecodeHTTP(%s) failed: %v", format, err)
+		}
+		if decoded.ID != e.ID || decoded.Data.(map[string]any)["bookId"] != "b-123" {
+			t.Errorf("unexpected event %+v in %s", decoded, format)
+		}
+	}
+
+	if _, _, err := EncodeHTTPFormat(e, "application/xml"); err == nil {
+		t.Error("expected error for an unsupported format")
+	}
+}
@@ -0,0 +1,171 @@
+package event
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Handshaker performs the CloudEvents webhook abuse-protection handshake for webhook URLs.
+// Successful handshakes are cached per URL together with the allowed request rate.
+type Handshaker struct {
+	origin   string
+	mu       sync.Mutex
+	webhooks map[string]*limiter
+}
+
+// limiter spaces out requests to a webhook according to its allowed rate.
+// A zero interval means the webhook accepts an unlimited rate.
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// handshakeClient sends the validation requests. The timeout keeps an unreachable webhook from blocking
+// its callers.
+var handshakeClient = &http.Client{Timeout: 10 * time.Second}
+
+// RateLimitError is returned instead of sending when a request would exceed the allowed rate of a webhook.
+type RateLimitError struct {
+	URL        string
+	RetryAfter time.Duration // time until the webhook accepts the next request
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("allowed rate of webhook %s exceeded, retry after %s", e.URL, e.RetryAfter)
+}
+
+// NewHandshaker creates a Handshaker that announces itself with the given origin.
+func NewHandshaker(origin string) *Handshaker {
+	return &Handshaker{origin: origin, webhooks: make(map[string]*limiter)}
+}
+
+// Register performs the handshake for the given URL and caches the result.
+func (h *Handshaker) Register(url string) error {
+	_, err := h.register(url)
+	return err
+}
+
+func (h *Handshaker) register(url string) (*limiter, error) {
+	rate, err := Handshake(url, h.origin)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &limiter{}
+	if rate > 0 {
+		l.interval = time.Minute / time.Duration(rate)
+	}
+
+	h.mu.Lock()
+	h.webhooks[url] = l
+	h.mu.Unlock()
+
+	log.Printf("INFO Webhook %s validated (allowed rate: %d/min)", url, rate)
+	return l, nil
+}
+
+// Wrap returns a send function that only delivers to validated webhooks and honours their allowed rate.
+// Webhooks that have not been validated yet are validated before the first delivery. Requests over the
+// allowed rate are not sent; a *RateLimitError tells the caller when to retry.
+func (h *Handshaker) Wrap(send func(url string, e Event) (string, error)) func(url string, e Event) (string, error) {
+	return func(url string, e Event) (string, error) {
+		h.mu.Lock()
+		l, ok := h.webhooks[url]
+		h.mu.Unlock()
+
+		if !ok {
+			var err error
+			if l, err = h.register(url); err != nil {
+				return "", err
+			}
+		}
+
+		if delay := l.reserve(); delay > 0 {
+			return "", &RateLimitError{URL: url, RetryAfter: delay}
+		}
+		return send(url, e)
+	}
+}
+
+// reserve takes the next request slot of the webhook. If the slot is not due yet, it returns the time
+// until it is and takes nothing.
+func (l *limiter) reserve() time.Duration {
+	if l.interval == 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(l.next) {
+		return l.next.Sub(now)
+	}
+	l.next = now.Add(l.interval)
+	return 0
+}
+
+// Handshake sends the validation request to the webhook and returns the allowed rate in requests per minute.
+// A rate of 0 means the webhook does not limit the request rate.
+func Handshake(url, origin string) (int, error) {
+	req, err := http.NewRequest(http.MethodOptions, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("WebHook-Request-Origin", origin)
+
+	resp, err := handshakeClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("webhook %s rejected handshake with status %d", url, resp.StatusCode)
+	}
+
+	allowedOrigin := strings.TrimSpace(resp.Header.Get("WebHook-Allowed-Origin"))
+	if allowedOrigin != "*" && allowedOrigin != origin {
+		return 0, fmt.Errorf("webhook %s did not allow origin %s", url, origin)
+	}
+
+	allowedRate := strings.TrimSpace(resp.Header.Get("WebHook-Allowed-Rate"))
+	if allowedRate == "" || allowedRate == "*" {
+		return 0, nil
+	}
+
+	rate, err := strconv.Atoi(allowedRate)
+	if err != nil || rate < 1 {
+		return 0, fmt.Errorf("webhook %s returned invalid allowed rate %q", url, allowedRate)
+	}
+
+	return rate, nil
+}
+
+// HandshakeHandler returns an HTTP handler that answers the CloudEvents webhook validation handshake.
+// Every requesting origin is allowed and no request rate limit is announced.
+func HandshakeHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		origin := r.Header.Get("WebHook-Request-Origin")
+		if origin == "" {
+			http.Error(w, "Missing WebHook-Request-Origin header", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Allow", http.MethodPost)
+		w.Header().Set("WebHook-Allowed-Origin", origin)
+		w.Header().Set("WebHook-Allowed-Rate", "*")
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,208 @@
+package event
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newHandshakeServer(allowedOrigin, allowedRate string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			w.Header().Set("WebHook-Allowed-Origin", allowedOrigin)
+			if allowedRate != "" {
+				w.Header().Set("WebHook-Allowed-Rate", allowedRate)
+			}
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+}
+
+func TestHandshake_Success(t *testing.T) {
+	ts := newHandshakeServer("bus.example.com", "120")
+	defer ts.Close()
+
+	rate, err := Handshake(ts.URL, "bus.example.com")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rate != 120 {
+		t.Errorf("expected rate 120, got %d", rate)
+	}
+}
+
+func TestHandshake_UnlimitedRate(t *testing.T) {
+	ts := newHandshakeServer("*", "*")
+	defer ts.Close()
+
+	rate, err := Handshake(ts.URL, "bus.example.com")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rate != 0 {
+		t.Errorf("expected unlimited rate 0, got %d", rate)
+	}
+}
+
+func TestHandshake_OriginNotAllowed(t *testing.T) {
+	ts := newHandshakeServer("other.example.com", "")
+	defer ts.Close()
+
+	if _, err := Handshake(ts.URL, "bus.example.com"); err == nil {
+		t.Errorf("expected error for disallowed origin")
+	}
+}
+
+func TestHandshake_Rejected(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	if _, err := Handshake(ts.URL, "bus.example.com"); err == nil {
+		t.Errorf("expected error for rejected handshake")
+	}
+}
+
+func TestHandshaker_WrapValidatesOnce(t *testing.T) {
+	handshakes := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			handshakes++
+			w.Header().Set("WebHook-Allowed-Origin", "*")
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	post := func(url string, e Event) (string, error) {
+		resp, err := http.Post(url, ContentTypeJSON, nil)
+		if err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+		return resp.Status, nil
+	}
+
+	send := NewHandshaker("bus.example.com").Wrap(post)
+	for range 3 {
+		if _, err := send(ts.URL, Event{Type: "test"}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	if handshakes != 1 {
+		t.Errorf("expected 1 handshake, got %d", handshakes)
+	}
+}
+
+func TestHandshaker_WrapRejectsUnvalidatedWebhook(t *testing.T) {
+	ts := newHandshakeServer("other.example.com", "")
+	defer ts.Close()
+
+	called := false
+	send := NewHandshaker("bus.example.com").Wrap(func(url string, e Event) (string, error) {
+		called = true
+		return "ok", nil
+	})
+
+	if _, err := send(ts.URL, Event{Type: "test"}); err == nil {
+		t.Errorf("expected error for failed handshake")
+	}
+	if called {
+		t.Errorf("expected event not to be sent")
+	}
+}
+
+func TestHandshaker_WrapHonoursAllowedRate(t *testing.T) {
+	ts := newHandshakeServer("*", "600") // one request every 100ms
+	defer ts.Close()
+
+	h := NewHandshaker("bus.example.com")
+	if err := h.Register(ts.URL); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sent := 0
+	send := h.Wrap(func(url string, e Event) (string, error) { sent++; return "ok", nil })
+	if _, err := send(ts.URL, Event{Type: "test"}); err != nil {
+		t.Fatalf("expected first delivery to be sent, got %v", err)
+	}
+
+	start := time.Now()
+	_, err := send(ts.URL, Event{Type: "test"})
+	var rateLimit *RateLimitError
+	if !errors.As(err, &rateLimit) || rateLimit.RetryAfter <= 0 || rateLimit.RetryAfter > 100*time.Millisecond {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("expected over-rate delivery to be rejected without waiting, took %v", elapsed)
+	}
+
+	time.Sleep(rateLimit.RetryAfter)
+	if _, err := send(ts.URL, Event{Type: "test"}); err != nil || sent != 2 {
+		t.Errorf("expected delivery after the retry delay, got %v with %d sent", err, sent)
+	}
+}
+
+func TestHandshake_Timeout(t *testing.T) {
+	block := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { <-block }))
+	defer ts.Close()
+	defer close(block)
+
+	client := handshakeClient
+	handshakeClient = &http.Client{Timeout: 50 * time.Millisecond}
+	defer func() { handshakeClient = client }()
+
+	if _, err := Handshake(ts.URL, "bus.example.com"); err == nil {
+		t.Error("expected error for unresponsive webhook")
+	}
+}
+
+func TestHandshakeHandler(t *testing.T) {
+	handler := HandshakeHandler()
+	req := httptest.NewRequest(http.MethodOptions, "/publish", nil)
+	req.Header.Set("WebHook-Request-Origin", "eventemitter.example.com")
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("WebHook-Allowed-Origin"); got != "eventemitter.example.com" {
+		t.Errorf("expected allowed origin 'eventemitter.example.com', got %q", got)
+	}
+	if got := rec.Header().Get("WebHook-Allowed-Rate"); got != "*" {
+		t.Errorf("expected allowed rate '*', got %q", got)
+	}
+}
+
+func TestHandshakeHandler_MissingOrigin(t *testing.T) {
+	handler := HandshakeHandler()
+	req := httptest.NewRequest(http.MethodOptions, "/publish", nil)
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestHandshakeHandler_MethodNotAllowed(t *testing.T) {
+	handler := HandshakeHandler()
+	req := httptest.NewRequest(http.MethodGet, "/publish", nil)
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,414 @@
+package event
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ContentTypeProtobuf is the media type of events in the CloudEvents protobuf format.
+const ContentTypeProtobuf = "application/cloudevents+protobuf"
+
+// ProtoData is a payload in the protobuf format, the google.protobuf.Any proto_data of a protobuf event.
+// TypeURL identifies the message type and Value holds the serialized message. In the JSON format it is
+// encoded as data_base64 like a binary payload.
+type ProtoData struct {
+	TypeURL string
+	Value   []byte
+}
+
+// Field numbers of the io.cloudevents.v1.CloudEvent message.
+const (
+	protoFieldID          = 1
+	protoFieldSource      = 2
+	protoFieldSpecVersion = 3
+	protoFieldType        = 4
+	protoFieldAttributes  = 5
+	protoFieldBinaryData  = 6
+	protoFieldTextData    = 7
+	protoFieldProtoData   = 8
+)
+
+// Field numbers of the oneof of the io.cloudevents.v1.CloudEventAttributeValue message.
+const (
+	protoAttrBoolean   = 1
+	protoAttrInteger   = 2
+	protoAttrString    = 3
+	protoAttrBytes     = 4
+	protoAttrURI       = 5
+	protoAttrURIRef    = 6
+	protoAttrTimestamp = 7
+)
+
+// Protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// MarshalProto encodes the event as an io.cloudevents.v1.CloudEvent protobuf message.
+// ProtoData payloads are encoded as proto_data, binary payloads as binary_data, text payloads as text_data
+// and JSON payloads as text_data in the JSON format.
+func MarshalProto(e Event) ([]byte, error) {
+	var b []byte
+	b = appendProtoString(b, protoFieldID, e.ID.String())
+	b = appendProtoString(b, protoFieldSource, e.Source)
+	b = appendProtoString(b, protoFieldSpecVersion, SpecVersion)
+	b = appendProtoString(b, protoFieldType, e.Type)
+
+	attributes := make(map[string]any, len(e.Extensions)+4)
+	for name, value := range e.Extensions {
+		if reservedAttributes[name] {
+			return nil, fmt.Errorf("invalid event extension name %q", name)
+		}
+		v, err := normalizeExtension(value)
+		if err != nil {
+			return nil, fmt.Errorf("event extension %s: %w", name, err)
+		}
+		attributes[name] = v
+	}
+	if !e.Time.IsZero() {
+		attributes["time"] = e.Time
+	}
+	if e.Subject != "" {
+		attributes["subject"] = e.Subject
+	}
+	if e.DataContentType != "" {
+		attributes["datacontenttype"] = e.DataContentType
+	}
+	if e.DataSchema != "" {
+		u, err := url.Parse(e.DataSchema)
+		if err != nil {
+			return nil, fmt.Errorf("invalid event dataschema: %w", err)
+		}
+		attributes["dataschema"] = u
+	}
+
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var entry []byte
+		entry = appendProtoString(entry, 1, name)
+		entry = appendProtoBytes(entry, 2, marshalProtoAttribute(attributes[name]))
+		b = appendProtoBytes(b, protoFieldAttributes, entry)
+	}
+
+	switch data := e.Data.(type) {
+	case nil:
+	case ProtoData:
+		var a []byte
+		a = appendProtoString(a, 1, data.TypeURL)
+		a = appendProtoBytes(a, 2, data.Value)
+		b = appendProtoBytes(b, protoFieldProtoData, a)
+	case []byte:
+		b = appendProtoBytes(b, protoFieldBinaryData, data)
+	default:
+		text, err := e.DataBytes()
+		if err != nil {
+			return nil, err
+		}
+		b = appendProtoBytes(b, protoFieldTextData, text)
+	}
+
+	return b, nil
+}
+
+// marshalProtoAttribute encodes a normalized attribute value as a CloudEventAttributeValue message.
+func marshalProtoAttribute(value any) []byte {
+	var b []byte
+	switch v := value.(type) {
+	case bool:
+		var n uint64
+		if v {
+			n = 1
+		}
+		b = appendProtoVarint(b, protoAttrBoolean, n)
+	case int32:
+		b = appendProtoVarint(b, protoAttrInteger, uint64(int64(v)))
+	case string:
+		b = appendProtoString(b, protoAttrString, v)
+	case []byte:
+		b = appendProtoBytes(b, protoAttrBytes, v)
+	case *url.URL:
+		if v.IsAbs() {
+			b = appendProtoString(b, protoAttrURI, v.String())
+		} else {
+			b = appendProtoString(b, protoAttrURIRef, v.String())
+		}
+	case time.Time:
+		var ts []byte
+		ts = appendProtoVarint(ts, 1, uint64(v.Unix()))
+		ts = appendProtoVarint(ts, 2, uint64(v.Nanosecond()))
+		b = appendProtoBytes(b, protoAttrTimestamp, ts)
+	}
+
+	return b
+}
+
+// UnmarshalProto decodes an event from an io.cloudevents.v1.CloudEvent protobuf message. text_data is decoded
+// like the body of the datacontenttype (see SetDataBytes); text_data without a datacontenttype that is not JSON
+// is kept as text/plain. proto_data is decoded as ProtoData with the datacontenttype application/protobuf
+// unless another one is set. The event is not validated.
+func UnmarshalProto(b []byte) (*Event, error) {
+	var e Event
+	var specVersion string
+	var data any
+	var text []byte
+	var isText bool
+
+	err := parseProto(b, func(field int, wireType int, n uint64, v []byte) error {
+		if wireType != wireBytes {
+			return nil
+		}
+		switch field {
+		case protoFieldID:
+			id, err := uuid.ParseBytes(v)
+			if err != nil {
+				return fmt.Errorf("invalid event id: %w", err)
+			}
+			e.ID = id
+		case protoFieldSource:
+			e.Source = string(v)
+		case protoFieldSpecVersion:
+			specVersion = string(v)
+		case protoFieldType:
+			e.Type = string(v)
+		case protoFieldAttributes:
+			name, value, err := unmarshalProtoEntry(v)
+			if err != nil {
+				return err
+			}
+			return e.setProtoAttribute(name, value)
+		case protoFieldBinaryData:
+			data, isText = append([]byte(nil), v...), false
+		case protoFieldTextData:
+			text, isText = v, true
+		case protoFieldProtoData:
+			var p ProtoData
+			err := parseProto(v, func(field int, wireType int, _ uint64, v []byte) error {
+				switch {
+				case field == 1 && wireType == wireBytes:
+					p.TypeURL = string(v)
+				case field == 2 && wireType == wireBytes:
+					p.Value = append([]byte(nil), v...)
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("invalid event proto_data: %w", err)
+			}
+			data, isText = p, false
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if specVersion != SpecVersion {
+		return nil, fmt.Errorf("unsupported event specversion %q", specVersion)
+	}
+
+	switch {
+	case isText:
+		if err := e.SetDataBytes(e.DataContentType, text); err != nil {
+			if e.DataContentType != "" {
+				return nil, fmt.Errorf("invalid event data: %w", err)
+			}
+			e.Data, e.DataContentType = string(text), "text/plain"
+		}
+	case data != nil:
+		e.Data = data
+		if _, ok := data.(ProtoData); ok && e.DataContentType == "" {
+			e.DataContentType = "application/protobuf"
+		}
+	}
+
+	return &e, nil
+}
+
+// setProtoAttribute sets a standard attribute or an extension from a decoded attribute value.
+func (e *Event) setProtoAttribute(name string, value any) error {
+	switch name {
+	case "time":
+		t, ok := value.(time.Time)
+		if !ok {
+			return errors.New("event time must be a timestamp")
+		}
+		e.Time = t
+	case "subject", "datacontenttype", "dataschema":
+		var s string
+		switch v := value.(type) {
+		case string:
+			s = v
+		case *url.URL:
+			s = v.String()
+		default:
+			return fmt.Errorf("event %s must be a string", name)
+		}
+		switch name {
+		case "subject":
+			e.Subject = s
+		case "datacontenttype":
+			e.DataContentType = s
+		default:
+			e.DataSchema = s
+		}
+	default:
+		if err := validateExtensionName(name); err != nil {
+			return err
+		}
+		if e.Extensions == nil {
+			e.Extensions = make(map[string]any)
+		}
+		e.Extensions[name] = value
+	}
+
+	return nil
+}
+
+// unmarshalProtoEntry decodes an entry of the attributes map.
+func unmarshalProtoEntry(b []byte) (string, any, error) {
+	var name string
+	var value any
+	err := parseProto(b, func(field int, wireType int, _ uint64, v []byte) error {
+		if wireType != wireBytes {
+			return nil
+		}
+		switch field {
+		case 1:
+			name = string(v)
+		case 2:
+			var err error
+			value, err = unmarshalProtoAttribute(v)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if value == nil {
+		return "", nil, fmt.Errorf("event attribute %q has no value", name)
+	}
+
+	return name, value, nil
+}
+
+// unmarshalProtoAttribute decodes a CloudEventAttributeValue message.
+func unmarshalProtoAttribute(b []byte) (any, error) {
+	var value any
+	err := parseProto(b, func(field int, wireType int, n uint64, v []byte) error {
+		var err error
+		switch {
+		case field == protoAttrBoolean && wireType == wireVarint:
+			value = n != 0
+		case field == protoAttrInteger && wireType == wireVarint:
+			value = int32(n)
+		case field == protoAttrString && wireType == wireBytes:
+			value = string(v)
+		case field == protoAttrBytes && wireType == wireBytes:
+			value = append([]byte(nil), v...)
+		case (field == protoAttrURI || field == protoAttrURIRef) && wireType == wireBytes:
+			value, err = url.Parse(string(v))
+		case field == protoAttrTimestamp && wireType == wireBytes:
+			var seconds, nanos uint64
+			err = parseProto(v, func(field int, wireType int, n uint64, _ []byte) error {
+				switch {
+				case field == 1 && wireType == wireVarint:
+					seconds = n
+				case field == 2 && wireType == wireVarint:
+					nanos = n
+				}
+				return nil
+			})
+			value = time.Unix(int64(seconds), int64(int32(nanos))).UTC()
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid event attribute value: %w", err)
+	}
+
+	return value, nil
+}
+
+// parseProto calls fn for each field of the protobuf message b with the value of varint fields in n and the
+// content of length-delimited fields in v. Fixed-size fields are skipped.
+func parseProto(b []byte, fn func(field int, wireType int, n uint64, v []byte) error) error {
+	for len(b) > 0 {
+		tag, size := binary.Uvarint(b)
+		if size <= 0 {
+			return errors.New("malformed protobuf tag")
+		}
+		b = b[size:]
+
+		field, wireType := int(tag>>3), int(tag&7)
+		if field == 0 || tag>>3 > math.MaxInt32 {
+			return errors.New("malformed protobuf field number")
+		}
+
+		var n uint64
+		var v []byte
+		switch wireType {
+		case wireVarint:
+			n, size = binary.Uvarint(b)
+			if size <= 0 {
+				return errors.New("malformed protobuf varint")
+			}
+			b = b[size:]
+		case wireBytes:
+			length, size := binary.Uvarint(b)
+			if size <= 0 || length > uint64(len(b)-size) {
+				return errors.New("malformed protobuf length")
+			}
+			v, b = b[size:size+int(length)], b[size+int(length):]
+		case wireFixed64, wireFixed32:
+			length := 8
+			if wireType == wireFixed32 {
+				length = 4
+			}
+			if len(b) < length {
+				return errors.New("malformed protobuf fixed-size field")
+			}
+			b = b[length:]
+			continue
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", wireType)
+		}
+
+		if err := fn(field, wireType, n, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func appendProtoTag(b []byte, field int, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field)<<3|uint64(wireType))
+}
+
+func appendProtoVarint(b []byte, field int, n uint64) []byte {
+	return binary.AppendUvarint(appendProtoTag(b, field, wireVarint), n)
+}
+
+func appendProtoBytes(b []byte, field int, v []byte) []byte {
+	b = binary.AppendUvarint(appendProtoTag(b, field, wireBytes), uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendProtoString(b []byte, field int, s string) []byte {
+	return appendProtoBytes(b, field, []byte(s))
+}
@@ -0,0 +1,195 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// roundTripProto converts the event from JSON to protobuf and back to JSON.
+func roundTripProto(t *testing.T, e Event) *Event {
+	t.Helper()
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var fromJSON Event
+	if err := json.Unmarshal(b, &fromJSON); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	proto, err := MarshalProto(fromJSON)
+	if err != nil {
+		t.Fatalf("MarshalProto failed: %v", err)
+	}
+	decoded, err := UnmarshalProto(proto)
+	if err != nil {
+		t.Fatalf("UnmarshalProto failed: %v", err)
+	}
+
+	again, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !bytes.Equal(b, again) {
+		t.Errorf("expected JSON %s after the protobuf round trip, got %s", b, again)
+	}
+
+	return decoded
+}
+
+func TestProto_RoundTripJSONData(t *testing.T) {
+	e := newBookBorrowed(map[string]any{"bookId": "b-123", "copies": 2.0})
+	e.DataSchema = "https://library.example.com/schemas/book-borrowed.json"
+
+	decoded := roundTripProto(t, e)
+	if decoded.ID != e.ID || !decoded.Time.Equal(e.Time) || decoded.Subject != e.Subject || decoded.DataSchema != e.DataSchema {
+		t.Errorf("expected %+v, got %+v", e, decoded)
+	}
+	if !reflect.DeepEqual(decoded.Data, e.Data) {
+		t.Errorf("expected data %v, got %v", e.Data, decoded.Data)
+	}
+	if err := decoded.Validate(); err != nil {
+		t.Errorf("expected a valid event, got %v", err)
+	}
+}
+
+func TestProto_RoundTripBinaryAndTextData(t *testing.T) {
+	e := newBookBorrowed([]byte{0x00, 0xff, 0x10})
+	e.DataContentType = "application/octet-stream"
+	if decoded := roundTripProto(t, e); !bytes.Equal(decoded.Data.([]byte), e.Data.([]byte)) {
+		t.Errorf("expected binary data, got %v", decoded.Data)
+	}
+
+	e.Data, e.DataContentType = "<book id=\"b-123\"/>", "application/xml"
+	if decoded := roundTripProto(t, e); decoded.Data != e.Data || decoded.DataContentType != "application/xml" {
+		t.Errorf("expected text data, got %v", decoded.Data)
+	}
+}
+
+func TestProto_ProtoData(t *testing.T) {
+	e := newBookBorrowed(ProtoData{TypeURL: "type.googleapis.com/library.BookBorrowed", Value: []byte{0x0a, 0x05}})
+
+	b, err := MarshalProto(e)
+	if err != nil {
+		t.Fatalf("MarshalProto failed: %v", err)
+	}
+	decoded, err := UnmarshalProto(b)
+	if err != nil {
+		t.Fatalf("UnmarshalProto failed: %v", err)
+	}
+	if !reflect.DeepEqual(decoded.Data, e.Data) || decoded.DataContentType != "application/protobuf" {
+		t.Errorf("expected proto data %v, got %v (%s)", e.Data, decoded.Data, decoded.DataContentType)
+	}
+
+	// In the JSON format the serialized message is data_base64
+	j, _ := json.Marshal(decoded)
+	var fromJSON Event
+	if err := json.Unmarshal(j, &fromJSON); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !bytes.Equal(fromJSON.Data.([]byte), []byte{0x0a, 0x05}) {
+		t.Errorf("expected binary data, got %v", fromJSON.Data)
+	}
+}
+
+func TestProto_ExtensionTypes(t *testing.T) {
+	expires := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	e := newBookBorrowed(map[string]any{"bookId": "b-123"})
+	e.Extensions = map[string]any{
+		"sampled":  true,
+		"priority": -3,
+		"trace":    "00-abc-01",
+		"blob":     []byte{1, 2, 3},
+		"origin":   &url.URL{Scheme: "https", Host: "library.example.com"},
+		"branch":   &url.URL{Path: "/branches/7"},
+		"expires":  expires,
+	}
+
+	b, err := MarshalProto(e)
+	if err != nil {
+		t.Fatalf("MarshalProto failed: %v", err)
+	}
+	decoded, err := UnmarshalProto(b)
+	if err != nil {
+		t.Fatalf("UnmarshalProto failed: %v", err)
+	}
+
+	ext := decoded.Extensions
+	if ext["sampled"] != true || ext["priority"] != int32(-3) || ext["trace"] != "00-abc-01" || !bytes.Equal(ext["blob"].([]byte), []byte{1, 2, 3}) {
+		t.Errorf("unexpected extensions %v", ext)
+	}
+	if ext["origin"].(*url.URL).String() != "https://library.example.com" || ext["branch"].(*url.URL).String() != "/branches/7" {
+		t.Errorf("unexpected URI extensions %v", ext)
+	}
+	if !ext["expires"].(time.Time).Equal(expires) {
+		t.Errorf("expected timestamp %v, got %v", expires, ext["expires"])
+	}
+	if err := decoded.Validate(); err != nil {
+		t.Errorf("expected a valid event, got %v", err)
+	}
+
+	// The JSON format keeps the extensions as strings, numbers and booleans
+	decoded = roundTripProto(t, e)
+	if decoded.Extensions["expires"] != "2026-01-02T03:04:05.000000006Z" || decoded.Extensions["origin"] != "https://library.example.com" {
+		t.Errorf("unexpected extensions %v", decoded.Extensions)
+	}
+}
+
+func TestUnmarshalProto_WireFormat(t *testing.T) {
+	// id, source, spec_version, type, attributes {"priority": ce_integer -1}, text_data "x"
+	// with datacontenttype text/plain and an unknown fixed32 field
+	var b []byte
+	b = append(b, 0x0a, 0x24)
+	b = append(b, "6ba7b810-9dad-11d1-80b4-00c04fd430c8"...)
+	b = append(b, 0x12, 0x01, 's', 0x1a, 0x03, '1', '.', '0', 0x22, 0x01, 't')
+	priority := append([]byte{0x2a, 0x17, 0x0a, 0x08}, "priority"...)
+	priority = append(priority, 0x12, 0x0b, 0x10, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01)
+	b = append(b, priority...)
+	b = append(b, 0x2a, 0x1d, 0x0a, 0x0f)
+	b = append(b, "datacontenttype"...)
+	b = append(b, 0x12, 0x0a, 0x1a, 0x08)
+	b = append(b, "text/pla"...)
+	b = append(b, 0x3a, 0x01, 'x')
+	b = append(b, 0x4d, 0x01, 0x02, 0x03, 0x04)
+
+	e, err := UnmarshalProto(b)
+	if err != nil {
+		t.Fatalf("UnmarshalProto failed: %v", err)
+	}
+	if e.ID.String() != "6ba7b810-9dad-11d1-80b4-00c04fd430c8" || e.Source != "s" || e.Type != "t" {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if e.Extensions["priority"] != int32(-1) || e.DataContentType != "text/pla" || e.Data != "x" {
+		t.Errorf("unexpected attributes %+v", e)
+	}
+
+	// The encoder produces the same integer and data fields
+	out, err := MarshalProto(*e)
+	if err != nil {
+		t.Fatalf("MarshalProto failed: %v", err)
+	}
+	if !bytes.Contains(out, priority) || !bytes.HasSuffix(out, []byte{0x3a, 0x01, 'x'}) {
+		t.Errorf("unexpected encoding %x", out)
+	}
+}
+
+func TestUnmarshalProto_Invalid(t *testing.T) {
+	valid, _ := MarshalProto(newBookBorrowed(map[string]any{}))
+
+	for name, b := range map[string][]byte{
+		"truncated":    valid[:len(valid)-1],
+		"spec version": bytes.Replace(valid, []byte{0x1a, 0x03, '1', '.', '0'}, []byte{0x1a, 0x03, '0', '.', '3'}, 1),
+		"missing spec": {0x22, 0x01, 't'},
+		"bad id":       append([]byte{0x0a, 0x01, 'x'}, valid...),
+		"wire type":    append(append([]byte(nil), valid...), 0x0b),
+	} {
+		if _, err := UnmarshalProto(b); err == nil {
+			t.Errorf("expected error for %s", name)
+		}
+	}
+}
@@ -0,0 +1,562 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Schema is a compiled JSON Schema that validates event data.
+// It supports the validation keywords of JSON Schema 2020-12 except references: type, enum, const,
+// properties, required, additionalProperties, minProperties, maxProperties, items, minItems, maxItems,
+// uniqueItems, minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf, minLength, maxLength,
+// pattern, format (date-time, date, email, uri, uuid), allOf, anyOf, oneOf and not.
+// Annotations such as title or description are ignored, $ref is rejected.
+type Schema struct {
+	// doc is the decoded schema document.
+	doc any
+	// always is set for the boolean schemas true and false.
+	always *bool
+
+	types                []string
+	enum                 []any
+	constant             any
+	hasConst             bool
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *Schema
+	minProperties        *int
+	maxProperties        *int
+	items                *Schema
+	minItems             *int
+	maxItems             *int
+	uniqueItems          bool
+	minimum              *float64
+	maximum              *float64
+	exclusiveMinimum     *float64
+	exclusiveMaximum     *float64
+	multipleOf           *float64
+	minLength            *int
+	maxLength            *int
+	pattern              *regexp.Regexp
+	format               string
+	allOf                []*Schema
+	anyOf                []*Schema
+	oneOf                []*Schema
+	not                  *Schema
+}
+
+// SchemaViolation describes why data does not conform to a schema.
+// Path is the JSON pointer of the offending value, empty for the data itself.
+type SchemaViolation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (v SchemaViolation) String() string {
+	if v.Path == "" {
+		return v.Message
+	}
+
+	return v.Path + ": " + v.Message
+}
+
+var schemaTypes = map[string]bool{"null": true, "boolean": true, "object": true, "array": true, "number": true, "integer": true, "string": true}
+
+var schemaFormats = map[string]bool{"date-time": true, "date": true, "email": true, "uri": true, "uuid": true}
+
+// CompileSchema parses a JSON Schema document.
+func CompileSchema(raw []byte) (*Schema, error) {
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("invalid schema JSON: %w", err)
+	}
+
+	return compileSchema(doc, "")
+}
+
+func compileSchema(doc any, path string) (*Schema, error) {
+	if b, ok := doc.(bool); ok {
+		return &Schema{doc: doc, always: &b}, nil
+	}
+
+	m, ok := doc.(map[string]any)
+	if !ok {
+		return nil, schemaError(path, "", "a schema must be an object or a boolean")
+	}
+
+	s := &Schema{doc: doc}
+	var err error
+
+	if _, exists := m["$ref"]; exists {
+		return nil, schemaError(path, "$ref", "references are not supported")
+	}
+
+	if v, exists := m["type"]; exists {
+		switch t := v.(type) {
+		case string:
+			s.types = []string{t}
+		case []any:
+			for _, item := range t {
+				name, ok := item.(string)
+				if !ok {
+					return nil, schemaError(path, "type", "must be a string or an array of strings")
+				}
+				s.types = append(s.types, name)
+			}
+		default:
+			return nil, schemaError(path, "type", "must be a string or an array of strings")
+		}
+		for _, t := range s.types {
+			if !schemaTypes[t] {
+				return nil, schemaError(path, "type", "unknown type "+t)
+			}
+		}
+	}
+
+	if v, exists := m["enum"]; exists {
+		values, ok := v.([]any)
+		if !ok {
+			return nil, schemaError(path, "enum", "must be an array")
+		}
+		s.enum = values
+	}
+
+	if v, exists := m["const"]; exists {
+		s.constant, s.hasConst = v, true
+	}
+
+	if v, exists := m["properties"]; exists {
+		props, ok := v.(map[string]any)
+		if !ok {
+			return nil, schemaError(path, "properties", "must be an object")
+		}
+		s.properties = make(map[string]*Schema, len(props))
+		for name, sub := range props {
+			if s.properties[name], err = compileSchema(sub, path+"/properties/"+escapePointer(name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if v, exists := m["required"]; exists {
+		names, ok := v.([]any)
+		if !ok {
+			return nil, schemaError(path, "required", "must be an array of strings")
+		}
+		for _, item := range names {
+			name, ok := item.(string)
+			if !ok {
+				return nil, schemaError(path, "required", "must be an array of strings")
+			}
+			s.required = append(s.required, name)
+		}
+	}
+
+	if v, exists := m["additionalProperties"]; exists {
+		if s.additionalProperties, err = compileSchema(v, path+"/additionalProperties"); err != nil {
+			return nil, err
+		}
+	}
+	if v, exists := m["items"]; exists {
+		if s.items, err = compileSchema(v, path+"/items"); err != nil {
+			return nil, err
+		}
+	}
+	if v, exists := m["not"]; exists {
+		if s.not, err = compileSchema(v, path+"/not"); err != nil {
+			return nil, err
+		}
+	}
+
+	for keyword, target := range map[string]*[]*Schema{"allOf": &s.allOf, "anyOf": &s.anyOf, "oneOf": &s.oneOf} {
+		v, exists := m[keyword]
+		if !exists {
+			continue
+		}
+		subs, ok := v.([]any)
+		if !ok || len(subs) == 0 {
+			return nil, schemaError(path, keyword, "must be a non-empty array of schemas")
+		}
+		for i, sub := range subs {
+			compiled, err := compileSchema(sub, path+"/"+keyword+"/"+strconv.Itoa(i))
+			if err != nil {
+				return nil, err
+			}
+			*target = append(*target, compiled)
+		}
+	}
+
+	for keyword, target := range map[string]**int{
+		"minProperties": &s.minProperties, "maxProperties": &s.maxProperties,
+		"minItems": &s.minItems, "maxItems": &s.maxItems,
+		"minLength": &s.minLength, "maxLength": &s.maxLength,
+	} {
+		v, exists := m[keyword]
+		if !exists {
+			continue
+		}
+		n, ok := v.(float64)
+		if !ok || n < 0 || n != math.Trunc(n) {
+			return nil, schemaError(path, keyword, "must be a non-negative integer")
+		}
+		i := int(n)
+		*target = &i
+	}
+
+	for keyword, target := range map[string]**float64{
+		"minimum": &s.minimum, "maximum": &s.maximum,
+		"exclusiveMinimum": &s.exclusiveMinimum, "exclusiveMaximum": &s.exclusiveMaximum,
+		"multipleOf": &s.multipleOf,
+	} {
+		v, exists := m[keyword]
+		if !exists {
+			continue
+		}
+		n, ok := v.(float64)
+		if !ok {
+			return nil, schemaError(path, keyword, "must be a number")
+		}
+		*target = &n
+	}
+	if s.multipleOf != nil && *s.multipleOf <= 0 {
+		return nil, schemaError(path, "multipleOf", "must be greater than 0")
+	}
+
+	if v, exists := m["uniqueItems"]; exists {
+		unique, ok := v.(bool)
+		if !ok {
+			return nil, schemaError(path, "uniqueItems", "must be a boolean")
+		}
+		s.uniqueItems = unique
+	}
+
+	if v, exists := m["pattern"]; exists {
+		pattern, ok := v.(string)
+		if !ok {
+			return nil, schemaError(path, "pattern", "must be a string")
+		}
+		if s.pattern, err = regexp.Compile(pattern); err != nil {
+			return nil, schemaError(path, "pattern", err.Error())
+		}
+	}
+
+	if v, exists := m["format"]; exists {
+		format, ok := v.(string)
+		if !ok {
+			return nil, schemaError(path, "format", "must be a string")
+		}
+		if !schemaFormats[format] {
+			return nil, schemaError(path, "format", "unsupported format "+format)
+		}
+		s.format = format
+	}
+
+	return s, nil
+}
+
+func schemaError(path, keyword, message string) error {
+	if keyword != "" {
+		path += "/" + keyword
+	}
+	if path == "" {
+		return errors.New("invalid schema: " + message)
+	}
+
+	return fmt.Errorf("invalid schema at %s: %s", path, message)
+}
+
+// Validate returns the violations of the data against the schema, or nil if the data conforms.
+// Data that is not made of the types produced by encoding/json, such as a struct, is converted to them first.
+func (s *Schema) Validate(data any) []SchemaViolation {
+	normalized, err := normalizeJSON(data)
+	if err != nil {
+		return []SchemaViolation{{Message: "data is not JSON: " + err.Error()}}
+	}
+
+	return s.validate(normalized, "")
+}
+
+func (s *Schema) validate(v any, path string) []SchemaViolation {
+	if s.always != nil {
+		if *s.always {
+			return nil
+		}
+		return []SchemaViolation{{Path: path, Message: "no value is allowed"}}
+	}
+
+	var violations []SchemaViolation
+	fail := func(format string, args ...any) {
+		violations = append(violations, SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.types) > 0 && !matchesAnyType(v, s.types) {
+		fail("expected %s, got %s", strings.Join(s.types, " or "), jsonType(v))
+		// The other keywords would only repeat the type mismatch
+		return violations
+	}
+
+	if s.enum != nil && !containsJSON(s.enum, v) {
+		fail("value is not one of the allowed values")
+	}
+	if s.hasConst && !reflect.DeepEqual(s.constant, v) {
+		fail("value must be %s", formatJSON(s.constant))
+	}
+
+	switch value := v.(type) {
+	case map[string]any:
+		violations = append(violations, s.validateObject(value, path)...)
+	case []any:
+		violations = append(violations, s.validateArray(value, path)...)
+	case float64:
+		violations = append(violations, s.validateNumber(value, path)...)
+	case string:
+		violations = append(violations, s.validateString(value, path)...)
+	}
+
+	for _, sub := range s.allOf {
+		violations = append(violations, sub.validate(v, path)...)
+	}
+	if s.anyOf != nil && countValid(s.anyOf, v, path) == 0 {
+		fail("value does not match any of the schemas in anyOf")
+	}
+	if s.oneOf != nil {
+		if n := countValid(s.oneOf, v, path); n != 1 {
+			fail("value must match exactly one schema in oneOf, matches %d", n)
+		}
+	}
+	if s.not != nil && len(s.not.validate(v, path)) == 0 {
+		fail("value must not match the schema in not")
+	}
+
+	return violations
+}
+
+func (s *Schema) validateObject(m map[string]any, path string) []SchemaViolation {
+	var violations []SchemaViolation
+
+	for _, name := range s.required {
+		if _, exists := m[name]; !exists {
+			violations = append(violations, SchemaViolation{Path: path, Message: "missing required property " + name})
+		}
+	}
+	if s.minProperties != nil && len(m) < *s.minProperties {
+		violations = append(violations, SchemaViolation{Path: path, Message: fmt.Sprintf("must have at least %d properties", *s.minProperties)})
+	}
+	if s.maxProperties != nil && len(m) > *s.maxProperties {
+		violations = append(violations, SchemaViolation{Path: path, Message: fmt.Sprintf("must have at most %d properties", *s.maxProperties)})
+	}
+
+	// Sorted names keep the order of the violations stable
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propertyPath := path + "/" + escapePointer(name)
+		if sub, exists := s.properties[name]; exists {
+			violations = append(violations, sub.validate(m[name], propertyPath)...)
+		} else if s.additionalProperties != nil {
+			if s.additionalProperties.always != nil && !*s.additionalProperties.always {
+				violations = append(violations, SchemaViolation{Path: propertyPath, Message: "additional property is not allowed"})
+				continue
+			}
+			violations = append(violations, s.additionalProperties.validate(m[name], propertyPath)...)
+		}
+	}
+
+	return violations
+}
+
+func (s *Schema) validateArray(items []any, path string) []SchemaViolation {
+	var violations []SchemaViolation
+
+	if s.minItems != nil && len(items) < *s.minItems {
+		violations = append(violations, SchemaViolation{Path: path, Message: fmt.Sprintf("must have at least %d items", *s.minItems)})
+	}
+	if s.maxItems != nil && len(items) > *s.maxItems {
+		violations = append(violations, SchemaViolation{Path: path, Message: fmt.Sprintf("must have at most %d items", *s.maxItems)})
+	}
+	if s.uniqueItems {
+		for i := range items {
+			if containsJSON(items[:i], items[i]) {
+				violations = append(violations, SchemaViolation{Path: path, Message: "items must be unique"})
+				break
+			}
+		}
+	}
+	if s.items != nil {
+		for i, item := range items {
+			violations = append(violations, s.items.validate(item, path+"/"+strconv.Itoa(i))...)
+		}
+	}
+
+	return violations
+}
+
+func (s *Schema) validateNumber(n float64, path string) []SchemaViolation {
+	var violations []SchemaViolation
+	fail := func(format string, args ...any) {
+		violations = append(violations, SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.minimum != nil && n < *s.minimum {
+		fail("must be at least %v", *s.minimum)
+	}
+	if s.maximum != nil && n > *s.maximum {
+		fail("must be at most %v", *s.maximum)
+	}
+	if s.exclusiveMinimum != nil && n <= *s.exclusiveMinimum {
+		fail("must be greater than %v", *s.exclusiveMinimum)
+	}
+	if s.exclusiveMaximum != nil && n >= *s.exclusiveMaximum {
+		fail("must be less than %v", *s.exclusiveMaximum)
+	}
+	if s.multipleOf != nil {
+		if q := n / *s.multipleOf; math.Abs(q-math.Round(q)) > 1e-9 {
+			fail("must be a multiple of %v", *s.multipleOf)
+		}
+	}
+
+	return violations
+}
+
+func (s *Schema) validateString(str string, path string) []SchemaViolation {
+	var violations []SchemaViolation
+	fail := func(format string, args ...any) {
+		violations = append(violations, SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	length := utf8.RuneCountInString(str)
+	if s.minLength != nil && length < *s.minLength {
+		fail("must be at least %d characters long", *s.minLength)
+	}
+	if s.maxLength != nil && length > *s.maxLength {
+		fail("must be at most %d characters long", *s.maxLength)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		fail("must match the pattern %s", s.pattern.String())
+	}
+	if s.format != "" && !matchesFormat(s.format, str) {
+		fail("must be a valid %s", s.format)
+	}
+
+	return violations
+}
+
+func countValid(schemas []*Schema, v any, path string) int {
+	n := 0
+	for _, sub := range schemas {
+		if len(sub.validate(v, path)) == 0 {
+			n++
+		}
+	}
+
+	return n
+}
+
+func matchesAnyType(v any, types []string) bool {
+	actual := jsonType(v)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// jsonType returns the JSON Schema type of a value decoded by encoding/json.
+// Numbers without a fractional part are integers.
+func jsonType(v any) string {
+	switch n := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case float64:
+		if n == math.Trunc(n) && !math.IsInf(n, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	}
+
+	return fmt.Sprintf("%T", v)
+}
+
+func matchesFormat(format, s string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, s)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, s)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	case "uri":
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs()
+	case "uuid":
+		_, err := uuid.Parse(s)
+		return err == nil && len(s) == 36
+	}
+
+	return true
+}
+
+func containsJSON(values []any, v any) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(candidate, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func formatJSON(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// normalizeJSON converts a value to the types produced by encoding/json by encoding and decoding it.
+func normalizeJSON(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized any
+	if err := json.Unmarshal(b, &normalized); err != nil {
+		return nil, err
+	}
+
+	return normalized, nil
+}
+
+// escapePointer escapes a property name for use as a JSON pointer segment.
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,113 @@
+package event
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const bookBorrowedSchema = `{
+	"$id": "https://library.example.com/schemas/book-borrowed-v1.json",
+	"type": "object",
+	"required": ["bookId", "memberId", "dueDate"],
+	"properties": {
+		"bookId": {"type": "string", "minLength": 3},
+		"memberId": {"type": "integer", "minimum": 1},
+		"dueDate": {"type": "string", "format": "date"},
+		"tags": {"type": "array", "items": {"enum": ["fiction", "science"]}, "uniqueItems": true}
+	},
+	"additionalProperties": false
+}`
+
+func TestSchema_Validate(t *testing.T) {
+	schema, err := CompileSchema([]byte(bookBorrowedSchema))
+	if err != nil {
+		t.Fatalf("CompileSchema failed: %v", err)
+	}
+
+	cases := []struct {
+		name       string
+		data       string
+		violations []string
+	}{
+		{"valid", `{"bookId":"b-123","memberId":7,"dueDate":"2025-10-01","tags":["fiction"]}`, nil},
+		{"not an object", `"book"`, []string{"expected object, got string"}},
+		{"missing required", `{"bookId":"b-123","memberId":7}`, []string{"missing required property dueDate"}},
+		{"wrong types", `{"bookId":123,"memberId":7.5,"dueDate":"2025-10-01"}`, []string{"/bookId: expected string, got integer", "/memberId: expected integer, got number"}},
+		{"constraints", `{"bookId":"b","memberId":0,"dueDate":"tomorrow"}`, []string{"/bookId: must be at least 3 characters long", "/dueDate: must be a valid date", "/memberId: must be at least 1"}},
+		{"items", `{"bookId":"b-123","memberId":7,"dueDate":"2025-10-01","tags":["fiction","poetry","fiction"]}`, []string{"/tags: items must be unique", "/tags/1: value is not one of the allowed values"}},
+		{"additional property", `{"bookId":"b-123","memberId":7,"dueDate":"2025-10-01","title":"Dune"}`, []string{"/title: additional property is not allowed"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var data any
+			if err := json.Unmarshal([]byte(tc.data), &data); err != nil {
+				t.Fatalf("invalid test data: %v", err)
+			}
+
+			var got []string
+			for _, v := range schema.Validate(data) {
+				got = append(got, v.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tc.violations, "\n") {
+				t.Errorf("expected violations %q, got %q", tc.violations, got)
+			}
+		})
+	}
+}
+
+func TestSchema_ValidateStruct(t *testing.T) {
+	schema, err := CompileSchema([]byte(`{"type":"object","required":["amount"],"properties":{"amount":{"type":"number","exclusiveMinimum":0}}}`))
+	if err != nil {
+		t.Fatalf("CompileSchema failed: %v", err)
+	}
+
+	type order struct {
+		Amount float64 `json:"amount"`
+	}
+	if violations := schema.Validate(order{Amount: 19.99}); len(violations) != 0 {
+		t.Errorf("expected struct to conform, got %v", violations)
+	}
+	if violations := schema.Validate(order{Amount: 0}); len(violations) != 1 {
+		t.Errorf("expected one violation, got %v", violations)
+	}
+}
+
+func TestSchema_Combinators(t *testing.T) {
+	schema, err := CompileSchema([]byte(`{"oneOf":[{"type":"string"},{"type":"integer"}],"not":{"const":"none"}}`))
+	if err != nil {
+		t.Fatalf("CompileSchema failed: %v", err)
+	}
+
+	for _, valid := range []any{"some", 3.0} {
+		if violations := schema.Validate(valid); len(violations) != 0 {
+			t.Errorf("expected %v to conform, got %v", valid, violations)
+		}
+	}
+	for _, invalid := range []any{"none", 1.5, true} {
+		if violations := schema.Validate(invalid); len(violations) == 0 {
+			t.Errorf("expected %v not to conform", invalid)
+		}
+	}
+}
+
+func TestCompileSchema_Errors(t *testing.T) {
+	cases := map[string]string{
+		"not JSON":        `{`,
+		"not a schema":    `42`,
+		"unknown type":    `{"type":"decimal"}`,
+		"invalid pattern": `{"pattern":"("}`,
+		"invalid nested":  `{"properties":{"a":{"minLength":-1}}}`,
+		"reference":       `{"$ref":"#/$defs/book"}`,
+		"unknown format":  `{"format":"isbn"}`,
+	}
+
+	for name, raw := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := CompileSchema([]byte(raw)); err == nil {
+				t.Errorf("expected error for %s", raw)
+			}
+		})
+	}
+}
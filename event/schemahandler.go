@@ -0,0 +1,130 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// SchemasResponse is the response of the list schemas endpoint.
+type SchemasResponse struct {
+	Ok      bool          `json:"ok"`
+	Schemas []SchemaEntry `json:"schemas"`
+}
+
+// SchemaResponse is the response of the get and register schema endpoints.
+type SchemaResponse struct {
+	Ok     bool        `json:"ok"`
+	Schema SchemaEntry `json:"schema"`
+}
+
+// DeleteSchemaResponse is the response of the delete schema endpoint.
+type DeleteSchemaResponse struct {
+	Ok   bool   `json:"ok"`
+	Type string `json:"type"`
+}
+
+// SchemaErrorResponse is the response of the schema endpoints if the request failed.
+type SchemaErrorResponse struct {
+	Ok    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// SchemaHandler returns an HTTP handler that manages the schemas of the registry. Mount it at both
+// "/schemas" and "/schemas/":
+//
+//	GET    /schemas        lists the registered schemas
+//	GET    /schemas/{type} returns the schema of the event type
+//	PUT    /schemas/{type} registers the JSON Schema in the request body for the event type
+//	DELETE /schemas/{type} removes the schema of the event type
+//
+// The compatibility query parameter of PUT overrides the compatibility rule of the registry. Registering
+// responds with 409 if a different schema is registered for the type or if the schema is not compatible
+// with the neighbouring versions of the type.
+func SchemaHandler(registry *SchemaRegistry) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /schemas", listSchemas(registry))
+	mux.HandleFunc("GET /schemas/{type}", getSchema(registry))
+	mux.HandleFunc("PUT /schemas/{type}", registerSchema(registry))
+	mux.HandleFunc("DELETE /schemas/{type}", deleteSchema(registry))
+	return mux
+}
+
+func listSchemas(registry *SchemaRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeSchemaResponse(w, http.StatusOK, SchemasResponse{Ok: true, Schemas: registry.List()})
+	}
+}
+
+func getSchema(registry *SchemaRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entry, exists := registry.Get(r.PathValue("type"))
+		if !exists {
+			writeSchemaResponse(w, http.StatusNotFound, SchemaErrorResponse{Error: ErrSchemaNotFound.Error()})
+			return
+		}
+
+		writeSchemaResponse(w, http.StatusOK, SchemaResponse{Ok: true, Schema: entry})
+	}
+}
+
+func registerSchema(registry *SchemaRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var compatibility Compatibility
+		if c := r.URL.Query().Get("compatibility"); c != "" {
+			var err error
+			if compatibility, err = ParseCompatibility(c); err != nil {
+				writeSchemaResponse(w, http.StatusBadRequest, SchemaErrorResponse{Error: err.Error()})
+				return
+			}
+		}
+
+		var schema json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&schema); err != nil {
+			log.Printf("ERROR Failed to decode JSON request: %v", err)
+			http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		eventType := r.PathValue("type")
+		entry, err := registry.RegisterWith(eventType, schema, compatibility)
+		if err != nil {
+			status := http.StatusBadRequest
+			var compatibilityErr *SchemaCompatibilityError
+			if errors.Is(err, ErrSchemaExists) || errors.As(err, &compatibilityErr) {
+				status = http.StatusConflict
+			}
+			log.Printf("WARN Failed to register schema for %s: %v", eventType, err)
+			writeSchemaResponse(w, status, SchemaErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("INFO Registered schema for %s", eventType)
+		writeSchemaResponse(w, http.StatusOK, SchemaResponse{Ok: true, Schema: entry})
+	}
+}
+
+func deleteSchema(registry *SchemaRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		eventType := r.PathValue("type")
+		if err := registry.Delete(eventType); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, ErrSchemaNotFound) {
+				status = http.StatusNotFound
+			}
+			writeSchemaResponse(w, status, SchemaErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("INFO Deleted schema for %s", eventType)
+		writeSchemaResponse(w, http.StatusOK, DeleteSchemaResponse{Ok: true, Type: eventType})
+	}
+}
+
+// writeSchemaResponse sends the data as JSON response with the given HTTP status code.
+func writeSchemaResponse(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
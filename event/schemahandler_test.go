@@ -0,0 +1,86 @@
+package event
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSchemaHandler(t *testing.T) {
+	handler := SchemaHandler(NewSchemaRegistry())
+	mux := http.NewServeMux()
+	mux.Handle("/schemas", handler)
+	mux.Handle("/schemas/", handler)
+
+	v1 := `{"type":"object","required":["bookId"],"properties":{"bookId":{"type":"string"}}}`
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/schemas/com.library.book.borrowed:v1", strings.NewReader(v1)))
+	var registered SchemaResponse
+	if err := json.NewDecoder(rec.Body).Decode(&registered); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !registered.Ok || registered.Schema.Type != "com.library.book.borrowed:v1" {
+		t.Errorf("unexpected response %+v", registered)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/schemas/com.library.book.borrowed:v1", strings.NewReader(`{"type":"string"}`)))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 for a different schema, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/schemas/com.library.book.returned:v1", strings.NewReader(`{"type":"text"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid schema, got %d", rec.Code)
+	}
+
+	v2 := `{"type":"object","required":["bookId","dueDate"],"properties":{"bookId":{"type":"string"},"dueDate":{"type":"string"}}}`
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/schemas/com.library.book.borrowed:v2", strings.NewReader(v2)))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 for a backward incompatible schema, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/schemas/com.library.book.borrowed:v2?compatibility=sideways", strings.NewReader(v2)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid compatibility, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/schemas/com.library.book.borrowed:v2?compatibility=forward", strings.NewReader(v2)))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200 for a forward compatible schema, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/schemas", nil))
+	var list SchemasResponse
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !list.Ok || len(list.Schemas) != 2 {
+		t.Errorf("unexpected response %+v", list)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/schemas/com.library.book.borrowed:v1", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/schemas/com.library.book.borrowed:v1", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for a deleted schema, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/schemas/com.library.book.borrowed:v2", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", rec.Code)
+	}
+}
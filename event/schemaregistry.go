@@ -0,0 +1,340 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrSchemaNotFound is returned for event types without a registered schema.
+	ErrSchemaNotFound = errors.New("schema not found")
+	// ErrSchemaExists is returned when registering a different schema for an event type that already has one.
+	ErrSchemaExists = errors.New("a different schema is already registered for this event type")
+)
+
+// SchemaEntry is a JSON Schema registered for an event type. The type includes the version, e.g.
+// com.library.book.borrowed:v1. ID is the $id of the schema, which events can reference as their dataschema.
+type SchemaEntry struct {
+	Type   string          `json:"type"`
+	ID     string          `json:"id,omitempty"`
+	Schema json.RawMessage `json:"schema"`
+
+	compiled *Schema
+}
+
+// SchemaValidationError is returned for events whose data does not conform to the schema of their type.
+type SchemaValidationError struct {
+	Type       string            `json:"type"`
+	Violations []SchemaViolation `json:"violations"`
+}
+
+func (e *SchemaValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.String()
+	}
+
+	return fmt.Sprintf("event data does not conform to the schema of %s: %s", e.Type, strings.Join(messages, "; "))
+}
+
+// SchemaRegistry holds the JSON Schemas of event types. It is safe for concurrent use.
+// A registry opened with a file path persists every change to that file.
+// New schema versions must be compatible with their neighbouring versions, backward by default.
+type SchemaRegistry struct {
+	path string
+
+	mu            sync.RWMutex
+	entries       map[string]SchemaEntry
+	compatibility Compatibility
+}
+
+// NewSchemaRegistry creates an empty registry that is kept in memory.
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{entries: make(map[string]SchemaEntry), compatibility: CompatibilityBackward}
+}
+
+// SetCompatibility sets the compatibility rule for new schema versions.
+func (r *SchemaRegistry) SetCompatibility(c Compatibility) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.compatibility = c
+}
+
+// Compatibility returns the compatibility rule for new schema versions.
+func (r *SchemaRegistry) Compatibility() Compatibility {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.compatibility
+}
+
+// OpenSchemaRegistry loads the registry from the JSON file at the path, if it exists,
+// and persists every change to it.
+func OpenSchemaRegistry(path string) (*SchemaRegistry, error) {
+	r := NewSchemaRegistry()
+	r.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []SchemaEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid schema registry %s: %w", path, err)
+	}
+	for _, entry := range entries {
+		if entry.compiled, err = CompileSchema(entry.Schema); err != nil {
+			return nil, fmt.Errorf("schema of %s: %w", entry.Type, err)
+		}
+		r.entries[entry.Type] = entry
+	}
+
+	return r, nil
+}
+
+// ParseEventType splits an event type into its name and version, e.g. com.library.book.borrowed:v2 into
+// com.library.book.borrowed and 2. Types without a version suffix have version 0.
+func ParseEventType(eventType string) (name string, version int) {
+	name, suffix, found := strings.Cut(eventType, ":v")
+	if !found {
+		return eventType, 0
+	}
+
+	version, err := strconv.Atoi(suffix)
+	if err != nil || version < 1 {
+		return eventType, 0
+	}
+
+	return name, version
+}
+
+// Register registers the JSON Schema for the event type. Registering the same schema again has no effect.
+// The schema must satisfy the compatibility rule of the registry towards the closest lower and higher versions
+// of the event type, otherwise a *SchemaCompatibilityError is returned.
+func (r *SchemaRegistry) Register(eventType string, schema json.RawMessage) (SchemaEntry, error) {
+	return r.RegisterWith(eventType, schema, "")
+}
+
+// RegisterWith registers the JSON Schema for the event type like Register, but with the compatibility rule c
+// instead of the rule of the registry if c is not empty.
+func (r *SchemaRegistry) RegisterWith(eventType string, schema json.RawMessage, c Compatibility) (SchemaEntry, error) {
+	if len(strings.TrimSpace(eventType)) < 5 {
+		return SchemaEntry{}, errors.New("event type must be at least 5 characters long")
+	}
+
+	compiled, err := CompileSchema(schema)
+	if err != nil {
+		return SchemaEntry{}, err
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, schema); err != nil {
+		return SchemaEntry{}, err
+	}
+	entry := SchemaEntry{Type: eventType, ID: schemaID(schema), Schema: compact.Bytes(), compiled: compiled}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, exists := r.entries[eventType]; exists {
+		if bytes.Equal(existing.Schema, entry.Schema) {
+			return existing, nil
+		}
+		return SchemaEntry{}, ErrSchemaExists
+	}
+	if entry.ID != "" {
+		for _, other := range r.entries {
+			if other.ID == entry.ID {
+				return SchemaEntry{}, fmt.Errorf("schema $id %s is already used by %s", entry.ID, other.Type)
+			}
+		}
+	}
+	if c == "" {
+		c = r.compatibility
+	}
+	if err := r.checkCompatibility(entry, c); err != nil {
+		return SchemaEntry{}, err
+	}
+
+	r.entries[eventType] = entry
+	if err := r.persist(); err != nil {
+		delete(r.entries, eventType)
+		return SchemaEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// Get returns the schema registered for the event type.
+func (r *SchemaRegistry) Get(eventType string) (SchemaEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, exists := r.entries[eventType]
+	return entry, exists
+}
+
+// List returns all schemas sorted by event name and version.
+func (r *SchemaRegistry) List() []SchemaEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]SchemaEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		nameI, versionI := ParseEventType(entries[i].Type)
+		nameJ, versionJ := ParseEventType(entries[j].Type)
+		if nameI != nameJ {
+			return nameI < nameJ
+		}
+		return versionI < versionJ
+	})
+
+	return entries
+}
+
+// Delete removes the schema of the event type.
+func (r *SchemaRegistry) Delete(eventType string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, exists := r.entries[eventType]
+	if !exists {
+		return ErrSchemaNotFound
+	}
+
+	delete(r.entries, eventType)
+	if err := r.persist(); err != nil {
+		r.entries[eventType] = entry
+		return err
+	}
+
+	return nil
+}
+
+// ValidateEvent validates the data of the event against its schema and returns a *SchemaValidationError
+// listing the violations if it does not conform. The schema is selected by the dataschema of the event if it
+// matches the $id of a registered schema, otherwise by the event type. Events without a schema are valid.
+func (r *SchemaRegistry) ValidateEvent(e Event) error {
+	entry, exists := r.lookup(e)
+	if !exists {
+		return nil
+	}
+
+	if violations := entry.compiled.Validate(e.Data); len(violations) > 0 {
+		return &SchemaValidationError{Type: entry.Type, Violations: violations}
+	}
+
+	return nil
+}
+
+// checkCompatibility checks the new entry against the closest lower and higher registered versions of its
+// event type. The caller must hold the lock.
+func (r *SchemaRegistry) checkCompatibility(entry SchemaEntry, c Compatibility) error {
+	name, version := ParseEventType(entry.Type)
+	if version == 0 || c == CompatibilityNone {
+		return nil
+	}
+
+	var previous, next *SchemaEntry
+	for _, other := range r.entries {
+		otherName, otherVersion := ParseEventType(other.Type)
+		if otherName != name || otherVersion == 0 {
+			continue
+		}
+		if otherVersion < version && (previous == nil || otherVersion > versionOf(previous)) {
+			previous = &other
+		}
+		if otherVersion > version && (next == nil || otherVersion < versionOf(next)) {
+			next = &other
+		}
+	}
+
+	if previous != nil {
+		if issues := CheckCompatibility(previous.compiled, entry.compiled, c); len(issues) > 0 {
+			return &SchemaCompatibilityError{Type: entry.Type, Other: previous.Type, Compatibility: c, Issues: issues}
+		}
+	}
+	if next != nil {
+		if issues := CheckCompatibility(entry.compiled, next.compiled, c); len(issues) > 0 {
+			return &SchemaCompatibilityError{Type: next.Type, Other: entry.Type, Compatibility: c, Issues: issues}
+		}
+	}
+
+	return nil
+}
+
+func versionOf(entry *SchemaEntry) int {
+	_, version := ParseEventType(entry.Type)
+	return version
+}
+
+func (r *SchemaRegistry) lookup(e Event) (SchemaEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if e.DataSchema != "" {
+		for _, entry := range r.entries {
+			if entry.ID == e.DataSchema {
+				return entry, true
+			}
+		}
+	}
+
+	entry, exists := r.entries[e.Type]
+	return entry, exists
+}
+
+// persist writes the registry to its file. The caller must hold the write lock.
+func (r *SchemaRegistry) persist() error {
+	if r.path == "" {
+		return nil
+	}
+
+	entries := make([]SchemaEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Type < entries[j].Type })
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+
+	tmpPath := r.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, r.path)
+}
+
+// schemaID returns the $id of the schema document, or an empty string.
+func schemaID(schema json.RawMessage) string {
+	var doc struct {
+		ID string `json:"$id"`
+	}
+	json.Unmarshal(schema, &doc)
+
+	return doc.ID
+}
@@ -0,0 +1,116 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newBookBorrowed(data any) Event {
+	return Event{ID: uuid.New(), Type: "com.library.book.borrowed:v1", Time: time.Now(), Source: "https://library.example.com", Subject: "/books/123", Data: data}
+}
+
+func TestParseEventType(t *testing.T) {
+	cases := []struct {
+		eventType string
+		name      string
+		version   int
+	}{
+		{"com.library.book.borrowed:v2", "com.library.book.borrowed", 2},
+		{"com.library.book.borrowed", "com.library.book.borrowed", 0},
+		{"com.library.book.borrowed:vx", "com.library.book.borrowed:vx", 0},
+	}
+
+	for _, tc := range cases {
+		name, version := ParseEventType(tc.eventType)
+		if name != tc.name || version != tc.version {
+			t.Errorf("ParseEventType(%q) = %q, %d", tc.eventType, name, version)
+		}
+	}
+}
+
+func TestSchemaRegistry_ValidateEvent(t *testing.T) {
+	r := NewSchemaRegistry()
+	if _, err := r.Register("com.library.book.borrowed:v1", json.RawMessage(bookBorrowedSchema)); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	valid := map[string]any{"bookId": "b-123", "memberId": 7, "dueDate": "2025-10-01"}
+	if err := r.ValidateEvent(newBookBorrowed(valid)); err != nil {
+		t.Errorf("expected valid event, got %v", err)
+	}
+
+	var validationErr *SchemaValidationError
+	err := r.ValidateEvent(newBookBorrowed(map[string]any{"bookId": "b-123"}))
+	if !errors.As(err, &validationErr) || len(validationErr.Violations) != 2 {
+		t.Fatalf("expected two violations, got %v", err)
+	}
+
+	other := newBookBorrowed(map[string]any{})
+	other.Type = "com.library.book.returned:v1"
+	if err := r.ValidateEvent(other); err != nil {
+		t.Errorf("expected event without schema to be valid, got %v", err)
+	}
+
+	// The dataschema selects the schema regardless of the type
+	other.DataSchema = "https://library.example.com/schemas/book-borrowed-v1.json"
+	if err := r.ValidateEvent(other); !errors.As(err, &validationErr) {
+		t.Errorf("expected the schema referenced by dataschema to be applied, got %v", err)
+	}
+}
+
+func TestSchemaRegistry_Register(t *testing.T) {
+	r := NewSchemaRegistry()
+	if _, err := r.Register("com.library.book.borrowed:v1", json.RawMessage(bookBorrowedSchema)); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if _, err := r.Register("com.library.book.borrowed:v1", json.RawMessage(bookBorrowedSchema)); err != nil {
+		t.Errorf("expected registering the same schema again to succeed, got %v", err)
+	}
+	if _, err := r.Register("com.library.book.borrowed:v1", json.RawMessage(`{"type":"object"}`)); !errors.Is(err, ErrSchemaExists) {
+		t.Errorf("expected ErrSchemaExists, got %v", err)
+	}
+	if _, err := r.Register("com.library.book.borrowed:v2", json.RawMessage(bookBorrowedSchema)); err == nil {
+		t.Error("expected error for a duplicate $id")
+	}
+	if _, err := r.Register("com.library.book.borrowed:v2", json.RawMessage(`{"type":"book"}`)); err == nil {
+		t.Error("expected error for an invalid schema")
+	}
+
+	if err := r.Delete("com.library.book.borrowed:v1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := r.Delete("com.library.book.borrowed:v1"); !errors.Is(err, ErrSchemaNotFound) {
+		t.Errorf("expected ErrSchemaNotFound, got %v", err)
+	}
+}
+
+func TestSchemaRegistry_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schemas.json")
+	r, err := OpenSchemaRegistry(path)
+	if err != nil {
+		t.Fatalf("OpenSchemaRegistry failed: %v", err)
+	}
+	for _, eventType := range []string{"com.library.book.borrowed:v10", "com.library.book.borrowed:v2"} {
+		if _, err := r.Register(eventType, json.RawMessage(`{"type":"object"}`)); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	r, err = OpenSchemaRegistry(path)
+	if err != nil {
+		t.Fatalf("OpenSchemaRegistry failed: %v", err)
+	}
+	entries := r.List()
+	if len(entries) != 2 || entries[0].Type != "com.library.book.borrowed:v2" || entries[1].Type != "com.library.book.borrowed:v10" {
+		t.Fatalf("expected the schemas sorted by version, got %v", entries)
+	}
+	if err := r.ValidateEvent(Event{Type: "com.library.book.borrowed:v2", Data: "text"}); err == nil {
+		t.Error("expected reopened schema to validate")
+	}
+}
@@ -0,0 +1,157 @@
+package event
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader is the HTTP header carrying the signature of a webhook request body.
+// Its value has the form "t=<unix timestamp>,v1=<hex HMAC-SHA256>[,v1=<hex HMAC-SHA256>]",
+// with one v1 entry per active secret. The HMAC is computed over "<timestamp>.<content>", where the content
+// is the body or, in binary content mode, the canonical form built by SignedContent.
+const SignatureHeader = "X-Cloudevents-Signature"
+
+// DefaultSignatureTolerance is the maximum accepted age of a signature timestamp.
+const DefaultSignatureTolerance = 5 * time.Minute
+
+// MaxSignedBodySize is the maximum size in bytes of a request body read by RequireSignature.
+const MaxSignedBodySize = 10 << 20
+
+// Sign computes the signature header value for the body at the given time using all given secrets.
+// Signing with two secrets allows receivers to rotate from the old to the new secret without downtime.
+func Sign(body []byte, t time.Time, secrets ...string) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+
+	parts := []string{"t=" + timestamp}
+	for _, secret := range secrets {
+		parts = append(parts, "v1="+hex.EncodeToString(computeSignature(body, timestamp, secret)))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// VerifySignature checks that the signature header value matches the body for at least one of the secrets
+// and that its timestamp is not older (or further in the future) than the tolerance.
+func VerifySignature(header string, body []byte, tolerance time.Duration, secrets ...string) error {
+	var timestamp string
+	var signatures [][]byte
+
+	for part := range strings.SplitSeq(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			if sig, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, sig)
+			}
+		}
+	}
+
+	if timestamp == "" || len(signatures) == 0 {
+		return errors.New("signature header is malformed")
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("signature timestamp is invalid: %w", err)
+	}
+	if age := time.Since(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return errors.New("signature timestamp is outside the tolerance")
+	}
+
+	for _, secret := range secrets {
+		expected := computeSignature(body, timestamp, secret)
+		for _, sig := range signatures {
+			if hmac.Equal(sig, expected) {
+				return nil
+			}
+		}
+	}
+
+	return errors.New("signature does not match")
+}
+
+// SignedContent returns the content of a webhook request covered by its signature.
+// In structured content mode this is the body. In binary content mode the event attributes are headers,
+// so the content starts with one "<name>:<value>" line per Ce- header in lexical order of the lower-case
+// names, followed by the Content-Type line, an empty line and the body.
+func SignedContent(header http.Header, body []byte) []byte {
+	if !IsBinaryHTTP(header) {
+		return body
+	}
+
+	names := []string{}
+	for name := range header {
+		if len(name) > 3 && strings.EqualFold(name[:3], "ce-") {
+			names = append(names, name)
+		}
+	}
+	slices.SortFunc(names, func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) })
+	names = append(names, "Content-Type")
+
+	var content bytes.Buffer
+	for _, name := range names {
+		content.WriteString(strings.ToLower(name) + ":" + strings.Join(header.Values(name), ",") + "\n")
+	}
+	content.WriteString("\n")
+	content.Write(body)
+
+	return content.Bytes()
+}
+
+// RequireSignature wraps an http.Handler and rejects requests whose content is not signed with one of the secrets.
+// Requests with a missing, invalid or stale signature are answered with 401 Unauthorized. In binary content mode
+// the signature covers the Ce- headers and the Content-Type as well as the body (see SignedContent).
+// Bodies larger than MaxSignedBodySize are rejected with 413 Request Entity Too Large.
+// The request body is restored so the wrapped handler can read it as usual.
+func RequireSignature(next http.Handler, tolerance time.Duration, secrets ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxSignedBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		header := r.Header.Get(SignatureHeader)
+		if header == "" {
+			http.Error(w, "Missing "+SignatureHeader+" header", http.StatusUnauthorized)
+			return
+		}
+
+		if err := VerifySignature(header, SignedContent(r.Header, body), tolerance, secrets...); err != nil {
+			http.Error(w, "Invalid signature: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// computeSignature returns the HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret.
+func computeSignature(body []byte, timestamp, secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,158 @@
+package event
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify_Success(t *testing.T) {
+	body := []byte(`{"type":"com.example.event:v1"}`)
+	header := Sign(body, time.Now(), "secret")
+
+	if err := VerifySignature(header, body, DefaultSignatureTolerance, "secret"); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+}
+
+func TestVerifySignature_Rotation(t *testing.T) {
+	body := []byte(`{"type":"com.example.event:v1"}`)
+	header := Sign(body, time.Now(), "new-secret", "old-secret")
+
+	if err := VerifySignature(header, body, DefaultSignatureTolerance, "old-secret"); err != nil {
+		t.Errorf("expected receiver with old secret to accept, got %v", err)
+	}
+	if err := VerifySignature(header, body, DefaultSignatureTolerance, "new-secret"); err != nil {
+		t.Errorf("expected receiver with new secret to accept, got %v", err)
+	}
+}
+
+func TestVerifySignature_Errors(t *testing.T) {
+	body := []byte(`{"type":"com.example.event:v1"}`)
+
+	cases := []struct {
+		name     string
+		header   string
+		body     []byte
+		expected string
+	}{
+		{"malformed", "garbage", body, "signature header is malformed"},
+		{"wrong secret", Sign(body, time.Now(), "other"), body, "signature does not match"},
+		{"tampered body", Sign(body, time.Now(), "secret"), []byte(`{}`), "signature does not match"},
+		{"stale", Sign(body, time.Now().Add(-time.Hour), "secret"), body, "signature timestamp is outside the tolerance"},
+		{"future", Sign(body, time.Now().Add(time.Hour), "secret"), body, "signature timestamp is outside the tolerance"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := VerifySignature(tc.header, tc.body, DefaultSignatureTolerance, "secret")
+			if err == nil || err.Error() != tc.expected {
+				t.Fatalf("expected error %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestRequireSignature(t *testing.T) {
+	var received string
+	handler := RequireSignature(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+	}), DefaultSignatureTolerance, "secret")
+
+	body := `{"type":"com.example.event:v1"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(SignatureHeader, Sign([]byte(body), time.Now(), "secret"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	if received != body {
+		t.Errorf("expected wrapped handler to receive body %q, got %q", body, received)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for missing signature, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(SignatureHeader, Sign([]byte(body), time.Now(), "other"))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for invalid signature, got %d", rec.Code)
+	}
+}
+
+func TestRequireSignature_BodyTooLarge(t *testing.T) {
+	called := false
+	handler := RequireSignature(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}), DefaultSignatureTolerance, "secret")
+
+	body := strings.Repeat("x", MaxSignedBodySize+1)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(SignatureHeader, Sign([]byte(body), time.Now(), "secret"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge || called {
+		t.Errorf("expected 413 without calling the handler, got %d", rec.Code)
+	}
+}
+
+func TestRequireSignature_BinaryMode(t *testing.T) {
+	handler := RequireSignature(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), DefaultSignatureTolerance, "secret")
+
+	e := newBookBorrowed("hello")
+	e.DataContentType = "text/plain"
+
+	header, body, err := EncodeHTTP(e)
+	if err != nil {
+		t.Fatalf("EncodeHTTP failed: %v", err)
+	}
+	signature := Sign(SignedContent(header, body), time.Now(), "secret")
+
+	if string(SignedContent(header, body)) == string(body) {
+		t.Fatalf("expected the signed content of a binary request to include its headers")
+	}
+
+	tests := []struct {
+		name   string
+		modify func(http.Header)
+		code   int
+	}{
+		{"untouched", func(h http.Header) {}, http.StatusOK},
+		{"changed type", func(h http.Header) { h.Set(HeaderType, "com.library.book.returned:v1") }, http.StatusUnauthorized},
+		{"changed subject", func(h http.Header) { h.Set(HeaderSubject, "/books/456") }, http.StatusUnauthorized},
+		{"removed subject", func(h http.Header) { h.Del(HeaderSubject) }, http.StatusUnauthorized},
+		{"added extension", func(h http.Header) { h.Set("Ce-Traceparent", "00-1-2-01") }, http.StatusUnauthorized},
+		{"changed content type", func(h http.Header) { h.Set("Content-Type", "application/octet-stream") }, http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(body)))
+			req.Header = header.Clone()
+			req.Header.Set(SignatureHeader, signature)
+			tc.modify(req.Header)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.code {
+				t.Errorf("expected status %d, got %d", tc.code, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package event
+
+import (
+	"errors"
+	"strings"
+)
+
+// Subject patterns match hierarchical subjects such as /libraries/7/books/123 segment by segment.
+// A "*" segment matches exactly one segment and a "**" segment matches any number of segments, including none.
+// All other segments must match exactly. E.g. /libraries/7/** matches /libraries/7 and everything below it,
+// /libraries/*/books/123 matches book 123 in every library.
+const (
+	SubjectWildcard          = "*"
+	SubjectRecursiveWildcard = "**"
+)
+
+// IsSubjectPattern reports whether the subject contains wildcard segments.
+func IsSubjectPattern(s string) bool {
+	for _, segment := range SubjectSegments(s) {
+		if segment == SubjectWildcard || segment == SubjectRecursiveWildcard {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateSubjectPattern checks that wildcards only appear as whole segments.
+func ValidateSubjectPattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return errors.New("subject pattern cannot be empty")
+	}
+
+	for _, segment := range SubjectSegments(pattern) {
+		if segment != SubjectWildcard && segment != SubjectRecursiveWildcard && strings.Contains(segment, "*") {
+			return errors.New("subject pattern wildcards must be whole segments")
+		}
+	}
+
+	return nil
+}
+
+// MatchSubject reports whether the subject matches the pattern.
+func MatchSubject(pattern, subject string) bool {
+	return matchSegments(SubjectSegments(pattern), SubjectSegments(subject))
+}
+
+// SubjectSegments splits a subject or pattern into its segments, ignoring leading and trailing slashes.
+func SubjectSegments(s string) []string {
+	s = strings.Trim(s, "/")
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, "/")
+}
+
+func matchSegments(pattern, subject []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case SubjectRecursiveWildcard:
+			for i := 0; i <= len(subject); i++ {
+				if matchSegments(pattern[1:], subject[i:]) {
+					return true
+				}
+			}
+			return false
+		case SubjectWildcard:
+			if len(subject) == 0 {
+				return false
+			}
+		default:
+			if len(subject) == 0 || subject[0] != pattern[0] {
+				return false
+			}
+		}
+
+		pattern, subject = pattern[1:], subject[1:]
+	}
+
+	return len(subject) == 0
+}
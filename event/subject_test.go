@@ -0,0 +1,54 @@
+package event
+
+import "testing"
+
+func TestMatchSubject(t *testing.T) {
+	tests := []struct {
+		pattern string
+		subject string
+		want    bool
+	}{
+		{"/libraries/7/books/123", "/libraries/7/books/123", true},
+		{"/libraries/7/books/123", "/libraries/7/books/124", false},
+		{"/libraries/7/**", "/libraries/7", true},
+		{"/libraries/7/**", "/libraries/7/books/123", true},
+		{"/libraries/7/**", "/libraries/8/books/123", false},
+		{"/libraries/*/books/123", "/libraries/7/books/123", true},
+		{"/libraries/*/books/123", "/libraries/books/123", false},
+		{"/libraries/*", "/libraries/7/books", false},
+		{"/**/books/123", "/libraries/7/books/123", true},
+		{"/**/books/123", "/books/123", true},
+		{"/**", "/anything/at/all", true},
+		{"/libraries/**/123", "/libraries/7/books/124", false},
+		{"/libraries/7/", "/libraries/7", true},
+	}
+
+	for _, tt := range tests {
+		if got := MatchSubject(tt.pattern, tt.subject); got != tt.want {
+			t.Errorf("MatchSubject(%q, %q) = %v, want %v", tt.pattern, tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestIsSubjectPattern(t *testing.T) {
+	if IsSubjectPattern("/libraries/7/books/123") {
+		t.Error("expected exact subject not to be a pattern")
+	}
+	if !IsSubjectPattern("/libraries/*/books") || !IsSubjectPattern("/libraries/**") {
+		t.Error("expected wildcard subjects to be patterns")
+	}
+}
+
+func TestValidateSubjectPattern(t *testing.T) {
+	for _, pattern := range []string{"/libraries/**", "/libraries/*/books", "/users/1"} {
+		if err := ValidateSubjectPattern(pattern); err != nil {
+			t.Errorf("expected %q to be valid, got %v", pattern, err)
+		}
+	}
+
+	for _, pattern := range []string{"", "/libraries/7*", "/libraries/***"} {
+		if err := ValidateSubjectPattern(pattern); err == nil {
+			t.Errorf("expected %q to be invalid", pattern)
+		}
+	}
+}
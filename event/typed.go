@@ -0,0 +1,315 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TypedEvent is an Event whose payload has the Go type T.
+// It has the same JSON shape as Event, with the extensions as top-level members.
+type TypedEvent[T any] struct {
+	ID              uuid.UUID `json:"id"`
+	Type            string    `json:"type"`
+	Time            time.Time `json:"time"`
+	Source          string    `json:"source"`
+	Subject         string    `json:"subject"`
+	DataContentType string    `json:"datacontenttype,omitempty"`
+	DataSchema      string    `json:"dataschema,omitempty"`
+	Data            T         `json:"data"`
+	// Extension attributes by name, e.g. traceparent (see Event).
+	Extensions map[string]any `json:"-"`
+}
+
+// TypedCandidate represents the input required to create a new TypedEvent.
+type TypedCandidate[T any] struct {
+	Type            string `json:"type"`
+	Source          string `json:"source"`
+	Subject         string `json:"subject"`
+	DataContentType string `json:"datacontenttype,omitempty"`
+	DataSchema      string `json:"dataschema,omitempty"`
+	Data            T      `json:"data"`
+	// Extension attributes by name, e.g. traceparent (see Event).
+	Extensions map[string]any `json:"-"`
+}
+
+// plainTypedEvent and plainTypedCandidate have the fields of TypedEvent and TypedCandidate without their JSON methods.
+type (
+	plainTypedEvent[T any]     TypedEvent[T]
+	plainTypedCandidate[T any] TypedCandidate[T]
+)
+
+// NewTyped creates a new TypedEvent like New.
+func NewTyped[T any](candidate TypedCandidate[T]) (*TypedEvent[T], error) {
+	e, err := New(Candidate{
+		Type:            candidate.Type,
+		Source:          candidate.Source,
+		Subject:         candidate.Subject,
+		DataContentType: candidate.DataContentType,
+		DataSchema:      candidate.DataSchema,
+		Data:            candidate.Data,
+		Extensions:      candidate.Extensions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TypedEvent[T]{
+		ID:              e.ID,
+		Type:            e.Type,
+		Time:            e.Time,
+		Source:          e.Source,
+		Subject:         e.Subject,
+		DataContentType: e.DataContentType,
+		DataSchema:      e.DataSchema,
+		Data:            candidate.Data,
+		Extensions:      e.Extensions,
+	}, nil
+}
+
+// Event returns the untyped event with the same attributes and payload.
+func (e TypedEvent[T]) Event() Event {
+	return Event{
+		ID:              e.ID,
+		Type:            e.Type,
+		Time:            e.Time,
+		Source:          e.Source,
+		Subject:         e.Subject,
+		DataContentType: e.DataContentType,
+		DataSchema:      e.DataSchema,
+		Data:            e.Data,
+		Extensions:      e.Extensions,
+	}
+}
+
+// MarshalJSON encodes the typed event like an event, with the extensions as top-level members.
+func (e TypedEvent[T]) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(plainTypedEvent[T](e))
+	if err != nil {
+		return nil, err
+	}
+
+	return appendExtensions(b, e.Extensions)
+}
+
+// UnmarshalJSON decodes a typed event like an event. Unknown members are decoded as extensions.
+func (e *TypedEvent[T]) UnmarshalJSON(b []byte) error {
+	var v plainTypedEvent[T]
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	extensions, err := decodeExtensions(b)
+	if err != nil {
+		return err
+	}
+
+	*e = TypedEvent[T](v)
+	e.Extensions = extensions
+
+	return nil
+}
+
+// MarshalJSON encodes the typed candidate like an event, with the extensions as top-level members.
+func (c TypedCandidate[T]) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(plainTypedCandidate[T](c))
+	if err != nil {
+		return nil, err
+	}
+
+	return appendExtensions(b, c.Extensions)
+}
+
+// UnmarshalJSON decodes a typed candidate like an event. Unknown members are decoded as extensions.
+func (c *TypedCandidate[T]) UnmarshalJSON(b []byte) error {
+	var v plainTypedCandidate[T]
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	extensions, err := decodeExtensions(b)
+	if err != nil {
+		return err
+	}
+
+	*c = TypedCandidate[T](v)
+	c.Extensions = extensions
+
+	return nil
+}
+
+// AsTyped converts the event to a TypedEvent by decoding its payload into T (see DecodeData).
+func AsTyped[T any](e Event) (*TypedEvent[T], error) {
+	data, err := DecodeData[T](e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TypedEvent[T]{
+		ID:              e.ID,
+		Type:            e.Type,
+		Time:            e.Time,
+		Source:          e.Source,
+		Subject:         e.Subject,
+		DataContentType: e.DataContentType,
+		DataSchema:      e.DataSchema,
+		Data:            data,
+		Extensions:      e.Extensions,
+	}, nil
+}
+
+// FromJSONTyped parses a JSON string into a TypedEvent and validates it.
+func FromJSONTyped[T any](s string) (*TypedEvent[T], error) {
+	var typed TypedEvent[T]
+	if err := json.Unmarshal([]byte(s), &typed); err != nil {
+		return nil, err
+	}
+
+	e := typed.Event()
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &typed, nil
+}
+
+// DecodeData returns the payload of the event as T. Payloads that already have the type T or *T are returned
+// as they are, any other payload, such as the map of a parsed JSON object or JSON bytes, is converted via JSON.
+// Payloads of a non-JSON datacontenttype cannot be converted and return an error.
+func DecodeData[T any](e Event) (T, error) {
+	var data T
+	switch v := e.Data.(type) {
+	case T:
+		return v, nil
+	case *T:
+		if v != nil {
+			return *v, nil
+		}
+	}
+
+	raw, err := jsonData(e)
+	if err != nil {
+		return data, err
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return data, fmt.Errorf("decode data of %s: %w", e.Type, err)
+	}
+
+	return data, nil
+}
+
+// jsonData returns the payload of the event as JSON for decoding it into a Go type.
+// Binary and text payloads of a non-JSON datacontenttype have no JSON form and return an error.
+func jsonData(e Event) ([]byte, error) {
+	if !IsJSONContentType(e.DataContentType) {
+		return nil, fmt.Errorf("decode data of %s: datacontenttype %s is not JSON", e.Type, e.DataContentType)
+	}
+
+	raw, err := e.DataBytes()
+	if err != nil {
+		return nil, fmt.Errorf("decode data of %s: %w", e.Type, err)
+	}
+
+	return raw, nil
+}
+
+// TypeRegistry maps event types to the Go types of their payloads. It is safe for concurrent use.
+type TypeRegistry struct {
+	mu    sync.RWMutex
+	types map[string]reflect.Type
+}
+
+// DefaultTypeRegistry is the registry used by FromJSON.
+var DefaultTypeRegistry = NewTypeRegistry()
+
+// NewTypeRegistry creates an empty type registry.
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{types: make(map[string]reflect.Type)}
+}
+
+// RegisterType registers T as the payload type of the event type, e.g.
+// RegisterType[BookBorrowed](event.DefaultTypeRegistry, "com.library.book.borrowed:v1").
+func RegisterType[T any](r *TypeRegistry, eventType string) error {
+	if eventType == "" {
+		return errors.New("event type must not be empty")
+	}
+
+	t := reflect.TypeFor[T]()
+	if t.Kind() == reflect.Interface {
+		return fmt.Errorf("payload type of %s must not be an interface", eventType)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, exists := r.types[eventType]; exists && existing != t {
+		return fmt.Errorf("event type %s is already registered with payload type %s", eventType, existing)
+	}
+	r.types[eventType] = t
+
+	return nil
+}
+
+// Types returns the registered event types in lexical order.
+func (r *TypeRegistry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.types))
+	for t := range r.types {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+// Decode replaces the payload of the event with a value of the Go type registered for its type.
+// Events of unregistered types are left unchanged. Payloads of a non-JSON datacontenttype can only be decoded
+// into the Go type they already have, e.g. []byte, and return an error for any other registered type.
+func (r *TypeRegistry) Decode(e *Event) error {
+	r.mu.RLock()
+	t, exists := r.types[e.Type]
+	r.mu.RUnlock()
+	if !exists || e.Data == nil || reflect.TypeOf(e.Data) == t {
+		return nil
+	}
+
+	raw, err := jsonData(*e)
+	if err != nil {
+		return err
+	}
+	data := reflect.New(t)
+	if err := json.Unmarshal(raw, data.Interface()); err != nil {
+		return fmt.Errorf("decode data of %s: %w", e.Type, err)
+	}
+	e.Data = data.Elem().Interface()
+
+	return nil
+}
+
+// FromJSON parses a JSON string into an Event, validates it and decodes its payload into the Go type
+// registered for its type.
+func (r *TypeRegistry) FromJSON(s string) (*Event, error) {
+	var event Event
+
+	if err := json.Unmarshal([]byte(s), &event); err != nil {
+		return nil, err
+	}
+
+	if err := event.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := r.Decode(&event); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
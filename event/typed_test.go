@@ -0,0 +1,183 @@
+package event
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type bookBorrowed struct {
+	BookID   string `json:"bookId"`
+	MemberID int    `json:"memberId"`
+}
+
+func TestNewTyped(t *testing.T) {
+	e, err := NewTyped(TypedCandidate[bookBorrowed]{
+		Type:    "com.library.book.borrowed:v1",
+		Source:  "https://library.example.com",
+		Subject: "/books/123",
+		Data:    bookBorrowed{BookID: "b-123", MemberID: 7},
+	})
+	if err != nil {
+		t.Fatalf("NewTyped failed: %v", err)
+	}
+	if e.Data.MemberID != 7 || e.Event().Data.(bookBorrowed).BookID != "b-123" {
+		t.Errorf("unexpected event %+v", e)
+	}
+
+	b, _ := json.Marshal(e)
+	parsed, err := FromJSONTyped[bookBorrowed](string(b))
+	if err != nil {
+		t.Fatalf("FromJSONTyped failed: %v", err)
+	}
+	if parsed.ID != e.ID || parsed.Data != e.Data {
+		t.Errorf("expected %+v, got %+v", e, parsed)
+	}
+
+	if _, err := NewTyped(TypedCandidate[bookBorrowed]{Type: "x", Source: "https://library.example.com", Subject: "/books/123"}); err == nil {
+		t.Error("expected validation error")
+	}
+	if _, err := FromJSONTyped[bookBorrowed](`{"type":"com.library.book.borrowed:v1","data":{"bookId":1}}`); err == nil {
+		t.Error("expected decode error")
+	}
+}
+
+func TestDecodeData(t *testing.T) {
+	e := newBookBorrowed(map[string]any{"bookId": "b-123", "memberId": 7.0})
+	data, err := DecodeData[bookBorrowed](e)
+	if err != nil || data != (bookBorrowed{BookID: "b-123", MemberID: 7}) {
+		t.Errorf("unexpected data %+v, %v", data, err)
+	}
+
+	e.Data = &bookBorrowed{BookID: "b-456"}
+	if data, err := DecodeData[bookBorrowed](e); err != nil || data.BookID != "b-456" {
+		t.Errorf("unexpected data %+v, %v", data, err)
+	}
+
+	e.Data = map[string]any{"memberId": "seven"}
+	if _, err := DecodeData[bookBorrowed](e); err == nil {
+		t.Error("expected decode error")
+	}
+
+	e.Data = map[string]any{"bookId": "b-789"}
+	typed, err := AsTyped[bookBorrowed](e)
+	if err != nil || typed.ID != e.ID || typed.Data.BookID != "b-789" {
+		t.Errorf("unexpected typed event %+v, %v", typed, err)
+	}
+}
+
+func TestTypeRegistry(t *testing.T) {
+	r := NewTypeRegistry()
+	if err := RegisterType[bookBorrowed](r, "com.library.book.borrowed:v1"); err != nil {
+		t.Fatalf("RegisterType failed: %v", err)
+	}
+	if err := RegisterType[bookBorrowed](r, "com.library.book.borrowed:v1"); err != nil {
+		t.Errorf("expected registering the same type again to succeed, got %v", err)
+	}
+	if err := RegisterType[string](r, "com.library.book.borrowed:v1"); err == nil {
+		t.Error("expected error for a different payload type")
+	}
+	if err := RegisterType[any](r, "com.library.book.returned:v1"); err == nil {
+		t.Error("expected error for an interface payload type")
+	}
+	if types := r.Types(); len(types) != 1 || types[0] != "com.library.book.borrowed:v1" {
+		t.Errorf("unexpected types %v", types)
+	}
+
+	e, err := r.FromJSON(`{"id":"f8ceae97-5a98-473d-a075-c1f0a530da2c","type":"com.library.book.borrowed:v1","time":"2025-09-01T17:09:53Z","source":"https://library.example.com","subject":"/books/123","data":{"bookId":"b-123","memberId":7}}`)
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	if data, ok := e.Data.(bookBorrowed); !ok || data.MemberID != 7 {
+		t.Errorf("expected bookBorrowed payload, got %T %v", e.Data, e.Data)
+	}
+
+	other, err := r.FromJSON(`{"id":"f8ceae97-5a98-473d-a075-c1f0a530da2c","type":"com.library.book.returned:v1","time":"2025-09-01T17:09:53Z","source":"https://library.example.com","subject":"/books/123","data":{"bookId":"b-123"}}`)
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	if _, ok := other.Data.(map[string]any); !ok {
+		t.Errorf("expected generic payload for unregistered type, got %T", other.Data)
+	}
+
+	if _, err := r.FromJSON(`{"id":"f8ceae97-5a98-473d-a075-c1f0a530da2c","type":"com.library.book.borrowed:v1","time":"2025-09-01T17:09:53Z","source":"https://library.example.com","subject":"/books/123","data":{"bookId":123}}`); err == nil {
+		t.Error("expected decode error")
+	}
+}
+
+func TestTypeRegistry_DecodeNonJSONData(t *testing.T) {
+	r := NewTypeRegistry()
+	if err := RegisterType[bookBorrowed](r, "com.library.book.borrowed:v1"); err != nil {
+		t.Fatalf("RegisterType failed: %v", err)
+	}
+
+	e := newBookBorrowed([]byte(`{"bookId":"b-123","memberId":7}`))
+	e.DataContentType = "application/json"
+	if err := r.Decode(&e); err != nil || e.Data != (bookBorrowed{BookID: "b-123", MemberID: 7}) {
+		t.Errorf("expected JSON bytes to be decoded, got %+v, %v", e.Data, err)
+	}
+
+	e = newBookBorrowed([]byte{0x0a, 0x05})
+	e.DataContentType = "application/protobuf"
+	if err := r.Decode(&e); err == nil || !strings.Contains(err.Error(), "datacontenttype application/protobuf is not JSON") {
+		t.Errorf("expected error for binary data, got %v", err)
+	}
+	if _, ok := e.Data.([]byte); !ok {
+		t.Errorf("expected binary data to be left unchanged, got %T", e.Data)
+	}
+
+	e = newBookBorrowed("<book/>")
+	e.DataContentType = "application/xml"
+	if _, err := DecodeData[bookBorrowed](e); err == nil || !strings.Contains(err.Error(), "is not JSON") {
+		t.Errorf("expected error for text data, got %v", err)
+	}
+	if data, err := DecodeData[string](e); err != nil || data != "<book/>" {
+		t.Errorf("expected text data as string, got %q, %v", data, err)
+	}
+}
+
+func TestTypedEvent_Extensions(t *testing.T) {
+	e, err := NewTyped(TypedCandidate[bookBorrowed]{
+		Type:       "com.library.book.borrowed:v1",
+		Source:     "https://library.example.com",
+		Subject:    "/books/123",
+		Data:       bookBorrowed{BookID: "b-123"},
+		Extensions: map[string]any{"traceparent": "00-abc-def-01"},
+	})
+	if err != nil {
+		t.Fatalf("NewTyped failed: %v", err)
+	}
+	if e.Extensions["traceparent"] != "00-abc-def-01" {
+		t.Errorf("expected NewTyped to keep the extensions, got %v", e.Extensions)
+	}
+
+	untyped := e.Event()
+	if untyped.Extensions["traceparent"] != "00-abc-def-01" {
+		t.Errorf("expected Event to keep the extensions, got %v", untyped.Extensions)
+	}
+
+	typed, err := AsTyped[bookBorrowed](untyped)
+	if err != nil || typed.Extensions["traceparent"] != "00-abc-def-01" {
+		t.Errorf("expected AsTyped to keep the extensions, got %v, %v", typed, err)
+	}
+
+	b, _ := json.Marshal(e)
+	if !strings.Contains(string(b), `"traceparent":"00-abc-def-01"`) {
+		t.Errorf("expected the extension as a top-level member, got %s", b)
+	}
+	parsed, err := FromJSONTyped[bookBorrowed](string(b))
+	if err != nil {
+		t.Fatalf("FromJSONTyped failed: %v", err)
+	}
+	if parsed.Extensions["traceparent"] != "00-abc-def-01" || parsed.Data.BookID != "b-123" {
+		t.Errorf("expected %+v, got %+v", e, parsed)
+	}
+
+	var candidate TypedCandidate[bookBorrowed]
+	if err := json.Unmarshal([]byte(`{"type":"com.library.book.borrowed:v1","data":{"bookId":"b-123"},"traceparent":"00-abc-def-01"}`), &candidate); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if candidate.Extensions["traceparent"] != "00-abc-def-01" || candidate.Data.BookID != "b-123" {
+		t.Errorf("expected the candidate to keep the extensions, got %+v", candidate)
+	}
+}
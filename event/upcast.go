@@ -0,0 +1,108 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNoUpcaster is returned when no chain of upcasters leads from the type of an event to the requested type.
+var ErrNoUpcaster = errors.New("no upcaster registered")
+
+// UpcastFunc converts an event of one version of its type to the next version, e.g. from
+// com.library.book.borrowed:v1 to com.library.book.borrowed:v2. It only has to convert the data;
+// the type of the returned event is set by Upcasters.
+type UpcastFunc func(Event) (Event, error)
+
+// Upcasters holds the upcast functions of versioned event types. It is safe for concurrent use.
+type Upcasters struct {
+	mu    sync.RWMutex
+	funcs map[string]map[int]UpcastFunc
+}
+
+// NewUpcasters creates an empty upcaster registry.
+func NewUpcasters() *Upcasters {
+	return &Upcasters{funcs: make(map[string]map[int]UpcastFunc)}
+}
+
+// Register registers the function that upcasts events of the versioned source type to the next version,
+// e.g. com.library.book.borrowed:v1 to com.library.book.borrowed:v2.
+func (u *Upcasters) Register(sourceType string, fn UpcastFunc) error {
+	name, version := ParseEventType(sourceType)
+	if version == 0 {
+		return fmt.Errorf("event type %s has no version suffix", sourceType)
+	}
+	if fn == nil {
+		return errors.New("upcast function must not be nil")
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if _, exists := u.funcs[name][version]; exists {
+		return fmt.Errorf("an upcaster for %s is already registered", sourceType)
+	}
+	if u.funcs[name] == nil {
+		u.funcs[name] = make(map[int]UpcastFunc)
+	}
+	u.funcs[name][version] = fn
+
+	return nil
+}
+
+// CanUpcast reports whether events of the type from can be upcast to the type to.
+func (u *Upcasters) CanUpcast(from, to string) bool {
+	_, err := u.chain(from, to)
+	return err == nil
+}
+
+// Upcast converts the event to the target type by applying the upcasters of every version in between.
+// Events that already have the target type are returned unchanged.
+func (u *Upcasters) Upcast(e Event, targetType string) (Event, error) {
+	chain, err := u.chain(e.Type, targetType)
+	if err != nil {
+		return Event{}, err
+	}
+
+	name, version := ParseEventType(e.Type)
+	for _, fn := range chain {
+		from := e.Type
+		if e, err = fn(e); err != nil {
+			return Event{}, fmt.Errorf("upcast %s: %w", from, err)
+		}
+		version++
+		e.Type = fmt.Sprintf("%s:v%d", name, version)
+	}
+
+	return e, nil
+}
+
+// chain returns the upcast functions that lead from one type to the other, in order.
+func (u *Upcasters) chain(from, to string) ([]UpcastFunc, error) {
+	if from == to {
+		return nil, nil
+	}
+
+	name, version := ParseEventType(from)
+	targetName, targetVersion := ParseEventType(to)
+	if version == 0 || targetVersion == 0 || name != targetName {
+		return nil, fmt.Errorf("cannot upcast %s to %s: %w", from, to, ErrNoUpcaster)
+	}
+	if targetVersion < version {
+		return nil, fmt.Errorf("cannot downcast %s to %s", from, to)
+	}
+
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	chain := make([]UpcastFunc, 0, targetVersion-version)
+	for v := version; v < targetVersion; v++ {
+		fn, exists := u.funcs[name][v]
+		if !exists {
+			return nil, fmt.Errorf("cannot upcast %s:v%d to %s:v%d: %w", name, v, name, v+1, ErrNoUpcaster)
+		}
+		chain = append(chain, fn)
+	}
+
+	return chain, nil
+}
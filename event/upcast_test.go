@@ -0,0 +1,71 @@
+package event
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUpcasters_Upcast(t *testing.T) {
+	u := NewUpcasters()
+	if err := u.Register("com.library.book.borrowed:v1", func(e Event) (Event, error) {
+		data := e.Data.(map[string]any)
+		e.Data = map[string]any{"bookId": data["bookId"], "memberId": data["member"]}
+		return e, nil
+	}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := u.Register("com.library.book.borrowed:v2", func(e Event) (Event, error) {
+		data := e.Data.(map[string]any)
+		data["dueDate"] = "unknown"
+		return e, nil
+	}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	e := newBookBorrowed(map[string]any{"bookId": "b-123", "member": 7})
+	got, err := u.Upcast(e, "com.library.book.borrowed:v3")
+	if err != nil {
+		t.Fatalf("Upcast failed: %v", err)
+	}
+	if got.Type != "com.library.book.borrowed:v3" || got.ID != e.ID {
+		t.Errorf("unexpected event: %+v", got)
+	}
+	data := got.Data.(map[string]any)
+	if data["memberId"] != 7 || data["dueDate"] != "unknown" {
+		t.Errorf("unexpected data: %v", data)
+	}
+
+	if same, err := u.Upcast(e, e.Type); err != nil || same.Type != e.Type {
+		t.Errorf("expected event to be returned unchanged, got %+v, %v", same, err)
+	}
+	if _, err := u.Upcast(e, "com.library.book.borrowed:v4"); !errors.Is(err, ErrNoUpcaster) {
+		t.Errorf("expected ErrNoUpcaster, got %v", err)
+	}
+	if _, err := u.Upcast(got, "com.library.book.borrowed:v1"); err == nil {
+		t.Error("expected error for downcast")
+	}
+	if !u.CanUpcast("com.library.book.borrowed:v2", "com.library.book.borrowed:v3") || u.CanUpcast("com.library.book.borrowed:v1", "com.library.book.returned:v2") {
+		t.Error("unexpected CanUpcast result")
+	}
+}
+
+func TestUpcasters_Register(t *testing.T) {
+	u := NewUpcasters()
+	noop := func(e Event) (Event, error) { return e, nil }
+
+	if err := u.Register("com.library.book.borrowed", noop); err == nil {
+		t.Error("expected error for unversioned type")
+	}
+	if err := u.Register("com.library.book.borrowed:v1", noop); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := u.Register("com.library.book.borrowed:v1", noop); err == nil {
+		t.Error("expected error for duplicate upcaster")
+	}
+
+	failing := NewUpcasters()
+	failing.Register("com.library.book.borrowed:v1", func(e Event) (Event, error) { return Event{}, errors.New("boom") })
+	if _, err := failing.Upcast(newBookBorrowed(nil), "com.library.book.borrowed:v2"); err == nil {
+		t.Error("expected error from upcast function")
+	}
+}
@@ -15,21 +15,24 @@ type EnqueueResponseSuccess struct {
 }
 
 // EnqueueResponseError represents a failed response from the enqueue API endpoint.
+// Violations lists why the data does not conform to the schema of the event type, if that is the error.
 type EnqueueResponseError struct {
-	Ok    bool   `json:"ok"`
-	Error string `json:"error"`
+	Ok         bool                    `json:"ok"`
+	Error      string                  `json:"error"`
+	Violations []event.SchemaViolation `json:"violations,omitempty"`
 }
 
 // NewEnqueueHandler returns an HTTP handler for enqueuing messages into the queue.
-// It expects a POST request with a JSON body containing a 'message' field.
-func NewEnqueueHandler(appQueue queue.Queue) http.HandlerFunc {
+// It expects a POST request with a JSON or protobuf cloudevent, or the event data in binary content mode.
+// If schemas is not nil, events whose data does not conform to the schema of their type are rejected with 422.
+func NewEnqueueHandler(appQueue queue.Queue, schemas *event.SchemaRegistry) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if !validateMethod(w, r, http.MethodPost) {
 			return
 		}
 
 		message := event.Event{}
-		if !readJSONRequest(w, r, &message) {
+		if !readEventRequest(w, r, &message) {
 			return
 		}
 
@@ -42,6 +45,10 @@ func NewEnqueueHandler(appQueue queue.Queue) http.HandlerFunc {
 			return
 		}
 
+		if !validateSchema(w, schemas, message) {
+			return
+		}
+
 		appQueue.Queue <- queue.QueueMessage{Message: message, Attempts: 0}
 
 		sendJSONResponse(w, EnqueueResponseSuccess{
@@ -13,7 +13,7 @@ import (
 
 func TestNewEnqueueHandler_Success(t *testing.T) {
 	q := &queue.Queue{Queue: make(chan queue.QueueMessage, 1)}
-	handler := NewEnqueueHandler(*q)
+	handler := NewEnqueueHandler(*q, nil)
 
 	e, err := event.New(event.Candidate{Type: "com.example.event:v1", Source: "https://example.com", Subject: "/users/123", Data: map[string]any{"k": "v"}})
 	if err != nil {
@@ -53,7 +53,7 @@ func TestNewEnqueueHandler_Success(t *testing.T) {
 
 func TestNewEnqueueHandler_MethodNotAllowed(t *testing.T) {
 	q := &queue.Queue{Queue: make(chan queue.QueueMessage, 1)}
-	handler := NewEnqueueHandler(*q)
+	handler := NewEnqueueHandler(*q, nil)
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	rec := httptest.NewRecorder()
 	handler(rec, req)
@@ -64,7 +64,7 @@ func TestNewEnqueueHandler_MethodNotAllowed(t *testing.T) {
 
 func TestNewEnqueueHandler_InvalidJSON(t *testing.T) {
 	q := &queue.Queue{Queue: make(chan queue.QueueMessage, 1)}
-	handler := NewEnqueueHandler(*q)
+	handler := NewEnqueueHandler(*q, nil)
 	body := bytes.NewBufferString(`{"invalid_json":}`)
 	req := httptest.NewRequest(http.MethodPost, "/", body)
 	rec := httptest.NewRecorder()
@@ -76,7 +76,7 @@ func TestNewEnqueueHandler_InvalidJSON(t *testing.T) {
 
 func TestNewEnqueueHandler_InvalidEvent(t *testing.T) {
 	q := &queue.Queue{Queue: make(chan queue.QueueMessage, 1)}
-	handler := NewEnqueueHandler(*q)
+	handler := NewEnqueueHandler(*q, nil)
 	body := bytes.NewBufferString(`{"type":"", "source":""}`)
 	req := httptest.NewRequest(http.MethodPost, "/", body)
 	rec := httptest.NewRecorder()
@@ -95,3 +95,102 @@ func TestNewEnqueueHandler_InvalidEvent(t *testing.T) {
 		t.Errorf("expected error message, got empty")
 	}
 }
+
+func TestNewEnqueueHandler_SchemaValidation(t *testing.T) {
+	schemas := event.NewSchemaRegistry()
+	if _, err := schemas.Register("com.example.event:v1", json.RawMessage(`{"type":"object","required":["k"]}`)); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	q := &queue.Queue{Queue: make(chan queue.QueueMessage, 1)}
+	handler := NewEnqueueHandler(*q, schemas)
+
+	e, err := event.New(event.Candidate{Type: "com.example.event:v1", Source: "https://example.com", Subject: "/users/123", Data: map[string]any{"other": "v"}})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	body, _ := json.Marshal(e)
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status 422, got %d", rec.Code)
+	}
+	var resp EnqueueResponseError
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Ok || len(resp.Violations) != 1 || len(q.Queue) != 0 {
+		t.Errorf("expected the event to be rejected with one violation, got %+v", resp)
+	}
+}
+
+func TestNewEnqueueHandler_BinaryContentMode(t *testing.T) {
+	q := &queue.Queue{Queue: make(chan queue.QueueMessage, 1)}
+	handler := NewEnqueueHandler(*q, nil)
+
+	e, err := event.New(event.Candidate{Type: "com.example.event:v1", Source: "https://example.com", Subject: "/users/123", DataContentType: "application/xml", Data: "<book/>"})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	header, body, err := event.EncodeHTTP(*e)
+	if err != nil {
+		t.Fatalf("failed to encode event: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header = header
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	var resp EnqueueResponseSuccess
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || !resp.Ok {
+		t.Fatalf("expected ok response, got %+v, %v", resp, err)
+	}
+	if got := <-q.Queue; got.Message.DataContentType != "application/xml" || got.Message.Data != "<book/>" {
+		t.Errorf("unexpected enqueued event %+v", got.Message)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header = header.Clone()
+	req.Header.Set(event.HeaderTime, "yesterday")
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid binary event, got %d", rec.Code)
+	}
+}
+
+func TestNewEnqueueHandler_Protobuf(t *testing.T) {
+	q := &queue.Queue{Queue: make(chan queue.QueueMessage, 1)}
+	handler := NewEnqueueHandler(*q, nil)
+
+	e, err := event.New(event.Candidate{Type: "com.example.event:v1", Source: "https://example.com", Subject: "/users/123", Data: map[string]any{"k": "v"}, Extensions: map[string]any{"traceparent": "00-abc-01"}})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	body, err := event.MarshalProto(*e)
+	if err != nil {
+		t.Fatalf("failed to encode event: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", event.ContentTypeProtobuf)
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	var resp EnqueueResponseSuccess
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || !resp.Ok {
+		t.Fatalf("expected ok response, got %+v, %v", resp, err)
+	}
+	if got := <-q.Queue; got.Message.ID != e.ID || got.Message.Data.(map[string]any)["k"] != "v" || got.Message.Extensions["traceparent"] != "00-abc-01" {
+		t.Errorf("unexpected enqueued event %+v", got.Message)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body[:len(body)-1]))
+	req.Header.Set("Content-Type", event.ContentTypeProtobuf)
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid protobuf event, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/nicograef/cloudevents/event"
+)
+
+// validateSchema validates the data of the event against the schema of its type and responds with 422 and the
+// violations if it does not conform. Returns false if the event was rejected.
+func validateSchema(w http.ResponseWriter, schemas *event.SchemaRegistry, e event.Event) bool {
+	if schemas == nil {
+		return true
+	}
+
+	err := schemas.ValidateEvent(e)
+	if err == nil {
+		return true
+	}
+
+	log.Printf("Invalid event data: %v", err)
+	sendJSONResponseWithStatus(w, http.StatusUnprocessableEntity, EnqueueResponseError{
+		Ok:         false,
+		Error:      err.Error(),
+		Violations: schemaViolations(err),
+	})
+	return false
+}
+
+// schemaViolations returns the violations of a schema validation error, or nil for other errors.
+func schemaViolations(err error) []event.SchemaViolation {
+	var validationErr *event.SchemaValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Violations
+	}
+
+	return nil
+}
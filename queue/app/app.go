@@ -8,23 +8,33 @@ import (
 	"sync"
 	"time"
 
+	"github.com/nicograef/cloudevents/event"
 	"github.com/nicograef/cloudevents/queue/api"
 	"github.com/nicograef/cloudevents/queue/config"
 	"github.com/nicograef/cloudevents/queue/queue"
 )
 
 type App struct {
-	Queue  queue.Queue
-	Server *http.Server
-	Config config.Config
-	router *http.ServeMux
-	wg     sync.WaitGroup
+	Queue   queue.Queue
+	Server  *http.Server
+	Config  config.Config
+	Schemas *event.SchemaRegistry
+	router  *http.ServeMux
+	wg      sync.WaitGroup
 }
 
 // NewApp creates a new application instance
 func NewApp(cfg config.Config) (*App, error) {
 	appQueue := queue.NewQueue(cfg.Capacity)
 
+	schemas, err := openSchemaRegistry(cfg.SchemaFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SchemaCompatibility != "" {
+		schemas.SetCompatibility(cfg.SchemaCompatibility)
+	}
+
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Port),
 		ReadTimeout:  30 * time.Second,
@@ -34,20 +44,39 @@ func NewApp(cfg config.Config) (*App, error) {
 	router := http.NewServeMux()
 
 	return &App{
-		Queue:  appQueue,
-		Server: server,
-		Config: cfg,
-		router: router,
+		Queue:   appQueue,
+		Server:  server,
+		Config:  cfg,
+		Schemas: schemas,
+		router:  router,
 	}, nil
 }
 
 // SetupRoutes configures HTTP routes
 func (app *App) SetupRoutes() {
-	app.router.HandleFunc("POST /enqueue", api.NewEnqueueHandler(app.Queue))
+	var schemas *event.SchemaRegistry
+	if app.Config.SchemaValidation {
+		schemas = app.Schemas
+	}
+
+	app.router.HandleFunc("POST /enqueue", api.NewEnqueueHandler(app.Queue, schemas))
+	app.router.HandleFunc("OPTIONS /enqueue", event.HandshakeHandler())
 	app.router.HandleFunc("GET /health", api.NewHealthHandler())
+	schemaHandler := event.SchemaHandler(app.Schemas)
+	app.router.Handle("/schemas", schemaHandler)
+	app.router.Handle("/schemas/", schemaHandler)
 	app.Server.Handler = app.router
 }
 
+// openSchemaRegistry opens the schema registry persisted to the file, or an in-memory registry if file is empty.
+func openSchemaRegistry(file string) (*event.SchemaRegistry, error) {
+	if file == "" {
+		return event.NewSchemaRegistry(), nil
+	}
+
+	return event.OpenSchemaRegistry(file)
+}
+
 // Run starts the application with graceful shutdown
 func (app *App) Run(ctx context.Context) error {
 	app.SetupRoutes()
@@ -76,15 +105,37 @@ func (app *App) Run(ctx context.Context) error {
 
 // startQueueConsumer starts the queue consumer goroutine
 func (app *App) startQueueConsumer() {
+	send := app.sendFunc()
+
 	app.wg.Add(1)
 	go func() {
 		defer app.wg.Done()
 		for item := range app.Queue.Queue {
-			app.Queue.HandleQueueItem(item, app.Config.ConsumerURL, queue.SendToWebhook)
+			app.Queue.HandleQueueItem(item, app.Config.ConsumerURL, send)
 		}
 	}()
 }
 
+// sendFunc returns the function used to deliver messages to the consumer.
+// Requests are signed if consumer secrets are configured and encoded in the consumer format, if one is
+// configured. If a webhook origin is configured,
+// the consumer is validated with the webhook handshake first, in the background.
+func (app *App) sendFunc() queue.SendFunc {
+	send := queue.NewSignedSendToWebhook(app.Config.ConsumerSecrets, app.Config.ConsumerFormat)
+	if app.Config.WebhookOrigin == "" {
+		return send
+	}
+
+	handshaker := event.NewHandshaker(app.Config.WebhookOrigin)
+	go func() {
+		if err := handshaker.Register(app.Config.ConsumerURL); err != nil {
+			log.Printf("WARN Webhook handshake failed for consumer %s: %v", app.Config.ConsumerURL, err)
+		}
+	}()
+
+	return handshaker.Wrap(send)
+}
+
 // Shutdown gracefully stops the application
 func (app *App) Shutdown() error {
 	// Create shutdown context with timeout
@@ -4,30 +4,79 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+
+	"github.com/nicograef/cloudevents/event"
 )
 
 // Config holds application configuration values loaded from environment variables.
 type Config struct {
-	Port             int    // Port for the HTTP server
-	Capacity         int    // Maximum number of messages in the queue
-	ConsumerURL      string // Webhook URL to deliver messages
-	DeliveryAttempts int    // Number of attempts for delivering a message
+	Port             int      // Port for the HTTP server
+	Capacity         int      // Maximum number of messages in the queue
+	ConsumerURL      string   // Webhook URL to deliver messages
+	DeliveryAttempts int      // Number of attempts for delivering a message
+	WebhookOrigin    string   // Origin announced in the webhook validation handshake (handshake disabled if empty)
+	ConsumerSecrets  []string // Secrets for signing messages to the consumer (at most two for rotation)
+	ConsumerFormat   string   // Event format (media type) of messages to the consumer (default encoding if empty)
+	SchemaValidation bool     // Validate the data of enqueued events against the schema of their type
+	SchemaFile       string   // File the schema registry is persisted to (kept in memory if empty)
+	// Compatibility rule for new schema versions
+	SchemaCompatibility event.Compatibility
 }
 
 // Load reads configuration from environment variables and returns a Config struct.
-// Defaults: PORT=3000 CAPACITY=1000, CONSUMER_URL="http://localhost:4000" DELIVERY_ATTEMPTS=3
-func Load() Config {
+// It returns an error if CONSUMER_SECRETS holds more than two secrets or CONSUMER_FORMAT or SCHEMA_COMPATIBILITY is invalid.
+// Defaults: PORT=3000 CAPACITY=1000, CONSUMER_URL="http://localhost:4000" DELIVERY_ATTEMPTS=3 WEBHOOK_ORIGIN=<host name> WEBHOOK_HANDSHAKE=true CONSUMER_SECRETS=""
+// CONSUMER_FORMAT="" SCHEMA_VALIDATION=false SCHEMA_FILE="" SCHEMA_COMPATIBILITY=backward
+func Load() (Config, error) {
 	port := parseEnvInt("PORT", 3000)
 	capacity := parseEnvInt("CAPACITY", 1000)
 	consumerURL := parseEnvString("CONSUMER_URL", "http://localhost:4000")
 	deliveryAttempts := parseEnvInt("DELIVERY_ATTEMPTS", 3)
+	webhookOrigin := parseWebhookOrigin()
+	consumerSecrets, err := parseEnvSecrets("CONSUMER_SECRETS")
+	if err != nil {
+		return Config{}, err
+	}
+	consumerFormat, err := event.ParseFormat(parseEnvString("CONSUMER_FORMAT", ""))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid CONSUMER_FORMAT: %w", err)
+	}
+	schemaValidation := parseEnvBool("SCHEMA_VALIDATION", false)
+	schemaFile := parseEnvString("SCHEMA_FILE", "")
+	schemaCompatibility, err := event.ParseCompatibility(parseEnvString("SCHEMA_COMPATIBILITY", "backward"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid SCHEMA_COMPATIBILITY: %w", err)
+	}
 
 	return Config{
 		Port:             port,
 		Capacity:         capacity,
 		ConsumerURL:      consumerURL,
 		DeliveryAttempts: deliveryAttempts,
+		WebhookOrigin:    webhookOrigin,
+		ConsumerSecrets:  consumerSecrets,
+		ConsumerFormat:   consumerFormat,
+		SchemaValidation: schemaValidation,
+		SchemaFile:       schemaFile,
+
+		SchemaCompatibility: schemaCompatibility,
+	}, nil
+}
+
+// parseWebhookOrigin returns the origin announced in the webhook validation handshake.
+// WEBHOOK_ORIGIN defaults to the host name; WEBHOOK_HANDSHAKE=false disables the handshake.
+func parseWebhookOrigin() string {
+	if !parseEnvBool("WEBHOOK_HANDSHAKE", true) {
+		return ""
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
 	}
+
+	return strings.TrimSpace(parseEnvString("WEBHOOK_ORIGIN", hostname))
 }
 
 // parseEnvString reads an environment variable by name and returns its value, or the provided default if unset.
@@ -40,6 +89,40 @@ func parseEnvString(name, defaultValue string) string {
 	return v
 }
 
+// parseEnvSecrets reads an environment variable by name and splits it into at most two "|"-separated secrets.
+// It returns an error if more than two secrets are given.
+func parseEnvSecrets(name string) ([]string, error) {
+	secrets := []string{}
+	for secret := range strings.SplitSeq(os.Getenv(name), "|") {
+		if trimmed := strings.TrimSpace(secret); trimmed != "" {
+			secrets = append(secrets, trimmed)
+		}
+	}
+
+	if len(secrets) > 2 {
+		return nil, fmt.Errorf("%s has more than two secrets", name)
+	}
+
+	return secrets, nil
+}
+
+// parseEnvBool reads an environment variable by name and converts it to bool.
+// If conversion fails, logs an error and returns the provided default value.
+func parseEnvBool(name string, defaultValue bool) bool {
+	v := os.Getenv(name)
+	if v == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid %s value: %v\n", name, err)
+		return defaultValue
+	}
+
+	return b
+}
+
 // parseEnvInt reads an environment variable by name and converts it to int.
 // If conversion fails, logs an error and returns the provided default value.
 func parseEnvInt(name string, defaultValue int) int {
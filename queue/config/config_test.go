@@ -2,13 +2,19 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/nicograef/cloudevents/event"
 )
 
 func TestLoad_Defaults(t *testing.T) {
 	os.Clearenv()
 
-	cfg := Load()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
 
 	if cfg.Port != 3000 {
 		t.Errorf("expected default port 3000, got %d", cfg.Port)
@@ -40,7 +46,10 @@ func TestLoad_EnvValues(t *testing.T) {
 		t.Fatalf("Failed to set DELIVERY_ATTEMPTS: %v", err)
 	}
 
-	cfg := Load()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
 
 	if cfg.Port != 8080 {
 		t.Errorf("expected port 8080, got %d", cfg.Port)
@@ -72,7 +81,10 @@ func TestLoad_InvalidIntAndLowValues(t *testing.T) {
 		t.Fatalf("Failed to set CONSUMER_URL: %v", err)
 	}
 
-	cfg := Load()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
 
 	if cfg.Port != 3000 {
 		t.Errorf("expected fallback port 3000, got %d", cfg.Port)
@@ -101,7 +113,10 @@ func TestLoad_NegativeValues(t *testing.T) {
 		t.Fatalf("Failed to set DELIVERY_ATTEMPTS: %v", err)
 	}
 
-	cfg := Load()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
 
 	// Should fallback to defaults due to validation (must be at least 1)
 	if cfg.Port != 3000 {
@@ -128,7 +143,10 @@ func TestLoad_ValidEdgeCases(t *testing.T) {
 		t.Fatalf("Failed to set DELIVERY_ATTEMPTS: %v", err)
 	}
 
-	cfg := Load()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
 
 	if cfg.Port != 1 {
 		t.Errorf("expected port 1, got %d", cfg.Port)
@@ -157,7 +175,10 @@ func TestLoad_HighValues(t *testing.T) {
 		t.Fatalf("Failed to set CONSUMER_URL: %v", err)
 	}
 
-	cfg := Load()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
 
 	if cfg.Port != 65535 {
 		t.Errorf("expected port 65535, got %d", cfg.Port)
@@ -172,3 +193,134 @@ func TestLoad_HighValues(t *testing.T) {
 		t.Errorf("expected long consumer URL, got %s", cfg.ConsumerURL)
 	}
 }
+
+func TestLoad_WebhookOrigin(t *testing.T) {
+	os.Clearenv()
+
+	if err := os.Setenv("WEBHOOK_ORIGIN", "queue.example.com"); err != nil {
+		t.Fatalf("Failed to set WEBHOOK_ORIGIN: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if cfg.WebhookOrigin != "queue.example.com" {
+		t.Errorf("expected webhook origin 'queue.example.com', got %q", cfg.WebhookOrigin)
+	}
+}
+
+func TestLoad_WebhookOriginDefault(t *testing.T) {
+	os.Clearenv()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+	if cfg.WebhookOrigin != hostname {
+		t.Errorf("expected webhook origin %q, got %q", hostname, cfg.WebhookOrigin)
+	}
+}
+
+func TestLoad_WebhookHandshakeDisabled(t *testing.T) {
+	os.Clearenv()
+
+	if err := os.Setenv("WEBHOOK_HANDSHAKE", "false"); err != nil {
+		t.Fatalf("Failed to set WEBHOOK_HANDSHAKE: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if cfg.WebhookOrigin != "" {
+		t.Errorf("expected handshake to be disabled, got webhook origin %q", cfg.WebhookOrigin)
+	}
+}
+
+func TestLoad_ConsumerSecrets(t *testing.T) {
+	os.Clearenv()
+
+	if err := os.Setenv("CONSUMER_SECRETS", "new-secret | old-secret"); err != nil {
+		t.Fatalf("Failed to set CONSUMER_SECRETS: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if len(cfg.ConsumerSecrets) != 2 || cfg.ConsumerSecrets[0] != "new-secret" || cfg.ConsumerSecrets[1] != "old-secret" {
+		t.Errorf("expected both consumer secrets, got %v", cfg.ConsumerSecrets)
+	}
+
+	if err := os.Setenv("CONSUMER_SECRETS", "new-secret | old-secret | third-secret"); err != nil {
+		t.Fatalf("Failed to set CONSUMER_SECRETS: %v", err)
+	}
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "CONSUMER_SECRETS") {
+		t.Fatalf("expected error about CONSUMER_SECRETS, got %v", err)
+	}
+}
+
+func TestLoad_ConsumerFormat(t *testing.T) {
+	os.Clearenv()
+
+	if cfg, err := Load(); err != nil || cfg.ConsumerFormat != "" {
+		t.Errorf("expected no default consumer format, got %q", cfg.ConsumerFormat)
+	}
+
+	if err := os.Setenv("CONSUMER_FORMAT", " application/cloudevents+avro "); err != nil {
+		t.Fatalf("Failed to set CONSUMER_FORMAT: %v", err)
+	}
+
+	if cfg, err := Load(); err != nil || cfg.ConsumerFormat != event.ContentTypeAvro {
+		t.Errorf("expected consumer format %s, got %q", event.ContentTypeAvro, cfg.ConsumerFormat)
+	}
+
+	if err := os.Setenv("CONSUMER_FORMAT", "application/xml"); err != nil {
+		t.Fatalf("Failed to set CONSUMER_FORMAT: %v", err)
+	}
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "CONSUMER_FORMAT") {
+		t.Errorf("expected error about CONSUMER_FORMAT, got %v", err)
+	}
+}
+
+func TestLoad_SchemaValidation(t *testing.T) {
+	os.Clearenv()
+
+	if cfg, err := Load(); err != nil || cfg.SchemaValidation || cfg.SchemaFile != "" || cfg.SchemaCompatibility != event.CompatibilityBackward {
+		t.Errorf("expected schema validation to be disabled and backward compatibility by default, got %+v", cfg)
+	}
+
+	if err := os.Setenv("SCHEMA_VALIDATION", "true"); err != nil {
+		t.Fatalf("Failed to set SCHEMA_VALIDATION: %v", err)
+	}
+	if err := os.Setenv("SCHEMA_FILE", "/data/schemas.json"); err != nil {
+		t.Fatalf("Failed to set SCHEMA_FILE: %v", err)
+	}
+
+	if err := os.Setenv("SCHEMA_COMPATIBILITY", "none"); err != nil {
+		t.Fatalf("Failed to set SCHEMA_COMPATIBILITY: %v", err)
+	}
+
+	if cfg, err := Load(); err != nil || !cfg.SchemaValidation || cfg.SchemaFile != "/data/schemas.json" || cfg.SchemaCompatibility != event.CompatibilityNone {
+		t.Errorf("expected schema validation with /data/schemas.json and no compatibility checks, got %+v", cfg)
+	}
+
+	if err := os.Setenv("SCHEMA_COMPATIBILITY", "sideways"); err != nil {
+		t.Fatalf("Failed to set SCHEMA_COMPATIBILITY: %v", err)
+	}
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "SCHEMA_COMPATIBILITY") {
+		t.Errorf("expected error about SCHEMA_COMPATIBILITY, got %v", err)
+	}
+}
@@ -5,3 +5,5 @@ go 1.24.5
 require github.com/nicograef/cloudevents/event v0.0.0-20250915202856-8554513e6fc0
 
 require github.com/google/uuid v1.6.0 // indirect
+
+replace github.com/nicograef/cloudevents/event => ../event
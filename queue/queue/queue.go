@@ -1,7 +1,9 @@
 package queue
 
 import (
+	"errors"
 	"log"
+	"time"
 
 	"github.com/nicograef/cloudevents/event"
 )
@@ -20,13 +22,32 @@ func NewQueue(capacity int) Queue {
 	return Queue{Queue: make(chan QueueMessage, capacity), FailedQueue: []QueueMessage{}}
 }
 
+// Enqueue adds the message to the queue without blocking.
+// It returns an error if the queue is full.
+func (q *Queue) Enqueue(msg event.Event) error {
+	select {
+	case q.Queue <- QueueMessage{Message: msg, Attempts: 0}:
+		return nil
+	default:
+		return errors.New("queue is full")
+	}
+}
+
 // StartConsumer starts a goroutine that reads from the queue and calls the webhook for each message.
 // It takes the queue, consumerURL, and a WaitGroup pointer.
 // SendFunc defines the signature for sending a message to a webhook.
 type SendFunc func(url string, msg event.Event) (string, error)
 
+// HandleQueueItem sends the message to the consumer and re-enqueues it if sending fails, up to three attempts.
+// Messages over the allowed rate of the consumer are held back until the consumer accepts them, without
+// counting as a failed attempt.
 func (q *Queue) HandleQueueItem(item QueueMessage, consumerURL string, sendFunc SendFunc) {
 	resp, err := sendFunc(consumerURL, item.Message)
+	var rateLimit *event.RateLimitError
+	for errors.As(err, &rateLimit) {
+		time.Sleep(rateLimit.RetryAfter)
+		resp, err = sendFunc(consumerURL, item.Message)
+	}
 
 	if err != nil {
 		log.Printf("Error sending to webhook: %v", err)
@@ -2,6 +2,7 @@ package queue
 
 import (
 	"testing"
+	"time"
 
 	"github.com/nicograef/cloudevents/event"
 )
@@ -28,6 +29,26 @@ func TestHandleQueueItem_Success(t *testing.T) {
 	}
 }
 
+func TestHandleQueueItem_RateLimited(t *testing.T) {
+	q := NewQueue(1)
+	calls := 0
+	sendFunc := func(url string, msg event.Event) (string, error) {
+		calls++
+		if calls < 3 {
+			return "", &event.RateLimitError{URL: url, RetryAfter: time.Millisecond}
+		}
+		return "ok", nil
+	}
+
+	q.HandleQueueItem(QueueMessage{Message: event.Event{Type: "limited"}}, "http://test", sendFunc)
+	if calls != 3 {
+		t.Errorf("expected the message to be sent once the rate allows it, got %d calls", calls)
+	}
+	if len(q.Queue) != 0 || len(q.FailedQueue) != 0 {
+		t.Errorf("expected rate limited message not to count as a failed attempt")
+	}
+}
+
 func TestHandleQueueItem_RetryAndMaxAttempts(t *testing.T) {
 	q := NewQueue(1)
 	attempts := 0
@@ -65,6 +86,22 @@ func TestHandleQueueItem_RetryAndMaxAttempts(t *testing.T) {
 	}
 }
 
+func TestEnqueue(t *testing.T) {
+	q := NewQueue(1)
+
+	if err := q.Enqueue(event.Event{Type: "first"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := q.Enqueue(event.Event{Type: "second"}); err == nil {
+		t.Errorf("expected error when the queue is full")
+	}
+
+	item := <-q.Queue
+	if item.Message.Type != "first" || item.Attempts != 0 {
+		t.Errorf("unexpected queue item: %+v", item)
+	}
+}
+
 // assertError is a helper to create a test error
 func assertError(msg string) error {
 	return &testError{msg}
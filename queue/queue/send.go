@@ -2,31 +2,63 @@ package queue
 
 import (
 	"bytes"
-	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/nicograef/cloudevents/event"
 )
 
-// SendToWebhook posts the message to the consumer webhook and returns the response body or error
+// SendToWebhook posts the message to the consumer webhook and returns the response body.
+// It returns an error if the request fails or the consumer responds with a non-2xx status.
 func SendToWebhook(url string, msg event.Event) (string, error) {
-	importBytes, err := json.Marshal(msg)
+	return sendToWebhook(url, msg, nil, "")
+}
+
+// NewSignedSendToWebhook returns a SendFunc that posts the message like SendToWebhook, in the given event format,
+// and signs the request with the given consumer secrets.
+func NewSignedSendToWebhook(secrets []string, format string) SendFunc {
+	return func(url string, msg event.Event) (string, error) {
+		return sendToWebhook(url, msg, secrets, format)
+	}
+}
+
+// sendToWebhook posts the message to the webhook in the given format, signing it if any secrets are given.
+// Without a format, JSON data is sent as a JSON event, binary and text data as the body with the attributes
+// in Ce- headers.
+func sendToWebhook(url string, msg event.Event, secrets []string, format string) (string, error) {
+	header, body, err := event.EncodeHTTPFormat(msg, format)
 	if err != nil {
 		return "", err
 	}
 
-	resp, err := http.Post(url, "application/json", bytes.NewReader(importBytes))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if len(secrets) > 0 {
+		req.Header.Set(event.SignatureHeader, event.Sign(event.SignedContent(req.Header, body), time.Now(), secrets...))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return "", err
 	}
 
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", err
 	}
 
-	return string(body), nil
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("webhook %s returned status %d", url, resp.StatusCode)
+	}
+
+	return string(respBody), nil
 }
@@ -1,6 +1,7 @@
 package queue
 
 import (
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -8,6 +9,35 @@ import (
 	"github.com/nicograef/cloudevents/event"
 )
 
+func TestNewSignedSendToWebhook_Format(t *testing.T) {
+	var contentType string
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		contentType = r.Header.Get("Content-Type")
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	e, err := event.New(event.Candidate{Type: "com.example.event:v1", Source: "https://example.com", Subject: "/users/123", Data: map[string]any{"k": "v"}})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+
+	send := NewSignedSendToWebhook(nil, event.ContentTypeAvro)
+	if _, err := send(ts.URL, *e); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if contentType != event.ContentTypeAvro {
+		t.Errorf("expected Content-Type %s, got %s", event.ContentTypeAvro, contentType)
+	}
+	decoded, err := event.UnmarshalAvro(body)
+	if err != nil || decoded.ID != e.ID || decoded.Data.(map[string]any)["k"] != "v" {
+		t.Errorf("expected the event in the Avro format, got %+v, %v", decoded, err)
+	}
+}
+
 func TestSendToWebhook_Success(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("ok"))
@@ -24,6 +54,47 @@ func TestSendToWebhook_Success(t *testing.T) {
 	}
 }
 
+func TestSendToWebhook_ContentType(t *testing.T) {
+	var contentTypes []string
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	xml := event.Event{Type: "test", DataContentType: "application/xml", Data: "<book/>"}
+	png := event.Event{Type: "test", DataContentType: "image/png", Data: []byte{0x89, 0x50}}
+	for _, e := range []event.Event{{Type: "test", Data: map[string]any{"k": "v"}}, xml, png} {
+		if _, err := SendToWebhook(ts.URL, e); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	if contentTypes[0] != "application/json" || bodies[0][0] != '{' {
+		t.Errorf("expected a JSON event, got %s %s", contentTypes[0], bodies[0])
+	}
+	if contentTypes[1] != "application/xml" || bodies[1] != "<book/>" {
+		t.Errorf("expected the XML data, got %s %s", contentTypes[1], bodies[1])
+	}
+	if contentTypes[2] != "image/png" || bodies[2] != "\x89P" {
+		t.Errorf("expected the binary data, got %s %q", contentTypes[2], bodies[2])
+	}
+}
+
+func TestSendToWebhook_ErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	if _, err := SendToWebhook(ts.URL, event.Event{Type: "test"}); err == nil {
+		t.Error("expected error for status 500")
+	}
+}
+
 func TestSendToWebhook_BadURL(t *testing.T) {
 	msg := event.Event{Type: "test"}
 	_, err := SendToWebhook("http://bad url", msg)
@@ -72,3 +143,25 @@ func TestSendToWebhook_InvalidResponseBody(t *testing.T) {
 		t.Errorf("expected empty response body on server error, got %s", resp)
 	}
 }
+
+func TestNewSignedSendToWebhook(t *testing.T) {
+	var header string
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get(event.SignatureHeader)
+		body, _ = io.ReadAll(r.Body)
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	send := NewSignedSendToWebhook([]string{"new-secret", "old-secret"}, "")
+	if _, err := send(ts.URL, event.Event{Type: "test"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, secret := range []string{"new-secret", "old-secret"} {
+		if err := event.VerifySignature(header, body, event.DefaultSignatureTolerance, secret); err != nil {
+			t.Errorf("expected signature to verify with %s, got %v", secret, err)
+		}
+	}
+}